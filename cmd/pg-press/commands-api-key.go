@@ -2,21 +2,39 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
 
-	"github.com/SuperPaintman/nice/cli"
+	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
-	"github.com/williepotgieter/keymaker"
+	"github.com/knackwurstking/pg-press/internal/shared"
+
+	"github.com/SuperPaintman/nice/cli"
 )
 
 func apiKeyCommand() cli.Command {
 	return cli.Command{
 		Name:  "api-key",
-		Usage: cli.Usage("Generating a new Api Key"),
+		Usage: cli.Usage("Handle the (hashed) api keys of users, generate, add, list or revoke keys"),
+		Commands: []cli.Command{
+			generateApiKeyCommand(),
+			listApiKeysCommand(),
+			addApiKeyCommand(),
+			revokeApiKeyCommand(),
+		},
+	}
+}
+
+func generateApiKeyCommand() cli.Command {
+	return cli.Command{
+		Name:  "generate",
+		Usage: cli.Usage("Generating a new Api Key without storing it"),
 		Action: cli.ActionFunc(func(cmd *cli.Command) cli.ActionRunner {
 			return func(cmd *cli.Command) error {
-				apiKey, err := keymaker.NewApiKey("pgp", 32)
+				apiKey, err := shared.GenerateApiKey()
 				if err != nil {
-					return errors.Wrap(err, "generate api key")
+					return err
 				}
 
 				fmt.Print(apiKey) // Yes, no newline at the end
@@ -25,3 +43,144 @@ func apiKeyCommand() cli.Command {
 		}),
 	}
 }
+
+func listApiKeysCommand() cli.Command {
+	return cli.Command{
+		Name:  "list",
+		Usage: cli.Usage("List the api keys of all users or of one user"),
+		Action: cli.ActionFunc(func(cmd *cli.Command) cli.ActionRunner {
+			customDBPath := createDBPathOption(cmd)
+			telegramIDArg := cli.Int64Arg(cmd, "telegram-id", cli.Optional)
+
+			return func(cmd *cli.Command) error {
+				return withDBOperation(*customDBPath, false, func(store *db.Store) error {
+					var (
+						keys []*shared.ApiKey
+						merr *errors.HTTPError
+					)
+					if *telegramIDArg != 0 {
+						keys, merr = store.ListApiKeysByUserID(shared.TelegramID(*telegramIDArg))
+					} else {
+						keys, merr = store.ListApiKeys()
+					}
+					if merr != nil {
+						return merr.Wrap("list api keys")
+					}
+
+					printApiKeys(keys)
+					return nil
+				})
+			}
+		}),
+	}
+}
+
+func addApiKeyCommand() cli.Command {
+	return cli.Command{
+		Name:  "add",
+		Usage: cli.Usage("Generate a new named api key for a user, the key is printed once"),
+		Action: cli.ActionFunc(func(cmd *cli.Command) cli.ActionRunner {
+			customDBPath := createDBPathOption(cmd)
+			name := cli.String(cmd, "name",
+				cli.WithShort("n"),
+				cli.Usage("Where the key is used, e.g. \"Tablet Presse 3\""),
+				cli.Required)
+			expires := cli.String(cmd, "expires",
+				cli.Usage("Last day the key is valid (YYYY-MM-DD), no expiry if empty"),
+				cli.Optional)
+			telegramIDArg := cli.Int64Arg(cmd, "telegram-id", cli.Required)
+
+			return func(cmd *cli.Command) error {
+				var expiresAt shared.UnixMilli
+				if *expires != "" {
+					t, err := time.ParseInLocation("2006-01-02", *expires, time.Local)
+					if err != nil {
+						return fmt.Errorf("invalid expiry date %q: %v", *expires, err)
+					}
+					// Keys are valid until the end of the day
+					expiresAt = shared.NewUnixMilli(t.AddDate(0, 0, 1).Add(-time.Millisecond))
+				}
+
+				return withDBOperation(*customDBPath, false, func(store *db.Store) error {
+					apiKey, err := addApiKey(store, shared.TelegramID(*telegramIDArg), *name, expiresAt)
+					if err != nil {
+						return err
+					}
+
+					fmt.Print(apiKey) // Yes, no newline at the end
+					return nil
+				})
+			}
+		}),
+	}
+}
+
+func revokeApiKeyCommand() cli.Command {
+	return cli.Command{
+		Name:  "revoke",
+		Usage: cli.Usage("Revoke (delete) an api key by its ID"),
+		Action: cli.ActionFunc(func(cmd *cli.Command) cli.ActionRunner {
+			customDBPath := createDBPathOption(cmd)
+			idArg := cli.Int64Arg(cmd, "id", cli.Required)
+
+			return func(cmd *cli.Command) error {
+				return withDBOperation(*customDBPath, false, func(store *db.Store) error {
+					if merr := store.DeleteApiKey(shared.EntityID(*idArg)); merr != nil {
+						return merr.Wrap("revoke api key %d", *idArg)
+					}
+					return nil
+				})
+			}
+		}),
+	}
+}
+
+// addApiKey generates a new key, stores its hash for the user and returns the
+// key, which can't be read from the database afterwards
+func addApiKey(store *db.Store, userID shared.TelegramID, name string, expiresAt shared.UnixMilli) (string, error) {
+	if _, merr := store.GetUser(userID); merr != nil {
+		return "", merr.Wrap("get user")
+	}
+
+	apiKey, err := shared.GenerateApiKey()
+	if err != nil {
+		return "", err
+	}
+
+	key, err := shared.NewApiKey(userID, name, apiKey, expiresAt)
+	if err != nil {
+		return "", err
+	}
+
+	if merr := store.AddApiKey(key); merr != nil {
+		return "", merr.Wrap("add api key")
+	}
+
+	return apiKey, nil
+}
+
+func printApiKeys(keys []*shared.ApiKey) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintf(w, "ID\tUSER\tNAME\tPREFIX\tCREATED\tEXPIRES\tLAST USED\n")
+	fmt.Fprintf(w, "--\t----\t----\t------\t-------\t-------\t---------\n")
+	for _, k := range keys {
+		expires := "never"
+		if k.ExpiresAt > 0 {
+			expires = k.ExpiresAt.FormatDate()
+			if k.IsExpired() {
+				expires += " (expired)"
+			}
+		}
+
+		lastUsed := "never"
+		if k.LastUsed > 0 {
+			lastUsed = k.LastUsed.FormatDateTime()
+		}
+
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			k.ID, k.UserID, k.Name, k.Prefix,
+			k.CreatedAt.FormatDateTime(), expires, lastUsed)
+	}
+}
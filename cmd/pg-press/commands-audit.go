@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/knackwurstking/pg-press/internal/db"
+	"github.com/knackwurstking/pg-press/internal/shared"
+
+	"github.com/SuperPaintman/nice/cli"
+)
+
+func auditCommand() cli.Command {
+	return cli.Command{
+		Name:  "audit",
+		Usage: cli.Usage("Show the audit log, who changed what and when"),
+		Commands: []cli.Command{
+			listAuditCommand(),
+		},
+	}
+}
+
+func listAuditCommand() cli.Command {
+	return cli.Command{
+		Name:  "list",
+		Usage: cli.Usage("List the latest changes, optionally only for one entity and its children"),
+		Action: cli.ActionFunc(func(cmd *cli.Command) cli.ActionRunner {
+			customDBPath := createDBPathOption(cmd)
+			entity := cli.String(cmd, "entity",
+				cli.WithShort("e"),
+				cli.Usage("Entity reference, e.g. \"tool_12\" or \"press_5\""),
+				cli.Optional)
+			limit := cli.Int(cmd, "limit",
+				cli.WithShort("n"),
+				cli.Usage("Maximum number of entries, 0 for all (default 100)"),
+				cli.Optional)
+			*limit = 100
+
+			return func(cmd *cli.Command) error {
+				return withDBOperation(*customDBPath, false, func(store *db.Store) error {
+					entries, merr := store.ListAuditEntries(*entity, *limit)
+					if merr != nil {
+						return merr.Wrap("list audit entries")
+					}
+
+					userNames, merr := store.ListUserNames()
+					if merr != nil {
+						return merr.Wrap("list users")
+					}
+
+					w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+					defer w.Flush()
+
+					fmt.Fprintf(w, "ID\tTIME\tUSER\tACTION\tENTITY\tLINKED\tCHANGES\n")
+					fmt.Fprintf(w, "--\t----\t----\t------\t------\t------\t-------\n")
+					for _, e := range entries {
+						fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+							e.ID,
+							e.CreatedAt.FormatDateTime(),
+							auditUserName(e.UserID, userNames),
+							e.Action,
+							e.Entity(),
+							e.Linked,
+							auditChanges(e),
+						)
+					}
+
+					return nil
+				})
+			}
+		}),
+	}
+}
+
+// auditUserName formats the user of an audit entry like "Name (ID)"
+func auditUserName(id shared.TelegramID, userNames map[shared.TelegramID]string) string {
+	if id == 0 {
+		return "command line"
+	}
+	if name, ok := userNames[id]; ok {
+		return fmt.Sprintf("%s (%d)", name, id)
+	}
+	return id.String()
+}
+
+// auditChanges formats the changed fields of an update in a single line
+func auditChanges(e *shared.AuditEntry) string {
+	if e.Action != shared.AuditActionUpdate {
+		return ""
+	}
+
+	var changes []string
+	for _, c := range e.Changes() {
+		changes = append(changes, fmt.Sprintf("%s: %s -> %s", c.Field, c.Before, c.After))
+	}
+	return strings.Join(changes, ", ")
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/knackwurstking/pg-press/internal/env"
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/jobs"
+
+	"github.com/SuperPaintman/nice/cli"
+)
+
+func configCommand() cli.Command {
+	return cli.Command{
+		Name: "config",
+		Usage: cli.Usage(fmt.Sprintf(
+			"Handle the configuration file, show, validate or create it (%s)", env.ConfigFile)),
+		Commands: []cli.Command{
+			showConfigCommand(),
+			validateConfigCommand(),
+			initConfigCommand(),
+		},
+	}
+}
+
+func showConfigCommand() cli.Command {
+	return cli.Command{
+		Name:  "show",
+		Usage: cli.Usage("Print the resolved configuration, the file overridden by environment variables"),
+		Action: cli.ActionFunc(func(cmd *cli.Command) cli.ActionRunner {
+			return func(cmd *cli.Command) error {
+				data, err := json.MarshalIndent(env.Current, "", "  ")
+				if err != nil {
+					return errors.Wrap(err, "marshal config")
+				}
+
+				fmt.Println(string(data))
+				return nil
+			}
+		}),
+	}
+}
+
+func validateConfigCommand() cli.Command {
+	return cli.Command{
+		Name:  "validate",
+		Usage: cli.Usage("Validate the configuration file together with the environment variables"),
+		Action: cli.ActionFunc(func(cmd *cli.Command) cli.ActionRunner {
+			path := cli.StringArg(cmd, "path",
+				cli.Usage("Configuration file to validate, defaults to the configuration file in use"),
+				cli.Optional)
+
+			return func(cmd *cli.Command) error {
+				if *path == "" {
+					*path = env.ConfigFile
+				}
+
+				c, err := env.ResolveConfig(*path)
+				if err != nil {
+					return errors.Wrap(err, "invalid config %s", *path)
+				}
+
+				schedules := c.Jobs.Schedules()
+				for _, key := range slices.Sorted(maps.Keys(schedules)) {
+					schedule := schedules[key]
+					if strings.TrimSpace(schedule) == jobs.ScheduleOff {
+						continue
+					}
+					if _, err := jobs.ParseSchedule(schedule); err != nil {
+						return errors.Wrap(err, "invalid config %s: jobs.%s", *path, key)
+					}
+				}
+
+				if _, err := os.Stat(*path); os.IsNotExist(err) {
+					fmt.Printf("No config file at %s, defaults and environment variables are valid\n", *path)
+					return nil
+				}
+
+				fmt.Printf("Config %s is valid\n", *path)
+				return nil
+			}
+		}),
+	}
+}
+
+func initConfigCommand() cli.Command {
+	return cli.Command{
+		Name:  "init",
+		Usage: cli.Usage("Write a configuration file with the default values"),
+		Action: cli.ActionFunc(func(cmd *cli.Command) cli.ActionRunner {
+			force := cli.Bool(cmd, "force",
+				cli.Usage("Overwrite an existing configuration file"),
+				cli.Optional)
+			path := cli.StringArg(cmd, "path",
+				cli.Usage("Where to write the configuration file, defaults to the configuration file in use"),
+				cli.Optional)
+
+			return func(cmd *cli.Command) error {
+				if *path == "" {
+					*path = env.ConfigFile
+				}
+
+				if _, err := os.Stat(*path); err == nil && !*force {
+					return fmt.Errorf("config %s already exists, use --force to overwrite it", *path)
+				}
+
+				data, err := json.MarshalIndent(env.DefaultConfig(), "", "  ")
+				if err != nil {
+					return errors.Wrap(err, "marshal config")
+				}
+
+				if err := os.MkdirAll(filepath.Dir(*path), 0700); err != nil {
+					return errors.Wrap(err, "create config directory")
+				}
+				if err := os.WriteFile(*path, append(data, '\n'), 0600); err != nil {
+					return errors.Wrap(err, "write config")
+				}
+
+				fmt.Printf("Wrote default config to %s\n", *path)
+				return nil
+			}
+		}),
+	}
+}
@@ -25,9 +25,9 @@ func removeCookiesCommand() cli.Command {
 				cli.Required)
 
 			return func(cmd *cli.Command) error {
-				return withDBOperation(*customDBPath, false, func() error {
+				return withDBOperation(*customDBPath, false, func(store *db.Store) error {
 					if *useApiKey {
-						user, merr := db.GetUserByApiKey(*value)
+						user, merr := store.GetUserByApiKey(*value)
 						if merr != nil {
 							fmt.Fprintf(os.Stderr, "Failed to get user by api key: %v\n", merr)
 							if merr.IsNotFoundError() {
@@ -36,7 +36,7 @@ func removeCookiesCommand() cli.Command {
 							os.Exit(exitCodeGeneric)
 						}
 
-						merr = db.DeleteCookiesByUserID(user.ID)
+						merr = store.DeleteCookiesByUserID(user.ID)
 						if merr != nil {
 							fmt.Fprintf(os.Stderr, "Failed to remove cookies for user: %v\n", merr)
 							if merr.IsNotFoundError() {
@@ -46,7 +46,7 @@ func removeCookiesCommand() cli.Command {
 						}
 					}
 
-					merr := db.DeleteCookie(*value)
+					merr := store.DeleteCookie(*value)
 					if merr != nil {
 						fmt.Fprintf(os.Stderr, "Failed to remove cookie: %v\n", merr)
 						if merr.IsNotFoundError() {
@@ -70,32 +70,21 @@ func autoCleanCookiesCommand() cli.Command {
 			argTelegramIDArg := cli.Int64(cmd, "user", cli.WithShort("u"), cli.Optional)
 
 			return func(cmd *cli.Command) error {
-				return withDBOperation(*argCustomDBPath, false, func() error {
+				return withDBOperation(*argCustomDBPath, false, func(store *db.Store) error {
 					telegramID := shared.TelegramID(*argTelegramIDArg)
 
 					// Clean up cookies for a specific telegram user
 					if telegramID != 0 {
-						cleanUpCookiesForUser(telegramID)
+						cleanUpCookiesForUser(store, telegramID)
 						os.Exit(0)
 					}
 
 					// Clean up all cookies
-					cookies, merr := db.ListCookies()
-					if merr != nil {
-						fmt.Fprintf(os.Stderr, "List cookies from database failed: %v\n", merr)
+					if _, merr := store.DeleteExpiredCookies(); merr != nil {
+						fmt.Fprintf(os.Stderr, "Removing expired cookies failed: %v\n", merr)
 						os.Exit(exitCodeGeneric)
 					}
 
-					for _, c := range cookies {
-						if c.IsExpired() {
-							if merr = db.DeleteCookie(c.Value); merr != nil {
-								// Print out error and continue
-								fmt.Fprintf(os.Stderr, "Removing cookie with value \"%s\": %v\n", c.Value, merr)
-								os.Exit(exitCodeGeneric)
-							}
-						}
-					}
-
 					return nil
 				})
 			}
@@ -103,8 +92,8 @@ func autoCleanCookiesCommand() cli.Command {
 	}
 }
 
-func cleanUpCookiesForUser(telegramID shared.TelegramID) {
-	cookies, merr := db.ListCookiesByUserID(telegramID)
+func cleanUpCookiesForUser(store *db.Store, telegramID shared.TelegramID) {
+	cookies, merr := store.ListCookiesByUserID(telegramID)
 	if merr != nil {
 		fmt.Fprintf(os.Stderr, "Failed to get the cookies: %v\n", merr)
 		os.Exit(exitCodeGeneric)
@@ -116,7 +105,7 @@ func cleanUpCookiesForUser(telegramID shared.TelegramID) {
 		}
 
 		if c.IsExpired() {
-			if merr = db.DeleteCookie(c.Value); merr != nil {
+			if merr = store.DeleteCookie(c.Value); merr != nil {
 				// Print out error and continue
 				fmt.Fprintf(os.Stderr, "Removing cookie for user %d with value \"%s\": %v\n",
 					telegramID, c.Value, merr)
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/knackwurstking/pg-press/internal/backup"
+	"github.com/knackwurstking/pg-press/internal/db"
+	"github.com/knackwurstking/pg-press/internal/env"
+	"github.com/knackwurstking/pg-press/internal/errors"
+
+	"github.com/SuperPaintman/nice/cli"
+)
+
+func dbCommand() cli.Command {
+	return cli.Command{
+		Name:  "db",
+		Usage: cli.Usage("Database maintenance, run schema migrations, backup and restore"),
+		Commands: []cli.Command{
+			migrateDBCommand(),
+			backupDBCommand(),
+			restoreDBCommand(),
+			checkDBCommand(),
+		},
+	}
+}
+
+func migrateDBCommand() cli.Command {
+	return cli.Command{
+		Name:  "migrate",
+		Usage: cli.Usage("Apply all pending schema migrations to all databases"),
+		Action: cli.ActionFunc(func(cmd *cli.Command) cli.ActionRunner {
+			customDBPath := createDBPathOption(cmd)
+			dryRun := cli.Bool(cmd, "dry-run",
+				cli.Usage("Only list the pending migrations, do not apply them"),
+				cli.Optional)
+
+			return func(cmd *cli.Command) error {
+				store, err := db.Connect(*customDBPath, true)
+				if err != nil {
+					return errors.Wrap(err, "connect to databases")
+				}
+				defer store.Close()
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				defer w.Flush()
+
+				fmt.Fprintf(w, "DATABASE\tVERSION\tLATEST\n")
+				fmt.Fprintf(w, "--------\t-------\t------\n")
+				for _, name := range db.DatabaseNames() {
+					version, err := store.SchemaVersion(name)
+					if err != nil {
+						return errors.Wrap(err, "get schema version for %s database", name)
+					}
+					fmt.Fprintf(w, "%s\t%d\t%d\n", name, version, db.LatestSchemaVersion(name))
+				}
+				fmt.Fprintln(w)
+
+				migrations, err := store.Migrate(*dryRun)
+				if err != nil {
+					return errors.Wrap(err, "migrate databases")
+				}
+
+				if len(migrations) == 0 {
+					fmt.Fprintf(w, "All databases are up to date\n")
+					return nil
+				}
+
+				if *dryRun {
+					fmt.Fprintf(w, "Pending migrations:\n\n")
+				} else {
+					fmt.Fprintf(w, "Applied migrations:\n\n")
+				}
+				fmt.Fprintf(w, "DATABASE\tVERSION\tDESCRIPTION\n")
+				fmt.Fprintf(w, "--------\t-------\t-----------\n")
+				for _, m := range migrations {
+					fmt.Fprintf(w, "%s\t%d\t%s\n", m.Database, m.Version, m.Description)
+				}
+
+				return nil
+			}
+		}),
+	}
+}
+
+func backupDBCommand() cli.Command {
+	return cli.Command{
+		Name: "backup",
+		Usage: cli.Usage(
+			"Write a snapshot of all databases and images into a tar.gz archive"),
+		Action: cli.ActionFunc(func(cmd *cli.Command) cli.ActionRunner {
+			customDBPath := createDBPathOption(cmd)
+			target := cli.StringArg(cmd, "target",
+				cli.Usage("Directory for a new timestamped archive, or the archive path"),
+				cli.Required)
+
+			return func(cmd *cli.Command) error {
+				// The snapshot keeps the schema version, a restored backup is
+				// migrated like any other database. It locks all database files
+				// while copying, so it is consistent also while the server runs.
+				return withConnectedDBOperation(*customDBPath, func(store *db.Store) error {
+					archivePath, err := backup.Create(store, *target, env.ServerPathImages)
+					if err != nil {
+						return errors.Wrap(err, "create backup")
+					}
+
+					fmt.Println(archivePath)
+					return nil
+				})
+			}
+		}),
+	}
+}
+
+func restoreDBCommand() cli.Command {
+	return cli.Command{
+		Name: "restore",
+		Usage: cli.Usage(
+			"Restore all databases and images from a backup archive"),
+		Action: cli.ActionFunc(func(cmd *cli.Command) cli.ActionRunner {
+			customDBPath := createDBPathOption(cmd)
+			force := cli.Bool(cmd, "force",
+				cli.Usage("Overwrite existing databases and images, stop the server first"),
+				cli.Optional)
+			archive := cli.StringArg(cmd, "archive",
+				cli.Usage("The backup archive to restore"),
+				cli.Required)
+
+			return func(cmd *cli.Command) error {
+				manifest, err := backup.Restore(
+					*archive, *customDBPath, env.ServerPathImages, *force,
+				)
+				if err != nil {
+					return errors.Wrap(err, "restore backup")
+				}
+
+				fmt.Printf("Restored %d files from backup created at %s (%s)\n",
+					len(manifest.Files),
+					manifest.CreatedAt.Format("2006-01-02 15:04:05"),
+					manifest.Version,
+				)
+
+				return withConnectedDBOperation(*customDBPath, func(store *db.Store) error {
+					pending, err := store.Migrate(true)
+					if err != nil {
+						return errors.Wrap(err, "check restored databases")
+					}
+					if len(pending) > 0 {
+						fmt.Printf("The restored databases have %d pending migrations, run \"pg-press db migrate\"\n",
+							len(pending))
+					}
+					return nil
+				})
+			}
+		}),
+	}
+}
+
+func checkDBCommand() cli.Command {
+	return cli.Command{
+		Name: "check",
+		Usage: cli.Usage(
+			"Report references to tools, presses and users which do not exist anymore"),
+		Action: cli.ActionFunc(func(cmd *cli.Command) cli.ActionRunner {
+			customDBPath := createDBPathOption(cmd)
+			fix := cli.Bool(cmd, "fix",
+				cli.Usage("Ask how to fix the dangling references, for each reference"),
+				cli.Optional)
+
+			return func(cmd *cli.Command) error {
+				return withDBOperation(*customDBPath, false, func(store *db.Store) error {
+					orphans, err := store.Check()
+					if err != nil {
+						return errors.Wrap(err, "check databases")
+					}
+
+					if len(orphans) == 0 {
+						fmt.Println("No dangling references found")
+						return nil
+					}
+
+					w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+					fmt.Fprintf(w, "REFERENCE\tROW ID\tVALUE\n")
+					fmt.Fprintf(w, "---------\t------\t-----\n")
+					for _, o := range orphans {
+						fmt.Fprintf(w, "%s\t%d\t%s\n", o.Reference, o.RowID, o.Value)
+					}
+					w.Flush()
+
+					if !*fix {
+						return fmt.Errorf("found %d dangling references, run with --fix to repair them", len(orphans))
+					}
+
+					return fixOrphans(store, orphans)
+				})
+			}
+		}),
+	}
+}
+
+// fixOrphans asks for each reference how to fix its orphans.
+func fixOrphans(store *db.Store, orphans []*db.Orphan) error {
+	in := bufio.NewReader(os.Stdin)
+	ask := func(question string) (string, error) {
+		fmt.Print(question)
+		answer, err := in.ReadString('\n')
+		return strings.TrimSpace(answer), err
+	}
+
+	// Group by reference, keeping the order from Check
+	var groups [][]*db.Orphan
+	for _, o := range orphans {
+		if n := len(groups); n > 0 && groups[n-1][0].Reference == o.Reference {
+			groups[n-1] = append(groups[n-1], o)
+			continue
+		}
+		groups = append(groups, []*db.Orphan{o})
+	}
+
+	for _, group := range groups {
+		ref := group[0].Reference
+
+		var options []string
+		for _, a := range ref.Actions() {
+			options = append(options, fmt.Sprintf("[%c]%s", a[0], a[1:]))
+		}
+
+		answer, err := ask(fmt.Sprintf("\n%s: %d dangling references\n%s or [s]kip? ",
+			ref, len(group), strings.Join(options, ", ")))
+		if err != nil {
+			return errors.Wrap(err, "read answer")
+		}
+
+		idx := slices.IndexFunc(ref.Actions(), func(a db.FixAction) bool {
+			return answer != "" && strings.HasPrefix(string(a), strings.ToLower(answer))
+		})
+		if idx < 0 {
+			fmt.Println("Skipped")
+			continue
+		}
+		action := ref.Actions()[idx]
+
+		var target int64
+		if action == db.FixReassign {
+			answer, err = ask(fmt.Sprintf("Reassign to %s ID: ", ref.TargetTable))
+			if err != nil {
+				return errors.Wrap(err, "read answer")
+			}
+			if target, err = strconv.ParseInt(answer, 10, 64); err != nil {
+				return errors.Wrap(err, "parse %s ID", ref.TargetTable)
+			}
+		}
+
+		if err = store.FixOrphans(group, action, target); err != nil {
+			return errors.Wrap(err, "fix %s", ref)
+		}
+		fmt.Printf("Fixed %d rows (%s)\n", len(group), action)
+	}
+
+	return nil
+}
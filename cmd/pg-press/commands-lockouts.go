@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/knackwurstking/pg-press/internal/db"
+
+	"github.com/SuperPaintman/nice/cli"
+)
+
+func lockoutsCommand() cli.Command {
+	return cli.Command{
+		Name:  "lockouts",
+		Usage: cli.Usage("Handle the login lockouts after failed logins, list or clear lockouts"),
+		Commands: []cli.Command{
+			listLockoutsCommand(),
+			clearLockoutCommand(),
+			clearAllLockoutsCommand(),
+		},
+	}
+}
+
+func listLockoutsCommand() cli.Command {
+	return cli.Command{
+		Name:  "list",
+		Usage: cli.Usage("List all client IPs and api key prefixes with failed logins, locked first"),
+		Action: cli.ActionFunc(func(cmd *cli.Command) cli.ActionRunner {
+			customDBPath := createDBPathOption(cmd)
+
+			return func(cmd *cli.Command) error {
+				return withDBOperation(*customDBPath, false, func(store *db.Store) error {
+					lockouts, merr := store.ListLockouts()
+					if merr != nil {
+						return merr.Wrap("list lockouts")
+					}
+
+					w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+					defer w.Flush()
+
+					fmt.Fprintf(w, "KEY\tFAILURES\tLAST FAILURE\tLOCKED UNTIL\n")
+					fmt.Fprintf(w, "---\t--------\t------------\t------------\n")
+					for _, l := range lockouts {
+						lockedUntil := "-"
+						if l.IsLocked() {
+							lockedUntil = l.LockedUntil.FormatDateTime()
+						}
+
+						fmt.Fprintf(w, "%s\t%d\t%s\t%s\n",
+							l.Key, l.Failures, l.LastFailure.FormatDateTime(), lockedUntil)
+					}
+
+					return nil
+				})
+			}
+		}),
+	}
+}
+
+func clearLockoutCommand() cli.Command {
+	return cli.Command{
+		Name:  "clear",
+		Usage: cli.Usage("Clear the lockout and the failed logins of a client IP or an api key prefix"),
+		Action: cli.ActionFunc(func(cmd *cli.Command) cli.ActionRunner {
+			customDBPath := createDBPathOption(cmd)
+			key := cli.StringArg(cmd, "key", cli.Required,
+				cli.Usage("Lockout key as listed, or a plain IP or api key prefix"))
+
+			return func(cmd *cli.Command) error {
+				return withDBOperation(*customDBPath, false, func(store *db.Store) error {
+					if merr := store.ClearLockout(*key); merr != nil {
+						return merr.Wrap("clear lockout")
+					}
+					return nil
+				})
+			}
+		}),
+	}
+}
+
+func clearAllLockoutsCommand() cli.Command {
+	return cli.Command{
+		Name:  "clear-all",
+		Usage: cli.Usage("Clear all lockouts and failed logins"),
+		Action: cli.ActionFunc(func(cmd *cli.Command) cli.ActionRunner {
+			customDBPath := createDBPathOption(cmd)
+
+			return func(cmd *cli.Command) error {
+				return withDBOperation(*customDBPath, false, func(store *db.Store) error {
+					n, merr := store.ClearAllLockouts()
+					if merr != nil {
+						return merr.Wrap("clear lockouts")
+					}
+
+					fmt.Printf("Cleared %d lockouts\n", n)
+					return nil
+				})
+			}
+		}),
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/knackwurstking/pg-press/internal/db"
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/pdf"
+	"github.com/knackwurstking/pg-press/internal/shared"
+
+	"github.com/SuperPaintman/nice/cli"
+)
+
+func pressCommand() cli.Command {
+	return cli.Command{
+		Name:  "press",
+		Usage: cli.Usage("Handle presses, export press cycle summaries"),
+		Commands: []cli.Command{
+			cycleSummaryCommand(),
+		},
+	}
+}
+
+func cycleSummaryCommand() cli.Command {
+	return cli.Command{
+		Name:  "cycle-summary",
+		Usage: cli.Usage("Write the cycle summary PDF of a press by ID"),
+		Action: cli.ActionFunc(func(cmd *cli.Command) cli.ActionRunner {
+			customDBPath := createDBPathOption(cmd)
+			output := cli.String(cmd, "output",
+				cli.WithShort("o"),
+				cli.Usage("Output file (default \"press_<id>_cycle_summary.pdf\")"),
+				cli.Optional)
+			from := cli.String(cmd, "from",
+				cli.Usage("First day to include (YYYY-MM-DD)"),
+				cli.Optional)
+			to := cli.String(cmd, "to",
+				cli.Usage("Last day to include (YYYY-MM-DD)"),
+				cli.Optional)
+			tools := cli.String(cmd, "tools",
+				cli.Usage("Only include these tool IDs (e.g., '5,7,9')"),
+				cli.Optional)
+			pressIDArg := cli.Int64Arg(cmd, "press-id", cli.Required)
+
+			return func(cmd *cli.Command) error {
+				var toolIDs []shared.EntityID
+				if *tools != "" {
+					var err error
+					toolIDs, err = parseIDList(*tools)
+					if err != nil {
+						return errors.Wrap(err, "parse tool IDs")
+					}
+				}
+
+				filter, err := shared.NewCycleSummaryFilter(*from, *to, toolIDs)
+				if err != nil {
+					return err
+				}
+
+				if *output == "" {
+					*output = fmt.Sprintf("press_%d_cycle_summary.pdf", *pressIDArg)
+				}
+
+				return withDBOperation(*customDBPath, false, func(store *db.Store) error {
+					summary, merr := store.GetCycleSummary(shared.EntityID(*pressIDArg), filter)
+					if merr != nil {
+						return merr.Wrap("get cycle summary for press %d", *pressIDArg)
+					}
+
+					buf, err := pdf.GenerateCycleSummaryPDF(summary)
+					if err != nil {
+						return errors.Wrap(err, "generate PDF")
+					}
+
+					if err := os.WriteFile(*output, buf.Bytes(), 0644); err != nil {
+						return errors.Wrap(err, "write %s", *output)
+					}
+
+					fmt.Printf("Cycle summary for press %d written to %s\n", summary.Press.Number, *output)
+					return nil
+				})
+			}
+		}),
+	}
+}
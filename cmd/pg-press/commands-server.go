@@ -6,12 +6,23 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/knackwurstking/pg-press/internal/assets"
+	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/env"
+	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/handlers"
+	"github.com/knackwurstking/pg-press/internal/handlers/health"
+	"github.com/knackwurstking/pg-press/internal/jobs"
+	"github.com/knackwurstking/pg-press/internal/pdf"
 
 	"github.com/SuperPaintman/nice/cli"
 	"github.com/knackwurstking/ui"
@@ -19,6 +30,9 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 )
 
+// shutdownTimeout limits the graceful shutdown, see shutdownServer
+const shutdownTimeout = 30 * time.Second
+
 // serverCommand creates the CLI command for starting the HTTP server.
 func serverCommand() cli.Command {
 	return cli.Command{
@@ -31,18 +45,58 @@ func serverCommand() cli.Command {
 				cli.WithShort("a"),
 				cli.Usage("Set server address in format <host>:<port> (e.g., localhost:8080)"))
 
+			_ = cli.StringVar(cmd, &env.ServerTLSCert, "tls-cert",
+				cli.Usage("TLS certificate file, serves HTTPS together with --tls-key"),
+				cli.Optional)
+
+			_ = cli.StringVar(cmd, &env.ServerTLSKey, "tls-key",
+				cli.Usage("TLS private key file, serves HTTPS together with --tls-cert"),
+				cli.Optional)
+
 			return func(cmd *cli.Command) error {
-				return withDBOperation(*customDBPath, true, func() error {
+				if env.ConfigErr != nil {
+					return errors.Wrap(env.ConfigErr, "invalid config %s", env.ConfigFile)
+				}
+				if (env.ServerTLSCert == "") != (env.ServerTLSKey == "") {
+					return fmt.Errorf("TLS needs both --tls-cert and --tls-key")
+				}
+
+				// The databases get closed after the shutdown, the exit on
+				// start errors has to wait for that. Pending migrations are
+				// applied on start.
+				var startErr error
+				err := withMigratedDBOperation(*customDBPath, func(store *db.Store) error {
 					e := echo.New()
 					e.HideBanner = true
 					e.HidePort = true
+					e.HTTPErrorHandler = httpErrorHandler
+					e.IPExtractor = ipExtractor(env.ServerTrustedProxies)
+
+					scheduler := jobs.NewScheduler()
+					if err := scheduler.RegisterBuiltin(store); err != nil {
+						return errors.Wrap(err, "register jobs")
+					}
+					scheduler.Start()
+					defer func() {
+						// No-op after shutdownServer, stops the jobs on start errors
+						ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+						defer cancel()
+						if err := scheduler.Stop(ctx); err != nil {
+							slog.Error("Failed to wait for running jobs", "error", err)
+						}
+					}()
 
-					middlewareConfiguration(e)
-					setupRouter(e, env.ServerPathPrefix)
-					startServer(e, env.ServerAddress)
+					middlewareConfiguration(e, store)
+					setupRouter(e, env.ServerPathPrefix, store, scheduler)
+					startErr = runServer(e, env.ServerAddress, scheduler)
 
 					return nil
 				})
+				if startErr != nil {
+					slog.Error("Failed to start server", "error", startErr)
+					os.Exit(exitCodeServerStart)
+				}
+				return err
 			}
 		}),
 	}
@@ -52,9 +106,11 @@ func serverCommand() cli.Command {
  * Server Middleware Configuration
  ******************************************************************************/
 
-func middlewareConfiguration(e *echo.Echo) {
+func middlewareConfiguration(e *echo.Echo, store *db.Store) {
 	e.Use(middleware.RequestLogger())
-	e.Use(middlewareKeyAuth())
+	e.Use(middlewareMetrics())
+	e.Use(middlewareKeyAuth(store))
+	e.Use(middlewareCSRF())
 	e.Use(ui.EchoMiddlewareCache(pages))
 }
 
@@ -62,21 +118,72 @@ func middlewareConfiguration(e *echo.Echo) {
  * Server Route Configuration
  ******************************************************************************/
 
-func setupRouter(e *echo.Echo, prefix string) {
+func setupRouter(e *echo.Echo, prefix string, store *db.Store, scheduler *jobs.Scheduler) {
 	e.StaticFS(prefix+"/", assets.GetPublic())
 	e.Static(prefix+"/images", env.ServerPathImages)
-	handlers.RegisterAll(e)
+	handlers.RegisterAll(e, store, scheduler)
 }
 
 /*******************************************************************************
  * Server Startup
  ******************************************************************************/
 
-func startServer(e *echo.Echo, address string) {
-	slog.Info("Starting HTTP server", "address", address)
+// runServer serves until SIGINT or SIGTERM and shuts down gracefully, see
+// shutdownServer. The returned error is set if the server failed to start.
+func runServer(e *echo.Echo, address string, scheduler *jobs.Scheduler) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	chErr := make(chan error, 1)
+	go func() {
+		chErr <- startServer(e, address)
+	}()
+
+	select {
+	case err := <-chErr:
+		if err != nil && err != http.ErrServerClosed {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	// A second signal kills the process without waiting for the shutdown
+	stop()
+	shutdownServer(e, scheduler)
+
+	return nil
+}
+
+// shutdownServer stops accepting requests, drains the in-flight requests and
+// waits for PDF renders and running jobs, within shutdownTimeout. The databases
+// are closed by the caller afterwards.
+func shutdownServer(e *echo.Echo, scheduler *jobs.Scheduler) {
+	slog.Info("Shutting down server", "timeout", shutdownTimeout)
+	health.ShuttingDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
 
-	if err := e.Start(address); err != nil {
-		slog.Error("Failed to start server", "error", err)
-		os.Exit(exitCodeServerStart)
+	if err := e.Shutdown(ctx); err != nil {
+		slog.Error("Failed to drain requests", "error", err)
 	}
+	if err := pdf.Wait(ctx); err != nil {
+		slog.Error("Failed to wait for PDF renders", "error", err)
+	}
+	if err := scheduler.Stop(ctx); err != nil {
+		slog.Error("Failed to wait for running jobs", "error", err)
+	}
+
+	slog.Info("Server stopped")
+}
+
+func startServer(e *echo.Echo, address string) error {
+	if env.ServerTLSCert != "" {
+		slog.Info("Starting HTTPS server", "address", address)
+		return e.StartTLS(address, env.ServerTLSCert, env.ServerTLSKey)
+	}
+
+	slog.Info("Starting HTTP server", "address", address)
+	return e.Start(address)
 }
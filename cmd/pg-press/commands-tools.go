@@ -48,8 +48,8 @@ func listToolsCommand() cli.Command {
 				cli.Optional)
 
 			return func(cmd *cli.Command) error {
-				return withDBOperation(*customDBPath, false, func() error {
-					tools, merr := db.ListTools()
+				return withDBOperation(*customDBPath, false, func(store *db.Store) error {
+					tools, merr := store.ListTools()
 					if merr != nil {
 						return merr.Wrap("list tools")
 					}
@@ -121,17 +121,24 @@ func listToolsCommand() cli.Command {
 func deleteToolCommand() cli.Command {
 	return cli.Command{
 		Name:  "delete",
-		Usage: cli.Usage("Delete a tool by ID"),
+		Usage: cli.Usage("Move a tool by ID to the trash, including its metal sheets, cycles and notes"),
 		Action: cli.ActionFunc(func(cmd *cli.Command) cli.ActionRunner {
 			customDBPath := createDBPathOption(cmd)
 			toolIDArg := cli.Int64Arg(cmd, "tool-id", cli.Required)
 
 			return func(cmd *cli.Command) error {
-				return withDBOperation(*customDBPath, false, func() error {
-					merr := db.DeleteTool(shared.EntityID(*toolIDArg))
+				return withDBOperation(*customDBPath, false, func(store *db.Store) error {
+					tool, merr := store.GetTool(shared.EntityID(*toolIDArg))
+					if merr != nil {
+						return merr.Wrap("get tool")
+					}
+
+					// Deleted from the command line, there is no user to record
+					merr = store.DeleteTool(tool.ID, 0)
 					if merr != nil {
 						return merr.Wrap("delete tool")
 					}
+					store.Audit(0, shared.AuditActionDelete, tool, nil)
 					return nil
 				})
 			}
@@ -148,12 +155,17 @@ func markDeadCommand() cli.Command {
 			toolIDArg := cli.Int64Arg(cmd, "tool-id", cli.Required)
 
 			return func(cmd *cli.Command) error {
-				return withDBOperation(*customDBPath, false, func() error {
-					merr := db.MarkToolAsDead(shared.EntityID(*toolIDArg))
+				return withDBOperation(*customDBPath, false, func(store *db.Store) error {
+					before, merr := store.GetTool(shared.EntityID(*toolIDArg))
+					if merr != nil {
+						return merr.Wrap("get tool")
+					}
+
+					merr = store.MarkToolAsDead(before.ID)
 					if merr != nil {
 						return merr.Wrap("mark tool as dead")
 					}
-					return nil
+					return auditToolUpdate(store, before)
 				})
 			}
 		}),
@@ -169,17 +181,32 @@ func reviveDeadToolCommand() cli.Command {
 			toolIDArg := cli.Int64Arg(cmd, "tool-id", cli.Required)
 
 			return func(cmd *cli.Command) error {
-				return withDBOperation(*customDBPath, false, func() error {
-					if merr := db.ReviveTool(shared.EntityID(*toolIDArg)); merr != nil {
+				return withDBOperation(*customDBPath, false, func(store *db.Store) error {
+					before, merr := store.GetTool(shared.EntityID(*toolIDArg))
+					if merr != nil {
+						return merr.Wrap("get tool")
+					}
+
+					if merr := store.ReviveTool(before.ID); merr != nil {
 						return merr.Wrap("revive tool")
 					}
-					return nil
+					return auditToolUpdate(store, before)
 				})
 			}
 		}),
 	}
 }
 
+// auditToolUpdate records a change of a tool made from the command line
+func auditToolUpdate(store *db.Store, before *shared.Tool) error {
+	tool, merr := store.GetTool(before.ID)
+	if merr != nil {
+		return merr.Wrap("get tool")
+	}
+	store.Audit(0, shared.AuditActionUpdate, before, tool)
+	return nil
+}
+
 // -----------------------------------------------------------------------------
 // Tool Press Cycles Commands
 // -----------------------------------------------------------------------------
@@ -193,14 +220,14 @@ func listCyclesCommand() cli.Command {
 			toolIDArg := cli.Int64Arg(cmd, "tool-id", cli.Required)
 
 			return func(cmd *cli.Command) error {
-				return withDBOperation(*customDBPath, false, func() error {
+				return withDBOperation(*customDBPath, false, func(store *db.Store) error {
 					// Get tool first to check if it exists and show info
-					tool, merr := db.GetTool(shared.EntityID(*toolIDArg))
+					tool, merr := store.GetTool(shared.EntityID(*toolIDArg))
 					if merr != nil {
 						return merr.Wrap("find tool with ID %d", *toolIDArg)
 					}
 
-					totalCycles, merr := db.GetTotalToolCycles(tool.ID)
+					totalCycles, merr := store.GetTotalToolCycles(tool.ID)
 					if merr != nil {
 						return merr.Wrap("calculate total cycles for tool ID %d", tool.ID)
 					}
@@ -208,7 +235,7 @@ func listCyclesCommand() cli.Command {
 					fmt.Printf("\nTotal cycles: %d\n", totalCycles)
 
 					// Get cycles for this tool
-					cycles, merr := db.ListToolCycles(tool.ID)
+					cycles, merr := store.ListToolCycles(tool.ID)
 					if merr != nil {
 						return fmt.Errorf("retrieve cycles: %v", merr)
 					}
@@ -256,11 +283,11 @@ func listRegenerationsCommand() cli.Command {
 			toolIDArg := cli.Int64Arg(cmd, "tool-id", cli.Required)
 
 			return func(cmd *cli.Command) error {
-				return withDBOperation(*customDBPath, false, func() error {
+				return withDBOperation(*customDBPath, false, func(store *db.Store) error {
 					toolID := shared.EntityID(*toolIDArg)
 
 					// Get tool first to check if it exists and show info
-					tool, merr := db.GetTool(toolID)
+					tool, merr := store.GetTool(toolID)
 					if merr != nil {
 						return merr.Wrap("find tool with ID %d", toolID)
 					}
@@ -268,7 +295,7 @@ func listRegenerationsCommand() cli.Command {
 					fmt.Printf("Tool Information: %s\n\n", tool.String())
 
 					// Get regenerations for this tool
-					regenerations, merr := db.ListToolRegenerationsByTool(toolID)
+					regenerations, merr := store.ListToolRegenerationsByTool(toolID)
 					if merr != nil {
 						return merr.Wrap("retrieve regenerations")
 					}
@@ -310,11 +337,11 @@ func deleteRegenerationCommand() cli.Command {
 			regenerationIDArg := cli.Int64Arg(cmd, "regeneration-id", cli.Required)
 
 			return func(cmd *cli.Command) error {
-				return withDBOperation(*customDBPath, false, func() error {
+				return withDBOperation(*customDBPath, false, func(store *db.Store) error {
 					regenerationID := shared.EntityID(*regenerationIDArg)
 
 					// Delete the regeneration
-					if merr := db.DeleteToolRegeneration(regenerationID); merr != nil {
+					if merr := store.DeleteToolRegeneration(regenerationID); merr != nil {
 						return fmt.Errorf("delete regeneration: %v", merr)
 					}
 					return nil
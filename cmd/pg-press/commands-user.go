@@ -2,7 +2,7 @@ package main
 
 import (
 	"fmt"
-	"os"
+	"strings"
 
 	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/shared"
@@ -18,16 +18,16 @@ func listUserCommand() cli.Command {
 			customDBPath := createDBPathOption(cmd)
 
 			return func(cmd *cli.Command) error {
-				return withDBOperation(*customDBPath, false, func() error {
-					users, merr := db.ListUsers()
+				return withDBOperation(*customDBPath, false, func(store *db.Store) error {
+					users, merr := store.ListUsers()
 					if merr != nil {
 						return merr
 					}
 
-					fmt.Printf("TELEGRAM ID\tUSER NAME\n")
-					fmt.Printf("-----------\t---------\n")
+					fmt.Printf("TELEGRAM ID\tUSER NAME\tROLE\n")
+					fmt.Printf("-----------\t---------\t----\n")
 					for _, u := range users {
-						fmt.Printf("%d\t%s\n", u.ID, u.Name)
+						fmt.Printf("%d\t%s\t%s\n", u.ID, u.Name, u.EffectiveRole())
 					}
 
 					return nil
@@ -42,26 +42,30 @@ func showUserCommand() cli.Command {
 		Name: "show",
 		Action: cli.ActionFunc(func(cmd *cli.Command) cli.ActionRunner {
 			customDBPath := createDBPathOption(cmd)
-			flagApiKey := cli.Bool(cmd, "api-key", cli.Optional)
 			telegramIDArg := cli.Int64Arg(cmd, "telegram-id", cli.Required)
 
 			return func(cmd *cli.Command) error {
-				return withDBOperation(*customDBPath, false, func() error {
-					user, merr := db.GetUser(shared.TelegramID(*telegramIDArg))
+				return withDBOperation(*customDBPath, false, func(store *db.Store) error {
+					user, merr := store.GetUser(shared.TelegramID(*telegramIDArg))
 					if merr != nil {
 						return merr.Wrap("get user")
 					}
 
-					if *flagApiKey {
-						fmt.Fprint(os.Stdout, user.ApiKey)
-						return nil
+					fmt.Printf("Telegram ID\tUser Name\tRole\n")
+					fmt.Printf("-----------\t---------\t----\n")
+					fmt.Printf("%d\t%s\t%s\n", user.ID, user.Name, user.EffectiveRole())
+
+					keys, merr := store.ListApiKeysByUserID(user.ID)
+					if merr != nil {
+						return merr.Wrap("list api keys for user ID %d", user.ID)
 					}
 
-					fmt.Printf("Telegram ID\tUser Name\tApi Key\n")
-					fmt.Printf("-----------\t---------\t-------\n")
-					fmt.Printf("%d\t%s\t%s\n", user.ID, user.Name, user.ApiKey)
+					if len(keys) > 0 {
+						fmt.Printf("\n=== Api Keys ===\n")
+						printApiKeys(keys)
+					}
 
-					cookies, merr := db.ListCookiesByUserID(user.ID)
+					cookies, merr := store.ListCookiesByUserID(user.ID)
 					if merr != nil {
 						return merr.Wrap("list cookies for user ID %d", user.ID)
 					}
@@ -89,22 +93,34 @@ func addUserCommand() cli.Command {
 		Name: "add",
 		Action: cli.ActionFunc(func(cmd *cli.Command) cli.ActionRunner {
 			customDBPath := createDBPathOption(cmd)
+			role := cli.String(cmd, "role", cli.Optional,
+				cli.Usage(userRoleUsage()))
 			telegramIDArg := cli.Int64Arg(cmd, "telegram-id", cli.Required)
 			userName := cli.StringArg(cmd, "user-name", cli.Required)
-			apiKey := cli.StringArg(cmd, "api-key", cli.Required)
 
 			return func(cmd *cli.Command) error {
-				return withDBOperation(*customDBPath, false, func() error {
+				return withDBOperation(*customDBPath, false, func(store *db.Store) error {
 					telegramID := shared.TelegramID(*telegramIDArg)
 
-					merr := db.AddUser(&shared.User{
-						ID:     telegramID,
-						Name:   *userName,
-						ApiKey: *apiKey,
+					merr := store.AddUser(&shared.User{
+						ID:   telegramID,
+						Name: *userName,
+						Role: shared.UserRole(*role),
 					})
-					if merr != nil && merr.IsExistsError() {
-						return fmt.Errorf("user already exists: %d (%s)", telegramID, *userName)
+					if merr != nil {
+						if merr.IsExistsError() {
+							return fmt.Errorf("user already exists: %d (%s)", telegramID, *userName)
+						}
+						return merr
+					}
+
+					// New users get a first key for the login, printed once
+					apiKey, err := addApiKey(store, telegramID, "Standard", 0)
+					if err != nil {
+						return err
 					}
+
+					fmt.Print(apiKey) // Yes, no newline at the end
 					return nil
 				})
 			}
@@ -120,8 +136,8 @@ func removeUserCommand() cli.Command {
 			telegramIDArg := cli.Int64Arg(cmd, "telegram-id", cli.Required)
 
 			return func(cmd *cli.Command) error {
-				return withDBOperation(*customDBPath, false, func() error {
-					return db.DeleteUser(shared.TelegramID(*telegramIDArg))
+				return withDBOperation(*customDBPath, false, func(store *db.Store) error {
+					return store.DeleteUser(shared.TelegramID(*telegramIDArg))
 				})
 			}
 		}),
@@ -134,12 +150,13 @@ func modUserCommand() cli.Command {
 		Action: cli.ActionFunc(func(cmd *cli.Command) cli.ActionRunner {
 			customDBPath := createDBPathOption(cmd)
 			userName := cli.String(cmd, "name", cli.WithShort("n"), cli.Optional)
-			apiKey := cli.String(cmd, "api-key", cli.Optional)
+			role := cli.String(cmd, "role", cli.Optional,
+				cli.Usage(userRoleUsage()))
 			telegramID := cli.Int64Arg(cmd, "telegram-id", cli.Required)
 
 			return func(cmd *cli.Command) error {
-				return withDBOperation(*customDBPath, false, func() error {
-					user, err := db.GetUser(shared.TelegramID(*telegramID))
+				return withDBOperation(*customDBPath, false, func(store *db.Store) error {
+					user, err := store.GetUser(shared.TelegramID(*telegramID))
 					if err != nil {
 						return err
 					}
@@ -148,13 +165,22 @@ func modUserCommand() cli.Command {
 						user.Name = *userName
 					}
 
-					if *apiKey != "" {
-						user.ApiKey = *apiKey
+					if *role != "" {
+						user.Role = shared.UserRole(*role)
 					}
 
-					return db.UpdateUser(user)
+					return store.UpdateUser(user)
 				})
 			}
 		}),
 	}
 }
+
+// userRoleUsage lists the available roles for the --role flag
+func userRoleUsage() string {
+	roles := make([]string, 0, len(shared.UserRoles))
+	for _, r := range shared.UserRoles {
+		roles = append(roles, r.String())
+	}
+	return "User role, one of: " + strings.Join(roles, ", ")
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/handlers/api"
+	"github.com/knackwurstking/pg-press/internal/templates/components"
+
+	"github.com/labstack/echo/v4"
+)
+
+// alertEvent is the HTMX event triggered for failed HTMX requests, handled by
+// the global alert system in "js/layout/main.js"
+const alertEvent = "pgpress:alert"
+
+// alert is the detail of the alertEvent
+type alert struct {
+	Severity string `json:"severity"` // Severity is "error" or "warning"
+	Title    string `json:"title"`
+	Message  string `json:"message"`
+	Code     int    `json:"code"`
+}
+
+// httpErrorHandler replaces the echo default error handler, errors get written
+// as JSON for API clients, as alert event for HTMX requests and as error page
+// for everything else. Server errors are logged here, clients only get a
+// generic message, see errors.HTTPError.ClientMessage
+func httpErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	r := c.Request()
+	herr := toHTTPError(err)
+	if herr.Code() >= http.StatusInternalServerError {
+		slog.Error("Request failed", "method", r.Method, "path", r.URL.Path, "error", herr)
+	}
+
+	if err := writeError(c, herr); err != nil {
+		slog.Error("Failed to write error response", "path", r.URL.Path, "error", err)
+	}
+}
+
+func writeError(c echo.Context, herr *errors.HTTPError) error {
+	r := c.Request()
+	switch {
+	case api.IsClientPath(r.URL.Path):
+		if err := api.WriteError(c, herr); err != nil {
+			return err
+		}
+		return nil
+	case r.Header.Get("HX-Request") == "true":
+		return writeAlert(c, herr)
+	case r.Method == http.MethodHead:
+		return c.NoContent(herr.Code())
+	default:
+		return writeErrorPage(c, herr)
+	}
+}
+
+// toHTTPError converts the errors returned from handlers and middlewares,
+// mostly echo HTTP errors created from an errors.HTTPError
+func toHTTPError(err error) *errors.HTTPError {
+	if he, ok := err.(*echo.HTTPError); ok {
+		return errors.NewHTTPError(fmt.Errorf("%v", he.Message)).SetCode(he.Code)
+	}
+	return errors.NewHTTPError(err)
+}
+
+// writeAlert keeps the status code, so HTMX does not swap anything, and sends
+// the error with the HX-Trigger header
+func writeAlert(c echo.Context, herr *errors.HTTPError) error {
+	data, err := json.Marshal(map[string]alert{
+		alertEvent: {
+			Severity: herr.Severity(),
+			Title:    herr.Title(),
+			Message:  herr.ClientMessage(),
+			Code:     herr.Code(),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set("HX-Trigger", escapeNonASCII(data))
+	return c.String(herr.Code(), herr.ClientMessage())
+}
+
+func writeErrorPage(c echo.Context, herr *errors.HTTPError) error {
+	c.Response().Header().Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
+	c.Response().WriteHeader(herr.Code())
+
+	t := components.ErrorPage(components.ErrorPageProps{
+		Code:    herr.Code(),
+		Title:   herr.Title(),
+		Message: herr.ClientMessage(),
+	})
+	return t.Render(c.Request().Context(), c.Response())
+}
+
+// escapeNonASCII escapes all non ASCII characters of the JSON data, browsers
+// do not decode header values as UTF-8
+func escapeNonASCII(data []byte) string {
+	var b strings.Builder
+	for _, r := range string(data) {
+		if r < utf8.RuneSelf {
+			b.WriteRune(r)
+			continue
+		}
+		for _, u := range utf16.Encode([]rune{r}) {
+			fmt.Fprintf(&b, `\u%04x`, u)
+		}
+	}
+	return b.String()
+}
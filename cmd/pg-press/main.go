@@ -16,21 +16,11 @@ const (
 )
 
 var (
-	appName    string
-	configPath string
+	appName string
 )
 
 func init() {
 	appName = filepath.Base(os.Args[0])
-
-	p, err := os.UserConfigDir()
-	if err != nil {
-		panic(err)
-	}
-	configPath = filepath.Join(p, appName)
-	if err := os.MkdirAll(configPath, 0700); err != nil {
-		panic(err)
-	}
 }
 
 func main() {
@@ -66,10 +56,20 @@ func main() {
 				},
 			},
 
+			lockoutsCommand(),
+
 			toolsCommand(),
 
+			pressCommand(),
+
+			auditCommand(),
+
+			dbCommand(),
+
 			serverCommand(),
 
+			configCommand(),
+
 			cli.CompletionCommand(),
 		},
 		CommandFlags: []cli.CommandFlag{
@@ -3,14 +3,20 @@ package main
 import (
 	"fmt"
 	"log/slog"
+	"net"
+	"net/http"
 	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/env"
 	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/handlers/api"
 	"github.com/knackwurstking/pg-press/internal/handlers/auth"
+	"github.com/knackwurstking/pg-press/internal/metrics"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/knackwurstking/pg-press/internal/urlb"
 	"github.com/knackwurstking/pg-press/internal/utils"
@@ -19,6 +25,16 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 )
 
+const (
+	// keyAuthLockout is the context key for the lockout which rejected a request,
+	// see authenticateApiKey
+	keyAuthLockout = "key-auth-lockout"
+
+	// keyAuthCookie is the context key for the session cookie of requests
+	// authenticated by cookie, see middlewareCSRF
+	keyAuthCookie = "key-auth-cookie"
+)
+
 var (
 	keyAuthFilesToSkip []string
 	pages              []string
@@ -37,6 +53,9 @@ func init() {
 		env.ServerPathPrefix + "/tool",
 		env.ServerPathPrefix + "/press",
 		env.ServerPathPrefix + "/umbau",
+		env.ServerPathPrefix + "/admin",
+		env.ServerPathPrefix + "/trash",
+		env.ServerPathPrefix + "/search",
 	}
 
 	// NOTE: Important for skipping key authentication
@@ -44,6 +63,10 @@ func init() {
 		// Pages
 		env.ServerPathPrefix + "/login",
 
+		// Health checks
+		env.ServerPathPrefix + "/healthz",
+		env.ServerPathPrefix + "/readyz",
+
 		// CSS
 		env.ServerPathPrefix + "/css/output.css",
 
@@ -74,14 +97,14 @@ func init() {
 	}
 }
 
-func middlewareKeyAuth() echo.MiddlewareFunc {
+func middlewareKeyAuth(store *db.Store) echo.MiddlewareFunc {
 	return middleware.KeyAuthWithConfig(middleware.KeyAuthConfig{
 		Skipper: keyAuthSkipper,
 		KeyLookup: "header:" + echo.HeaderAuthorization +
 			",query:access_token,cookie:" + auth.CookieName,
 		AuthScheme: "Bearer",
 		Validator: func(auth string, ctx echo.Context) (bool, error) {
-			return keyAuthValidator(auth, ctx)
+			return keyAuthValidator(store, auth, ctx)
 		},
 		ErrorHandler: func(err error, c echo.Context) error {
 			slog.Error("KeyAuth error",
@@ -90,7 +113,28 @@ func middlewareKeyAuth() echo.MiddlewareFunc {
 				"path", c.Request().URL.Path,
 				"real_ip", c.RealIP())
 
-			merr := utils.RedirectTo(c, urlb.Login("", nil))
+			herr := errors.NewAuthorizationError("invalid or missing API key").HTTPError()
+			lockout, locked := c.Get(keyAuthLockout).(*shared.Lockout)
+			if locked {
+				c.Response().Header().Set(echo.HeaderRetryAfter,
+					strconv.Itoa(int(lockout.RetryAfter().Seconds())))
+				herr = errors.NewRateLimitError(
+					"too many failed logins, retry in %s", lockout.RetryAfter()).HTTPError()
+			}
+
+			// API clients get a JSON error instead of the login page
+			if api.IsClientPath(c.Request().URL.Path) {
+				if eerr := api.WriteError(c, herr); eerr != nil {
+					return eerr
+				}
+				return nil
+			}
+
+			url := urlb.Login("", nil)
+			if locked {
+				url = urlb.LoginLocked(lockout.RetryAfter())
+			}
+			merr := utils.RedirectTo(c, url)
 			if merr != nil {
 				return merr.Err()
 			}
@@ -107,18 +151,23 @@ func keyAuthSkipper(ctx echo.Context) bool {
 		slices.Contains(keyAuthFilesToSkip, url)
 }
 
-func keyAuthValidator(auth string, ctx echo.Context) (bool, error) {
+func keyAuthValidator(store *db.Store, auth string, ctx echo.Context) (bool, error) {
 	realIP := ctx.RealIP()
 
-	user, err := validateUserFromCookie(ctx)
+	user, err := validateUserFromCookie(store, ctx)
 	if err != nil {
 		slog.Warn("Validate user from cookie failed",
 			"error", err,
 			"real_ip", realIP)
 
+		// An invalid session cookie is no guess of an API key
+		if isCookieValue(ctx, auth) {
+			return false, err
+		}
+
 		// Try to get user directly from the API key
 		var merr *errors.HTTPError
-		user, merr = db.GetUserByApiKey(auth)
+		user, merr = authenticateApiKey(store, auth, ctx)
 		if merr != nil {
 			return false, merr
 		}
@@ -133,14 +182,61 @@ func keyAuthValidator(auth string, ctx echo.Context) (bool, error) {
 	return true, nil
 }
 
-func validateUserFromCookie(ctx echo.Context) (*shared.User, error) {
+// authenticateApiKey looks up the user for an API key sent with the
+// Authorization header or the access_token query parameter.
+//
+// Failed attempts are recorded and counted for the lockouts of the client IP
+// and of the key prefix, locked requests are rejected without a lookup, see
+// shared.LoginLockoutKeys. Successful attempts are not recorded, API clients authenticate with
+// every request, see shared.ApiKey.LastUsed.
+func authenticateApiKey(store *db.Store, apiKey string, ctx echo.Context) (*shared.User, *errors.HTTPError) {
+	keys := shared.LoginLockoutKeys(ctx.RealIP(), apiKey)
+
+	lockouts, merr := store.ListLockoutsByKeys(keys...)
+	if merr != nil {
+		return nil, merr
+	}
+	if l := shared.ActiveLockout(lockouts); l != nil {
+		ctx.Set(keyAuthLockout, l)
+		return nil, errors.NewRateLimitError("login locked: %s", l.Key).HTTPError()
+	}
+
+	user, merr := store.GetUserByApiKey(apiKey)
+	if merr != nil {
+		store.RecordLoginAttempt(&shared.LoginAttempt{
+			Source:    shared.LoginSourceToken,
+			RealIP:    ctx.RealIP(),
+			UserAgent: ctx.Request().UserAgent(),
+			KeyPrefix: shared.ApiKeyPrefix(apiKey),
+			Reason:    merr.Error(),
+			CreatedAt: shared.NewUnixMilli(time.Now()),
+		}, keys...)
+		return nil, merr
+	}
+
+	if len(lockouts) > 0 {
+		if merr = store.ClearLockouts(keys...); merr != nil {
+			slog.Error("Failed to clear lockouts", "keys", keys, "error", merr)
+		}
+	}
+
+	return user, nil
+}
+
+// isCookieValue returns true if value is the session cookie of the request
+func isCookieValue(ctx echo.Context, value string) bool {
+	cookie, err := ctx.Cookie(auth.CookieName)
+	return err == nil && cookie.Value == value
+}
+
+func validateUserFromCookie(store *db.Store, ctx echo.Context) (*shared.User, error) {
 	realIP := ctx.RealIP()
 	httpCookie, err := ctx.Cookie(auth.CookieName)
 	if err != nil {
 		return nil, errors.Wrap(err, "get cookie")
 	}
 
-	cookie, merr := db.GetCookie(httpCookie.Value)
+	cookie, merr := store.GetCookie(httpCookie.Value)
 	if merr != nil {
 		return nil, merr.Wrap("get cookie").Err()
 	}
@@ -150,7 +246,15 @@ func validateUserFromCookie(ctx echo.Context) (*shared.User, error) {
 		return nil, fmt.Errorf("cookie has expired")
 	}
 
-	user, merr := db.GetUser(cookie.UserID)
+	// Sessions created before CSRF protection get their token now
+	if cookie.CSRFToken == "" {
+		cookie.CSRFToken = utils.NewCSRFToken()
+		if merr = store.UpdateCookie(cookie); merr != nil {
+			return nil, merr.Wrap("set csrf token").Err()
+		}
+	}
+
+	user, merr := store.GetUser(cookie.UserID)
 	if merr != nil {
 		return nil, merr.Wrap("validate user from API key").Err()
 	}
@@ -167,7 +271,7 @@ func validateUserFromCookie(ctx echo.Context) (*shared.User, error) {
 		cookie.LastLogin = shared.NewUnixMilli(time.Now())
 		httpCookie.Expires = cookie.ExpiredAtTime()
 
-		merr = db.UpdateCookie(cookie)
+		merr = store.UpdateCookie(cookie)
 		if merr != nil {
 			slog.Error("Failed to update cookie",
 				"error", merr,
@@ -176,5 +280,117 @@ func validateUserFromCookie(ctx echo.Context) (*shared.User, error) {
 		}
 	}
 
+	ctx.Set(keyAuthCookie, cookie)
 	return user, nil
 }
+
+// middlewareCSRF rejects state changing requests of cookie sessions without the
+// CSRF token of the session, requests authenticated by an API key (Authorization
+// header or access_token query) are not affected.
+//
+// The token is passed to the templates via the request context, the layout
+// template renders it as meta tag, see utils.GetCSRFToken.
+func middlewareCSRF() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			cookie, ok := c.Get(keyAuthCookie).(*shared.Cookie)
+			if !ok {
+				return next(c)
+			}
+
+			r := c.Request()
+			c.SetRequest(r.WithContext(utils.WithCSRFToken(r.Context(), cookie.CSRFToken)))
+
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				return next(c)
+			}
+
+			token := r.Header.Get(utils.CSRFHeader)
+			if token == "" {
+				token = c.FormValue(utils.CSRFFormField)
+			}
+
+			if !utils.ValidCSRFToken(cookie.CSRFToken, token) {
+				slog.Warn("CSRF token invalid or missing",
+					"method", r.Method,
+					"path", r.URL.Path,
+					"user_id", cookie.UserID,
+					"real_ip", c.RealIP())
+
+				herr := errors.NewPermissionError("invalid or missing CSRF token").HTTPError()
+				if api.IsClientPath(r.URL.Path) {
+					if eerr := api.WriteError(c, herr); eerr != nil {
+						return eerr
+					}
+					return nil
+				}
+				return herr.Echo()
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// middlewareMetrics counts the requests and records their latencies per route,
+// see metrics.HTTPRequests. Requests without a matching route have an empty
+// route, to keep unknown paths out of the labels.
+func middlewareMetrics() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+
+			// The error handler sets the status after the middlewares
+			code := c.Response().Status
+			if err != nil {
+				code = http.StatusInternalServerError
+				if herr, ok := err.(*echo.HTTPError); ok {
+					code = herr.Code
+				}
+			}
+
+			route, method := c.Path(), c.Request().Method
+			metrics.HTTPRequests.Inc(route, method, strconv.Itoa(code))
+			metrics.HTTPRequestDuration.ObserveSince(start, route, method)
+
+			return err
+		}
+	}
+}
+
+// ipExtractor returns how the client IP is read, used for the login lockouts
+// and logs.
+//
+// Without trusted proxies X-Forwarded-For and X-Real-IP are ignored, a client
+// could forge them to get around its lockout, or to lock out another IP. The
+// first request with X-Forwarded-For logs a warning then, behind a reverse proxy
+// all clients share the IP of the proxy.
+func ipExtractor(trustedProxies []*net.IPNet) echo.IPExtractor {
+	if len(trustedProxies) == 0 {
+		extract := echo.ExtractIPDirect()
+		warn := &sync.Once{}
+		return func(r *http.Request) string {
+			ip := extract(r)
+			if r.Header.Get(echo.HeaderXForwardedFor) != "" {
+				warn.Do(func() {
+					slog.Warn("Request with X-Forwarded-For, but no trusted proxies configured, "+
+						"all clients behind a reverse proxy share its IP, set TRUSTED_PROXIES",
+						"real_ip", ip)
+				})
+			}
+			return ip
+		}
+	}
+
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, p := range trustedProxies {
+		options = append(options, echo.TrustIPRange(p))
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/knackwurstking/pg-press/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestIPExtractorLockoutKey(t *testing.T) {
+	_, proxies, err := net.ParseCIDR("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name           string
+		trustedProxies []*net.IPNet
+		remoteAddr     string
+		headers        map[string]string
+		want           string
+	}{
+		{
+			name:       "direct",
+			remoteAddr: "192.0.2.10:4321",
+			want:       "192.0.2.10",
+		},
+		{
+			name:       "forged X-Forwarded-For",
+			remoteAddr: "192.0.2.10:4321",
+			headers:    map[string]string{echo.HeaderXForwardedFor: "203.0.113.7"},
+			want:       "192.0.2.10",
+		},
+		{
+			name:       "forged X-Real-IP",
+			remoteAddr: "192.0.2.10:4321",
+			headers:    map[string]string{echo.HeaderXRealIP: "203.0.113.7"},
+			want:       "192.0.2.10",
+		},
+		{
+			name:           "forged X-Forwarded-For from an untrusted client",
+			trustedProxies: []*net.IPNet{proxies},
+			remoteAddr:     "192.0.2.10:4321",
+			headers:        map[string]string{echo.HeaderXForwardedFor: "203.0.113.7"},
+			want:           "192.0.2.10",
+		},
+		{
+			name:           "trusted proxy",
+			trustedProxies: []*net.IPNet{proxies},
+			remoteAddr:     "10.0.0.1:4321",
+			headers:        map[string]string{echo.HeaderXForwardedFor: "203.0.113.7"},
+			want:           "203.0.113.7",
+		},
+		{
+			name:           "forged X-Forwarded-For through a trusted proxy",
+			trustedProxies: []*net.IPNet{proxies},
+			remoteAddr:     "10.0.0.1:4321",
+			headers:        map[string]string{echo.HeaderXForwardedFor: "198.51.100.1, 203.0.113.7"},
+			want:           "203.0.113.7",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			e.IPExtractor = ipExtractor(tc.trustedProxies)
+
+			req := httptest.NewRequest(http.MethodPost, "/login", nil)
+			req.RemoteAddr = tc.remoteAddr
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			got := shared.LoginLockoutKeys(c.RealIP(), "")
+			if want := shared.NewLockoutKeyIP(tc.want); len(got) != 1 || got[0] != want {
+				t.Errorf("lockout keys = %v, want [%s]", got, want)
+			}
+		})
+	}
+}
@@ -2,8 +2,10 @@ package main
 
 import (
 	"fmt"
+	"log/slog"
 
 	"github.com/knackwurstking/pg-press/internal/db"
+	"github.com/knackwurstking/pg-press/internal/env"
 
 	"github.com/SuperPaintman/nice/cli"
 
@@ -17,18 +19,60 @@ func createDBPathOption(cmd *cli.Command) *string {
 		cli.Usage("Custom database path"),
 		cli.Optional,
 	)
-	*db = configPath
+	*db = env.ServerPathDB
 	return db
 }
 
 // withDBOperation is a helper that handles common database operations
-func withDBOperation(dbPath string, createMode bool, operation func() error) error {
-	if err := db.Open(dbPath, createMode); err != nil {
+func withDBOperation(dbPath string, createMode bool, operation func(store *db.Store) error) error {
+	store, err := db.Open(dbPath, createMode)
+	if err != nil {
 		return fmt.Errorf(
 			"Error: could not open database %s (createMode: %t): %v\n",
 			dbPath, createMode, err,
 		)
 	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			slog.Error("Failed to close database", "path", dbPath, "error", err)
+		}
+	}()
 
-	return operation()
+	return operation(store)
+}
+
+// withMigratedDBOperation is like withDBOperation, but applies all pending
+// migrations first, the databases are created if missing
+func withMigratedDBOperation(dbPath string, operation func(store *db.Store) error) error {
+	store, err := db.Connect(dbPath, true)
+	if err != nil {
+		return fmt.Errorf("Error: could not connect to database %s: %v\n", dbPath, err)
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			slog.Error("Failed to close database", "path", dbPath, "error", err)
+		}
+	}()
+
+	if _, err := store.Migrate(false); err != nil {
+		return fmt.Errorf("Error: could not migrate database %s: %v\n", dbPath, err)
+	}
+
+	return operation(store)
+}
+
+// withConnectedDBOperation is like withDBOperation, but accepts databases with
+// pending migrations and never migrates them, used to copy the databases as they are
+func withConnectedDBOperation(dbPath string, operation func(store *db.Store) error) error {
+	store, err := db.Connect(dbPath, false)
+	if err != nil {
+		return fmt.Errorf("Error: could not connect to database %s: %v\n", dbPath, err)
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			slog.Error("Failed to close database", "path", dbPath, "error", err)
+		}
+	}()
+
+	return operation(store)
 }
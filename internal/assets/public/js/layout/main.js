@@ -24,6 +24,93 @@ matchMedia("(prefers-color-scheme: dark)").addEventListener(
 	updateDataTheme,
 );
 
+// CSRF token of the session, see the "csrf-token" meta tag in the layout
+function csrfToken() {
+	var meta = document.querySelector(`meta[name="csrf-token"]`);
+	return meta ? meta.getAttribute("content") : "";
+}
+
+// Send the CSRF token with all HTMX requests
+document.addEventListener("htmx:configRequest", function(event) {
+	var token = csrfToken();
+	if (token) event.detail.headers["X-CSRF-Token"] = token;
+});
+
+// Add the CSRF token to plain POST forms (e.g. the editor), before HTMX or the
+// browser collect the form data
+document.addEventListener(
+	"submit",
+	function(event) {
+		var form = event.target;
+		var token = csrfToken();
+		if (!token || form.method.toLowerCase() !== "post") return;
+
+		var input = form.querySelector(`input[name="csrf_token"]`);
+		if (!input) {
+			input = document.createElement("input");
+			input.type = "hidden";
+			input.name = "csrf_token";
+			form.appendChild(input);
+		}
+		input.value = token;
+	},
+	true,
+);
+
+// Global alert system, failed HTMX requests get an "pgpress:alert" event from
+// the HX-Trigger header of the server error handler
+const alertTimeout = 8000;
+
+function showAlert(severity, title, message) {
+	var container = document.querySelector(".alerts");
+	if (!container) {
+		container = document.createElement("div");
+		container.className = "alerts";
+		container.setAttribute("role", "status");
+		document.body.appendChild(container);
+	}
+
+	var alert = document.createElement("div");
+	alert.className = "alert";
+	alert.dataset.severity = severity || "error";
+
+	var titleElement = document.createElement("div");
+	titleElement.className = "alert-title";
+	titleElement.textContent = title || "Fehler";
+	alert.appendChild(titleElement);
+
+	if (message) {
+		var messageElement = document.createElement("div");
+		messageElement.className = "alert-message";
+		messageElement.textContent = message;
+		alert.appendChild(messageElement);
+	}
+
+	alert.addEventListener("click", function() {
+		alert.remove();
+	});
+	setTimeout(function() {
+		alert.remove();
+	}, alertTimeout);
+
+	container.appendChild(alert);
+}
+
+document.addEventListener("pgpress:alert", function(event) {
+	showAlert(event.detail.severity, event.detail.title, event.detail.message);
+});
+
+// Fallback for error responses without alert event, e.g. from a proxy
+document.addEventListener("htmx:responseError", function(event) {
+	var xhr = event.detail.xhr;
+	if (xhr.getResponseHeader("HX-Trigger")) return;
+	showAlert("error", `Fehler ${xhr.status}`, xhr.responseText);
+});
+
+document.addEventListener("htmx:sendError", function() {
+	showAlert("error", "Keine Verbindung", "Der Server ist nicht erreichbar.");
+});
+
 document.addEventListener("DOMContentLoaded", function() {
 	window.triggers = window.triggers || [];
 
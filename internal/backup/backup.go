@@ -0,0 +1,436 @@
+// Package backup creates and restores archives containing a consistent snapshot
+// of all databases and the attachment images.
+//
+// Archive layout (tar.gz):
+//   - db/<name>.sqlite: One snapshot per database
+//   - images/...: All files from the images directory
+//   - manifest.json: Version, creation time and a SHA-256 checksum for every file
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/knackwurstking/pg-press/internal/db"
+	"github.com/knackwurstking/pg-press/internal/env"
+	"github.com/knackwurstking/pg-press/internal/version"
+)
+
+const (
+	ManifestName = "manifest.json"
+
+	dirDatabases = "db"
+	dirImages    = "images"
+)
+
+// Manifest describes the content of a backup archive.
+type Manifest struct {
+	Version   string          `json:"version"`
+	CreatedAt time.Time       `json:"created_at"`
+	Files     []*ManifestFile `json:"files"`
+}
+
+// ManifestFile is a single file stored in a backup archive.
+type ManifestFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Create writes a new backup archive.
+//
+// Parameters:
+//   - store: The opened databases to snapshot
+//   - target: An existing directory for a new timestamped archive, or the archive path
+//   - imagesPath: The attachment images directory to include
+//
+// Returns:
+//   - string: The path of the written archive
+//   - error: An error if the snapshot or writing the archive fails
+func Create(store *db.Store, target, imagesPath string) (string, error) {
+	archivePath := target
+	if info, err := os.Stat(target); err == nil && info.IsDir() {
+		archivePath = filepath.Join(target, fmt.Sprintf(
+			"%s-backup-%s.tar.gz", env.Name, time.Now().Format("20060102-150405"),
+		))
+	}
+
+	tmpDir, err := os.MkdirTemp("", env.Name+"-backup-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	dbFiles, err := store.Snapshot(tmpDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to snapshot databases: %w", err)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(archivePath), 0700); err != nil {
+		return "", fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	// Write to a temporary file first, a broken archive should never look like a backup
+	tmpArchivePath := archivePath + ".tmp"
+	if err = writeArchive(tmpArchivePath, dbFiles, imagesPath); err != nil {
+		os.Remove(tmpArchivePath)
+		return "", err
+	}
+
+	if err = os.Rename(tmpArchivePath, archivePath); err != nil {
+		os.Remove(tmpArchivePath)
+		return "", fmt.Errorf("failed to move archive into place: %w", err)
+	}
+
+	return archivePath, nil
+}
+
+func writeArchive(archivePath string, dbFiles []string, imagesPath string) error {
+	f, err := os.OpenFile(archivePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+
+	manifest := &Manifest{
+		Version:   version.Get(),
+		CreatedAt: time.Now(),
+	}
+
+	for _, file := range dbFiles {
+		mf, err := addFile(tw, path.Join(dirDatabases, filepath.Base(file)), file)
+		if err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, mf)
+	}
+
+	if imagesPath != "" {
+		err = filepath.WalkDir(imagesPath, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+
+			rel, err := filepath.Rel(imagesPath, p)
+			if err != nil {
+				return err
+			}
+
+			mf, err := addFile(tw, path.Join(dirImages, filepath.ToSlash(rel)), p)
+			if err != nil {
+				return err
+			}
+			manifest.Files = append(manifest.Files, mf)
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to add images: %w", err)
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	err = tw.WriteHeader(&tar.Header{
+		Name:    ManifestName,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: manifest.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write manifest header: %w", err)
+	}
+	if _, err = tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	if err = tw.Close(); err != nil {
+		return fmt.Errorf("failed to close tar writer: %w", err)
+	}
+	if err = gw.Close(); err != nil {
+		return fmt.Errorf("failed to close gzip writer: %w", err)
+	}
+	return f.Sync()
+}
+
+func addFile(tw *tar.Writer, name, file string) (*ManifestFile, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", file, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", file, err)
+	}
+
+	err = tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to write header for %s: %w", name, err)
+	}
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tw, h), f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	return &ManifestFile{
+		Path:   name,
+		Size:   n,
+		SHA256: hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
+
+// Restore extracts a backup archive, verifies it against its manifest and moves
+// the databases and images into place.
+//
+// The databases must not be opened by this process while restoring.
+//
+// Parameters:
+//   - archivePath: The backup archive to restore
+//   - dbPath: The database directory to restore the databases to
+//   - imagesPath: The directory to restore the attachment images to
+//   - force: Overwrite existing databases and images
+//
+// Returns:
+//   - *Manifest: The verified manifest of the archive
+//   - error: An error if the archive is invalid or a file would be overwritten without force
+func Restore(archivePath, dbPath, imagesPath string, force bool) (*Manifest, error) {
+	tmpDir, err := os.MkdirTemp("", env.Name+"-restore-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	manifest, err := extract(archivePath, tmpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = verify(manifest, tmpDir); err != nil {
+		return manifest, err
+	}
+
+	// Check everything before touching a single file
+	if !force {
+		for _, mf := range manifest.Files {
+			dest := destination(mf.Path, dbPath, imagesPath)
+			if _, err := os.Stat(dest); err == nil {
+				if strings.HasPrefix(mf.Path, dirDatabases+"/") {
+					return manifest, fmt.Errorf(
+						"refusing to overwrite existing database %s (is the server still running?), use --force",
+						dest,
+					)
+				}
+				// Images never change, an identical file is not an overwrite
+				if sum, _ := checksum(dest); sum == mf.SHA256 {
+					continue
+				}
+				return manifest, fmt.Errorf(
+					"refusing to overwrite existing file %s, use --force", dest,
+				)
+			}
+		}
+	}
+
+	for _, mf := range manifest.Files {
+		dest := destination(mf.Path, dbPath, imagesPath)
+
+		if strings.HasPrefix(mf.Path, dirDatabases+"/") {
+			// A stale hot journal would be rolled back into the restored
+			// database, and a stale WAL would be applied to it
+			for _, suffix := range []string{"-journal", "-wal", "-shm"} {
+				if err := os.Remove(dest + suffix); err != nil && !os.IsNotExist(err) {
+					return manifest, fmt.Errorf("failed to remove %s: %w", dest+suffix, err)
+				}
+			}
+		}
+
+		if err := copyFile(filepath.Join(tmpDir, filepath.FromSlash(mf.Path)), dest); err != nil {
+			return manifest, err
+		}
+	}
+
+	return manifest, nil
+}
+
+func destination(name, dbPath, imagesPath string) string {
+	if rel, ok := strings.CutPrefix(name, dirImages+"/"); ok {
+		return filepath.Join(imagesPath, filepath.FromSlash(rel))
+	}
+	return filepath.Join(dbPath, path.Base(name))
+}
+
+func extract(archivePath, dir string) (*Manifest, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer gr.Close()
+
+	var manifest *Manifest
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if h.Name == ManifestName {
+			manifest = &Manifest{}
+			if err = json.NewDecoder(tr).Decode(manifest); err != nil {
+				return nil, fmt.Errorf("failed to decode manifest: %w", err)
+			}
+			continue
+		}
+
+		if !filepath.IsLocal(h.Name) {
+			return nil, fmt.Errorf("invalid file path in archive: %s", h.Name)
+		}
+
+		p := filepath.Join(dir, filepath.FromSlash(h.Name))
+		if err = os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+			return nil, err
+		}
+
+		out, err := os.OpenFile(p, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.Copy(out, tr)
+		out.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract %s: %w", h.Name, err)
+		}
+	}
+
+	if manifest == nil {
+		return nil, fmt.Errorf("archive has no %s", ManifestName)
+	}
+
+	return manifest, nil
+}
+
+func verify(manifest *Manifest, dir string) error {
+	for _, name := range db.DatabaseNames() {
+		p := path.Join(dirDatabases, name+".sqlite")
+		if !slices.ContainsFunc(manifest.Files, func(mf *ManifestFile) bool {
+			return mf.Path == p
+		}) {
+			return fmt.Errorf("manifest is missing the %s database", name)
+		}
+	}
+
+	for _, mf := range manifest.Files {
+		if !filepath.IsLocal(mf.Path) ||
+			(!strings.HasPrefix(mf.Path, dirDatabases+"/") && !strings.HasPrefix(mf.Path, dirImages+"/")) {
+			return fmt.Errorf("invalid file path in manifest: %s", mf.Path)
+		}
+
+		p := filepath.Join(dir, filepath.FromSlash(mf.Path))
+		info, err := os.Stat(p)
+		if err != nil {
+			return fmt.Errorf("file %s from manifest is missing in archive", mf.Path)
+		}
+
+		sum, err := checksum(p)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", mf.Path, err)
+		}
+
+		if info.Size() != mf.Size || sum != mf.SHA256 {
+			return fmt.Errorf("checksum mismatch for %s", mf.Path)
+		}
+	}
+
+	return nil
+}
+
+func checksum(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func copyFile(src, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", dest, err)
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	// Write next to the destination and rename, so a file is either old or new
+	tmp := dest + ".restore"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", tmp, err)
+	}
+
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", tmp, err)
+	}
+	if err = out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if err = os.Rename(tmp, dest); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to move %s into place: %w", dest, err)
+	}
+	return nil
+}
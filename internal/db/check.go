@@ -0,0 +1,413 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/shared"
+)
+
+// FixAction is a way to repair a dangling reference.
+type FixAction string
+
+const (
+	// FixDelete removes the referencing row
+	FixDelete FixAction = "delete"
+	// FixNullify resets the reference to "nothing" (0 or an empty string)
+	FixNullify FixAction = "nullify"
+	// FixReassign points the reference to another, existing row
+	FixReassign FixAction = "reassign"
+)
+
+// Reference describes a column pointing to a row in another table, maybe in
+// another database.
+//
+// SQLite can not enforce references across database files, so these are checked
+// by Check, and applied by the delete functions for tools, presses and users.
+type Reference struct {
+	Database string // Database of the referencing table
+	Table    string
+	Column   string
+	Prefix   string // Prefix of the stored value, e.g. "tool_" for linked notes
+
+	TargetDatabase string
+	TargetTable    string // The referenced table, always referenced by its "id" column
+
+	// Cascade is the action applied if the referenced row gets deleted, empty
+	// keeps the referencing rows as they are, also after the referenced row got
+	// purged, Check skips these references
+	Cascade FixAction
+	// Nullable is true if 0 or an empty string means "no reference"
+	Nullable bool
+}
+
+// String returns the reference in the format "database.table.column -> table"
+func (r *Reference) String() string {
+	return fmt.Sprintf("%s.%s.%s -> %s", r.Database, r.Table, r.Column, r.TargetTable)
+}
+
+// Actions returns all fix actions possible for this reference.
+func (r *Reference) Actions() []FixAction {
+	actions := []FixAction{}
+	if r.Cascade == FixDelete {
+		actions = append(actions, FixDelete)
+	}
+	if r.Nullable {
+		actions = append(actions, FixNullify)
+	}
+	return append(actions, FixReassign)
+}
+
+// value returns the stored value for a referenced ID
+func (r *Reference) value(id int64) any {
+	if r.Prefix != "" {
+		return fmt.Sprintf("%s%d", r.Prefix, id)
+	}
+	return id
+}
+
+// zero returns the stored value meaning "no reference"
+func (r *Reference) zero() any {
+	if r.Prefix != "" {
+		return ""
+	}
+	return 0
+}
+
+// references contains all references between the tables of all databases.
+//
+// NOTE: The queries for checking and fixing are built from these, never pass
+// any user input into table or column names.
+var references = []*Reference{
+	{
+		Database: "tool", Table: "tools", Column: "cassette",
+		TargetDatabase: "tool", TargetTable: "tools",
+		Cascade: FixNullify, Nullable: true,
+	},
+	{
+		Database: "tool", Table: "metal_sheets", Column: "tool_id",
+		TargetDatabase: "tool", TargetTable: "tools",
+		Cascade: FixDelete,
+	},
+	{
+		Database: "tool", Table: "tool_regenerations", Column: "tool_id",
+		TargetDatabase: "tool", TargetTable: "tools",
+		Cascade: FixDelete,
+	},
+	{
+		Database: "press", Table: "cycles", Column: "tool_id",
+		TargetDatabase: "tool", TargetTable: "tools",
+		Cascade: FixDelete,
+	},
+	{
+		// The cycles are the wear history of the tools, deleting a press must
+		// not change the total cycles of its tools
+		Database: "press", Table: "cycles", Column: "press_id",
+		TargetDatabase: "press", TargetTable: "presses",
+	},
+	{
+		Database: "press", Table: "presses", Column: "slot_up",
+		TargetDatabase: "tool", TargetTable: "tools",
+		Cascade: FixNullify, Nullable: true,
+	},
+	{
+		Database: "press", Table: "presses", Column: "slot_down",
+		TargetDatabase: "tool", TargetTable: "tools",
+		Cascade: FixNullify, Nullable: true,
+	},
+	{
+		Database: "note", Table: "notes", Column: "linked", Prefix: "tool_",
+		TargetDatabase: "tool", TargetTable: "tools",
+		Cascade: FixDelete, Nullable: true,
+	},
+	{
+		Database: "note", Table: "notes", Column: "linked", Prefix: "press_",
+		TargetDatabase: "press", TargetTable: "presses",
+		Cascade: FixDelete, Nullable: true,
+	},
+	{
+		Database: "user", Table: "cookies", Column: "user_id",
+		TargetDatabase: "user", TargetTable: "users",
+		Cascade: FixDelete,
+	},
+	{
+		Database: "user", Table: "api_keys", Column: "user_id",
+		TargetDatabase: "user", TargetTable: "users",
+		Cascade: FixDelete,
+	},
+	{
+		Database: "user", Table: "login_attempts", Column: "user_id",
+		TargetDatabase: "user", TargetTable: "users",
+		Cascade: FixDelete, Nullable: true,
+	},
+}
+
+// Orphan is a row with a reference to a row which does not exist.
+type Orphan struct {
+	*Reference
+	RowID int64  // SQLite rowid of the referencing row
+	Value string // The dangling value as stored
+}
+
+// -----------------------------------------------------------------------------
+// Check Functions
+// -----------------------------------------------------------------------------
+
+// Check searches all databases for dangling references, references without a
+// Cascade are kept on purpose and not checked.
+//
+// Returns:
+//   - []*Orphan: All rows with a dangling reference, grouped by reference
+//   - error: An error if any database could not be read
+func (s *Store) Check() ([]*Orphan, error) {
+	var orphans []*Orphan
+
+	for _, ref := range references {
+		if ref.Cascade == "" {
+			continue
+		}
+
+		ids, err := s.listIDs(ref.TargetDatabase, ref.TargetTable)
+		if err != nil {
+			return orphans, err
+		}
+
+		db, err := s.database(ref.Database)
+		if err != nil {
+			return orphans, err
+		}
+
+		r, err := db.Query(fmt.Sprintf(`SELECT rowid, %s FROM %s ORDER BY rowid;`, ref.Column, ref.Table))
+		if err != nil {
+			return orphans, fmt.Errorf("failed to check %s: %v", ref, err)
+		}
+
+		for r.Next() {
+			o := &Orphan{Reference: ref}
+			if err = r.Scan(&o.RowID, &o.Value); err != nil {
+				r.Close()
+				return orphans, fmt.Errorf("failed to check %s: %v", ref, err)
+			}
+
+			if ref.Nullable && (o.Value == "" || o.Value == "0") {
+				continue
+			}
+			if !strings.HasPrefix(o.Value, ref.Prefix) {
+				continue // Linked to something else
+			}
+
+			id, err := strconv.ParseInt(strings.TrimPrefix(o.Value, ref.Prefix), 10, 64)
+			if err == nil {
+				if _, ok := ids[id]; ok {
+					continue
+				}
+			}
+
+			orphans = append(orphans, o)
+		}
+		r.Close()
+	}
+
+	return orphans, nil
+}
+
+// FixOrphans repairs dangling references.
+//
+// All orphans get fixed within a single unit of work.
+//
+// Parameters:
+//   - orphans: The orphans to fix, as returned from Check
+//   - action: The fix to apply, must be one of the actions of each orphans reference
+//   - target: The ID to reassign to, only used for FixReassign
+//
+// Returns:
+//   - error: An error if the action is not possible or any fix fails
+func (s *Store) FixOrphans(orphans []*Orphan, action FixAction, target int64) error {
+	checked := make(map[*Reference]bool)
+	for _, o := range orphans {
+		if checked[o.Reference] {
+			continue
+		}
+		checked[o.Reference] = true
+
+		if !slices.Contains(o.Actions(), action) {
+			return fmt.Errorf("%s is not possible for %s", action, o.Reference)
+		}
+
+		if action == FixReassign {
+			ids, err := s.listIDs(o.TargetDatabase, o.TargetTable)
+			if err != nil {
+				return err
+			}
+			if _, ok := ids[target]; !ok {
+				return fmt.Errorf("%s with ID %d does not exist", o.TargetTable, target)
+			}
+		}
+	}
+
+	herr := s.Transaction(func(tx *Tx) *errors.HTTPError {
+		for _, o := range orphans {
+			e, herr := tx.get(o.Database)
+			if herr != nil {
+				return herr
+			}
+
+			var err error
+			switch action {
+			case FixDelete:
+				_, err = e.Exec(fmt.Sprintf(`DELETE FROM %s WHERE rowid = ?;`, o.Table), o.RowID)
+			case FixNullify:
+				_, err = e.Exec(fmt.Sprintf(`UPDATE %s SET %s = ? WHERE rowid = ?;`, o.Table, o.Column),
+					o.zero(), o.RowID)
+			case FixReassign:
+				_, err = e.Exec(fmt.Sprintf(`UPDATE %s SET %s = ? WHERE rowid = ?;`, o.Table, o.Column),
+					o.value(target), o.RowID)
+			}
+			if err != nil {
+				return errors.NewHTTPError(fmt.Errorf("failed to fix %s for row %d: %v", o.Reference, o.RowID, err))
+			}
+		}
+		return nil
+	})
+	if herr != nil {
+		return herr
+	}
+
+	return nil
+}
+
+// listIDs returns all IDs of a table
+func (s *Store) listIDs(database, table string) (map[int64]struct{}, error) {
+	db, err := s.database(database)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := db.Query(fmt.Sprintf(`SELECT id FROM %s;`, table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s IDs: %v", table, err)
+	}
+	defer r.Close()
+
+	ids := make(map[int64]struct{})
+	for r.Next() {
+		var id int64
+		if err = r.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to list %s IDs: %v", table, err)
+		}
+		ids[id] = struct{}{}
+	}
+
+	return ids, nil
+}
+
+// -----------------------------------------------------------------------------
+// Cascade Functions
+// -----------------------------------------------------------------------------
+
+// cascade applies the cascade rule of all references to a row of table, which
+// is about to be deleted.
+//
+// If d is not nil the row gets soft deleted, referencing rows in soft deletable
+// tables are marked as deleted with the same origin, so they can be restored
+// together. Cleared references are recorded in cleared_references for the restore.
+// Rows in all other tables are kept until the row gets purged.
+func (tx *Tx) cascade(table string, id int64, d *deletion) *errors.HTTPError {
+	for _, ref := range references {
+		if ref.TargetTable != table || ref.Cascade == "" {
+			continue
+		}
+		if d != nil && ref.Cascade == FixDelete && !isSoftDeletable(ref.Table) {
+			continue
+		}
+
+		e, herr := tx.get(ref.Database)
+		if herr != nil {
+			return herr
+		}
+
+		var err error
+		if ref.Cascade == FixNullify && d != nil {
+			_, err = e.Exec(fmt.Sprintf(`INSERT OR IGNORE INTO cleared_references (deleted_with, table_name, column_name, row_id) SELECT :deleted_with, :table, :column, id FROM %s WHERE %s = :value;`, ref.Table, ref.Column),
+				sql.Named("deleted_with", d.with), sql.Named("table", ref.Table), sql.Named("column", ref.Column), sql.Named("value", ref.value(id)))
+			if err != nil {
+				return errors.NewHTTPError(fmt.Errorf("failed to record %s cleared by %s ID %d: %v",
+					ref, table, id, err))
+			}
+		}
+
+		switch {
+		case ref.Cascade == FixDelete && d != nil:
+			_, err = e.Exec(fmt.Sprintf(`UPDATE %s SET deleted_at = :deleted_at, deleted_by = :deleted_by, deleted_with = :deleted_with WHERE %s = :value AND deleted_at = 0;`, ref.Table, ref.Column),
+				sql.Named("deleted_at", d.at), sql.Named("deleted_by", d.by), sql.Named("deleted_with", d.with), sql.Named("value", ref.value(id)))
+		case ref.Cascade == FixDelete:
+			_, err = e.Exec(fmt.Sprintf(`DELETE FROM %s WHERE %s = :value;`, ref.Table, ref.Column),
+				sql.Named("value", ref.value(id)))
+		case ref.Cascade == FixNullify:
+			_, err = e.Exec(fmt.Sprintf(`UPDATE %s SET %s = :zero WHERE %s = :value;`, ref.Table, ref.Column, ref.Column),
+				sql.Named("zero", ref.zero()), sql.Named("value", ref.value(id)))
+		}
+		if err != nil {
+			return errors.NewHTTPError(fmt.Errorf("failed to cascade delete of %s ID %d to %s: %v",
+				table, id, ref, err))
+		}
+	}
+
+	return nil
+}
+
+// deleteWithCascade runs a delete query for a row of table, and applies all
+// cascade rules for it within the same unit of work.
+func (s *Store) deleteWithCascade(database, table, query string, id int64) *errors.HTTPError {
+	return s.Transaction(func(tx *Tx) *errors.HTTPError {
+		if herr := tx.cascade(table, id, nil); herr != nil {
+			return herr
+		}
+
+		e, herr := tx.get(database)
+		if herr != nil {
+			return herr
+		}
+
+		if _, err := e.Exec(query, sql.Named("id", id)); err != nil {
+			return errors.NewHTTPError(err)
+		}
+		return nil
+	})
+}
+
+// softDeleteWithCascade runs a soft delete query for a row of table, and applies
+// all cascade rules for it within the same unit of work.
+//
+// The query gets the named parameters "id", "deleted_at", "deleted_by" and
+// "deleted_with".
+func (s *Store) softDeleteWithCascade(database, table, query string, id int64, by shared.TelegramID) *errors.HTTPError {
+	d := &deletion{at: shared.NewUnixMilli(time.Now()), by: by, with: deletedWith(table, id)}
+
+	return s.Transaction(func(tx *Tx) *errors.HTTPError {
+		if herr := tx.cascade(table, id, d); herr != nil {
+			return herr
+		}
+
+		e, herr := tx.get(database)
+		if herr != nil {
+			return herr
+		}
+
+		_, err := e.Exec(query,
+			sql.Named("id", id),
+			sql.Named("deleted_at", d.at),
+			sql.Named("deleted_by", d.by),
+			sql.Named("deleted_with", d.with),
+		)
+		if err != nil {
+			return errors.NewHTTPError(err)
+		}
+		return nil
+	})
+}
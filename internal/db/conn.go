@@ -0,0 +1,57 @@
+package db
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/knackwurstking/pg-press/internal/metrics"
+)
+
+// conn is a database connection recording the duration of every query, see
+// metrics.DBQueryDuration. Everything else is passed to the *sql.DB.
+//
+// Exec holds the write gate of the Store, see Store.Snapshot.
+type conn struct {
+	*sql.DB
+	name   string
+	writes *sync.RWMutex
+}
+
+func (c *conn) Exec(query string, args ...any) (sql.Result, error) {
+	c.writes.RLock()
+	defer c.writes.RUnlock()
+	defer metrics.DBQueryDuration.ObserveSince(time.Now(), c.name)
+	return c.DB.Exec(query, args...)
+}
+
+func (c *conn) Query(query string, args ...any) (*sql.Rows, error) {
+	defer metrics.DBQueryDuration.ObserveSince(time.Now(), c.name)
+	return c.DB.Query(query, args...)
+}
+
+func (c *conn) QueryRow(query string, args ...any) *sql.Row {
+	defer metrics.DBQueryDuration.ObserveSince(time.Now(), c.name)
+	return c.DB.QueryRow(query, args...)
+}
+
+// txConn is a transaction recording the duration of every query, like conn
+type txConn struct {
+	*sql.Tx
+	name string
+}
+
+func (c *txConn) Exec(query string, args ...any) (sql.Result, error) {
+	defer metrics.DBQueryDuration.ObserveSince(time.Now(), c.name)
+	return c.Tx.Exec(query, args...)
+}
+
+func (c *txConn) Query(query string, args ...any) (*sql.Rows, error) {
+	defer metrics.DBQueryDuration.ObserveSince(time.Now(), c.name)
+	return c.Tx.Query(query, args...)
+}
+
+func (c *txConn) QueryRow(query string, args ...any) *sql.Row {
+	defer metrics.DBQueryDuration.ObserveSince(time.Now(), c.name)
+	return c.Tx.QueryRow(query, args...)
+}
@@ -12,16 +12,18 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/shared"
 )
 
 // Scannable interface defines the required scanning method for database rows.
@@ -29,29 +31,80 @@ type Scannable interface {
 	Scan(dest ...any) error
 }
 
-// Database connection variables for different modules.
-var (
-	dbTool    *sql.DB
-	dbPress   *sql.DB
-	dbNote    *sql.DB
-	dbUser    *sql.DB
-	dbReports *sql.DB
-)
+// lastInsertID returns the ID of the row inserted by an add query
+func lastInsertID(r sql.Result) (shared.EntityID, error) {
+	id, err := r.LastInsertId()
+	return shared.EntityID(id), err
+}
+
+// Store holds the connections to all databases.
+//
+// All database functions are methods on the Store, grouped by the per-domain
+// repository interfaces (see repositories.go).
+type Store struct {
+	path string // path of the database directory, see dsn
+
+	// writes is read locked by every write and unit of work, Snapshot locks it
+	// to copy all databases at the same point
+	writes sync.RWMutex
+
+	tool    *conn
+	press   *conn
+	note    *conn
+	user    *conn
+	reports *conn
+}
+
+// databaseNames contains all database names in the order they get opened and migrated.
+var databaseNames = []string{"tool", "press", "note", "user", "reports"}
+
+// Open initializes and opens all database connections and refuses databases
+// with pending migrations. Migrations are only applied explicitly with Migrate,
+// by the "db migrate" command or on server start.
+//
+// Parameters:
+//   - path: The directory path where database files should be stored
+//   - allowCreate: Whether to create new database files if they don't exist
+//
+// Returns:
+//   - *Store: The store holding all database connections
+//   - error: An error if any database connection or the version check fails, or
+//     if a database needs a migration
+func Open(path string, allowCreate bool) (*Store, error) {
+	s, err := Connect(path, allowCreate)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := s.Migrate(true)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	if len(pending) > 0 {
+		s.Close()
+		return nil, newSchemaOutdatedError(pending)
+	}
+
+	return s, nil
+}
 
-// Open initializes and opens all database connections.
+// Connect opens all database connections without applying any migrations.
 //
 // It creates the necessary directories for database files, configures SQLite
-// connection parameters for optimal performance, and initializes all required tables.
+// connection parameters for optimal performance, and refuses databases with a
+// schema version newer than this binary supports.
 //
 // Parameters:
 //   - path: The directory path where database files should be stored
 //   - allowCreate: Whether to create new database files if they don't exist
 //
 // Returns:
-//   - error: An error if any database connection or initialization fails
-func Open(path string, allowCreate bool) error {
+//   - *Store: The store holding all database connections
+//   - error: An error if any database connection or the version check fails
+func Connect(path string, allowCreate bool) (*Store, error) {
 	if err := os.MkdirAll(path, 0700); err != nil {
-		return fmt.Errorf("failed to create database directory: %v", err)
+		return nil, fmt.Errorf("failed to create database directory: %v", err)
 	}
 
 	mode := "rw"
@@ -59,20 +112,17 @@ func Open(path string, allowCreate bool) error {
 		mode = "rwc"
 	}
 
+	s := &Store{path: path}
+	m := &sync.Mutex{}
 	wg := &sync.WaitGroup{}
-	names := []string{"tool", "press", "note", "user", "reports"}
-	chErr := make(chan error, len(names))
-	for _, name := range names {
+	chErr := make(chan error, len(databaseNames))
+	for _, name := range databaseNames {
 		slog.Debug("Opening database",
 			"name", name,
 			"path", path)
 
 		wg.Go(func() {
-			path := fmt.Sprintf(
-				"file:%s.sqlite?cache=shared&mode=%s&journal=WAL&synchronous=1",
-				filepath.Join(path, name), mode,
-			)
-			db, err := sql.Open("sqlite3", path)
+			db, err := sql.Open("sqlite3", dsn(path, name, mode))
 			if err != nil {
 				chErr <- fmt.Errorf("failed to open %s database: %v", name, err)
 				return
@@ -83,60 +133,28 @@ func Open(path string, allowCreate bool) error {
 			db.SetMaxIdleConns(5)                  // Keep some connections alive
 			db.SetConnMaxLifetime(5 * time.Minute) // Close connections after 5 minutes
 
+			c := &conn{DB: db, name: name, writes: &s.writes}
+			m.Lock()
 			switch name {
 			case "tool":
-				dbTool = db
-				if err = createTable(db, sqlCreateMetalSheetsTable); err != nil {
-					chErr <- errors.Wrap(err, "failed to create metal_sheets table")
-					return
-				}
-				if err = createTable(db, sqlCreateToolRegenerationsTable); err != nil {
-					chErr <- errors.Wrap(err, "failed to create tool_regenerations table")
-					return
-				}
-				if err = createTable(db, sqlCreateToolsTable); err != nil {
-					chErr <- errors.Wrap(err, "failed to create tools table")
-					return
-				}
-
+				s.tool = c
 			case "press":
-				dbPress = db
-				if err = createTable(db, sqlCreateCyclesTable); err != nil {
-					chErr <- errors.Wrap(err, "failed to create cycles table")
-					return
-				}
-				if err = createTable(db, sqlCreatePressesTable); err != nil {
-					chErr <- errors.Wrap(err, "failed to create presses table")
-					return
-				}
-
+				s.press = c
 			case "note":
-				dbNote = db
-				if err := createTable(db, sqlCreateNotesTable); err != nil {
-					chErr <- errors.Wrap(err, "failed to create notes table")
-					return
-				}
-
+				s.note = c
 			case "user":
-				dbUser = db
-				if err := createTable(db, sqlCreateCookiesTable); err != nil {
-					chErr <- errors.Wrap(err, "failed to create cookies table")
-					return
-				}
-				if err := createTable(db, sqlCreateUsersTable); err != nil {
-					chErr <- errors.Wrap(err, "failed to create users table")
-					return
-				}
-
+				s.user = c
 			case "reports":
-				dbReports = db
-				if err := createTable(db, sqlCreateTroubleReportsTable); err != nil {
-					chErr <- errors.Wrap(err, "failed to create trouble_reports table")
-					return
-				}
+				s.reports = c
+			}
+			m.Unlock()
+
+			if err := checkJournalMode(name, db); err != nil {
+				chErr <- err
+				return
 			}
 
-			chErr <- nil
+			chErr <- checkSchemaVersion(name, db)
 		})
 	}
 	wg.Wait()
@@ -150,32 +168,108 @@ func Open(path string, allowCreate bool) error {
 	}
 
 	if len(errs) > 0 {
-		return fmt.Errorf("%s", strings.Join(errs, "\n"))
+		s.Close()
+		return nil, fmt.Errorf("%s", strings.Join(errs, "\n"))
 	}
-	return nil
+	return s, nil
 }
 
 // Close closes all open database connections.
-func Close() {
-	for _, db := range []*sql.DB{dbTool, dbPress, dbNote, dbUser} {
-		if db != nil {
-			db.Close()
+//
+// Closing continues on errors, the returned error lists all failed databases.
+func (s *Store) Close() error {
+	var errs []string
+	for _, name := range databaseNames {
+		db, err := s.database(name)
+		if err != nil {
+			continue // Not opened
+		}
+
+		if err := db.Close(); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to close %s database: %v", name, err))
 		}
 	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return nil
 }
 
-// createTable executes a SQL statement to create a database table.
-//
-// Parameters:
-//   - db: The database connection to use
-//   - query: The SQL CREATE TABLE statement to execute
-//
-// Returns:
-//   - error: An error if the table creation fails
-func createTable(db *sql.DB, query string) error {
-	_, err := db.Exec(query)
+// Ping checks the connection of a database, see DatabaseNames.
+func (s *Store) Ping(ctx context.Context, name string) error {
+	db, err := s.database(name)
 	if err != nil {
-		return fmt.Errorf("failed to create table: %v", err)
+		return err
+	}
+	return db.PingContext(ctx)
+}
+
+// DatabaseNames returns the names of all databases, in the order they get migrated.
+func DatabaseNames() []string {
+	return slices.Clone(databaseNames)
+}
+
+// dsn returns the data source name of a database file in path.
+//
+// Every connection switches the database to the rollback journal, SQLite
+// commits a transaction over attached databases atomically only without WAL,
+// see Transaction. The journal mode is stored in the file, so this also
+// converts databases created in WAL mode by older versions.
+func dsn(path, name, mode string) string {
+	return fmt.Sprintf(
+		"file:%s.sqlite?cache=shared&mode=%s&synchronous=1&_journal_mode=DELETE",
+		filepath.Join(path, name), mode,
+	)
+}
+
+// checkJournalMode refuses a database still using the write-ahead log, a unit
+// of work would not be atomic over all databases, see dsn.
+func checkJournalMode(name string, db *sql.DB) error {
+	var mode string
+	if err := db.QueryRow(`PRAGMA journal_mode;`).Scan(&mode); err != nil {
+		return fmt.Errorf("failed to get journal mode for %s database: %v", name, err)
+	}
+
+	if strings.EqualFold(mode, "wal") {
+		return fmt.Errorf(
+			"%s database is still in WAL mode, stop all other processes using it and try again",
+			name,
+		)
 	}
 	return nil
 }
+
+// database returns the connection for a database name.
+func (s *Store) database(name string) (*sql.DB, error) {
+	c, err := s.conn(name)
+	if err != nil {
+		return nil, err
+	}
+	return c.DB, nil
+}
+
+// conn returns the connection of a database, writes through it are gated like
+// the Store methods, see Store.writes
+func (s *Store) conn(name string) (*conn, error) {
+	var c *conn
+	switch name {
+	case "tool":
+		c = s.tool
+	case "press":
+		c = s.press
+	case "note":
+		c = s.note
+	case "user":
+		c = s.user
+	case "reports":
+		c = s.reports
+	default:
+		return nil, fmt.Errorf("unknown database: %s", name)
+	}
+
+	if c == nil {
+		return nil, fmt.Errorf("%s database is not open", name)
+	}
+	return c, nil
+}
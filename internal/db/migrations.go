@@ -0,0 +1,446 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Migration describes a single, versioned schema change for one of the databases.
+//
+// Versions are counted per database and must be strictly increasing, starting with 1.
+// Never change a migration after it was released, always append a new one instead.
+type Migration struct {
+	Database    string
+	Version     int
+	Description string
+	Queries     []string
+
+	// Func runs after the queries within the same transaction, for changes which
+	// can not be done in SQL, optional
+	Func func(tx *sql.Tx) error
+}
+
+// migrations contains all schema migrations for all databases, grouped by database
+// and ordered by version.
+var migrations = map[string][]*Migration{
+	"tool": {
+		{
+			Version:     1,
+			Description: "Create metal_sheets, tool_regenerations and tools tables",
+			Queries: []string{
+				sqlCreateMetalSheetsTable,
+				sqlCreateToolRegenerationsTable,
+				sqlCreateToolsTable,
+			},
+		},
+		{
+			Version:     2,
+			Description: "Add soft delete columns to metal_sheets and tools",
+			Queries: []string{
+				`ALTER TABLE metal_sheets ADD COLUMN deleted_at INTEGER NOT NULL DEFAULT 0;`,
+				`ALTER TABLE metal_sheets ADD COLUMN deleted_by INTEGER NOT NULL DEFAULT 0;`,
+				`ALTER TABLE tools ADD COLUMN deleted_at INTEGER NOT NULL DEFAULT 0;`,
+				`ALTER TABLE tools ADD COLUMN deleted_by INTEGER NOT NULL DEFAULT 0;`,
+			},
+		},
+		{
+			Version:     3,
+			Description: "Create tools_fts search index",
+			Queries: []string{
+				sqlCreateToolsFTSTable,
+				sqlCreateToolsFTSTriggers,
+				sqlFillToolsFTSTable,
+			},
+		},
+		{
+			Version:     4,
+			Description: "Create tool_thresholds table and add cycle threshold overrides to tools",
+			Queries: []string{
+				sqlCreateToolThresholdsTable,
+				`ALTER TABLE tools ADD COLUMN cycles_warning INTEGER NOT NULL DEFAULT 0;`,
+				`ALTER TABLE tools ADD COLUMN cycles_error INTEGER NOT NULL DEFAULT 0;`,
+			},
+		},
+		{
+			Version:     5,
+			Description: "Add deleted_with column to metal_sheets and tools and create cleared_references table",
+			Queries: []string{
+				`ALTER TABLE metal_sheets ADD COLUMN deleted_with TEXT NOT NULL DEFAULT '';`,
+				`ALTER TABLE tools ADD COLUMN deleted_with TEXT NOT NULL DEFAULT '';`,
+				sqlCreateClearedReferencesTable,
+			},
+		},
+	},
+	"press": {
+		{
+			Version:     1,
+			Description: "Create cycles and presses tables",
+			Queries: []string{
+				sqlCreateCyclesTable,
+				sqlCreatePressesTable,
+			},
+		},
+		{
+			Version:     2,
+			Description: "Add soft delete columns to cycles and presses",
+			Queries: []string{
+				`ALTER TABLE cycles ADD COLUMN deleted_at INTEGER NOT NULL DEFAULT 0;`,
+				`ALTER TABLE cycles ADD COLUMN deleted_by INTEGER NOT NULL DEFAULT 0;`,
+				`ALTER TABLE presses ADD COLUMN deleted_at INTEGER NOT NULL DEFAULT 0;`,
+				`ALTER TABLE presses ADD COLUMN deleted_by INTEGER NOT NULL DEFAULT 0;`,
+			},
+		},
+		{
+			Version:     3,
+			Description: "Add performed_by column to cycles",
+			Queries: []string{
+				`ALTER TABLE cycles ADD COLUMN performed_by INTEGER NOT NULL DEFAULT 0;`,
+			},
+		},
+		{
+			Version:     4,
+			Description: "Add deleted_with column to cycles and presses",
+			Queries: []string{
+				`ALTER TABLE cycles ADD COLUMN deleted_with TEXT NOT NULL DEFAULT '';`,
+				`ALTER TABLE presses ADD COLUMN deleted_with TEXT NOT NULL DEFAULT '';`,
+			},
+		},
+	},
+	"note": {
+		{
+			Version:     1,
+			Description: "Create notes table",
+			Queries: []string{
+				sqlCreateNotesTable,
+			},
+		},
+		{
+			Version:     2,
+			Description: "Add soft delete columns to notes",
+			Queries: []string{
+				`ALTER TABLE notes ADD COLUMN deleted_at INTEGER NOT NULL DEFAULT 0;`,
+				`ALTER TABLE notes ADD COLUMN deleted_by INTEGER NOT NULL DEFAULT 0;`,
+			},
+		},
+		{
+			Version:     3,
+			Description: "Create notes_fts search index",
+			Queries: []string{
+				sqlCreateNotesFTSTable,
+				sqlCreateNotesFTSTriggers,
+				sqlFillNotesFTSTable,
+			},
+		},
+		{
+			Version:     4,
+			Description: "Add deleted_with column to notes",
+			Queries: []string{
+				`ALTER TABLE notes ADD COLUMN deleted_with TEXT NOT NULL DEFAULT '';`,
+			},
+		},
+	},
+	"user": {
+		{
+			Version:     1,
+			Description: "Create cookies and users tables",
+			Queries: []string{
+				sqlCreateCookiesTable,
+				sqlCreateUsersTable,
+			},
+		},
+		{
+			Version:     2,
+			Description: "Create audit_log table",
+			Queries: []string{
+				sqlCreateAuditLogTable,
+			},
+		},
+		{
+			Version:     3,
+			Description: "Create feeds table and add last_feed column to users",
+			Queries: []string{
+				sqlCreateFeedsTable,
+				`ALTER TABLE users ADD COLUMN last_feed INTEGER NOT NULL DEFAULT 0;`,
+			},
+		},
+		{
+			Version:     4,
+			Description: "Add role column to users",
+			Queries: []string{
+				`ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'operator';`,
+			},
+		},
+		{
+			Version:     5,
+			Description: "Create api_keys table and move the hashed API keys of users",
+			Queries: []string{
+				sqlCreateApiKeysTable,
+			},
+			Func: migrateUserApiKeys,
+		},
+		{
+			Version:     6,
+			Description: "Remove the plain text api_key column from users",
+			Queries: []string{
+				sqlCreateUsersTableWithoutApiKey,
+				`INSERT INTO users_new (id, name, last_feed, role) SELECT id, name, last_feed, role FROM users;`,
+				`DROP TABLE users;`,
+				`ALTER TABLE users_new RENAME TO users;`,
+			},
+		},
+		{
+			Version:     7,
+			Description: "Create login_attempts and lockouts tables",
+			Queries: []string{
+				sqlCreateLoginAttemptsTable,
+				sqlCreateLockoutsTable,
+			},
+		},
+		{
+			Version:     8,
+			Description: "Add csrf_token column to cookies",
+			Queries: []string{
+				`ALTER TABLE cookies ADD COLUMN csrf_token TEXT NOT NULL DEFAULT '';`,
+			},
+		},
+	},
+	"reports": {
+		{
+			Version:     1,
+			Description: "Create trouble_reports table",
+			Queries: []string{
+				sqlCreateTroubleReportsTable,
+			},
+		},
+		{
+			Version:     2,
+			Description: "Add soft delete columns to trouble_reports",
+			Queries: []string{
+				`ALTER TABLE trouble_reports ADD COLUMN deleted_at INTEGER NOT NULL DEFAULT 0;`,
+				`ALTER TABLE trouble_reports ADD COLUMN deleted_by INTEGER NOT NULL DEFAULT 0;`,
+			},
+		},
+		{
+			Version:     3,
+			Description: "Create trouble_reports_fts search index",
+			Queries: []string{
+				sqlCreateTroubleReportsFTSTable,
+				sqlCreateTroubleReportsFTSTriggers,
+				sqlFillTroubleReportsFTSTable,
+			},
+		},
+		{
+			Version:     4,
+			Description: "Add deleted_with column to trouble_reports",
+			Queries: []string{
+				`ALTER TABLE trouble_reports ADD COLUMN deleted_with TEXT NOT NULL DEFAULT '';`,
+			},
+		},
+	},
+}
+
+func init() {
+	for name, list := range migrations {
+		for i, m := range list {
+			if m.Version != i+1 {
+				panic(fmt.Sprintf("invalid migration version %d for the %s database, expected %d",
+					m.Version, name, i+1))
+			}
+			m.Database = name
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Table Creation Statements
+// -----------------------------------------------------------------------------
+
+const (
+	sqlCreateSchemaVersionTable string = `
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version 	INTEGER NOT NULL,
+			description TEXT NOT NULL,
+			applied_at 	INTEGER NOT NULL,
+
+			PRIMARY KEY("version")
+		);
+	`
+
+	sqlHasSchemaVersionTable string = `
+		SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_version';
+	`
+
+	sqlGetSchemaVersion string = `
+		SELECT COALESCE(MAX(version), 0) FROM schema_version;
+	`
+
+	sqlAddSchemaVersion string = `
+		INSERT INTO schema_version (version, description, applied_at)
+		VALUES (:version, :description, :applied_at);
+	`
+)
+
+// -----------------------------------------------------------------------------
+// Migration Functions
+// -----------------------------------------------------------------------------
+
+// LatestSchemaVersion returns the schema version this binary expects for a database.
+func LatestSchemaVersion(name string) int {
+	return len(migrations[name])
+}
+
+// SchemaVersion returns the current schema version stored in a database.
+//
+// A database without a schema_version table reports version 0.
+func (s *Store) SchemaVersion(name string) (int, error) {
+	db, err := s.database(name)
+	if err != nil {
+		return 0, err
+	}
+	return schemaVersion(db)
+}
+
+// Migrate applies all pending migrations to all open databases.
+//
+// Each migration runs inside its own transaction together with the update of the
+// schema_version table, so a failing migration leaves the database at the previous
+// version.
+//
+// Parameters:
+//   - dryRun: Only report the pending migrations, do not touch the databases
+//
+// Returns:
+//   - []*Migration: The migrations applied (or pending, if dryRun is set)
+//   - error: An error if reading the schema version or applying a migration fails
+func (s *Store) Migrate(dryRun bool) ([]*Migration, error) {
+	var pending []*Migration
+
+	for _, name := range databaseNames {
+		db, err := s.database(name)
+		if err != nil {
+			return pending, err
+		}
+
+		version, err := schemaVersion(db)
+		if err != nil {
+			return pending, fmt.Errorf("failed to get schema version for %s database: %v", name, err)
+		}
+		if latest := LatestSchemaVersion(name); version > latest {
+			return pending, newSchemaTooNewError(name, version, latest)
+		}
+
+		if !dryRun && version < LatestSchemaVersion(name) {
+			if err = checkFTS5(db); err != nil {
+				return pending, fmt.Errorf("failed to migrate %s database: %v", name, err)
+			}
+		}
+
+		for _, m := range migrations[name][version:] {
+			if !dryRun {
+				slog.Info("Applying database migration",
+					"database", name,
+					"version", m.Version,
+					"description", m.Description)
+
+				if err = applyMigration(db, m); err != nil {
+					return pending, fmt.Errorf(
+						"failed to apply migration %d for %s database: %v",
+						m.Version, name, err,
+					)
+				}
+			}
+			pending = append(pending, m)
+		}
+	}
+
+	return pending, nil
+}
+
+// checkSchemaVersion makes sure the database is not newer than this binary.
+func checkSchemaVersion(name string, db *sql.DB) error {
+	version, err := schemaVersion(db)
+	if err != nil {
+		return fmt.Errorf("failed to get schema version for %s database: %v", name, err)
+	}
+
+	if latest := LatestSchemaVersion(name); version > latest {
+		return newSchemaTooNewError(name, version, latest)
+	}
+
+	return nil
+}
+
+func newSchemaTooNewError(name string, version, latest int) error {
+	return fmt.Errorf(
+		"%s database has schema version %d, but this binary only supports up to version %d, please update",
+		name, version, latest,
+	)
+}
+
+func newSchemaOutdatedError(pending []*Migration) error {
+	var names []string
+	for _, m := range pending {
+		if !slices.Contains(names, m.Database) {
+			names = append(names, m.Database)
+		}
+	}
+	return fmt.Errorf(
+		"%d pending migrations (%s databases), run \"pg-press db migrate\" first",
+		len(pending), strings.Join(names, ", "),
+	)
+}
+
+func schemaVersion(db *sql.DB) (int, error) {
+	var count int
+	if err := db.QueryRow(sqlHasSchemaVersionTable).Scan(&count); err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	var version int
+	if err := db.QueryRow(sqlGetSchemaVersion).Scan(&version); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+func applyMigration(db *sql.DB, m *Migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(sqlCreateSchemaVersionTable); err != nil {
+		return err
+	}
+
+	for _, query := range m.Queries {
+		if _, err = tx.Exec(query); err != nil {
+			return err
+		}
+	}
+
+	if m.Func != nil {
+		if err = m.Func(tx); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(sqlAddSchemaVersion,
+		sql.Named("version", m.Version),
+		sql.Named("description", m.Description),
+		sql.Named("applied_at", time.Now().UnixMilli()),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
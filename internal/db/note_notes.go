@@ -43,21 +43,24 @@ WHERE id = :id;`
 	sqlGetNote string = `
 SELECT id, level, content, created_at, linked
 FROM notes
-WHERE id = :id;`
+WHERE id = :id AND deleted_at = 0;`
 
 	sqlListNotes string = `
 SELECT id, level, content, created_at, linked
 FROM notes
+WHERE deleted_at = 0
 ORDER BY created_at DESC;`
 
 	sqlListNotesForLinked string = `
 SELECT id, level, content, created_at, linked
 FROM notes
+WHERE deleted_at = 0
 ORDER BY created_at DESC;`
 
 	sqlDeleteNote string = `
-DELETE FROM notes
-WHERE id = :id;`
+UPDATE notes
+SET deleted_at = :deleted_at, deleted_by = :deleted_by, deleted_with = :deleted_with
+WHERE id = :id AND deleted_at = 0;`
 )
 
 // -----------------------------------------------------------------------------
@@ -65,7 +68,7 @@ WHERE id = :id;`
 // -----------------------------------------------------------------------------
 
 // AddNote adds a new note to the database
-func AddNote(note *shared.Note) *errors.HTTPError {
+func (s *Store) AddNote(note *shared.Note) *errors.HTTPError {
 	if verr := note.Validate(); verr != nil {
 		return verr.HTTPError().Wrap("invalid note data")
 	}
@@ -88,19 +91,23 @@ func AddNote(note *shared.Note) *errors.HTTPError {
 		sql.Named("linked", note.Linked),
 	)
 
-	if _, err := dbNote.Exec(query, queryArgs...); err != nil {
+	r, err := s.note.Exec(query, queryArgs...)
+	if err != nil {
+		return errors.NewHTTPError(err)
+	}
+	if note.ID, err = lastInsertID(r); err != nil {
 		return errors.NewHTTPError(err)
 	}
 	return nil
 }
 
 // UpdateNote updates an existing note in the database
-func UpdateNote(note *shared.Note) *errors.HTTPError {
+func (s *Store) UpdateNote(note *shared.Note) *errors.HTTPError {
 	if verr := note.Validate(); verr != nil {
 		return verr.HTTPError().Wrap("invalid note data")
 	}
 
-	_, err := dbNote.Exec(sqlUpdateNote,
+	_, err := s.note.Exec(sqlUpdateNote,
 		sql.Named("id", note.ID),
 		sql.Named("level", note.Level),
 		sql.Named("content", note.Content),
@@ -114,13 +121,13 @@ func UpdateNote(note *shared.Note) *errors.HTTPError {
 }
 
 // GetNote retrieves a note by its ID
-func GetNote(id shared.EntityID) (*shared.Note, *errors.HTTPError) {
-	return ScanNote(dbNote.QueryRow(sqlGetNote, sql.Named("id", id)))
+func (s *Store) GetNote(id shared.EntityID) (*shared.Note, *errors.HTTPError) {
+	return ScanNote(s.note.QueryRow(sqlGetNote, sql.Named("id", id)))
 }
 
 // ListNotes retrieves all notes from the database
-func ListNotes() ([]*shared.Note, *errors.HTTPError) {
-	rows, err := dbNote.Query(sqlListNotes)
+func (s *Store) ListNotes() ([]*shared.Note, *errors.HTTPError) {
+	rows, err := s.note.Query(sqlListNotes)
 	if err != nil {
 		return nil, errors.NewHTTPError(err)
 	}
@@ -138,8 +145,8 @@ func ListNotes() ([]*shared.Note, *errors.HTTPError) {
 }
 
 // ListNotesForLinked retrieves notes linked to a specific entity
-func ListNotesForLinked(linked string, id int) ([]*shared.Note, *errors.HTTPError) {
-	rows, err := dbNote.Query(sqlListNotesForLinked)
+func (s *Store) ListNotesForLinked(linked string, id int) ([]*shared.Note, *errors.HTTPError) {
+	rows, err := s.note.Query(sqlListNotesForLinked)
 	if err != nil {
 		return nil, errors.NewHTTPError(err)
 	}
@@ -167,13 +174,9 @@ func ListNotesForLinked(linked string, id int) ([]*shared.Note, *errors.HTTPErro
 	return notes[:n], nil
 }
 
-// DeleteNote removes a note from the database
-func DeleteNote(id shared.EntityID) *errors.HTTPError {
-	_, err := dbNote.Exec(sqlDeleteNote, sql.Named("id", id))
-	if err != nil {
-		return errors.NewHTTPError(err)
-	}
-	return nil
+// DeleteNote moves a note to the trash
+func (s *Store) DeleteNote(id shared.EntityID, deletedBy shared.TelegramID) *errors.HTTPError {
+	return s.softDeleteWithCascade("note", "notes", sqlDeleteNote, int64(id), deletedBy)
 }
 
 // -----------------------------------------------------------------------------
@@ -2,6 +2,7 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 
 	"github.com/knackwurstking/pg-press/internal/errors"
@@ -27,12 +28,12 @@ CREATE TABLE IF NOT EXISTS cycles (
 CREATE INDEX IF NOT EXISTS idx_cycles_press_stop ON cycles(press_id, stop DESC);`
 
 	sqlAddCycle string = `
-INSERT INTO cycles (tool_id, press_id, cycles, stop)
-VALUES (:tool_id, :press_id, :cycles, :stop)`
+INSERT INTO cycles (tool_id, press_id, cycles, stop, performed_by)
+VALUES (:tool_id, :press_id, :cycles, :stop, :performed_by)`
 
 	sqlAddCycleWithID string = `
-INSERT INTO cycles (id, tool_id, press_id, cycles, stop)
-VALUES (:id, :tool_id, :press_id, :cycles, :stop)`
+INSERT INTO cycles (id, tool_id, press_id, cycles, stop, performed_by)
+VALUES (:id, :tool_id, :press_id, :cycles, :stop, :performed_by)`
 
 	sqlUpdateCycle string = `
 UPDATE cycles
@@ -40,35 +41,75 @@ SET
 	tool_id = :tool_id,
 	press_id = :press_id,
 	cycles = :cycles,
-	stop = :stop
+	stop = :stop,
+	performed_by = :performed_by
 WHERE id = :id`
 
 	sqlDeleteCycle string = `
-DELETE FROM cycles
-WHERE id = :id;`
+UPDATE cycles
+SET deleted_at = :deleted_at, deleted_by = :deleted_by, deleted_with = :deleted_with
+WHERE id = :id AND deleted_at = 0;`
 
 	sqlGetCycle string = `
-SELECT id, tool_id, press_id, cycles, stop
-FROM cycles
-WHERE id = :id`
-
-	sqlListToolCycles string = `
-SELECT id, tool_id, press_id, cycles, stop
+SELECT id, tool_id, press_id, cycles, stop, performed_by
 FROM cycles
-WHERE tool_id = :tool_id
-ORDER BY stop DESC;`
-
-	sqlListCyclesByPressID string = `
-SELECT id, tool_id, press_id, cycles, stop
-FROM cycles
-WHERE press_id = :press_id
-ORDER BY stop DESC;`
-
-	sqlListPrevCycles string = `
-SELECT tool_id, cycles, stop
-FROM cycles
-WHERE press_id = ? AND stop < ?
-ORDER BY stop DESC;`
+WHERE id = :id AND deleted_at = 0`
+
+	// sqlListInjectedCycles lists the cycles with the start and partial cycles
+	// injected, see cycleInjector for the rules.
+	//
+	// The tool positions are passed as JSON object (:positions), they live in the
+	// tool database. Each press is numbered by stop (ties newest ID first), so
+	// the oldest cycle of a press is number 1. The previous cycle is the highest
+	// number before the stop, in the same position, or in the upper position for
+	// the upper cassette. Zero for :id, :tool_id or :press_id matches all.
+	sqlListInjectedCycles string = `
+WITH
+positions (tool_id, position) AS (
+	SELECT CAST(key AS INTEGER), value FROM json_each(:positions)
+),
+numbered AS (
+	SELECT
+		c.id, c.tool_id, c.press_id, c.cycles, c.stop, c.performed_by, p.position,
+		ROW_NUMBER() OVER (PARTITION BY c.press_id ORDER BY c.stop, c.id DESC) AS n
+	FROM cycles AS c
+	LEFT JOIN positions AS p ON p.tool_id = c.tool_id
+	WHERE c.deleted_at = 0
+		AND (:press_id = 0 OR c.press_id = :press_id)
+		AND (:tool_id = 0 OR c.press_id IN (
+			SELECT press_id FROM cycles WHERE tool_id = :tool_id AND deleted_at = 0))
+		AND (:id = 0 OR c.press_id IN (
+			SELECT press_id FROM cycles WHERE id = :id AND deleted_at = 0))
+),
+previous AS (
+	SELECT
+		*,
+		-- The oldest cycle of a press is never used as previous cycle
+		MAX(CASE WHEN n > 1 THEN n END) OVER (
+			PARTITION BY press_id, position ORDER BY stop
+			GROUPS BETWEEN UNBOUNDED PRECEDING AND 1 PRECEDING
+		) AS prev_same,
+		MAX(CASE WHEN n > 1 AND position = :slot_upper THEN n END) OVER (
+			PARTITION BY press_id ORDER BY stop
+			GROUPS BETWEEN UNBOUNDED PRECEDING AND 1 PRECEDING
+		) AS prev_upper
+	FROM numbered
+)
+SELECT
+	c.id, c.tool_id, c.press_id, c.cycles, c.stop, c.performed_by, c.position,
+	COALESCE(prev.stop, c.stop) AS start,
+	c.cycles - COALESCE(prev.cycles, presses.cycles_offset, 0) AS partial_cycles
+FROM previous AS c
+LEFT JOIN numbered AS prev
+	ON prev.press_id = c.press_id
+	AND prev.n = CASE
+		WHEN c.position = :slot_upper_cassette
+		THEN MAX(IFNULL(c.prev_same, 0), IFNULL(c.prev_upper, 0))
+		ELSE c.prev_same
+	END
+LEFT JOIN presses ON presses.id = c.press_id AND presses.deleted_at = 0
+WHERE (:tool_id = 0 OR c.tool_id = :tool_id) AND (:id = 0 OR c.id = :id)
+ORDER BY c.stop DESC, c.id;`
 )
 
 // -----------------------------------------------------------------------------
@@ -76,7 +117,11 @@ ORDER BY stop DESC;`
 // -----------------------------------------------------------------------------
 
 // AddCycle adds a new cycle entry to the database
-func AddCycle(cycle *shared.Cycle) *errors.HTTPError {
+func (s *Store) AddCycle(cycle *shared.Cycle) *errors.HTTPError {
+	return addCycle(s.press, cycle)
+}
+
+func addCycle(e executor, cycle *shared.Cycle) *errors.HTTPError {
 	if err := cycle.Validate(); err != nil {
 		return err.HTTPError()
 	}
@@ -97,9 +142,14 @@ func AddCycle(cycle *shared.Cycle) *errors.HTTPError {
 		sql.Named("press_id", cycle.PressID),
 		sql.Named("cycles", cycle.PressCycles),
 		sql.Named("stop", cycle.Stop),
+		sql.Named("performed_by", cycle.PerformedBy),
 	)
 
-	if _, err := dbPress.Exec(query, queryArgs...); err != nil {
+	r, err := e.Exec(query, queryArgs...)
+	if err != nil {
+		return errors.NewHTTPError(err)
+	}
+	if cycle.ID, err = lastInsertID(r); err != nil {
 		return errors.NewHTTPError(err)
 	}
 
@@ -107,17 +157,18 @@ func AddCycle(cycle *shared.Cycle) *errors.HTTPError {
 }
 
 // UpdateCycle updates an existing cycle entry in the database
-func UpdateCycle(cycle *shared.Cycle) *errors.HTTPError {
+func (s *Store) UpdateCycle(cycle *shared.Cycle) *errors.HTTPError {
 	if err := cycle.Validate(); err != nil {
 		return err.HTTPError()
 	}
 
-	_, err := dbPress.Exec(sqlUpdateCycle,
+	_, err := s.press.Exec(sqlUpdateCycle,
 		sql.Named("id", cycle.ID),
 		sql.Named("tool_id", cycle.ToolID),
 		sql.Named("press_id", cycle.PressID),
 		sql.Named("cycles", cycle.PressCycles),
 		sql.Named("stop", cycle.Stop),
+		sql.Named("performed_by", cycle.PerformedBy),
 	)
 	if err != nil {
 		return errors.NewHTTPError(err)
@@ -125,29 +176,29 @@ func UpdateCycle(cycle *shared.Cycle) *errors.HTTPError {
 	return nil
 }
 
-// DeleteCycle removes a cycle entry from the database
-func DeleteCycle(id shared.EntityID) *errors.HTTPError {
-	_, err := dbPress.Exec(sqlDeleteCycle, sql.Named("id", id))
-	if err != nil {
-		return errors.NewHTTPError(err)
-	}
-	return nil
+// DeleteCycle moves a cycle entry to the trash
+func (s *Store) DeleteCycle(id shared.EntityID, deletedBy shared.TelegramID) *errors.HTTPError {
+	return s.softDeleteWithCascade("press", "cycles", sqlDeleteCycle, int64(id), deletedBy)
 }
 
 // GetCycle retrieves a cycle entry by its ID
-func GetCycle(id shared.EntityID) (*shared.Cycle, *errors.HTTPError) {
-	return ScanCycle(dbPress.QueryRow(sqlGetCycle, sql.Named("id", id)))
+func (s *Store) GetCycle(id shared.EntityID) (*shared.Cycle, *errors.HTTPError) {
+	return ScanCycle(s.press.QueryRow(sqlGetCycle, sql.Named("id", id)))
 }
 
 // TotalToolCycles since last tool regeneration
-func GetTotalToolCycles(toolID shared.EntityID) (int64, *errors.HTTPError) {
-	cycles, herr := ListToolCycles(toolID)
+func (s *Store) GetTotalToolCycles(toolID shared.EntityID) (int64, *errors.HTTPError) {
+	return s.totalToolCycles(toolID, nil)
+}
+
+func (s *Store) totalToolCycles(toolID shared.EntityID, ci *cycleInjector) (int64, *errors.HTTPError) {
+	cycles, herr := s.listToolCycles(toolID, ci)
 	if herr != nil {
 		return 0, herr.Wrap("failed to list tool cycles for tool ID %d", toolID)
 	}
 
 	// Filter out cycles before last tool regeneration, if any
-	regenerations, herr := ListToolRegenerationsByTool(toolID)
+	regenerations, herr := s.ListToolRegenerationsByTool(toolID)
 	if herr != nil && !herr.IsNotFoundError() {
 		return 0, herr.Wrap("failed to list tool regenerations for tool ID %d", toolID)
 	}
@@ -172,167 +223,187 @@ func GetTotalToolCycles(toolID shared.EntityID) (int64, *errors.HTTPError) {
 }
 
 // ListToolCycles retrieves all cycle entries for a specific tool
-func ListToolCycles(toolID shared.EntityID) ([]*shared.Cycle, *errors.HTTPError) {
-	rows, err := dbPress.Query(sqlListToolCycles, sql.Named("tool_id", int64(toolID)))
-	if err != nil {
-		return nil, errors.NewHTTPError(err)
-	}
-
-	var cycles []*shared.Cycle
-	for rows.Next() {
-		cycle, herr := ScanCycle(rows)
-		if herr != nil {
-			rows.Close()
-			return nil, herr
-		}
-		cycles = append(cycles, cycle)
-	}
-	rows.Close()
+func (s *Store) ListToolCycles(toolID shared.EntityID) ([]*shared.Cycle, *errors.HTTPError) {
+	return s.listToolCycles(toolID, nil)
+}
 
-	var herr *errors.HTTPError
-	for _, c := range cycles {
-		if herr = CycleInject(c); herr != nil {
-			return nil, herr.Wrap("failed to inject into cycle with ID %d", c.ID)
-		}
+// listToolCycles lists the cycles of a tool, from ci if set
+func (s *Store) listToolCycles(toolID shared.EntityID, ci *cycleInjector) ([]*shared.Cycle, *errors.HTTPError) {
+	if ci != nil {
+		return ci.toolCycles(toolID)
 	}
+	return s.listInjectedCycles(0, toolID, 0)
+}
 
-	return cycles, nil
+func (s *Store) ListCyclesByPressID(pressID shared.EntityID) ([]*shared.Cycle, *errors.HTTPError) {
+	return s.listInjectedCycles(0, 0, pressID)
 }
 
-func ListCyclesByPressID(pressID shared.EntityID) ([]*shared.Cycle, *errors.HTTPError) {
-	rows, err := dbPress.Query(sqlListCyclesByPressID, sql.Named("press_id", pressID))
-	if err != nil {
-		return nil, errors.NewHTTPError(err)
+// GetCycleSummary collects the filtered cycles of a press together with the tools,
+// users and the currently mounted tools needed for the cycle summary
+func (s *Store) GetCycleSummary(pressID shared.EntityID, filter *shared.CycleSummaryFilter) (*shared.CycleSummary, *errors.HTTPError) {
+	press, herr := s.GetPress(pressID)
+	if herr != nil {
+		return nil, herr.Wrap("get press %d", pressID)
 	}
-	defer rows.Close()
 
-	var cycles []*shared.Cycle
-	for rows.Next() {
-		cycle, herr := ScanCycle(rows)
-		if herr != nil {
-			return nil, herr
-		}
-		cycles = append(cycles, cycle)
+	cycles, herr := s.ListCyclesByPressID(pressID)
+	if herr != nil {
+		return nil, herr.Wrap("list cycles for press %d", pressID)
 	}
 
-	var herr *errors.HTTPError
+	// Filter after the injection, so start and partial cycles are still calculated
+	// from the previous (maybe filtered out) cycle
+	summary := &shared.CycleSummary{
+		Press:  press,
+		Filter: filter,
+		Tools:  make(map[shared.EntityID]*shared.Tool),
+		Users:  make(map[shared.TelegramID]*shared.User),
+	}
 	for _, c := range cycles {
-		if herr = CycleInject(c); herr != nil {
-			return nil, herr.Wrap("failed to inject into cycle with ID %d", c.ID)
+		if filter == nil || filter.Match(c) {
+			summary.Cycles = append(summary.Cycles, c)
 		}
 	}
 
-	return cycles, nil
-}
-
-// CycleInject injects "start" and `PartialCycles` into cycle
-func CycleInject(cycle *shared.Cycle) *errors.HTTPError {
-	// Helper to fetch the position of a tool, needed to determine if the cycle
-	// is relevant for the current position
-	fetchPosition := func(toolID shared.EntityID) (shared.Slot, *errors.HTTPError) {
-		var position shared.Slot
-		err := dbTool.QueryRow(
-			`SELECT position FROM tools WHERE id = ?;`,
-			toolID,
-		).Scan(&position)
-		if err != nil {
-			return 0, errors.NewHTTPError(fmt.Errorf(
-				"failed to fetch position for tool ID %d: %w",
-				toolID, err,
-			))
-		}
-		return position, nil
+	tools, herr := s.ListTools()
+	if herr != nil {
+		return nil, herr.Wrap("list tools")
 	}
-
-	// Helper to fetch the last cycles for all tools in the same press before
-	// the current stop
-	type prevCycle struct {
-		ToolID     shared.EntityID
-		LastCycles int64
-		LastStop   int64
+	for _, t := range tools {
+		summary.Tools[t.ID] = t
 	}
 
-	fetchLastCycles := func(pressID shared.EntityID, stop int64) (data []prevCycle, herr *errors.HTTPError) {
-		r, err := dbPress.Query(sqlListPrevCycles, pressID, stop)
-		if err != nil {
-			herr = errors.NewHTTPError(err)
-		}
-		defer r.Close()
-		// Scan rows
-		for r.Next() {
-			pc := prevCycle{}
-			if err = r.Scan(&pc.ToolID, &pc.LastCycles, &pc.LastStop); err != nil {
-				herr = errors.NewHTTPError(err)
-				return
-			}
-			data = append(data, pc)
-		}
-		return
+	users, herr := s.ListUsers()
+	if herr != nil {
+		return nil, herr.Wrap("list users")
 	}
-
-	// Helper to determine if a slot matches the current position, only the
-	// upper cassette can match the upper tool
-	isPosition := func(slot, currentPosition shared.Slot) bool {
-		return slot == currentPosition ||
-			(currentPosition == shared.SlotUpperCassette &&
-				(slot == shared.SlotUpper || slot == shared.SlotUpperCassette))
+	for _, u := range users {
+		summary.Users[u.ID] = u
 	}
 
-	// Get the cycles offset for the press, needed to calculate the partial cycles
-	var cycleOffset int64 = 0
-	press, herr := GetPress(cycle.PressID)
-	if herr != nil && !herr.IsNotFoundError() {
-		return herr.Wrap("failed to get press ID %d for cycle injection", cycle.PressID)
-	}
-	if press != nil { // Press could be not found
-		cycleOffset = press.CyclesOffset
+	summary.Utilization, herr = s.GetPressUtilization(pressID)
+	if herr != nil {
+		return nil, herr
 	}
 
-	// Get the current position of the tool, needed to determine if the cycle
-	// is relevant for the current position
-	currentPosition, herr := fetchPosition(cycle.ToolID)
+	return summary, nil
+}
+
+// CycleInject injects "start" and `PartialCycles` into a stored cycle
+func (s *Store) CycleInject(cycle *shared.Cycle) *errors.HTTPError {
+	cycles, herr := s.listInjectedCycles(cycle.ID, 0, 0)
 	if herr != nil {
 		return herr
 	}
+	if len(cycles) == 0 {
+		return errors.NewNotFoundError("cycle with ID %d", cycle.ID).HTTPError()
+	}
+
+	cycle.Start = cycles[0].Start
+	cycle.PartialCycles = cycles[0].PartialCycles
+	return nil
+}
 
-	// Get the last cycles for all tools in the same press before the current stop
-	prevCycles, herr := fetchLastCycles(cycle.PressID, int64(cycle.Stop))
-	if herr != nil && herr.Err() != sql.ErrNoRows {
-		return errors.NewHTTPError(herr)
+// -----------------------------------------------------------------------------
+// Cycle Injection
+// -----------------------------------------------------------------------------
+
+// listInjectedCycles lists the cycles with the start and partial cycles injected,
+// newest first. The cycle, tool and press IDs filter the cycles if not zero.
+//
+// The partial cycles are counted from the most recent previous cycle of the same
+// press with a tool in the same position, or from the press cycles offset if there
+// is none. Only the upper cassette can match the upper tool too. The oldest cycle
+// of a press never counts as previous cycle. Everything is done with a single
+// query, see sqlListInjectedCycles.
+func (s *Store) listInjectedCycles(id, toolID, pressID shared.EntityID) ([]*shared.Cycle, *errors.HTTPError) {
+	positions, herr := s.listToolPositions()
+	if herr != nil {
+		return nil, herr
+	}
+	positionsJSON, err := json.Marshal(positions)
+	if err != nil {
+		return nil, errors.NewHTTPError(err)
 	}
 
-	if len(prevCycles) == 0 {
-		cycle.PartialCycles = cycle.PressCycles - cycleOffset
-		cycle.Start = cycle.Stop
-		return nil
+	rows, err := s.press.Query(sqlListInjectedCycles,
+		sql.Named("positions", string(positionsJSON)),
+		sql.Named("id", id),
+		sql.Named("tool_id", toolID),
+		sql.Named("press_id", pressID),
+		sql.Named("slot_upper", shared.SlotUpper),
+		sql.Named("slot_upper_cassette", shared.SlotUpperCassette),
+	)
+	if err != nil {
+		return nil, errors.NewHTTPError(err)
 	}
+	defer rows.Close()
 
-	// Iterate over the previous cycles to find the most recent one that matches
-	// the current position and tool, then calculate the partial cycles.
-	for i, pc := range prevCycles {
-		if i == len(prevCycles)-1 {
-			// If we are at the last previous cycle, we can calculate the partial cycles
-			// using the cycle offset, as there are no more previous cycles to compare to
-			cycle.PartialCycles = cycle.PressCycles - cycleOffset
-			cycle.Start = cycle.Stop
-			break
+	var cycles []*shared.Cycle
+	for rows.Next() {
+		var (
+			cycle    = &shared.Cycle{}
+			position sql.NullInt64
+		)
+		err = rows.Scan(
+			&cycle.ID,
+			&cycle.ToolID,
+			&cycle.PressID,
+			&cycle.PressCycles,
+			&cycle.Stop,
+			&cycle.PerformedBy,
+			&position,
+			&cycle.Start,
+			&cycle.PartialCycles,
+		)
+		if err != nil {
+			return nil, errors.NewHTTPError(err)
+		}
+		if !position.Valid {
+			return nil, errors.NewHTTPError(fmt.Errorf(
+				"failed to fetch position for tool ID %d: %w",
+				cycle.ToolID, sql.ErrNoRows,
+			))
 		}
+		cycles = append(cycles, cycle)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.NewHTTPError(err)
+	}
+
+	return cycles, nil
+}
+
+// cycleInjector shares the injected cycles between the tools of a list.
+//
+// All cycles get loaded with a single query on first use and are kept for the
+// lifetime of the injector. Use a new injector for each request, it does not
+// notice any changes made after loading.
+type cycleInjector struct {
+	store  *Store
+	cycles map[shared.EntityID][]*shared.Cycle // By tool ID, newest first
+}
+
+func (s *Store) newCycleInjector() *cycleInjector {
+	return &cycleInjector{store: s}
+}
 
-		// Check the tool_id for if the position is matching, only the
-		// upper cassette can match the upper tool too
-		slot, herr := fetchPosition(pc.ToolID)
+// toolCycles returns the injected cycles of a tool, loading all cycles on first use
+func (ci *cycleInjector) toolCycles(toolID shared.EntityID) ([]*shared.Cycle, *errors.HTTPError) {
+	if ci.cycles == nil {
+		cycles, herr := ci.store.listInjectedCycles(0, 0, 0)
 		if herr != nil {
-			return herr
+			return nil, herr
 		}
 
-		if isPosition(slot, currentPosition) && (cycle.ToolID != pc.ToolID || int64(cycle.Stop) != pc.LastStop) {
-			cycle.PartialCycles = cycle.PressCycles - pc.LastCycles
-			cycle.Start = shared.UnixMilli(pc.LastStop)
-			break
+		ci.cycles = make(map[shared.EntityID][]*shared.Cycle)
+		for _, c := range cycles {
+			ci.cycles[c.ToolID] = append(ci.cycles[c.ToolID], c)
 		}
 	}
 
-	return nil
+	return ci.cycles[toolID], nil
 }
 
 // -----------------------------------------------------------------------------
@@ -348,6 +419,7 @@ func ScanCycle(row Scannable) (cycle *shared.Cycle, herr *errors.HTTPError) {
 		&cycle.PressID,
 		&cycle.PressCycles,
 		&cycle.Stop,
+		&cycle.PerformedBy,
 	)
 	if err != nil {
 		return nil, errors.NewHTTPError(err)
@@ -0,0 +1,364 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/shared"
+)
+
+// Tool indexes of the injection fixture
+const (
+	upperA = iota
+	lowerA
+	cassetteA
+	upperB
+	lowerB
+	cassetteB
+)
+
+type fixtureCycle struct {
+	tool   int
+	press  int // 0 or 1
+	cycles int64
+	stop   shared.UnixMilli
+}
+
+func TestCycleInjection(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		cycles        []fixtureCycle
+		deleteTools   []int
+		deleteCycles  []int // Indexes into cycles
+		deletePresses []int
+	}{
+		{
+			name: "single tool",
+			cycles: []fixtureCycle{
+				{upperA, 0, 1500, 100},
+				{upperA, 0, 2500, 200},
+				{upperA, 0, 4000, 300},
+			},
+		},
+		{
+			name: "upper and lower",
+			cycles: []fixtureCycle{
+				{upperA, 0, 1500, 100},
+				{lowerA, 0, 1600, 110},
+				{upperA, 0, 2500, 200},
+				{lowerA, 0, 2600, 210},
+				{upperA, 0, 4000, 300},
+				{lowerA, 0, 4100, 310},
+			},
+		},
+		{
+			name: "overlapping stops",
+			cycles: []fixtureCycle{
+				{upperA, 0, 1500, 100},
+				{lowerA, 0, 1500, 100},
+				{upperA, 0, 3000, 200},
+				{lowerA, 0, 3000, 200},
+				{upperB, 0, 3000, 200},
+				{upperB, 0, 5000, 300},
+				{lowerA, 0, 5000, 300},
+				{upperA, 0, 5000, 300},
+				{lowerB, 0, 7000, 400},
+				{upperB, 0, 7000, 400},
+			},
+		},
+		{
+			name: "cassettes",
+			cycles: []fixtureCycle{
+				{upperA, 0, 1200, 100},
+				{cassetteA, 0, 1200, 100},
+				{lowerA, 0, 1200, 100},
+				{cassetteA, 0, 2000, 200},
+				{upperA, 0, 2500, 250},
+				{cassetteB, 0, 3000, 300},
+				{lowerA, 0, 3100, 310},
+				{upperB, 0, 3500, 350},
+				{cassetteA, 0, 4000, 400},
+				{cassetteB, 0, 4000, 400},
+				{upperA, 0, 4500, 450},
+			},
+		},
+		{
+			name: "two presses",
+			cycles: []fixtureCycle{
+				{upperA, 0, 1500, 100},
+				{upperB, 1, 100, 120},
+				{lowerA, 1, 150, 130},
+				{upperA, 0, 2500, 200},
+				{upperA, 1, 300, 250},
+				{upperB, 0, 3000, 300},
+				{lowerA, 1, 500, 350},
+				{upperB, 1, 600, 400},
+			},
+		},
+		{
+			name: "deleted tools",
+			cycles: []fixtureCycle{
+				{upperA, 0, 1500, 100},
+				{lowerA, 0, 1600, 110},
+				{upperB, 0, 2000, 200},
+				{cassetteB, 0, 2000, 200},
+				{lowerB, 0, 2100, 210},
+				{upperA, 0, 3000, 300},
+				{lowerA, 0, 3100, 310},
+				{cassetteA, 0, 3500, 350},
+				{upperA, 1, 200, 400},
+			},
+			deleteTools: []int{upperB, cassetteB},
+		},
+		{
+			name: "deleted cycles",
+			cycles: []fixtureCycle{
+				{upperA, 0, 1500, 100},
+				{upperA, 0, 2500, 200},
+				{lowerA, 0, 2600, 210},
+				{upperA, 0, 4000, 300},
+				{lowerA, 0, 4100, 310},
+				{upperA, 0, 5000, 400},
+			},
+			deleteCycles: []int{0, 3},
+		},
+		{
+			name: "deleted press",
+			cycles: []fixtureCycle{
+				{upperA, 0, 1500, 100},
+				{upperB, 1, 100, 150},
+				{upperA, 0, 2500, 200},
+				{upperB, 1, 300, 250},
+			},
+			deletePresses: []int{1},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestStore(t)
+
+			tools := []*shared.Tool{
+				upperA:    {Width: 120, Height: 60, Position: shared.SlotUpper, Type: "MASS", Code: "A1"},
+				lowerA:    {Width: 120, Height: 60, Position: shared.SlotLower, Type: "MASS", Code: "A2"},
+				cassetteA: {Width: 120, Height: 60, Position: shared.SlotUpperCassette, Type: "MASS", Code: "AK", MaxThickness: 10},
+				upperB:    {Width: 120, Height: 60, Position: shared.SlotUpper, Type: "MASS", Code: "B1"},
+				lowerB:    {Width: 120, Height: 60, Position: shared.SlotLower, Type: "MASS", Code: "B2"},
+				cassetteB: {Width: 120, Height: 60, Position: shared.SlotUpperCassette, Type: "MASS", Code: "BK", MaxThickness: 10},
+			}
+			for _, tool := range tools {
+				if herr := s.AddTool(tool); herr != nil {
+					t.Fatalf("add tool: %v", herr)
+				}
+			}
+
+			presses := []*shared.Press{
+				{Number: 1, Type: shared.MachineTypeSACMI, CyclesOffset: 1000},
+				{Number: 2, Type: shared.MachineTypeSITI},
+			}
+			for _, press := range presses {
+				if herr := s.AddPress(press); herr != nil {
+					t.Fatalf("add press: %v", herr)
+				}
+			}
+
+			var cycles []*shared.Cycle
+			for _, fc := range tc.cycles {
+				cycle := shared.NewCycle(tools[fc.tool].ID, presses[fc.press].ID, fc.cycles, fc.stop, 0)
+				if herr := s.AddCycle(cycle); herr != nil {
+					t.Fatalf("add cycle: %v", herr)
+				}
+				cycles = append(cycles, cycle)
+			}
+
+			for _, i := range tc.deleteTools {
+				if herr := s.DeleteTool(tools[i].ID, 0); herr != nil {
+					t.Fatalf("delete tool: %v", herr)
+				}
+			}
+			for _, i := range tc.deleteCycles {
+				if herr := s.DeleteCycle(cycles[i].ID, 0); herr != nil {
+					t.Fatalf("delete cycle: %v", herr)
+				}
+			}
+			for _, i := range tc.deletePresses {
+				if herr := s.DeletePress(presses[i].ID, 0); herr != nil {
+					t.Fatalf("delete press: %v", herr)
+				}
+			}
+
+			// The expected values of all cycles still listed
+			want := make(map[shared.EntityID]*shared.Cycle)
+			for _, press := range presses {
+				for _, c := range listStoredCycles(t, s, "press_id", press.ID) {
+					if herr := referenceCycleInject(s, c); herr != nil {
+						t.Fatalf("reference injection of cycle %d: %v", c.ID, herr)
+					}
+					want[c.ID] = c
+				}
+			}
+
+			compare := func(list string, got []*shared.Cycle) {
+				t.Helper()
+				for _, c := range got {
+					w, ok := want[c.ID]
+					if !ok {
+						t.Errorf("%s: unexpected cycle %d", list, c.ID)
+						continue
+					}
+					if c.Start != w.Start || c.PartialCycles != w.PartialCycles {
+						t.Errorf("%s: cycle %d has start %d and partial cycles %d, want %d and %d",
+							list, c.ID, c.Start, c.PartialCycles, w.Start, w.PartialCycles)
+					}
+				}
+			}
+
+			total := 0
+			for _, press := range presses {
+				got, herr := s.ListCyclesByPressID(press.ID)
+				if herr != nil {
+					t.Fatalf("list press cycles: %v", herr)
+				}
+				compare("ListCyclesByPressID", got)
+				total += len(got)
+			}
+			if total != len(want) {
+				t.Errorf("listed %d press cycles, want %d", total, len(want))
+			}
+
+			ci := s.newCycleInjector()
+			for _, tool := range tools {
+				got, herr := s.ListToolCycles(tool.ID)
+				if herr != nil {
+					t.Fatalf("list tool cycles: %v", herr)
+				}
+				compare("ListToolCycles", got)
+				if n := len(listStoredCycles(t, s, "tool_id", tool.ID)); len(got) != n {
+					t.Errorf("listed %d cycles for tool %d, want %d", len(got), tool.ID, n)
+				}
+
+				injected, herr := s.listToolCycles(tool.ID, ci)
+				if herr != nil {
+					t.Fatalf("list tool cycles with injector: %v", herr)
+				}
+				compare("cycleInjector", injected)
+				if len(injected) != len(got) {
+					t.Errorf("injector listed %d cycles for tool %d, want %d", len(injected), tool.ID, len(got))
+				}
+			}
+
+			for id, w := range want {
+				c := &shared.Cycle{ID: id, ToolID: w.ToolID, PressID: w.PressID, PressCycles: w.PressCycles, Stop: w.Stop}
+				if herr := s.CycleInject(c); herr != nil {
+					t.Fatalf("inject cycle %d: %v", id, herr)
+				}
+				compare("CycleInject", []*shared.Cycle{c})
+			}
+		})
+	}
+}
+
+// listStoredCycles lists the cycles not deleted, without injecting anything
+func listStoredCycles(t *testing.T, s *Store, column string, id shared.EntityID) []*shared.Cycle {
+	t.Helper()
+
+	rows, err := s.press.Query(
+		`SELECT id, tool_id, press_id, cycles, stop, performed_by FROM cycles
+		WHERE `+column+` = ? AND deleted_at = 0`, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var cycles []*shared.Cycle
+	for rows.Next() {
+		c, herr := ScanCycle(rows)
+		if herr != nil {
+			t.Fatal(herr)
+		}
+		cycles = append(cycles, c)
+	}
+	return cycles
+}
+
+// referenceCycleInject is the former per-row injection, which queried the press,
+// the tool positions and the previous cycles for each cycle, deleted cycles
+// are skipped like everywhere else.
+func referenceCycleInject(s *Store, cycle *shared.Cycle) *errors.HTTPError {
+	fetchPosition := func(toolID shared.EntityID) (shared.Slot, *errors.HTTPError) {
+		var position shared.Slot
+		err := s.tool.QueryRow(`SELECT position FROM tools WHERE id = ?;`, toolID).Scan(&position)
+		if err != nil {
+			return 0, errors.NewHTTPError(err)
+		}
+		return position, nil
+	}
+
+	type prevCycle struct {
+		ToolID     shared.EntityID
+		LastCycles int64
+		LastStop   int64
+	}
+
+	var cycleOffset int64 = 0
+	press, herr := s.GetPress(cycle.PressID)
+	if herr != nil && !herr.IsNotFoundError() {
+		return herr
+	}
+	if press != nil {
+		cycleOffset = press.CyclesOffset
+	}
+
+	currentPosition, herr := fetchPosition(cycle.ToolID)
+	if herr != nil {
+		return herr
+	}
+
+	r, err := s.press.Query(`
+SELECT tool_id, cycles, stop
+FROM cycles
+WHERE press_id = ? AND stop < ? AND deleted_at = 0
+ORDER BY stop DESC;`, cycle.PressID, cycle.Stop)
+	if err != nil {
+		return errors.NewHTTPError(err)
+	}
+	var prevCycles []prevCycle
+	for r.Next() {
+		pc := prevCycle{}
+		if err = r.Scan(&pc.ToolID, &pc.LastCycles, &pc.LastStop); err != nil {
+			r.Close()
+			return errors.NewHTTPError(err)
+		}
+		prevCycles = append(prevCycles, pc)
+	}
+	r.Close()
+
+	if len(prevCycles) == 0 {
+		cycle.PartialCycles = cycle.PressCycles - cycleOffset
+		cycle.Start = cycle.Stop
+		return nil
+	}
+
+	for i, pc := range prevCycles {
+		if i == len(prevCycles)-1 {
+			cycle.PartialCycles = cycle.PressCycles - cycleOffset
+			cycle.Start = cycle.Stop
+			break
+		}
+
+		slot, herr := fetchPosition(pc.ToolID)
+		if herr != nil {
+			return herr
+		}
+
+		isPosition := slot == currentPosition ||
+			(currentPosition == shared.SlotUpperCassette &&
+				(slot == shared.SlotUpper || slot == shared.SlotUpperCassette))
+
+		if isPosition && (cycle.ToolID != pc.ToolID || int64(cycle.Stop) != pc.LastStop) {
+			cycle.PartialCycles = cycle.PressCycles - pc.LastCycles
+			cycle.Start = shared.UnixMilli(pc.LastStop)
+			break
+		}
+	}
+
+	return nil
+}
@@ -87,7 +87,7 @@ SELECT
 	slot_down,
 	cycles_offset
 FROM presses
-WHERE id = :id`
+WHERE id = :id AND deleted_at = 0`
 
 	// sqlGetPressForTool finds the press number that contains a specific tool in either slot.
 	sqlGetPressForTool string = `
@@ -100,7 +100,7 @@ SELECT
 	slot_down,
 	cycles_offset
 FROM presses
-WHERE slot_up = :tool_id OR slot_down = :tool_id
+WHERE (slot_up = :tool_id OR slot_down = :tool_id) AND deleted_at = 0
 LIMIT 1;`
 
 	// sqlGetPressUtilization retrieves press details for utilization reporting.
@@ -114,7 +114,7 @@ SELECT
 	slot_down,
 	cycles_offset
 FROM presses
-WHERE id = :id;`
+WHERE id = :id AND deleted_at = 0;`
 
 	// sqlListPress retrieves all press records from the database.
 	sqlListPress string = `
@@ -127,12 +127,14 @@ SELECT
 	slot_down,
 	cycles_offset
 FROM presses
+WHERE deleted_at = 0
 ORDER BY id ASC`
 
 	// sqlDeletePress removes a press record from the database.
 	sqlDeletePress string = `
-DELETE FROM presses
-WHERE id = :id`
+UPDATE presses
+SET deleted_at = :deleted_at, deleted_by = :deleted_by, deleted_with = :deleted_with
+WHERE id = :id AND deleted_at = 0`
 )
 
 // -----------------------------------------------------------------------------
@@ -149,7 +151,7 @@ WHERE id = :id`
 //
 // Returns:
 //   - *errors.HTTPError: Error if operation fails, nil on success
-func AddPress(press *shared.Press) *errors.HTTPError {
+func (s *Store) AddPress(press *shared.Press) *errors.HTTPError {
 	if verr := press.Validate(); verr != nil {
 		return verr.HTTPError()
 	}
@@ -174,7 +176,11 @@ func AddPress(press *shared.Press) *errors.HTTPError {
 		sql.Named("cycles_offset", press.CyclesOffset),
 	)
 
-	if _, err := dbPress.Exec(query, queryArgs...); err != nil {
+	r, err := s.press.Exec(query, queryArgs...)
+	if err != nil {
+		return errors.NewHTTPError(err)
+	}
+	if press.ID, err = lastInsertID(r); err != nil {
 		return errors.NewHTTPError(err)
 	}
 	return nil
@@ -190,12 +196,16 @@ func AddPress(press *shared.Press) *errors.HTTPError {
 //
 // Returns:
 //   - *errors.HTTPError: Error if operation fails, nil on success
-func UpdatePress(press *shared.Press) *errors.HTTPError {
+func (s *Store) UpdatePress(press *shared.Press) *errors.HTTPError {
+	return updatePress(s.press, press)
+}
+
+func updatePress(e executor, press *shared.Press) *errors.HTTPError {
 	if verr := press.Validate(); verr != nil {
 		return verr.HTTPError()
 	}
 
-	_, err := dbPress.Exec(sqlUpdatePress,
+	_, err := e.Exec(sqlUpdatePress,
 		sql.Named("id", press.ID),
 		sql.Named("number", press.Number),
 		sql.Named("type", press.Type),
@@ -221,12 +231,12 @@ func UpdatePress(press *shared.Press) *errors.HTTPError {
 // Returns:
 //   - *shared.Press: Pointer to the retrieved Press struct, or nil if not found
 //   - *errors.HTTPError: Error if operation fails, nil on success
-func GetPress(id shared.EntityID) (*shared.Press, *errors.HTTPError) {
-	return ScanPress(dbPress.QueryRow(sqlGetPress, sql.Named("id", id)))
+func (s *Store) GetPress(id shared.EntityID) (*shared.Press, *errors.HTTPError) {
+	return ScanPress(s.press.QueryRow(sqlGetPress, sql.Named("id", id)))
 }
 
-func GetPressForTool(toolID shared.EntityID) (*shared.Press, *errors.HTTPError) {
-	press, herr := ScanPress(dbPress.QueryRow(sqlGetPressForTool, sql.Named("tool_id", toolID)))
+func (s *Store) GetPressForTool(toolID shared.EntityID) (*shared.Press, *errors.HTTPError) {
+	press, herr := ScanPress(s.press.QueryRow(sqlGetPressForTool, sql.Named("tool_id", toolID)))
 	if herr != nil && !herr.IsNotFoundError() {
 		return nil, herr
 	}
@@ -245,13 +255,13 @@ func GetPressForTool(toolID shared.EntityID) (*shared.Press, *errors.HTTPError)
 // Returns:
 //   - *shared.PressUtilization: Pointer to the populated PressUtilization struct
 //   - *errors.HTTPError: Error if operation fails, nil on success
-func GetPressUtilization(pressID shared.EntityID) (*shared.PressUtilization, *errors.HTTPError) {
+func (s *Store) GetPressUtilization(pressID shared.EntityID) (*shared.PressUtilization, *errors.HTTPError) {
 	var (
 		slotUpper shared.EntityID
 		slotLower shared.EntityID
 	)
 	pu := &shared.PressUtilization{}
-	err := dbPress.QueryRow(sqlGetPressUtilization, sql.Named("id", pressID)).Scan(
+	err := s.press.QueryRow(sqlGetPressUtilization, sql.Named("id", pressID)).Scan(
 		&pu.PressID,
 		&pu.PressNumber,
 		&pu.PressType,
@@ -265,14 +275,14 @@ func GetPressUtilization(pressID shared.EntityID) (*shared.PressUtilization, *er
 	}
 
 	if slotUpper > 0 {
-		tool, herr := GetTool(slotUpper)
+		tool, herr := s.GetTool(slotUpper)
 		if herr != nil {
 			return nil, herr
 		}
 		pu.SlotUpper = tool
 
 		if tool.Cassette > 0 {
-			cassette, herr := GetTool(tool.Cassette)
+			cassette, herr := s.GetTool(tool.Cassette)
 			if herr != nil {
 				return nil, herr.Wrap(
 					"error getting upper cassette tool (%d) for tool ID %d",
@@ -283,7 +293,7 @@ func GetPressUtilization(pressID shared.EntityID) (*shared.PressUtilization, *er
 		}
 	}
 	if slotLower > 0 {
-		tool, herr := GetTool(slotLower)
+		tool, herr := s.GetTool(slotLower)
 		if herr != nil {
 			return nil, herr
 		}
@@ -304,15 +314,15 @@ func GetPressUtilization(pressID shared.EntityID) (*shared.PressUtilization, *er
 // Returns:
 //   - map[shared.PressNumber]*shared.PressUtilization: Map of press numbers to utilization info
 //   - *errors.HTTPError: Error if operation fails, nil on success
-func GetPressUtilizations() (pu map[shared.EntityID]*shared.PressUtilization, herr *errors.HTTPError) {
-	presses, herr := ListPress()
+func (s *Store) GetPressUtilizations() (pu map[shared.EntityID]*shared.PressUtilization, herr *errors.HTTPError) {
+	presses, herr := s.ListPress()
 	if herr != nil {
 		return pu, herr.Wrap("listing presses for utilization retrieval failed")
 	}
 
 	pu = make(map[shared.EntityID]*shared.PressUtilization)
 	for _, p := range presses {
-		u, herr := GetPressUtilization(p.ID)
+		u, herr := s.GetPressUtilization(p.ID)
 		if herr != nil {
 			return pu, herr.Wrap("%d", p.ID)
 		}
@@ -330,8 +340,8 @@ func GetPressUtilizations() (pu map[shared.EntityID]*shared.PressUtilization, he
 // Returns:
 //   - []*shared.Press: Slice of pointers to Press structs
 //   - *errors.HTTPError: Error if operation fails, nil on success
-func ListPress() (presses []*shared.Press, herr *errors.HTTPError) {
-	r, err := dbPress.Query(sqlListPress)
+func (s *Store) ListPress() (presses []*shared.Press, herr *errors.HTTPError) {
+	r, err := s.press.Query(sqlListPress)
 	if err != nil {
 		return nil, errors.NewHTTPError(err)
 	}
@@ -352,21 +362,19 @@ func ListPress() (presses []*shared.Press, herr *errors.HTTPError) {
 	return presses, nil
 }
 
-// DeletePress removes a press from the database.
+// DeletePress moves a press to the trash.
 //
-// It deletes the specified press record and returns an error if the operation fails.
+// It marks the specified press record as deleted, together with all cycles and
+// notes of the press, and returns an error if the operation fails.
 //
 // Parameters:
 //   - id: The press number to delete
+//   - deletedBy: The user deleting the press, 0 if unknown
 //
 // Returns:
 //   - *errors.HTTPError: Error if operation fails, nil on success
-func DeletePress(id shared.EntityID) *errors.HTTPError {
-	_, err := dbPress.Exec(sqlDeletePress, sql.Named("id", id), sql.Named("type", id), sql.Named("code", id))
-	if err != nil {
-		return errors.NewHTTPError(err)
-	}
-	return nil
+func (s *Store) DeletePress(id shared.EntityID, deletedBy shared.TelegramID) *errors.HTTPError {
+	return s.softDeleteWithCascade("press", "presses", sqlDeletePress, int64(id), deletedBy)
 }
 
 // -----------------------------------------------------------------------------
@@ -43,16 +43,22 @@ WHERE id = :id;`
 	sqlGetTroubleReport string = `
 SELECT id, title, content, linked_attachments, use_markdown
 FROM trouble_reports
-WHERE id = :id;`
+WHERE id = :id AND deleted_at = 0;`
 
 	sqlListTroubleReports string = `
 SELECT id, title, content, linked_attachments, use_markdown
 FROM trouble_reports
+WHERE deleted_at = 0
 ORDER BY id DESC;`
 
+	sqlListTroubleReportAttachments string = `
+SELECT COALESCE(linked_attachments, '')
+FROM trouble_reports;`
+
 	sqlDeleteTroubleReport string = `
-DELETE FROM trouble_reports
-WHERE id = :id;`
+UPDATE trouble_reports
+SET deleted_at = :deleted_at, deleted_by = :deleted_by, deleted_with = :deleted_with
+WHERE id = :id AND deleted_at = 0;`
 )
 
 // -----------------------------------------------------------------------------
@@ -60,7 +66,7 @@ WHERE id = :id;`
 // -----------------------------------------------------------------------------
 
 // AddTroubleReport adds a new trouble report to the database
-func AddTroubleReport(report *shared.TroubleReport) *errors.HTTPError {
+func (s *Store) AddTroubleReport(report *shared.TroubleReport) *errors.HTTPError {
 	if verr := report.Validate(); verr != nil {
 		return verr.HTTPError().Wrap("invalid trouble report data")
 	}
@@ -89,7 +95,11 @@ func AddTroubleReport(report *shared.TroubleReport) *errors.HTTPError {
 		sql.Named("use_markdown", boolToInt(report.UseMarkdown)),
 	)
 
-	if _, err = dbReports.Exec(query, queryArgs...); err != nil {
+	r, err := s.reports.Exec(query, queryArgs...)
+	if err != nil {
+		return errors.NewHTTPError(err)
+	}
+	if report.ID, err = lastInsertID(r); err != nil {
 		return errors.NewHTTPError(err)
 	}
 
@@ -97,7 +107,7 @@ func AddTroubleReport(report *shared.TroubleReport) *errors.HTTPError {
 }
 
 // UpdateTroubleReport updates an existing trouble report in the database
-func UpdateTroubleReport(report *shared.TroubleReport) *errors.HTTPError {
+func (s *Store) UpdateTroubleReport(report *shared.TroubleReport) *errors.HTTPError {
 	if verr := report.Validate(); verr != nil {
 		return verr.HTTPError().Wrap("invalid trouble report data")
 	}
@@ -108,7 +118,7 @@ func UpdateTroubleReport(report *shared.TroubleReport) *errors.HTTPError {
 		return errors.NewHTTPError(err).Wrap("failed to marshal linked attachments")
 	}
 
-	_, err = dbReports.Exec(sqlUpdateTroubleReport,
+	_, err = s.reports.Exec(sqlUpdateTroubleReport,
 		sql.Named("id", report.ID),
 		sql.Named("title", report.Title),
 		sql.Named("content", report.Content),
@@ -123,13 +133,13 @@ func UpdateTroubleReport(report *shared.TroubleReport) *errors.HTTPError {
 }
 
 // GetTroubleReport retrieves a trouble report by its ID
-func GetTroubleReport(id shared.EntityID) (*shared.TroubleReport, *errors.HTTPError) {
-	return ScanTroubleReport(dbReports.QueryRow(sqlGetTroubleReport, sql.Named("id", id)))
+func (s *Store) GetTroubleReport(id shared.EntityID) (*shared.TroubleReport, *errors.HTTPError) {
+	return ScanTroubleReport(s.reports.QueryRow(sqlGetTroubleReport, sql.Named("id", id)))
 }
 
 // ListTroubleReports retrieves all trouble reports from the database
-func ListTroubleReports() (reports []*shared.TroubleReport, merr *errors.HTTPError) {
-	rows, err := dbReports.Query(sqlListTroubleReports)
+func (s *Store) ListTroubleReports() (reports []*shared.TroubleReport, merr *errors.HTTPError) {
+	rows, err := s.reports.Query(sqlListTroubleReports)
 	if err != nil {
 		return nil, errors.NewHTTPError(err)
 	}
@@ -146,13 +156,37 @@ func ListTroubleReports() (reports []*shared.TroubleReport, merr *errors.HTTPErr
 	return reports, nil
 }
 
-// DeleteTroubleReport removes a trouble report from the database
-func DeleteTroubleReport(id shared.EntityID) *errors.HTTPError {
-	_, err := dbReports.Exec(sqlDeleteTroubleReport, sql.Named("id", id))
+// ListTroubleReportAttachments returns the attachments of all trouble reports,
+// including the ones in the trash, which still need their images for a restore.
+func (s *Store) ListTroubleReportAttachments() (attachments []string, merr *errors.HTTPError) {
+	rows, err := s.reports.Query(sqlListTroubleReportAttachments)
 	if err != nil {
-		return errors.NewHTTPError(err)
+		return nil, errors.NewHTTPError(err)
 	}
-	return nil
+	defer rows.Close()
+
+	for rows.Next() {
+		var jsonStr string
+		if err = rows.Scan(&jsonStr); err != nil {
+			return nil, errors.NewHTTPError(err)
+		}
+		if jsonStr == "" {
+			continue
+		}
+
+		var linked []string
+		if err = json.Unmarshal([]byte(jsonStr), &linked); err != nil {
+			return nil, errors.NewHTTPError(err).Wrap("failed to unmarshal linked attachments")
+		}
+		attachments = append(attachments, linked...)
+	}
+
+	return attachments, nil
+}
+
+// DeleteTroubleReport moves a trouble report to the trash
+func (s *Store) DeleteTroubleReport(id shared.EntityID, deletedBy shared.TelegramID) *errors.HTTPError {
+	return s.softDeleteWithCascade("reports", "trouble_reports", sqlDeleteTroubleReport, int64(id), deletedBy)
 }
 
 // -----------------------------------------------------------------------------
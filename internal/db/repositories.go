@@ -0,0 +1,224 @@
+package db
+
+import (
+	"context"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/shared"
+)
+
+// ToolRepository contains all operations on tools, their metal sheets, regenerations
+// and cycle thresholds.
+type ToolRepository interface {
+	AddTool(tool *shared.Tool) *errors.HTTPError
+	UpdateTool(tool *shared.Tool) *errors.HTTPError
+	GetTool(id shared.EntityID) (*shared.Tool, *errors.HTTPError)
+	ListTools() ([]*shared.Tool, *errors.HTTPError)
+	ListBindableCassettes(id shared.EntityID) ([]*shared.Tool, *errors.HTTPError)
+	DeleteTool(id shared.EntityID, deletedBy shared.TelegramID) *errors.HTTPError
+	MarkToolAsDead(id shared.EntityID) *errors.HTTPError
+	ReviveTool(id shared.EntityID) *errors.HTTPError
+	BindTool(sourceID, targetID shared.EntityID) *errors.HTTPError
+	UnbindTool(sourceID shared.EntityID) *errors.HTTPError
+
+	AddUpperMetalSheet(ums *shared.UpperMetalSheet) *errors.HTTPError
+	UpdateUpperMetalSheet(ums *shared.UpperMetalSheet) *errors.HTTPError
+	GetUpperMetalSheet(metalSheetID shared.EntityID) (*shared.UpperMetalSheet, *errors.HTTPError)
+	ListUpperMetalSheetsByTool(toolID shared.EntityID) ([]*shared.UpperMetalSheet, *errors.HTTPError)
+	DeleteUpperMetalSheet(id shared.EntityID, deletedBy shared.TelegramID) *errors.HTTPError
+	AddLowerMetalSheet(lms *shared.LowerMetalSheet) *errors.HTTPError
+	UpdateLowerMetalSheet(lms *shared.LowerMetalSheet) *errors.HTTPError
+	GetLowerMetalSheet(metalSheetID shared.EntityID) (*shared.LowerMetalSheet, *errors.HTTPError)
+	ListLowerMetalSheetsByTool(toolID shared.EntityID) ([]*shared.LowerMetalSheet, *errors.HTTPError)
+	DeleteLowerMetalSheet(id shared.EntityID, deletedBy shared.TelegramID) *errors.HTTPError
+
+	AddToolRegeneration(tr *shared.ToolRegeneration) *errors.HTTPError
+	UpdateToolRegeneration(tr *shared.ToolRegeneration) *errors.HTTPError
+	GetToolRegeneration(id shared.EntityID) (*shared.ToolRegeneration, *errors.HTTPError)
+	ListToolRegenerations() ([]*shared.ToolRegeneration, *errors.HTTPError)
+	ListToolRegenerationsByTool(toolID shared.EntityID) ([]*shared.ToolRegeneration, *errors.HTTPError)
+	DeleteToolRegeneration(id shared.EntityID) *errors.HTTPError
+	DeleteToolRegenerationByTool(toolID shared.EntityID) *errors.HTTPError
+	ToolRegenerationInProgress(toolID shared.EntityID) (bool, *errors.HTTPError)
+	StartToolRegeneration(toolID shared.EntityID) *errors.HTTPError
+	StopToolRegeneration(toolID shared.EntityID) *errors.HTTPError
+	AbortToolRegeneration(toolID shared.EntityID) *errors.HTTPError
+
+	AddToolThreshold(tt *shared.ToolThreshold) *errors.HTTPError
+	UpdateToolThreshold(tt *shared.ToolThreshold) *errors.HTTPError
+	GetToolThreshold(id shared.EntityID) (*shared.ToolThreshold, *errors.HTTPError)
+	ListToolThresholds() ([]*shared.ToolThreshold, *errors.HTTPError)
+	DeleteToolThreshold(id shared.EntityID) *errors.HTTPError
+}
+
+// PressRepository contains all operations on presses.
+type PressRepository interface {
+	AddPress(press *shared.Press) *errors.HTTPError
+	UpdatePress(press *shared.Press) *errors.HTTPError
+	GetPress(id shared.EntityID) (*shared.Press, *errors.HTTPError)
+	GetPressForTool(toolID shared.EntityID) (*shared.Press, *errors.HTTPError)
+	GetPressUtilization(pressID shared.EntityID) (*shared.PressUtilization, *errors.HTTPError)
+	GetPressUtilizations() (map[shared.EntityID]*shared.PressUtilization, *errors.HTTPError)
+	ListPress() ([]*shared.Press, *errors.HTTPError)
+	DeletePress(id shared.EntityID, deletedBy shared.TelegramID) *errors.HTTPError
+}
+
+// CycleRepository contains all operations on press cycles.
+type CycleRepository interface {
+	AddCycle(cycle *shared.Cycle) *errors.HTTPError
+	UpdateCycle(cycle *shared.Cycle) *errors.HTTPError
+	GetCycle(id shared.EntityID) (*shared.Cycle, *errors.HTTPError)
+	GetTotalToolCycles(toolID shared.EntityID) (int64, *errors.HTTPError)
+	ListToolCycles(toolID shared.EntityID) ([]*shared.Cycle, *errors.HTTPError)
+	ListCyclesByPressID(pressID shared.EntityID) ([]*shared.Cycle, *errors.HTTPError)
+	GetCycleSummary(pressID shared.EntityID, filter *shared.CycleSummaryFilter) (*shared.CycleSummary, *errors.HTTPError)
+	DeleteCycle(id shared.EntityID, deletedBy shared.TelegramID) *errors.HTTPError
+	CycleInject(cycle *shared.Cycle) *errors.HTTPError
+	InjectCyclesIntoTool(tool *shared.Tool) *errors.HTTPError
+	GetToolForecast(toolID shared.EntityID) (*shared.ToolForecast, *errors.HTTPError)
+	ListToolForecasts() ([]*shared.ToolForecast, *errors.HTTPError)
+}
+
+// NoteRepository contains all operations on notes.
+type NoteRepository interface {
+	AddNote(note *shared.Note) *errors.HTTPError
+	UpdateNote(note *shared.Note) *errors.HTTPError
+	GetNote(id shared.EntityID) (*shared.Note, *errors.HTTPError)
+	ListNotes() ([]*shared.Note, *errors.HTTPError)
+	ListNotesForLinked(linked string, id int) ([]*shared.Note, *errors.HTTPError)
+	DeleteNote(id shared.EntityID, deletedBy shared.TelegramID) *errors.HTTPError
+}
+
+// UserRepository contains all operations on users and their session cookies.
+type UserRepository interface {
+	AddUser(user *shared.User) *errors.HTTPError
+	UpdateUser(user *shared.User) *errors.HTTPError
+	GetUser(id shared.TelegramID) (*shared.User, *errors.HTTPError)
+	ListUsers() ([]*shared.User, *errors.HTTPError)
+	ListUserNames() (map[shared.TelegramID]string, *errors.HTTPError)
+	DeleteUser(id shared.TelegramID) *errors.HTTPError
+
+	AddCookie(cookie *shared.Cookie) *errors.HTTPError
+	UpdateCookie(cookie *shared.Cookie) *errors.HTTPError
+	GetCookie(value string) (*shared.Cookie, *errors.HTTPError)
+	ListCookies() ([]*shared.Cookie, *errors.HTTPError)
+	ListCookiesByUserID(userID shared.TelegramID) ([]*shared.Cookie, *errors.HTTPError)
+	DeleteCookie(value string) *errors.HTTPError
+	DeleteCookiesByUserID(userID shared.TelegramID) *errors.HTTPError
+	DeleteExpiredCookies() (int, *errors.HTTPError)
+}
+
+// ApiKeyRepository contains all operations on the (hashed) API keys of users.
+type ApiKeyRepository interface {
+	AddApiKey(key *shared.ApiKey) *errors.HTTPError
+	GetApiKey(id shared.EntityID) (*shared.ApiKey, *errors.HTTPError)
+	GetUserByApiKey(apiKey string) (*shared.User, *errors.HTTPError)
+	ListApiKeys() ([]*shared.ApiKey, *errors.HTTPError)
+	ListApiKeysByUserID(userID shared.TelegramID) ([]*shared.ApiKey, *errors.HTTPError)
+	DeleteApiKey(id shared.EntityID) *errors.HTTPError
+}
+
+// LoginRepository contains all operations on the login history and the lockouts
+// after failed logins.
+type LoginRepository interface {
+	RecordLoginAttempt(attempt *shared.LoginAttempt, keys ...string)
+	AddLoginAttempt(attempt *shared.LoginAttempt) *errors.HTTPError
+	ListLoginAttemptsByUserID(userID shared.TelegramID, limit int) ([]*shared.LoginAttempt, *errors.HTTPError)
+	DeleteLoginAttemptsBefore(before shared.UnixMilli) (int, *errors.HTTPError)
+
+	GetLockout(key string) (*shared.Lockout, *errors.HTTPError)
+	ListLockouts() ([]*shared.Lockout, *errors.HTTPError)
+	ListLockoutsByKeys(keys ...string) ([]*shared.Lockout, *errors.HTTPError)
+	AddLoginFailure(keys ...string) *errors.HTTPError
+	ClearLockouts(keys ...string) *errors.HTTPError
+	ClearLockout(key string) *errors.HTTPError
+	ClearAllLockouts() (int, *errors.HTTPError)
+	DeleteStaleLockouts() (int, *errors.HTTPError)
+}
+
+// ReportRepository contains all operations on trouble reports.
+type ReportRepository interface {
+	AddTroubleReport(report *shared.TroubleReport) *errors.HTTPError
+	UpdateTroubleReport(report *shared.TroubleReport) *errors.HTTPError
+	GetTroubleReport(id shared.EntityID) (*shared.TroubleReport, *errors.HTTPError)
+	ListTroubleReports() ([]*shared.TroubleReport, *errors.HTTPError)
+	ListTroubleReportAttachments() ([]string, *errors.HTTPError)
+	DeleteTroubleReport(id shared.EntityID, deletedBy shared.TelegramID) *errors.HTTPError
+}
+
+// TrashRepository contains all operations on soft deleted entities.
+type TrashRepository interface {
+	ListTrash() ([]*shared.TrashItem, *errors.HTTPError)
+	RestoreTrashItem(kind shared.TrashKind, id shared.EntityID) *errors.HTTPError
+	PurgeTrash(before shared.UnixMilli) (int, *errors.HTTPError)
+}
+
+// SearchRepository contains the full-text search over all databases.
+type SearchRepository interface {
+	Search(query string, limit int) (*shared.SearchResults, *errors.HTTPError)
+}
+
+// AuditRepository contains all operations on the audit log.
+type AuditRepository interface {
+	AddAuditEntry(entry *shared.AuditEntry) *errors.HTTPError
+	Audit(userID shared.TelegramID, action shared.AuditAction, before, after shared.Auditable)
+	ListAuditEntries(entity string, limit int) ([]*shared.AuditEntry, *errors.HTTPError)
+}
+
+// FeedRepository contains all operations on the activity feed.
+type FeedRepository interface {
+	AddFeed(feed *shared.Feed) *errors.HTTPError
+	ListFeeds(filter shared.FeedFilter) ([]*shared.Feed, *errors.HTTPError)
+	GetLastFeed(userID shared.TelegramID) (shared.EntityID, *errors.HTTPError)
+	CountUnreadFeeds(userID shared.TelegramID) (int, *errors.HTTPError)
+	MarkFeedsRead(userID shared.TelegramID) *errors.HTTPError
+}
+
+// TransactionRepository runs operations on several databases as one unit of work.
+type TransactionRepository interface {
+	Transaction(fn func(tx *Tx) *errors.HTTPError) *errors.HTTPError
+}
+
+// HealthRepository contains the checks of the database connections.
+type HealthRepository interface {
+	Ping(ctx context.Context, name string) error
+}
+
+// Repository combines all repositories, handlers get it from RegisterAll and
+// keep only the repositories they use.
+type Repository interface {
+	ToolRepository
+	PressRepository
+	CycleRepository
+	NoteRepository
+	UserRepository
+	ApiKeyRepository
+	LoginRepository
+	ReportRepository
+	TrashRepository
+	SearchRepository
+	AuditRepository
+	FeedRepository
+	TransactionRepository
+	HealthRepository
+}
+
+var (
+	_ Repository = (*Store)(nil)
+
+	_ ToolRepository   = (*Store)(nil)
+	_ PressRepository  = (*Store)(nil)
+	_ CycleRepository  = (*Store)(nil)
+	_ NoteRepository   = (*Store)(nil)
+	_ UserRepository   = (*Store)(nil)
+	_ ApiKeyRepository = (*Store)(nil)
+	_ LoginRepository  = (*Store)(nil)
+	_ ReportRepository = (*Store)(nil)
+	_ TrashRepository  = (*Store)(nil)
+	_ SearchRepository = (*Store)(nil)
+	_ AuditRepository  = (*Store)(nil)
+	_ FeedRepository   = (*Store)(nil)
+
+	_ TransactionRepository = (*Store)(nil)
+	_ HealthRepository      = (*Store)(nil)
+)
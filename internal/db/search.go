@@ -0,0 +1,297 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/shared"
+)
+
+// -----------------------------------------------------------------------------
+// Table Creation Statements
+// -----------------------------------------------------------------------------
+
+// The search indexes are plain FTS5 tables using the ID of the indexed row as
+// rowid, kept up to date by triggers. Rows in the trash stay indexed, the search
+// queries filter them out.
+const (
+	sqlCreateToolsFTSTable string = `
+CREATE VIRTUAL TABLE IF NOT EXISTS tools_fts USING fts5(
+	type, code, format,
+	tokenize = 'unicode61 remove_diacritics 2'
+);`
+
+	sqlCreateToolsFTSTriggers string = `
+CREATE TRIGGER IF NOT EXISTS tools_fts_insert AFTER INSERT ON tools BEGIN
+	INSERT INTO tools_fts (rowid, type, code, format)
+	VALUES (new.id, new.type, new.code, new.width || 'x' || new.height);
+END;
+
+CREATE TRIGGER IF NOT EXISTS tools_fts_update AFTER UPDATE OF type, code, width, height ON tools BEGIN
+	DELETE FROM tools_fts WHERE rowid = old.id;
+	INSERT INTO tools_fts (rowid, type, code, format)
+	VALUES (new.id, new.type, new.code, new.width || 'x' || new.height);
+END;
+
+CREATE TRIGGER IF NOT EXISTS tools_fts_delete AFTER DELETE ON tools BEGIN
+	DELETE FROM tools_fts WHERE rowid = old.id;
+END;`
+
+	sqlFillToolsFTSTable string = `
+INSERT INTO tools_fts (rowid, type, code, format)
+SELECT id, type, code, width || 'x' || height FROM tools;`
+
+	sqlCreateNotesFTSTable string = `
+CREATE VIRTUAL TABLE IF NOT EXISTS notes_fts USING fts5(
+	content,
+	tokenize = 'unicode61 remove_diacritics 2'
+);`
+
+	sqlCreateNotesFTSTriggers string = `
+CREATE TRIGGER IF NOT EXISTS notes_fts_insert AFTER INSERT ON notes BEGIN
+	INSERT INTO notes_fts (rowid, content) VALUES (new.id, new.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS notes_fts_update AFTER UPDATE OF content ON notes BEGIN
+	DELETE FROM notes_fts WHERE rowid = old.id;
+	INSERT INTO notes_fts (rowid, content) VALUES (new.id, new.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS notes_fts_delete AFTER DELETE ON notes BEGIN
+	DELETE FROM notes_fts WHERE rowid = old.id;
+END;`
+
+	sqlFillNotesFTSTable string = `
+INSERT INTO notes_fts (rowid, content)
+SELECT id, content FROM notes;`
+
+	sqlCreateTroubleReportsFTSTable string = `
+CREATE VIRTUAL TABLE IF NOT EXISTS trouble_reports_fts USING fts5(
+	title, content,
+	tokenize = 'unicode61 remove_diacritics 2'
+);`
+
+	sqlCreateTroubleReportsFTSTriggers string = `
+CREATE TRIGGER IF NOT EXISTS trouble_reports_fts_insert AFTER INSERT ON trouble_reports BEGIN
+	INSERT INTO trouble_reports_fts (rowid, title, content) VALUES (new.id, new.title, new.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS trouble_reports_fts_update AFTER UPDATE OF title, content ON trouble_reports BEGIN
+	DELETE FROM trouble_reports_fts WHERE rowid = old.id;
+	INSERT INTO trouble_reports_fts (rowid, title, content) VALUES (new.id, new.title, new.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS trouble_reports_fts_delete AFTER DELETE ON trouble_reports BEGIN
+	DELETE FROM trouble_reports_fts WHERE rowid = old.id;
+END;`
+
+	sqlFillTroubleReportsFTSTable string = `
+INSERT INTO trouble_reports_fts (rowid, title, content)
+SELECT id, title, content FROM trouble_reports;`
+)
+
+// -----------------------------------------------------------------------------
+// Search Queries
+// -----------------------------------------------------------------------------
+
+const (
+	sqlSearchTools string = `
+SELECT t.id, t.width, t.height, t.position, t.type, t.code, t.cycles_offset, t.is_dead, t.cassette,
+	t.min_thickness, t.max_thickness, t.cycles_warning, t.cycles_error,
+	highlight(tools_fts, 2, :open, :close) || ' ' ||
+		highlight(tools_fts, 0, :open, :close) || ' ' ||
+		highlight(tools_fts, 1, :open, :close)
+FROM tools_fts
+JOIN tools t ON t.id = tools_fts.rowid
+WHERE tools_fts MATCH :query AND t.deleted_at = 0
+ORDER BY rank
+LIMIT :limit;`
+
+	sqlSearchNotes string = `
+SELECT n.id, n.level, n.content, n.created_at, n.linked,
+	snippet(notes_fts, 0, :open, :close, '…', 16)
+FROM notes_fts
+JOIN notes n ON n.id = notes_fts.rowid
+WHERE notes_fts MATCH :query AND n.deleted_at = 0
+ORDER BY rank
+LIMIT :limit;`
+
+	sqlSearchTroubleReports string = `
+SELECT r.id,
+	highlight(trouble_reports_fts, 0, :open, :close),
+	snippet(trouble_reports_fts, 1, :open, :close, '…', 16)
+FROM trouble_reports_fts
+JOIN trouble_reports r ON r.id = trouble_reports_fts.rowid
+WHERE trouble_reports_fts MATCH :query AND r.deleted_at = 0
+ORDER BY rank
+LIMIT :limit;`
+
+	sqlHasFTS5 string = `
+SELECT sqlite_compileoption_used('ENABLE_FTS5');`
+)
+
+// -----------------------------------------------------------------------------
+// Search Functions
+// -----------------------------------------------------------------------------
+
+// Search runs a full-text search over tools, notes and trouble reports.
+//
+// Every word of the query must match the beginning of a word in the entity,
+// entities in the trash are never found.
+//
+// Parameters:
+//   - query: The search input as typed by the user
+//   - limit: The maximum number of hits per group
+//
+// Returns:
+//   - *shared.SearchResults: The hits, grouped by entity, best matches first
+//   - *errors.HTTPError: Error if any of the searches fails
+func (s *Store) Search(query string, limit int) (*shared.SearchResults, *errors.HTTPError) {
+	results := &shared.SearchResults{Query: query}
+
+	match := matchQuery(query)
+	if match == "" {
+		return results, nil
+	}
+
+	args := []any{
+		sql.Named("query", match),
+		sql.Named("open", shared.SearchMarkOpen),
+		sql.Named("close", shared.SearchMarkClose),
+		sql.Named("limit", limit),
+	}
+
+	{ // Tools
+		r, err := s.tool.Query(sqlSearchTools, args...)
+		if err != nil {
+			return nil, errors.NewHTTPError(err).Wrap("search tools")
+		}
+		defer r.Close()
+
+		for r.Next() {
+			row := &searchRow{row: r}
+			tool, herr := ScanTool(row)
+			if herr != nil {
+				return nil, herr
+			}
+			results.Tools = append(results.Tools, &shared.SearchHit{
+				ID:      tool.ID,
+				Title:   tool.German(),
+				Snippet: row.snippet,
+			})
+		}
+	}
+
+	{ // Notes
+		r, err := s.note.Query(sqlSearchNotes, args...)
+		if err != nil {
+			return nil, errors.NewHTTPError(err).Wrap("search notes")
+		}
+		defer r.Close()
+
+		for r.Next() {
+			row := &searchRow{row: r}
+			note, herr := ScanNote(row)
+			if herr != nil {
+				return nil, herr
+			}
+			results.Notes = append(results.Notes, &shared.SearchHit{
+				ID:      note.ID,
+				Snippet: row.snippet,
+				Linked:  note.Linked,
+			})
+		}
+	}
+
+	if len(results.Notes) > 0 {
+		if herr := s.setNoteSearchTitles(results.Notes); herr != nil {
+			return nil, herr
+		}
+	}
+
+	{ // Trouble reports
+		r, err := s.reports.Query(sqlSearchTroubleReports, args...)
+		if err != nil {
+			return nil, errors.NewHTTPError(err).Wrap("search trouble reports")
+		}
+		defer r.Close()
+
+		for r.Next() {
+			hit := &shared.SearchHit{}
+			if err = r.Scan(&hit.ID, &hit.Title, &hit.Snippet); err != nil {
+				return nil, errors.NewHTTPError(err)
+			}
+			results.TroubleReports = append(results.TroubleReports, hit)
+		}
+	}
+
+	return results, nil
+}
+
+// setNoteSearchTitles uses the tool or press a note is linked to as title
+func (s *Store) setNoteSearchTitles(hits []*shared.SearchHit) *errors.HTTPError {
+	tools, herr := s.listToolsWithoutCycles()
+	if herr != nil {
+		return herr
+	}
+	presses, herr := s.ListPress()
+	if herr != nil {
+		return herr
+	}
+
+	for _, hit := range hits {
+		hit.Title = "Notiz"
+		if id, ok := linkedID(hit.Linked, "tool_"); ok {
+			if tool, ok := tools[id]; ok {
+				hit.Title = fmt.Sprintf("Notiz zu %s", tool.German())
+			}
+			continue
+		}
+		if id, ok := linkedID(hit.Linked, "press_"); ok {
+			for _, p := range presses {
+				if p.ID == id {
+					hit.Title = fmt.Sprintf("Notiz zu %s", p.German())
+					break
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// searchRow scans the highlighted snippet after the columns read by one of the
+// Scan helpers.
+type searchRow struct {
+	row     Scannable
+	snippet string
+}
+
+func (r *searchRow) Scan(dest ...any) error {
+	return r.row.Scan(append(dest, &r.snippet)...)
+}
+
+// matchQuery turns the user input into a FTS5 query, matching every word as a
+// prefix. Quotes are escaped, so the input can not use the FTS5 query syntax.
+func matchQuery(query string) string {
+	var terms []string
+	for _, word := range strings.Fields(query) {
+		terms = append(terms, `"`+strings.ReplaceAll(word, `"`, `""`)+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
+
+// checkFTS5 makes sure the SQLite library was built with the FTS5 extension,
+// which is required for the search index migrations.
+func checkFTS5(db *sql.DB) error {
+	var used bool
+	if err := db.QueryRow(sqlHasFTS5).Scan(&used); err != nil {
+		return err
+	}
+	if !used {
+		return fmt.Errorf("SQLite was built without FTS5, build with \"-tags sqlite_fts5\"")
+	}
+	return nil
+}
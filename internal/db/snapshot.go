@@ -0,0 +1,140 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Snapshot writes a copy of all open databases into dir.
+//
+// The copies are created with the SQLite online backup API, so it is safe to
+// call this while the server is running, also from another process. All
+// databases are attached to one connection and copied inside a single read
+// transaction, which holds a shared lock on every database file until the last
+// copy is done. Writers of other processes can not commit in between, so the
+// copies show the same point in time. Inside this process the write gate is
+// locked too: running units of work and writes finish first, new ones wait
+// until the last database is copied. Store methods writing with multiple
+// statements outside of a Transaction may still be copied in part.
+//
+// Parameters:
+//   - dir: The directory to write the "<name>.sqlite" files to
+//
+// Returns:
+//   - []string: The paths of the written database files
+//   - error: An error if any of the databases could not be copied
+func (s *Store) Snapshot(dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %v", err)
+	}
+
+	s.writes.Lock()
+	defer s.writes.Unlock()
+
+	ctx := context.Background()
+
+	c, err := s.tool.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection for snapshot: %v", err)
+	}
+	defer c.Close()
+
+	attached, herr := s.attach(ctx, c)
+	defer detach(ctx, c, attached)
+	if herr != nil {
+		return nil, herr.Err()
+	}
+
+	if _, err := c.ExecContext(ctx, `BEGIN;`); err != nil {
+		return nil, fmt.Errorf("failed to begin snapshot transaction: %v", err)
+	}
+	defer func() {
+		if _, err := c.ExecContext(ctx, `ROLLBACK;`); err != nil {
+			slog.Error("Failed to end snapshot transaction", "error", err)
+		}
+	}()
+
+	// Read from every database before copying the first one, the transaction
+	// holds the shared locks from the first read on
+	for _, name := range databaseNames {
+		var n int
+		err := c.QueryRowContext(ctx, fmt.Sprintf(
+			`SELECT COUNT(*) FROM "%s".sqlite_master;`, snapshotSchema(name),
+		)).Scan(&n)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock %s database: %v", name, err)
+		}
+	}
+
+	var files []string
+	for _, name := range databaseNames {
+		path := filepath.Join(dir, name+".sqlite")
+		if err = snapshotDatabase(c, snapshotSchema(name), path); err != nil {
+			return files, fmt.Errorf("failed to snapshot %s database: %v", name, err)
+		}
+		files = append(files, path)
+	}
+
+	return files, nil
+}
+
+// snapshotSchema returns the schema name of a database on the snapshot
+// connection, the tool database is the main database, see attach.
+func snapshotSchema(name string) string {
+	if name == "tool" {
+		return "main"
+	}
+	return name
+}
+
+func snapshotDatabase(srcConn *sql.Conn, schema, path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	dest, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=rwc", path))
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+
+	ctx := context.Background()
+
+	destConn, err := dest.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer destConn.Close()
+
+	return destConn.Raw(func(destDriverConn any) error {
+		return srcConn.Raw(func(srcDriverConn any) error {
+			d, ok := destDriverConn.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected driver connection: %T", destDriverConn)
+			}
+			s, ok := srcDriverConn.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected driver connection: %T", srcDriverConn)
+			}
+
+			b, err := d.Backup("main", s, schema)
+			if err != nil {
+				return err
+			}
+
+			// Copy all pages in one step
+			if _, err = b.Step(-1); err != nil {
+				b.Finish()
+				return err
+			}
+
+			return b.Finish()
+		})
+	})
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/shared"
+)
+
+func TestSnapshotWaitsForTransaction(t *testing.T) {
+	s := newTestStore(t)
+
+	tool := &shared.Tool{Width: 120, Height: 60, Position: shared.SlotUpper, Type: "MASS", Code: "G01"}
+	if herr := s.AddTool(tool); herr != nil {
+		t.Fatalf("add tool: %v", herr)
+	}
+	press := &shared.Press{Number: 5, Type: shared.MachineTypeSACMI, Code: "P5"}
+	if herr := s.AddPress(press); herr != nil {
+		t.Fatalf("add press: %v", herr)
+	}
+
+	dir := t.TempDir()
+	done := make(chan error, 1)
+
+	// The snapshot starts between the tool and the press update
+	herr := s.Transaction(func(tx *Tx) *errors.HTTPError {
+		tl, herr := tx.GetTool(tool.ID)
+		if herr != nil {
+			return herr
+		}
+		tl.Code = "snapshot"
+		if herr := tx.UpdateTool(tl); herr != nil {
+			return herr
+		}
+
+		go func() {
+			_, err := s.Snapshot(dir)
+			done <- err
+		}()
+		time.Sleep(100 * time.Millisecond)
+
+		p, herr := tx.GetPress(press.ID)
+		if herr != nil {
+			return herr
+		}
+		p.Code = "snapshot"
+		return tx.UpdatePress(p)
+	})
+	if herr != nil {
+		t.Fatalf("transaction: %v", herr)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+
+	for _, tc := range []struct {
+		database, table string
+		id              shared.EntityID
+	}{
+		{"tool", "tools", tool.ID},
+		{"press", "presses", press.ID},
+	} {
+		db, err := sql.Open("sqlite3", "file:"+filepath.Join(dir, tc.database+".sqlite")+"?mode=ro")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		var code string
+		if err := db.QueryRow(`SELECT code FROM `+tc.table+` WHERE id = ?;`, tc.id).Scan(&code); err != nil {
+			t.Fatalf("read %s snapshot: %v", tc.database, err)
+		}
+		if code != "snapshot" {
+			t.Errorf("%s snapshot has code %q, want the committed transaction", tc.database, code)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"time"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/shared"
+)
+
+// -----------------------------------------------------------------------------
+// Tool Forecast Functions
+// -----------------------------------------------------------------------------
+
+// GetToolForecast projects the threshold dates of a tool, see shared.NewToolForecast
+func (s *Store) GetToolForecast(toolID shared.EntityID) (*shared.ToolForecast, *errors.HTTPError) {
+	tool, herr := s.GetTool(toolID)
+	if herr != nil {
+		return nil, herr.Wrap("get tool %d", toolID)
+	}
+
+	mounted, herr := s.listMountedTools()
+	if herr != nil {
+		return nil, herr
+	}
+
+	cycles, herr := s.ListToolCycles(toolID)
+	if herr != nil {
+		return nil, herr.Wrap("list cycles for tool %d", toolID)
+	}
+
+	return shared.NewToolForecast(tool, mounted[toolID], cycles, time.Now()), nil
+}
+
+// ListToolForecasts returns the forecasts of all trackable tools and cassettes
+// mounted on a press, unsorted
+func (s *Store) ListToolForecasts() ([]*shared.ToolForecast, *errors.HTTPError) {
+	utilizations, herr := s.GetPressUtilizations()
+	if herr != nil && !herr.IsNotFoundError() {
+		return nil, herr.Wrap("get press utilizations")
+	}
+
+	now := time.Now()
+	ci := s.newCycleInjector()
+
+	var forecasts []*shared.ToolForecast
+	for _, u := range utilizations {
+		press := u.Press()
+		for _, tool := range []*shared.Tool{u.SlotUpper, u.SlotUpperCassette, u.SlotLower} {
+			if tool == nil || !tool.IsTrackable() {
+				continue
+			}
+
+			cycles, herr := s.listToolCycles(tool.ID, ci)
+			if herr != nil {
+				return nil, herr.Wrap("list cycles for tool %d", tool.ID)
+			}
+			forecasts = append(forecasts, shared.NewToolForecast(tool, press, cycles, now))
+		}
+	}
+
+	return forecasts, nil
+}
+
+// listMountedTools maps the IDs of all tools and cassettes mounted on a press to
+// the press, a cassette counts as mounted together with its upper tool
+func (s *Store) listMountedTools() (map[shared.EntityID]*shared.Press, *errors.HTTPError) {
+	presses, herr := s.ListPress()
+	if herr != nil {
+		return nil, herr.Wrap("list presses")
+	}
+
+	mounted := make(map[shared.EntityID]*shared.Press)
+	for _, p := range presses {
+		if p.SlotUp > 0 {
+			mounted[p.SlotUp] = p
+		}
+		if p.SlotDown > 0 {
+			mounted[p.SlotDown] = p
+		}
+	}
+
+	tools, herr := s.listToolsWithoutCycles()
+	if herr != nil {
+		return nil, herr.Wrap("list tools")
+	}
+	for _, t := range tools {
+		if p, ok := mounted[t.ID]; ok && t.Cassette > 0 {
+			mounted[t.Cassette] = p
+		}
+	}
+
+	return mounted, nil
+}
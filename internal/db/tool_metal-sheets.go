@@ -51,15 +51,20 @@ WHERE id = :id AND type = 'upper';`
 	sqlGetUpperMetalSheet string = `
 SELECT id, tool_id, tile_height, value
 FROM metal_sheets
-WHERE id = :id AND type = 'upper'
+WHERE id = :id AND type = 'upper' AND deleted_at = 0
 ORDER BY tile_height ASC, value ASC;`
 
 	sqlListUpperMetalSheetsByTool string = `
 SELECT id, tool_id, tile_height, value
 FROM metal_sheets
-WHERE tool_id = :tool_id AND type = 'upper'
+WHERE tool_id = :tool_id AND type = 'upper' AND deleted_at = 0
 ORDER BY tile_height ASC, value ASC;`
 
+	sqlDeleteUpperMetalSheet string = `
+UPDATE metal_sheets
+SET deleted_at = :deleted_at, deleted_by = :deleted_by, deleted_with = :deleted_with
+WHERE id = :id AND type = 'upper' AND deleted_at = 0;`
+
 	// -----------------------------------------------------------------------------
 	// Lower Metal Sheets Queries
 	// -----------------------------------------------------------------------------
@@ -86,14 +91,19 @@ WHERE id = :id AND type = 'lower';`
 	sqlGetLowerMetalSheet string = `
 SELECT id, tool_id, tile_height, value, marke_height, stf, stf_max, identifier
 FROM metal_sheets
-WHERE id = :id AND type = 'lower'
+WHERE id = :id AND type = 'lower' AND deleted_at = 0
 ORDER BY tile_height ASC, value ASC;`
 
 	sqlListLowerMetalSheetsByTool string = `
 SELECT id, tool_id, tile_height, value, marke_height, stf, stf_max, identifier
 FROM metal_sheets
-WHERE tool_id = :tool_id AND type = 'lower'
+WHERE tool_id = :tool_id AND type = 'lower' AND deleted_at = 0
 ORDER BY tile_height ASC, value ASC;`
+
+	sqlDeleteLowerMetalSheet string = `
+UPDATE metal_sheets
+SET deleted_at = :deleted_at, deleted_by = :deleted_by, deleted_with = :deleted_with
+WHERE id = :id AND type = 'lower' AND deleted_at = 0;`
 )
 
 // -----------------------------------------------------------------------------
@@ -101,7 +111,7 @@ ORDER BY tile_height ASC, value ASC;`
 // -----------------------------------------------------------------------------
 
 // AddUpperMetalSheet adds a new upper metal sheet to the database
-func AddUpperMetalSheet(ums *shared.UpperMetalSheet) *errors.HTTPError {
+func (s *Store) AddUpperMetalSheet(ums *shared.UpperMetalSheet) *errors.HTTPError {
 	if verr := ums.Validate(); verr != nil {
 		return verr.HTTPError().Wrap("invalid upper metal sheet")
 	}
@@ -123,19 +133,23 @@ func AddUpperMetalSheet(ums *shared.UpperMetalSheet) *errors.HTTPError {
 		sql.Named("value", ums.Value),
 	)
 
-	if _, err := dbTool.Exec(query, queryArgs...); err != nil {
+	r, err := s.tool.Exec(query, queryArgs...)
+	if err != nil {
+		return errors.NewHTTPError(err)
+	}
+	if ums.ID, err = lastInsertID(r); err != nil {
 		return errors.NewHTTPError(err)
 	}
 	return nil
 }
 
 // UpdateUpperMetalSheet updates an existing upper metal sheet in the database
-func UpdateUpperMetalSheet(ums *shared.UpperMetalSheet) *errors.HTTPError {
+func (s *Store) UpdateUpperMetalSheet(ums *shared.UpperMetalSheet) *errors.HTTPError {
 	if verr := ums.Validate(); verr != nil {
 		return verr.HTTPError().Wrap("invalid upper metal sheet")
 	}
 
-	_, err := dbTool.Exec(sqlUpdateUpperMetalSheet,
+	_, err := s.tool.Exec(sqlUpdateUpperMetalSheet,
 		sql.Named("id", ums.ID),
 		sql.Named("tool_id", ums.ToolID),
 		sql.Named("tile_height", ums.TileHeight),
@@ -148,8 +162,8 @@ func UpdateUpperMetalSheet(ums *shared.UpperMetalSheet) *errors.HTTPError {
 }
 
 // GetUpperMetalSheet retrieves an upper metal sheet by its ID
-func GetUpperMetalSheet(metalSheetID shared.EntityID) (*shared.UpperMetalSheet, *errors.HTTPError) {
-	r := dbTool.QueryRow(sqlGetUpperMetalSheet, sql.Named("id", metalSheetID))
+func (s *Store) GetUpperMetalSheet(metalSheetID shared.EntityID) (*shared.UpperMetalSheet, *errors.HTTPError) {
+	r := s.tool.QueryRow(sqlGetUpperMetalSheet, sql.Named("id", metalSheetID))
 	ums, merr := ScanUpperMetalSheet(r)
 	if merr != nil {
 		return nil, merr
@@ -158,8 +172,8 @@ func GetUpperMetalSheet(metalSheetID shared.EntityID) (*shared.UpperMetalSheet,
 }
 
 // ListUpperMetalSheetsByTool retrieves all upper metal sheets for a given tool
-func ListUpperMetalSheetsByTool(toolID shared.EntityID) ([]*shared.UpperMetalSheet, *errors.HTTPError) {
-	rows, err := dbTool.Query(sqlListUpperMetalSheetsByTool, sql.Named("tool_id", toolID))
+func (s *Store) ListUpperMetalSheetsByTool(toolID shared.EntityID) ([]*shared.UpperMetalSheet, *errors.HTTPError) {
+	rows, err := s.tool.Query(sqlListUpperMetalSheetsByTool, sql.Named("tool_id", toolID))
 	if err != nil {
 		return nil, errors.NewHTTPError(err)
 	}
@@ -176,13 +190,9 @@ func ListUpperMetalSheetsByTool(toolID shared.EntityID) ([]*shared.UpperMetalShe
 	return metalSheets, nil
 }
 
-// DeleteUpperMetalSheet removes an upper metal sheet from the database
-func DeleteUpperMetalSheet(id shared.EntityID) *errors.HTTPError {
-	_, err := dbTool.Exec("DELETE FROM metal_sheets WHERE id = ? AND type = 'upper'", id)
-	if err != nil {
-		return errors.NewHTTPError(err)
-	}
-	return nil
+// DeleteUpperMetalSheet moves an upper metal sheet to the trash
+func (s *Store) DeleteUpperMetalSheet(id shared.EntityID, deletedBy shared.TelegramID) *errors.HTTPError {
+	return s.softDeleteWithCascade("tool", "metal_sheets", sqlDeleteUpperMetalSheet, int64(id), deletedBy)
 }
 
 // -----------------------------------------------------------------------------
@@ -190,7 +200,7 @@ func DeleteUpperMetalSheet(id shared.EntityID) *errors.HTTPError {
 // -----------------------------------------------------------------------------
 
 // AddLowerMetalSheet adds a new lower metal sheet to the database
-func AddLowerMetalSheet(lms *shared.LowerMetalSheet) *errors.HTTPError {
+func (s *Store) AddLowerMetalSheet(lms *shared.LowerMetalSheet) *errors.HTTPError {
 	if verr := lms.Validate(); verr != nil {
 		return verr.HTTPError().Wrap("invalid lower metal sheet")
 	}
@@ -220,7 +230,11 @@ func AddLowerMetalSheet(lms *shared.LowerMetalSheet) *errors.HTTPError {
 		)
 	}
 
-	if _, err := dbTool.Exec(query, queryArgs...); err != nil {
+	r, err := s.tool.Exec(query, queryArgs...)
+	if err != nil {
+		return errors.NewHTTPError(err)
+	}
+	if lms.ID, err = lastInsertID(r); err != nil {
 		return errors.NewHTTPError(err)
 	}
 
@@ -228,12 +242,12 @@ func AddLowerMetalSheet(lms *shared.LowerMetalSheet) *errors.HTTPError {
 }
 
 // UpdateLowerMetalSheet updates an existing lower metal sheet in the database
-func UpdateLowerMetalSheet(lms *shared.LowerMetalSheet) *errors.HTTPError {
+func (s *Store) UpdateLowerMetalSheet(lms *shared.LowerMetalSheet) *errors.HTTPError {
 	if verr := lms.Validate(); verr != nil {
 		return verr.HTTPError().Wrap("invalid lower metal sheet")
 	}
 
-	_, err := dbTool.Exec(sqlUpdateLowerMetalSheet,
+	_, err := s.tool.Exec(sqlUpdateLowerMetalSheet,
 		sql.Named("id", lms.ID),
 		sql.Named("tool_id", lms.ToolID),
 		sql.Named("tile_height", lms.TileHeight),
@@ -250,8 +264,8 @@ func UpdateLowerMetalSheet(lms *shared.LowerMetalSheet) *errors.HTTPError {
 }
 
 // GetLowerMetalSheet retrieves a lower metal sheet by its ID
-func GetLowerMetalSheet(metalSheetID shared.EntityID) (*shared.LowerMetalSheet, *errors.HTTPError) {
-	r := dbTool.QueryRow(sqlGetLowerMetalSheet, sql.Named("id", metalSheetID))
+func (s *Store) GetLowerMetalSheet(metalSheetID shared.EntityID) (*shared.LowerMetalSheet, *errors.HTTPError) {
+	r := s.tool.QueryRow(sqlGetLowerMetalSheet, sql.Named("id", metalSheetID))
 	lms, merr := ScanLowerMetalSheet(r)
 	if merr != nil {
 		return nil, merr
@@ -260,8 +274,8 @@ func GetLowerMetalSheet(metalSheetID shared.EntityID) (*shared.LowerMetalSheet,
 }
 
 // ListLowerMetalSheetsByTool retrieves all lower metal sheets for a given tool
-func ListLowerMetalSheetsByTool(toolID shared.EntityID) ([]*shared.LowerMetalSheet, *errors.HTTPError) {
-	rows, err := dbTool.Query(sqlListLowerMetalSheetsByTool, sql.Named("tool_id", toolID))
+func (s *Store) ListLowerMetalSheetsByTool(toolID shared.EntityID) ([]*shared.LowerMetalSheet, *errors.HTTPError) {
+	rows, err := s.tool.Query(sqlListLowerMetalSheetsByTool, sql.Named("tool_id", toolID))
 	if err != nil {
 		return nil, errors.NewHTTPError(err)
 	}
@@ -278,13 +292,9 @@ func ListLowerMetalSheetsByTool(toolID shared.EntityID) ([]*shared.LowerMetalShe
 	return metalSheets, nil
 }
 
-// DeleteLowerMetalSheet removes a lower metal sheet from the database
-func DeleteLowerMetalSheet(id shared.EntityID) *errors.HTTPError {
-	_, err := dbTool.Exec("DELETE FROM metal_sheets WHERE id = ? AND type = 'lower'", id)
-	if err != nil {
-		return errors.NewHTTPError(err)
-	}
-	return nil
+// DeleteLowerMetalSheet moves a lower metal sheet to the trash
+func (s *Store) DeleteLowerMetalSheet(id shared.EntityID, deletedBy shared.TelegramID) *errors.HTTPError {
+	return s.softDeleteWithCascade("tool", "metal_sheets", sqlDeleteLowerMetalSheet, int64(id), deletedBy)
 }
 
 // -----------------------------------------------------------------------------
@@ -85,7 +85,7 @@ WHERE tool_id = :tool_id AND stop = 0;`
 // -----------------------------------------------------------------------------
 
 // AddToolRegeneration adds a new tool regeneration to the database
-func AddToolRegeneration(tr *shared.ToolRegeneration) *errors.HTTPError {
+func (s *Store) AddToolRegeneration(tr *shared.ToolRegeneration) *errors.HTTPError {
 	if err := tr.Validate(); err != nil {
 		return errors.NewHTTPError(err)
 	}
@@ -111,19 +111,23 @@ func AddToolRegeneration(tr *shared.ToolRegeneration) *errors.HTTPError {
 		)
 	}
 
-	if _, err := dbTool.Exec(query, queryArgs...); err != nil {
+	r, err := s.tool.Exec(query, queryArgs...)
+	if err != nil {
+		return errors.NewHTTPError(err)
+	}
+	if tr.ID, err = lastInsertID(r); err != nil {
 		return errors.NewHTTPError(err)
 	}
 	return nil
 }
 
 // UpdateToolRegeneration updates an existing tool regeneration in the database
-func UpdateToolRegeneration(tr *shared.ToolRegeneration) *errors.HTTPError {
+func (s *Store) UpdateToolRegeneration(tr *shared.ToolRegeneration) *errors.HTTPError {
 	if err := tr.Validate(); err != nil {
 		return errors.NewHTTPError(err)
 	}
 
-	_, err := dbTool.Exec(sqlUpdateToolRegeneration,
+	_, err := s.tool.Exec(sqlUpdateToolRegeneration,
 		sql.Named("id", tr.ID),
 		sql.Named("tool_id", tr.ToolID),
 		sql.Named("start", tr.Start),
@@ -136,8 +140,8 @@ func UpdateToolRegeneration(tr *shared.ToolRegeneration) *errors.HTTPError {
 }
 
 // GetToolRegeneration retrieves a tool regeneration by its ID
-func GetToolRegeneration(id shared.EntityID) (*shared.ToolRegeneration, *errors.HTTPError) {
-	row := dbTool.QueryRow(sqlGetToolRegeneration, sql.Named("id", int64(id)))
+func (s *Store) GetToolRegeneration(id shared.EntityID) (*shared.ToolRegeneration, *errors.HTTPError) {
+	row := s.tool.QueryRow(sqlGetToolRegeneration, sql.Named("id", int64(id)))
 	tr, herr := ScanToolRegeneration(row)
 	if herr != nil {
 		return nil, herr
@@ -146,8 +150,8 @@ func GetToolRegeneration(id shared.EntityID) (*shared.ToolRegeneration, *errors.
 }
 
 // ListToolRegenerations retrieves all tool regenerations from the database
-func ListToolRegenerations() ([]*shared.ToolRegeneration, *errors.HTTPError) {
-	r, err := dbTool.Query(sqlListToolRegenerations)
+func (s *Store) ListToolRegenerations() ([]*shared.ToolRegeneration, *errors.HTTPError) {
+	r, err := s.tool.Query(sqlListToolRegenerations)
 	if err != nil {
 		return nil, errors.NewHTTPError(err)
 	}
@@ -166,8 +170,8 @@ func ListToolRegenerations() ([]*shared.ToolRegeneration, *errors.HTTPError) {
 }
 
 // ListToolRegenerationsByTool retrieves all tool regenerations for a specific tool
-func ListToolRegenerationsByTool(toolID shared.EntityID) ([]*shared.ToolRegeneration, *errors.HTTPError) {
-	rows, err := dbTool.Query(sqlListToolRegenerationsByTool, sql.Named("tool_id", int64(toolID)))
+func (s *Store) ListToolRegenerationsByTool(toolID shared.EntityID) ([]*shared.ToolRegeneration, *errors.HTTPError) {
+	rows, err := s.tool.Query(sqlListToolRegenerationsByTool, sql.Named("tool_id", int64(toolID)))
 	if err != nil {
 		return nil, errors.NewHTTPError(err)
 	}
@@ -187,8 +191,8 @@ func ListToolRegenerationsByTool(toolID shared.EntityID) ([]*shared.ToolRegenera
 }
 
 // DeleteToolRegeneration removes a tool regeneration from the database
-func DeleteToolRegeneration(id shared.EntityID) *errors.HTTPError {
-	_, err := dbTool.Exec(sqlDeleteToolRegeneration, sql.Named("id", id))
+func (s *Store) DeleteToolRegeneration(id shared.EntityID) *errors.HTTPError {
+	_, err := s.tool.Exec(sqlDeleteToolRegeneration, sql.Named("id", id))
 	if err != nil {
 		return errors.NewHTTPError(err)
 	}
@@ -196,8 +200,8 @@ func DeleteToolRegeneration(id shared.EntityID) *errors.HTTPError {
 }
 
 // DeleteToolRegenerationByTool removes all tool regenerations for a specific tool
-func DeleteToolRegenerationByTool(toolID shared.EntityID) *errors.HTTPError {
-	_, err := dbTool.Exec(sqlDeleteToolRegenerationByTool, sql.Named("tool_id", toolID))
+func (s *Store) DeleteToolRegenerationByTool(toolID shared.EntityID) *errors.HTTPError {
+	_, err := s.tool.Exec(sqlDeleteToolRegenerationByTool, sql.Named("tool_id", toolID))
 	if err != nil {
 		return errors.NewHTTPError(err)
 	}
@@ -205,8 +209,8 @@ func DeleteToolRegenerationByTool(toolID shared.EntityID) *errors.HTTPError {
 }
 
 // ToolRegenerationInProgress checks if a tool regeneration is currently in progress
-func ToolRegenerationInProgress(toolID shared.EntityID) (bool, *errors.HTTPError) {
-	row := dbTool.QueryRow(sqlToolRegenerationInProgress, sql.Named("tool_id", toolID))
+func (s *Store) ToolRegenerationInProgress(toolID shared.EntityID) (bool, *errors.HTTPError) {
+	row := s.tool.QueryRow(sqlToolRegenerationInProgress, sql.Named("tool_id", toolID))
 	var count int
 	err := row.Scan(&count)
 	if err != nil {
@@ -216,20 +220,20 @@ func ToolRegenerationInProgress(toolID shared.EntityID) (bool, *errors.HTTPError
 }
 
 // StartToolRegeneration starts a new tool regeneration
-func StartToolRegeneration(toolID shared.EntityID) *errors.HTTPError {
-	tool, herr := GetTool(toolID)
+func (s *Store) StartToolRegeneration(toolID shared.EntityID) *errors.HTTPError {
+	tool, herr := s.GetTool(toolID)
 	if herr != nil {
 		return herr.Wrap("getting tool by ID failed")
 	}
 
 	// Check if a already started regeneration exists for this tool
-	if inProgress, herr := ToolRegenerationInProgress(tool.ID); herr != nil {
+	if inProgress, herr := s.ToolRegenerationInProgress(tool.ID); herr != nil {
 		return herr.Wrap("checking for in-progress regeneration failed (Tool ID %d)", tool.ID)
 	} else if inProgress {
 		return errors.NewHTTPError(fmt.Errorf("a tool regeneration is already in progress for tool with ID %d", tool.ID))
 	}
 
-	_, err := dbTool.Exec(sqlStartToolRegeneration,
+	_, err := s.tool.Exec(sqlStartToolRegeneration,
 		sql.Named("tool_id", tool.ID),
 		sql.Named("start", shared.NewUnixMilli(time.Now())),
 	)
@@ -241,34 +245,43 @@ func StartToolRegeneration(toolID shared.EntityID) *errors.HTTPError {
 }
 
 // StopToolRegeneration stops an ongoing tool regeneration
-func StopToolRegeneration(toolID shared.EntityID) *errors.HTTPError {
-	tool, herr := GetTool(toolID)
-	if herr != nil {
-		return herr.Wrap("getting tool by ID failed")
-	}
+func (s *Store) StopToolRegeneration(toolID shared.EntityID) *errors.HTTPError {
+	// Stopping the regeneration and resetting the tool cycles must not be split,
+	// the tool is read inside the unit of work to not overwrite concurrent changes
+	return s.Transaction(func(tx *Tx) *errors.HTTPError {
+		tool, herr := tx.GetTool(toolID)
+		if herr != nil {
+			return herr.Wrap("getting tool by ID failed")
+		}
 
-	_, err := dbTool.Exec(sqlStopToolRegeneration,
-		sql.Named("tool_id", toolID),
-		sql.Named("stop", shared.NewUnixMilli(time.Now())),
-	)
-	if err != nil {
-		return errors.NewHTTPError(err)
-	}
+		e, herr := tx.get("tool")
+		if herr != nil {
+			return herr
+		}
 
-	// Reset tool cycles to zero
-	tool.CyclesOffset = 0
-	tool.Cycles = 0
-	herr = UpdateTool(tool)
-	if herr != nil {
-		return herr.Wrap("updating tool after regeneration failed")
-	}
+		_, err := e.Exec(sqlStopToolRegeneration,
+			sql.Named("tool_id", toolID),
+			sql.Named("stop", shared.NewUnixMilli(time.Now())),
+		)
+		if err != nil {
+			return errors.NewHTTPError(err)
+		}
 
-	return nil
+		// Reset tool cycles to zero
+		tool.CyclesOffset = 0
+		tool.Cycles = 0
+		herr = tx.UpdateTool(tool)
+		if herr != nil {
+			return herr.Wrap("updating tool after regeneration failed")
+		}
+
+		return nil
+	})
 }
 
 // AbortToolRegeneration aborts an ongoing tool regeneration
-func AbortToolRegeneration(toolID shared.EntityID) *errors.HTTPError {
-	herr := DeleteToolRegenerationByTool(toolID)
+func (s *Store) AbortToolRegeneration(toolID shared.EntityID) *errors.HTTPError {
+	herr := s.DeleteToolRegenerationByTool(toolID)
 	if herr != nil {
 		return herr.Wrap("deleting tool regeneration failed")
 	}
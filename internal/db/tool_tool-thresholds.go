@@ -0,0 +1,185 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/shared"
+)
+
+// -----------------------------------------------------------------------------
+// Table Creation Statements
+// -----------------------------------------------------------------------------
+
+const (
+	sqlCreateToolThresholdsTable string = `
+CREATE TABLE IF NOT EXISTS tool_thresholds (
+	id INTEGER NOT NULL,
+	type TEXT NOT NULL,
+	width INTEGER NOT NULL DEFAULT 0, -- 0 for all formats
+	height INTEGER NOT NULL DEFAULT 0, -- 0 for all formats
+	position INTEGER NOT NULL DEFAULT 0, -- 0 for all positions
+	warning INTEGER NOT NULL,
+	error INTEGER NOT NULL,
+
+	PRIMARY KEY("id" AUTOINCREMENT),
+	UNIQUE(type COLLATE NOCASE, width, height, position)
+);`
+
+	sqlAddToolThreshold string = `
+INSERT INTO tool_thresholds (type, width, height, position, warning, error)
+VALUES (:type, :width, :height, :position, :warning, :error);`
+
+	sqlUpdateToolThreshold string = `
+UPDATE tool_thresholds
+SET
+	type = :type,
+	width = :width,
+	height = :height,
+	position = :position,
+	warning = :warning,
+	error = :error
+WHERE id = :id;`
+
+	sqlGetToolThreshold string = `
+SELECT id, type, width, height, position, warning, error
+FROM tool_thresholds
+WHERE id = :id;`
+
+	sqlListToolThresholds string = `
+SELECT id, type, width, height, position, warning, error
+FROM tool_thresholds
+ORDER BY type COLLATE NOCASE ASC, width ASC, height ASC, position ASC;`
+
+	sqlDeleteToolThreshold string = `
+DELETE FROM tool_thresholds
+WHERE id = :id;`
+)
+
+// -----------------------------------------------------------------------------
+// Tool Threshold Functions
+// -----------------------------------------------------------------------------
+
+// AddToolThreshold adds a new tool threshold, only one threshold per type, format
+// and position is allowed
+func (s *Store) AddToolThreshold(tt *shared.ToolThreshold) *errors.HTTPError {
+	if herr := s.checkToolThreshold(tt); herr != nil {
+		return herr
+	}
+
+	r, err := s.tool.Exec(sqlAddToolThreshold,
+		sql.Named("type", tt.Type),
+		sql.Named("width", tt.Width),
+		sql.Named("height", tt.Height),
+		sql.Named("position", tt.Position),
+		sql.Named("warning", tt.Warning),
+		sql.Named("error", tt.Error),
+	)
+	if err != nil {
+		return errors.NewHTTPError(err)
+	}
+	if tt.ID, err = lastInsertID(r); err != nil {
+		return errors.NewHTTPError(err)
+	}
+	return nil
+}
+
+// UpdateToolThreshold updates an existing tool threshold
+func (s *Store) UpdateToolThreshold(tt *shared.ToolThreshold) *errors.HTTPError {
+	if herr := s.checkToolThreshold(tt); herr != nil {
+		return herr
+	}
+
+	_, err := s.tool.Exec(sqlUpdateToolThreshold,
+		sql.Named("id", tt.ID),
+		sql.Named("type", tt.Type),
+		sql.Named("width", tt.Width),
+		sql.Named("height", tt.Height),
+		sql.Named("position", tt.Position),
+		sql.Named("warning", tt.Warning),
+		sql.Named("error", tt.Error),
+	)
+	if err != nil {
+		return errors.NewHTTPError(err)
+	}
+	return nil
+}
+
+// GetToolThreshold retrieves a tool threshold by its ID
+func (s *Store) GetToolThreshold(id shared.EntityID) (*shared.ToolThreshold, *errors.HTTPError) {
+	return ScanToolThreshold(s.tool.QueryRow(sqlGetToolThreshold, sql.Named("id", id)))
+}
+
+// ListToolThresholds retrieves all tool thresholds, ordered by type, format and position
+func (s *Store) ListToolThresholds() ([]*shared.ToolThreshold, *errors.HTTPError) {
+	r, err := s.tool.Query(sqlListToolThresholds)
+	if err != nil {
+		return nil, errors.NewHTTPError(err)
+	}
+	defer r.Close()
+
+	var thresholds []*shared.ToolThreshold
+	for r.Next() {
+		tt, herr := ScanToolThreshold(r)
+		if herr != nil {
+			return nil, herr
+		}
+		thresholds = append(thresholds, tt)
+	}
+
+	return thresholds, nil
+}
+
+// DeleteToolThreshold removes a tool threshold, the tools fall back to the next
+// matching threshold
+func (s *Store) DeleteToolThreshold(id shared.EntityID) *errors.HTTPError {
+	_, err := s.tool.Exec(sqlDeleteToolThreshold, sql.Named("id", id))
+	if err != nil {
+		return errors.NewHTTPError(err)
+	}
+	return nil
+}
+
+// checkToolThreshold validates the threshold and checks for another threshold
+// with the same type, format and position
+func (s *Store) checkToolThreshold(tt *shared.ToolThreshold) *errors.HTTPError {
+	if verr := tt.Validate(); verr != nil {
+		return verr.HTTPError().Wrap("invalid tool threshold data")
+	}
+
+	thresholds, herr := s.ListToolThresholds()
+	if herr != nil {
+		return herr
+	}
+	for _, t := range thresholds {
+		if t.ID != tt.ID && strings.EqualFold(t.Type, tt.Type) &&
+			t.Width == tt.Width && t.Height == tt.Height && t.Position == tt.Position {
+			return errors.NewExistsError("tool threshold", tt.German()).HTTPError()
+		}
+	}
+
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+// Scan Helpers
+// -----------------------------------------------------------------------------
+
+// ScanToolThreshold scans a database row into a ToolThreshold struct
+func ScanToolThreshold(row Scannable) (*shared.ToolThreshold, *errors.HTTPError) {
+	var tt shared.ToolThreshold
+	err := row.Scan(
+		&tt.ID,
+		&tt.Type,
+		&tt.Width,
+		&tt.Height,
+		&tt.Position,
+		&tt.Warning,
+		&tt.Error,
+	)
+	if err != nil {
+		return nil, errors.NewHTTPError(err)
+	}
+	return &tt, nil
+}
@@ -34,12 +34,16 @@ CREATE TABLE IF NOT EXISTS tools (
 );`
 
 	sqlAddTool string = `
-INSERT INTO tools (width, height, position, type, code, cycles_offset, is_dead, cassette, min_thickness, max_thickness)
-VALUES (:width, :height, :position, :type, :code, :cycles_offset, :is_dead, :cassette, :min_thickness, :max_thickness);`
+INSERT INTO tools (width, height, position, type, code, cycles_offset, is_dead, cassette, min_thickness, max_thickness,
+	cycles_warning, cycles_error)
+VALUES (:width, :height, :position, :type, :code, :cycles_offset, :is_dead, :cassette, :min_thickness, :max_thickness,
+	:cycles_warning, :cycles_error);`
 
 	sqlAddToolWithID string = `
-INSERT INTO tools (id, width, height, position, type, code, cycles_offset, is_dead, cassette, min_thickness, max_thickness)
-VALUES (:id, :width, :height, :position, :type, :code, :cycles_offset, :is_dead, :cassette, :min_thickness, :max_thickness);`
+INSERT INTO tools (id, width, height, position, type, code, cycles_offset, is_dead, cassette, min_thickness, max_thickness,
+	cycles_warning, cycles_error)
+VALUES (:id, :width, :height, :position, :type, :code, :cycles_offset, :is_dead, :cassette, :min_thickness, :max_thickness,
+	:cycles_warning, :cycles_error);`
 
 	sqlUpdateTool string = `
 UPDATE tools
@@ -52,22 +56,32 @@ SET width = :width,
 	is_dead = :is_dead,
 	cassette = :cassette,
 	min_thickness = :min_thickness,
-	max_thickness = :max_thickness
+	max_thickness = :max_thickness,
+	cycles_warning = :cycles_warning,
+	cycles_error = :cycles_error
 WHERE id = :id;`
 
 	sqlGetTool string = `
-SELECT id, width, height, position, type, code, cycles_offset, is_dead, cassette, min_thickness, max_thickness
+SELECT id, width, height, position, type, code, cycles_offset, is_dead, cassette, min_thickness, max_thickness,
+	cycles_warning, cycles_error
 FROM tools
-WHERE id = :id;`
+WHERE id = :id AND deleted_at = 0;`
 
 	sqlListTools string = `
-SELECT id, width, height, position, type, code, cycles_offset, is_dead, cassette, min_thickness, max_thickness
+SELECT id, width, height, position, type, code, cycles_offset, is_dead, cassette, min_thickness, max_thickness,
+	cycles_warning, cycles_error
 FROM tools
+WHERE deleted_at = 0
 ORDER BY id ASC;`
 
+	sqlListToolPositions string = `
+SELECT id, position
+FROM tools;`
+
 	sqlDeleteTool string = `
-DELETE FROM tools
-WHERE id = :id;`
+UPDATE tools
+SET deleted_at = :deleted_at, deleted_by = :deleted_by, deleted_with = :deleted_with
+WHERE id = :id AND deleted_at = 0;`
 
 	sqlMarkToolAsDead string = `
 UPDATE tools
@@ -95,10 +109,13 @@ WHERE id = :id;`
 // -----------------------------------------------------------------------------
 
 // AddTool adds a new tool to the database
-func AddTool(tool *shared.Tool) *errors.HTTPError {
+func (s *Store) AddTool(tool *shared.Tool) *errors.HTTPError {
 	if verr := tool.Validate(); verr != nil {
 		return verr.HTTPError().Wrap("invalid tool data")
 	}
+	if herr := s.checkToolCycleThresholds(tool); herr != nil {
+		return herr
+	}
 
 	var query string
 	if tool.ID > 0 {
@@ -122,21 +139,51 @@ func AddTool(tool *shared.Tool) *errors.HTTPError {
 		sql.Named("cassette", tool.Cassette),
 		sql.Named("min_thickness", tool.MinThickness),
 		sql.Named("max_thickness", tool.MaxThickness),
+		sql.Named("cycles_warning", tool.CyclesWarning),
+		sql.Named("cycles_error", tool.CyclesError),
 	)
 
-	if _, err := dbTool.Exec(query, queryArgs...); err != nil {
+	r, err := s.tool.Exec(query, queryArgs...)
+	if err != nil {
+		return errors.NewHTTPError(err)
+	}
+	if tool.ID, err = lastInsertID(r); err != nil {
 		return errors.NewHTTPError(err)
 	}
 	return nil
 }
 
 // UpdateTool updates an existing tool in the database
-func UpdateTool(tool *shared.Tool) *errors.HTTPError {
+func (s *Store) UpdateTool(tool *shared.Tool) *errors.HTTPError {
+	if herr := s.checkToolCycleThresholds(tool); herr != nil {
+		return herr
+	}
+	return updateTool(s.tool, tool)
+}
+
+// checkToolCycleThresholds checks the threshold overrides of the tool against
+// the thresholds it inherits, see shared.ValidateCycleThresholds
+func (s *Store) checkToolCycleThresholds(tool *shared.Tool) *errors.HTTPError {
+	if tool.CyclesWarning == 0 && tool.CyclesError == 0 {
+		return nil
+	}
+
+	thresholds, herr := s.ListToolThresholds()
+	if herr != nil {
+		return herr
+	}
+	if verr := shared.ValidateCycleThresholds(tool, thresholds); verr != nil {
+		return verr.HTTPError().Wrap("invalid tool data")
+	}
+	return nil
+}
+
+func updateTool(e executor, tool *shared.Tool) *errors.HTTPError {
 	if verr := tool.Validate(); verr != nil {
 		return verr.HTTPError().Wrap("invalid tool data")
 	}
 
-	_, err := dbTool.Exec(sqlUpdateTool,
+	_, err := e.Exec(sqlUpdateTool,
 		sql.Named("id", tool.ID),
 		sql.Named("width", tool.Width),
 		sql.Named("height", tool.Height),
@@ -148,6 +195,8 @@ func UpdateTool(tool *shared.Tool) *errors.HTTPError {
 		sql.Named("cassette", tool.Cassette),
 		sql.Named("min_thickness", tool.MinThickness),
 		sql.Named("max_thickness", tool.MaxThickness),
+		sql.Named("cycles_warning", tool.CyclesWarning),
+		sql.Named("cycles_error", tool.CyclesError),
 	)
 	if err != nil {
 		return errors.NewHTTPError(err)
@@ -156,23 +205,29 @@ func UpdateTool(tool *shared.Tool) *errors.HTTPError {
 }
 
 // GetTool retrieves a tool by its ID
-func GetTool(id shared.EntityID) (*shared.Tool, *errors.HTTPError) {
-	tool, merr := ScanTool(dbTool.QueryRow(sqlGetTool, id))
+func (s *Store) GetTool(id shared.EntityID) (*shared.Tool, *errors.HTTPError) {
+	tool, merr := ScanTool(s.tool.QueryRow(sqlGetTool, id))
 	if merr != nil {
 		return tool, merr
 	}
 
-	merr = InjectCyclesIntoTool(tool)
+	merr = s.InjectCyclesIntoTool(tool)
+	if merr != nil {
+		return nil, merr
+	}
+
+	thresholds, merr := s.ListToolThresholds()
 	if merr != nil {
 		return nil, merr
 	}
+	tool.Thresholds = shared.ResolveCycleThresholds(tool, thresholds)
 
 	return tool, nil
 }
 
 // ListTools retrieves all tools from the database
-func ListTools() (tools []*shared.Tool, merr *errors.HTTPError) {
-	r, err := dbTool.Query(sqlListTools)
+func (s *Store) ListTools() (tools []*shared.Tool, merr *errors.HTTPError) {
+	r, err := s.tool.Query(sqlListTools)
 	if err != nil {
 		return nil, errors.NewHTTPError(err)
 	}
@@ -187,21 +242,29 @@ func ListTools() (tools []*shared.Tool, merr *errors.HTTPError) {
 	}
 	r.Close()
 
+	thresholds, merr := s.ListToolThresholds()
+	if merr != nil {
+		return nil, merr
+	}
+
+	// Share the injector, all tools need the same positions and press cycles
+	ci := s.newCycleInjector()
 	for _, tool := range tools {
-		merr = InjectCyclesIntoTool(tool)
+		merr = s.injectCyclesIntoTool(tool, ci)
 		if merr != nil {
 			return nil, merr
 		}
+		tool.Thresholds = shared.ResolveCycleThresholds(tool, thresholds)
 	}
 
 	return tools, nil
 }
 
 // ListBindableCassettes retrieves cassettes that can be bound to a given tool
-func ListBindableCassettes(id shared.EntityID) (
+func (s *Store) ListBindableCassettes(id shared.EntityID) (
 	cassettes []*shared.Tool, merr *errors.HTTPError,
 ) {
-	tool, merr := GetTool(id)
+	tool, merr := s.GetTool(id)
 	if merr != nil {
 		return nil, merr
 	}
@@ -210,7 +273,7 @@ func ListBindableCassettes(id shared.EntityID) (
 	}
 
 	{ // Find compatible cassettes
-		tools, merr := ListTools()
+		tools, merr := s.ListTools()
 		if merr != nil {
 			return nil, merr
 		}
@@ -239,18 +302,18 @@ func ListBindableCassettes(id shared.EntityID) (
 	return cassettes, nil
 }
 
-// DeleteTool removes a tool from the database
-func DeleteTool(id shared.EntityID) *errors.HTTPError {
-	_, err := dbTool.Exec(sqlDeleteTool, sql.Named("id", id))
-	if err != nil {
-		return errors.NewHTTPError(err)
-	}
-	return nil
+// DeleteTool moves a tool to the trash
+//
+// Metal sheets, cycles and notes of the tool are moved to the trash too, presses
+// and cassettes holding the tool get their slot reset. Regenerations are kept
+// until the tool gets purged.
+func (s *Store) DeleteTool(id shared.EntityID, deletedBy shared.TelegramID) *errors.HTTPError {
+	return s.softDeleteWithCascade("tool", "tools", sqlDeleteTool, int64(id), deletedBy)
 }
 
 // MarkToolAsDead marks a tool as dead (destroyed)
-func MarkToolAsDead(id shared.EntityID) *errors.HTTPError {
-	_, err := dbTool.Exec(sqlMarkToolAsDead, sql.Named("id", id))
+func (s *Store) MarkToolAsDead(id shared.EntityID) *errors.HTTPError {
+	_, err := s.tool.Exec(sqlMarkToolAsDead, sql.Named("id", id))
 	if err != nil {
 		return errors.NewHTTPError(err)
 	}
@@ -258,8 +321,8 @@ func MarkToolAsDead(id shared.EntityID) *errors.HTTPError {
 }
 
 // ReviveTool revives a dead tool
-func ReviveTool(id shared.EntityID) *errors.HTTPError {
-	_, err := dbTool.Exec(sqlReviveTool, sql.Named("id", id))
+func (s *Store) ReviveTool(id shared.EntityID) *errors.HTTPError {
+	_, err := s.tool.Exec(sqlReviveTool, sql.Named("id", id))
 	if err != nil {
 		return errors.NewHTTPError(err)
 	}
@@ -267,8 +330,8 @@ func ReviveTool(id shared.EntityID) *errors.HTTPError {
 }
 
 // BindTool binds a cassette to a tool
-func BindTool(sourceID, targetID shared.EntityID) *errors.HTTPError {
-	res, err := dbTool.Exec(sqlBindTool,
+func (s *Store) BindTool(sourceID, targetID shared.EntityID) *errors.HTTPError {
+	res, err := s.tool.Exec(sqlBindTool,
 		sql.Named("source_id", sourceID),
 		sql.Named("target_id", targetID),
 	)
@@ -288,8 +351,8 @@ func BindTool(sourceID, targetID shared.EntityID) *errors.HTTPError {
 }
 
 // UnbindTool unbinds a cassette from a tool
-func UnbindTool(sourceID shared.EntityID) *errors.HTTPError {
-	_, err := dbTool.Exec(sqlUnbindTool,
+func (s *Store) UnbindTool(sourceID shared.EntityID) *errors.HTTPError {
+	_, err := s.tool.Exec(sqlUnbindTool,
 		sql.Named("id", sourceID),
 	)
 	if err != nil {
@@ -299,8 +362,12 @@ func UnbindTool(sourceID shared.EntityID) *errors.HTTPError {
 }
 
 // InjectCyclesIntoTool injects cycle count info into a tool
-func InjectCyclesIntoTool(tool *shared.Tool) *errors.HTTPError {
-	cycles, merr := GetTotalToolCycles(tool.ID)
+func (s *Store) InjectCyclesIntoTool(tool *shared.Tool) *errors.HTTPError {
+	return s.injectCyclesIntoTool(tool, nil)
+}
+
+func (s *Store) injectCyclesIntoTool(tool *shared.Tool, ci *cycleInjector) *errors.HTTPError {
+	cycles, merr := s.totalToolCycles(tool.ID, ci)
 	if merr != nil {
 		return merr.Wrap("could not get total cycles for tool ID %d", tool.ID)
 	}
@@ -308,6 +375,49 @@ func InjectCyclesIntoTool(tool *shared.Tool) *errors.HTTPError {
 	return nil
 }
 
+// listToolsWithoutCycles returns all tools mapped by ID, without injecting the cycles
+func (s *Store) listToolsWithoutCycles() (map[shared.EntityID]*shared.Tool, *errors.HTTPError) {
+	r, err := s.tool.Query(sqlListTools)
+	if err != nil {
+		return nil, errors.NewHTTPError(err)
+	}
+	defer r.Close()
+
+	tools := make(map[shared.EntityID]*shared.Tool)
+	for r.Next() {
+		tool, herr := ScanTool(r)
+		if herr != nil {
+			return nil, herr
+		}
+		tools[tool.ID] = tool
+	}
+
+	return tools, nil
+}
+
+// listToolPositions returns the position of all tools, mapped by tool ID
+func (s *Store) listToolPositions() (map[shared.EntityID]shared.Slot, *errors.HTTPError) {
+	r, err := s.tool.Query(sqlListToolPositions)
+	if err != nil {
+		return nil, errors.NewHTTPError(err)
+	}
+	defer r.Close()
+
+	positions := make(map[shared.EntityID]shared.Slot)
+	for r.Next() {
+		var (
+			id       shared.EntityID
+			position shared.Slot
+		)
+		if err = r.Scan(&id, &position); err != nil {
+			return nil, errors.NewHTTPError(err)
+		}
+		positions[id] = position
+	}
+
+	return positions, nil
+}
+
 // -----------------------------------------------------------------------------
 // Scan Helpers
 // -----------------------------------------------------------------------------
@@ -327,6 +437,8 @@ func ScanTool(row Scannable) (*shared.Tool, *errors.HTTPError) {
 		&t.Cassette,
 		&t.MinThickness,
 		&t.MaxThickness,
+		&t.CyclesWarning,
+		&t.CyclesError,
 	)
 	if err != nil {
 		return nil, errors.NewHTTPError(err)
@@ -0,0 +1,656 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/shared"
+)
+
+// trashTables maps all soft deletable tables to their database.
+//
+// NOTE: The trash queries are built from these, never pass any user input into
+// table names.
+var trashTables = map[shared.TrashKind]string{
+	shared.TrashKindTool:          "tool",
+	shared.TrashKindMetalSheet:    "tool",
+	shared.TrashKindPress:         "press",
+	shared.TrashKindCycle:         "press",
+	shared.TrashKindNote:          "note",
+	shared.TrashKindTroubleReport: "reports",
+}
+
+// deletion is the mark set on soft deleted rows
+type deletion struct {
+	at   shared.UnixMilli
+	by   shared.TelegramID
+	with string // The row deleted by the user, see deletedWith
+}
+
+func isSoftDeletable(table string) bool {
+	_, ok := trashTables[shared.TrashKind(table)]
+	return ok
+}
+
+// deletedWith returns the deleted_with value for a row deleted by the user, the
+// rows deleted together with it get the same value, e.g. "tools_5".
+//
+// An empty deleted_with belongs to rows deleted before it was recorded, these
+// are matched on the deleted_at timestamp instead.
+func deletedWith(table string, id int64) string {
+	return fmt.Sprintf("%s_%d", table, id)
+}
+
+// -----------------------------------------------------------------------------
+// Trash Queries
+// -----------------------------------------------------------------------------
+
+const (
+	// sqlCreateClearedReferencesTable records the references cleared by a soft
+	// delete, like the press slots and cassette bindings of a tool, so the restore
+	// can put them back. The referencing rows can be in any database.
+	sqlCreateClearedReferencesTable string = `
+CREATE TABLE IF NOT EXISTS cleared_references (
+	deleted_with TEXT NOT NULL,
+	table_name TEXT NOT NULL,
+	column_name TEXT NOT NULL,
+	row_id INTEGER NOT NULL,
+	PRIMARY KEY("deleted_with", "table_name", "column_name", "row_id")
+);`
+
+	sqlListClearedReferences string = `
+SELECT row_id FROM cleared_references
+WHERE deleted_with = :deleted_with AND table_name = :table AND column_name = :column;`
+
+	sqlDeleteClearedReferences string = `
+DELETE FROM cleared_references WHERE deleted_with = :deleted_with;`
+
+	sqlListDeletedTools string = `
+SELECT id, width, height, position, type, code, cycles_offset, is_dead, cassette, min_thickness, max_thickness,
+	cycles_warning, cycles_error, deleted_at, deleted_by, deleted_with
+FROM tools
+WHERE deleted_at > 0;`
+
+	sqlListDeletedMetalSheets string = `
+SELECT id, tool_id, tile_height, value, type, deleted_at, deleted_by, deleted_with
+FROM metal_sheets
+WHERE deleted_at > 0;`
+
+	sqlListDeletedPresses string = `
+SELECT id, number, type, code, slot_up, slot_down, cycles_offset, deleted_at, deleted_by, deleted_with
+FROM presses
+WHERE deleted_at > 0;`
+
+	sqlListDeletedCycles string = `
+SELECT id, tool_id, press_id, cycles, stop, performed_by, deleted_at, deleted_by, deleted_with
+FROM cycles
+WHERE deleted_at > 0;`
+
+	sqlListDeletedNotes string = `
+SELECT id, level, content, created_at, linked, deleted_at, deleted_by, deleted_with
+FROM notes
+WHERE deleted_at > 0;`
+
+	sqlListDeletedTroubleReports string = `
+SELECT id, title, content, linked_attachments, use_markdown, deleted_at, deleted_by, deleted_with
+FROM trouble_reports
+WHERE deleted_at > 0;`
+)
+
+// -----------------------------------------------------------------------------
+// Trash Functions
+// -----------------------------------------------------------------------------
+
+// ListTrash returns all soft deleted entities, newest first.
+//
+// Entities deleted together with their tool or press are not listed on their own,
+// they are restored with the parent. Entities deleted on their own are listed,
+// even if their tool or press got deleted later.
+func (s *Store) ListTrash() ([]*shared.TrashItem, *errors.HTTPError) {
+	tools, herr := s.listToolsWithoutCycles()
+	if herr != nil {
+		return nil, herr
+	}
+
+	presses := make(map[shared.EntityID]*shared.Press)
+	{
+		list, herr := s.ListPress()
+		if herr != nil {
+			return nil, herr
+		}
+		for _, p := range list {
+			presses[p.ID] = p
+		}
+	}
+
+	// The titles need the tools and presses in the trash too, filled while
+	// scanning them below
+	var (
+		deletedTools   = make(map[shared.EntityID]*shared.Tool)
+		deletedPresses = make(map[shared.EntityID]*shared.Press)
+	)
+
+	// Rows deleted before deleted_with was recorded are hidden if their tool or
+	// press is in the trash, they were most likely deleted together with it
+	lookupTool := func(row *deletedRow, id shared.EntityID) (*shared.Tool, bool) {
+		if tool, ok := tools[id]; ok {
+			return tool, true
+		}
+		if row.deletedWith == "" {
+			return nil, false
+		}
+		tool, ok := deletedTools[id]
+		return tool, ok
+	}
+	lookupPress := func(row *deletedRow, id shared.EntityID) (*shared.Press, bool) {
+		if press, ok := presses[id]; ok {
+			return press, true
+		}
+		if row.deletedWith == "" {
+			return nil, false
+		}
+		press, ok := deletedPresses[id]
+		return press, ok
+	}
+
+	var items []*shared.TrashItem
+
+	// Tools
+	herr = s.scanTrash(s.tool, sqlListDeletedTools, func(row *deletedRow) (*shared.TrashItem, *errors.HTTPError) {
+		tool, herr := ScanTool(row)
+		if herr != nil {
+			return nil, herr
+		}
+		deletedTools[tool.ID] = tool
+		return &shared.TrashItem{Kind: shared.TrashKindTool, ID: tool.ID, Title: tool.German()}, nil
+	}, &items)
+	if herr != nil {
+		return nil, herr
+	}
+
+	// Metal sheets
+	herr = s.scanTrash(s.tool, sqlListDeletedMetalSheets, func(row *deletedRow) (*shared.TrashItem, *errors.HTTPError) {
+		var (
+			sheet     shared.BaseMetalSheet
+			sheetType string
+		)
+		if err := row.Scan(&sheet.ID, &sheet.ToolID, &sheet.TileHeight, &sheet.Value, &sheetType); err != nil {
+			return nil, errors.NewHTTPError(err)
+		}
+
+		tool, ok := lookupTool(row, sheet.ToolID)
+		if !ok {
+			return nil, nil
+		}
+
+		slot := shared.SlotLower
+		if sheetType == "upper" {
+			slot = shared.SlotUpper
+		}
+		return &shared.TrashItem{
+			Kind: shared.TrashKindMetalSheet,
+			ID:   sheet.ID,
+			Title: fmt.Sprintf("Blech %s %.1f mm / %.1f mm, %s",
+				slot.German(), sheet.TileHeight, sheet.Value, tool.German()),
+		}, nil
+	}, &items)
+	if herr != nil {
+		return nil, herr
+	}
+
+	// Presses
+	herr = s.scanTrash(s.press, sqlListDeletedPresses, func(row *deletedRow) (*shared.TrashItem, *errors.HTTPError) {
+		press, herr := ScanPress(row)
+		if herr != nil {
+			return nil, herr
+		}
+		deletedPresses[press.ID] = press
+		return &shared.TrashItem{Kind: shared.TrashKindPress, ID: press.ID, Title: press.German()}, nil
+	}, &items)
+	if herr != nil {
+		return nil, herr
+	}
+
+	// Cycles
+	herr = s.scanTrash(s.press, sqlListDeletedCycles, func(row *deletedRow) (*shared.TrashItem, *errors.HTTPError) {
+		cycle, herr := ScanCycle(row)
+		if herr != nil {
+			return nil, herr
+		}
+
+		tool, ok := lookupTool(row, cycle.ToolID)
+		if !ok {
+			return nil, nil
+		}
+		press, ok := lookupPress(row, cycle.PressID)
+		if !ok {
+			return nil, nil
+		}
+
+		return &shared.TrashItem{
+			Kind: shared.TrashKindCycle,
+			ID:   cycle.ID,
+			Title: fmt.Sprintf("%d Zyklen am %s, %s, Presse %s",
+				cycle.PressCycles, cycle.Stop.FormatDate(), tool.German(), press.Number.String()),
+		}, nil
+	}, &items)
+	if herr != nil {
+		return nil, herr
+	}
+
+	// Notes
+	herr = s.scanTrash(s.note, sqlListDeletedNotes, func(row *deletedRow) (*shared.TrashItem, *errors.HTTPError) {
+		note, herr := ScanNote(row)
+		if herr != nil {
+			return nil, herr
+		}
+
+		if id, ok := linkedID(note.Linked, "tool_"); ok {
+			if _, ok = lookupTool(row, id); !ok {
+				return nil, nil
+			}
+		}
+		if id, ok := linkedID(note.Linked, "press_"); ok {
+			if _, ok = lookupPress(row, id); !ok {
+				return nil, nil
+			}
+		}
+
+		title := note.Content
+		if r := []rune(title); len(r) > 80 {
+			title = string(r[:80]) + "…"
+		}
+		return &shared.TrashItem{Kind: shared.TrashKindNote, ID: note.ID, Title: title}, nil
+	}, &items)
+	if herr != nil {
+		return nil, herr
+	}
+
+	// Trouble reports
+	herr = s.scanTrash(s.reports, sqlListDeletedTroubleReports, func(row *deletedRow) (*shared.TrashItem, *errors.HTTPError) {
+		report, herr := ScanTroubleReport(row)
+		if herr != nil {
+			return nil, herr
+		}
+		return &shared.TrashItem{Kind: shared.TrashKindTroubleReport, ID: report.ID, Title: report.German()}, nil
+	}, &items)
+	if herr != nil {
+		return nil, herr
+	}
+
+	slices.SortStableFunc(items, func(a, b *shared.TrashItem) int {
+		return int(b.DeletedAt - a.DeletedAt)
+	})
+
+	return items, nil
+}
+
+// RestoreTrashItem restores a soft deleted entity.
+//
+// Everything deleted together with the entity gets restored too. The press
+// slots and cassette bindings cleared by the delete are put back. Entities whose
+// tool or press is still in the trash can not be restored on their own.
+//
+// Parameters:
+//   - kind: The type of the entity
+//   - id: The ID of the entity
+//
+// Returns:
+//   - *errors.HTTPError: Not found if the entity is not in the trash, a
+//     validation error if its tool or press is still in the trash, or if a
+//     slot or binding is in use by another entity now
+func (s *Store) RestoreTrashItem(kind shared.TrashKind, id shared.EntityID) *errors.HTTPError {
+	database, ok := trashTables[kind]
+	if !ok {
+		return errors.NewValidationError("invalid trash kind: %s", kind).HTTPError()
+	}
+	table := string(kind)
+	origin := deletedWith(table, int64(id))
+
+	return s.Transaction(func(tx *Tx) *errors.HTTPError {
+		e, herr := tx.get(database)
+		if herr != nil {
+			return herr
+		}
+
+		var (
+			deletedAt shared.UnixMilli
+			with      string
+		)
+		err := e.QueryRow(fmt.Sprintf(`SELECT deleted_at, deleted_with FROM %s WHERE id = :id AND deleted_at > 0;`, table),
+			sql.Named("id", id)).Scan(&deletedAt, &with)
+		if err != nil {
+			return errors.NewHTTPError(err)
+		}
+		if with != "" && with != origin {
+			return errors.NewValidationError(
+				"%s ID %d was deleted together with %s, restore that instead", table, id, with,
+			).HTTPError()
+		}
+
+		for _, ref := range references {
+			if ref.Table != table {
+				continue
+			}
+
+			var (
+				// The tool or press of the entity must not be in the trash
+				checkParent = ref.Cascade == FixDelete && isSoftDeletable(ref.TargetTable)
+				// A slot or cassette of the entity must not be in use by another row
+				checkInUse = ref.Cascade == FixNullify
+			)
+			if !checkParent && !checkInUse {
+				continue
+			}
+
+			var value string
+			err = e.QueryRow(fmt.Sprintf(`SELECT %s FROM %s WHERE id = :id;`, ref.Column, table),
+				sql.Named("id", id)).Scan(&value)
+			if err != nil {
+				return errors.NewHTTPError(err)
+			}
+
+			parentID, ok := linkedID(value, ref.Prefix)
+			if !ok {
+				continue
+			}
+
+			if checkInUse {
+				var otherID shared.EntityID
+				err = e.QueryRow(fmt.Sprintf(`SELECT id FROM %s WHERE %s = :value AND id != :id AND deleted_at = 0 LIMIT 1;`, table, ref.Column),
+					sql.Named("value", value), sql.Named("id", id)).Scan(&otherID)
+				if err == sql.ErrNoRows {
+					continue
+				}
+				if err != nil {
+					return errors.NewHTTPError(err)
+				}
+				return errors.NewValidationError(
+					"%s ID %d is used by %s ID %d now, clear its %s first",
+					ref.TargetTable, parentID, table, otherID, ref.Column,
+				).HTTPError()
+			}
+
+			te, herr := tx.get(ref.TargetDatabase)
+			if herr != nil {
+				return herr
+			}
+
+			var parentDeletedAt shared.UnixMilli
+			err = te.QueryRow(fmt.Sprintf(`SELECT deleted_at FROM %s WHERE id = :id;`, ref.TargetTable),
+				sql.Named("id", parentID)).Scan(&parentDeletedAt)
+			if err == sql.ErrNoRows {
+				continue // Dangling reference, see Check
+			}
+			if err != nil {
+				return errors.NewHTTPError(err)
+			}
+			if parentDeletedAt > 0 {
+				return errors.NewValidationError(
+					"%s ID %d is in the trash, restore it first", ref.TargetTable, parentID,
+				).HTTPError()
+			}
+		}
+
+		_, err = e.Exec(fmt.Sprintf(`UPDATE %s SET deleted_at = 0, deleted_by = 0, deleted_with = '' WHERE id = :id;`, table),
+			sql.Named("id", id))
+		if err != nil {
+			return errors.NewHTTPError(err)
+		}
+
+		// Restore everything deleted together with the entity
+		for _, ref := range references {
+			if ref.TargetTable != table || ref.Cascade != FixDelete || !isSoftDeletable(ref.Table) {
+				continue
+			}
+
+			re, herr := tx.get(ref.Database)
+			if herr != nil {
+				return herr
+			}
+
+			_, err = re.Exec(fmt.Sprintf(`UPDATE %s SET deleted_at = 0, deleted_by = 0, deleted_with = '' WHERE %s = :value AND (deleted_with = :origin OR (deleted_with = '' AND deleted_at = :deleted_at));`, ref.Table, ref.Column),
+				sql.Named("value", ref.value(int64(id))), sql.Named("origin", origin), sql.Named("deleted_at", deletedAt))
+			if err != nil {
+				return errors.NewHTTPError(fmt.Errorf("failed to restore %s for %s ID %d: %v", ref.Table, table, id, err))
+			}
+		}
+
+		// Put back the slots and bindings cleared by the delete
+		for _, ref := range references {
+			if ref.TargetTable != table || ref.Cascade != FixNullify {
+				continue
+			}
+			if herr := tx.restoreClearedReferences(ref, origin, int64(id)); herr != nil {
+				return herr
+			}
+		}
+
+		te, herr := tx.get("tool")
+		if herr != nil {
+			return herr
+		}
+		if _, err = te.Exec(sqlDeleteClearedReferences, sql.Named("deleted_with", origin)); err != nil {
+			return errors.NewHTTPError(err)
+		}
+
+		return nil
+	})
+}
+
+// restoreClearedReferences sets the column of ref back to id for all rows recorded
+// for origin. Rows removed in the meantime are skipped.
+//
+// Returns:
+//   - *errors.HTTPError: A validation error if a row references another entity now
+func (tx *Tx) restoreClearedReferences(ref *Reference, origin string, id int64) *errors.HTTPError {
+	te, herr := tx.get("tool")
+	if herr != nil {
+		return herr
+	}
+
+	r, err := te.Query(sqlListClearedReferences,
+		sql.Named("deleted_with", origin), sql.Named("table", ref.Table), sql.Named("column", ref.Column))
+	if err != nil {
+		return errors.NewHTTPError(err)
+	}
+	var rowIDs []int64
+	for r.Next() {
+		var rowID int64
+		if err = r.Scan(&rowID); err != nil {
+			r.Close()
+			return errors.NewHTTPError(err)
+		}
+		rowIDs = append(rowIDs, rowID)
+	}
+	r.Close()
+
+	e, herr := tx.get(ref.Database)
+	if herr != nil {
+		return herr
+	}
+
+	for _, rowID := range rowIDs {
+		var value string
+		err = e.QueryRow(fmt.Sprintf(`SELECT %s FROM %s WHERE id = :id;`, ref.Column, ref.Table),
+			sql.Named("id", rowID)).Scan(&value)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return errors.NewHTTPError(err)
+		}
+
+		if otherID, ok := linkedID(value, ref.Prefix); ok && int64(otherID) != id {
+			return errors.NewValidationError(
+				"%s of %s ID %d is set to %s ID %d now, clear it first",
+				ref.Column, ref.Table, rowID, ref.TargetTable, otherID,
+			).HTTPError()
+		}
+
+		_, err = e.Exec(fmt.Sprintf(`UPDATE %s SET %s = :value WHERE id = :id;`, ref.Table, ref.Column),
+			sql.Named("value", ref.value(id)), sql.Named("id", rowID))
+		if err != nil {
+			return errors.NewHTTPError(fmt.Errorf("failed to restore %s: %v", ref, err))
+		}
+	}
+
+	return nil
+}
+
+// PurgeTrash removes all entities deleted before a given time for good.
+//
+// Parameters:
+//   - before: Entities deleted before this time get removed
+//
+// Returns:
+//   - int: The number of removed tools, presses and other entities
+//   - *errors.HTTPError: Error if any removal fails
+func (s *Store) PurgeTrash(before shared.UnixMilli) (int, *errors.HTTPError) {
+	var n int
+
+	// Tools and presses first, they remove everything else referencing them
+	for _, kind := range []shared.TrashKind{shared.TrashKindTool, shared.TrashKindPress} {
+		database := trashTables[kind]
+		db, err := s.database(database)
+		if err != nil {
+			return n, errors.NewHTTPError(err)
+		}
+
+		ids, herr := listDeletedIDs(db, string(kind), before)
+		if herr != nil {
+			return n, herr
+		}
+
+		query := fmt.Sprintf(`DELETE FROM %s WHERE id = :id;`, kind)
+		for _, id := range ids {
+			if herr = s.deleteWithCascade(database, string(kind), query, id); herr != nil {
+				return n, herr.Wrap("failed to purge %s ID %d", kind, id)
+			}
+			_, err = s.tool.Exec(sqlDeleteClearedReferences, sql.Named("deleted_with", deletedWith(string(kind), id)))
+			if err != nil {
+				return n, errors.NewHTTPError(err).Wrap("failed to purge cleared references of %s ID %d", kind, id)
+			}
+			n++
+		}
+	}
+
+	for _, kind := range []shared.TrashKind{
+		shared.TrashKindMetalSheet,
+		shared.TrashKindCycle,
+		shared.TrashKindNote,
+		shared.TrashKindTroubleReport,
+	} {
+		c, err := s.conn(trashTables[kind])
+		if err != nil {
+			return n, errors.NewHTTPError(err)
+		}
+
+		r, err := c.Exec(fmt.Sprintf(`DELETE FROM %s WHERE deleted_at > 0 AND deleted_at < :before;`, kind),
+			sql.Named("before", before))
+		if err != nil {
+			return n, errors.NewHTTPError(err).Wrap("failed to purge %s", kind)
+		}
+
+		count, err := r.RowsAffected()
+		if err != nil {
+			return n, errors.NewHTTPError(err)
+		}
+		n += int(count)
+	}
+
+	return n, nil
+}
+
+// -----------------------------------------------------------------------------
+// Helpers
+// -----------------------------------------------------------------------------
+
+// deletedRow scans the deleted_at, deleted_by and deleted_with columns after the
+// columns read by one of the Scan helpers.
+type deletedRow struct {
+	row         Scannable
+	deletedAt   shared.UnixMilli
+	deletedBy   shared.TelegramID
+	deletedWith string
+}
+
+func (r *deletedRow) Scan(dest ...any) error {
+	return r.row.Scan(append(dest, &r.deletedAt, &r.deletedBy, &r.deletedWith)...)
+}
+
+// scanTrash appends the items of a deleted rows query, scan returns nil for rows
+// which should not be listed. Rows deleted together with another row are skipped.
+func (s *Store) scanTrash(
+	e executor, query string,
+	scan func(row *deletedRow) (*shared.TrashItem, *errors.HTTPError),
+	items *[]*shared.TrashItem,
+) *errors.HTTPError {
+	r, err := e.Query(query)
+	if err != nil {
+		return errors.NewHTTPError(err)
+	}
+	defer r.Close()
+
+	for r.Next() {
+		row := &deletedRow{row: r}
+		item, herr := scan(row)
+		if herr != nil {
+			return herr
+		}
+		if item == nil {
+			continue
+		}
+		if row.deletedWith != "" && row.deletedWith != deletedWith(string(item.Kind), int64(item.ID)) {
+			continue
+		}
+
+		item.DeletedAt = row.deletedAt
+		item.DeletedBy = row.deletedBy
+		*items = append(*items, item)
+	}
+
+	if err = r.Err(); err != nil {
+		return errors.NewHTTPError(err)
+	}
+
+	return nil
+}
+
+func listDeletedIDs(db *sql.DB, table string, before shared.UnixMilli) ([]int64, *errors.HTTPError) {
+	r, err := db.Query(fmt.Sprintf(`SELECT id FROM %s WHERE deleted_at > 0 AND deleted_at < :before;`, table),
+		sql.Named("before", before))
+	if err != nil {
+		return nil, errors.NewHTTPError(err)
+	}
+	defer r.Close()
+
+	var ids []int64
+	for r.Next() {
+		var id int64
+		if err = r.Scan(&id); err != nil {
+			return nil, errors.NewHTTPError(err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// linkedID parses a reference value like "tool_5", an empty prefix expects a
+// plain ID.
+func linkedID(value, prefix string) (shared.EntityID, bool) {
+	if value == "" || value == "0" || !strings.HasPrefix(value, prefix) {
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(strings.TrimPrefix(value, prefix), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return shared.EntityID(id), true
+}
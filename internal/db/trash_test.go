@@ -0,0 +1,271 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/knackwurstking/pg-press/internal/shared"
+)
+
+// trashFixture is a press with a mounted upper tool, bound to a cassette, and
+// two cycles of the tool.
+type trashFixture struct {
+	s        *Store
+	tool     *shared.Tool
+	cassette *shared.Tool
+	press    *shared.Press
+	cycles   []*shared.Cycle
+}
+
+func newTrashFixture(t *testing.T) *trashFixture {
+	t.Helper()
+
+	f := &trashFixture{s: newTestStore(t)}
+
+	f.tool = &shared.Tool{Width: 120, Height: 60, Position: shared.SlotUpper, Type: "MASS", Code: "G01"}
+	f.cassette = &shared.Tool{Width: 120, Height: 60, Position: shared.SlotUpperCassette, Type: "MASS", Code: "K01", MaxThickness: 10}
+	for _, tool := range []*shared.Tool{f.tool, f.cassette} {
+		if herr := f.s.AddTool(tool); herr != nil {
+			t.Fatalf("add tool: %v", herr)
+		}
+	}
+	if herr := f.s.BindTool(f.tool.ID, f.cassette.ID); herr != nil {
+		t.Fatalf("bind tool: %v", herr)
+	}
+
+	f.press = &shared.Press{Number: 5, Type: shared.MachineTypeSACMI, SlotUp: f.tool.ID}
+	if herr := f.s.AddPress(f.press); herr != nil {
+		t.Fatalf("add press: %v", herr)
+	}
+
+	for i, stop := range []shared.UnixMilli{100, 200} {
+		cycle := shared.NewCycle(f.tool.ID, f.press.ID, int64(i+1)*1000, stop, 0)
+		if herr := f.s.AddCycle(cycle); herr != nil {
+			t.Fatalf("add cycle: %v", herr)
+		}
+		f.cycles = append(f.cycles, cycle)
+	}
+
+	return f
+}
+
+// listed returns the trash items by kind and ID
+func (f *trashFixture) listed(t *testing.T) map[shared.TrashKind]map[shared.EntityID]bool {
+	t.Helper()
+
+	items, herr := f.s.ListTrash()
+	if herr != nil {
+		t.Fatalf("list trash: %v", herr)
+	}
+
+	listed := make(map[shared.TrashKind]map[shared.EntityID]bool)
+	for _, item := range items {
+		if listed[item.Kind] == nil {
+			listed[item.Kind] = make(map[shared.EntityID]bool)
+		}
+		listed[item.Kind][item.ID] = true
+	}
+	return listed
+}
+
+// cycleDeleted reports whether a cycle is in the trash
+func (f *trashFixture) cycleDeleted(t *testing.T, cycle *shared.Cycle) bool {
+	t.Helper()
+
+	_, herr := f.s.GetCycle(cycle.ID)
+	if herr != nil && !herr.IsNotFoundError() {
+		t.Fatalf("get cycle: %v", herr)
+	}
+	return herr != nil
+}
+
+func TestRestoreToolKeepsCyclesDeletedOnTheirOwn(t *testing.T) {
+	f := newTrashFixture(t)
+
+	if herr := f.s.DeleteCycle(f.cycles[0].ID, 0); herr != nil {
+		t.Fatalf("delete cycle: %v", herr)
+	}
+	if herr := f.s.DeleteTool(f.tool.ID, 0); herr != nil {
+		t.Fatalf("delete tool: %v", herr)
+	}
+
+	listed := f.listed(t)
+	if !listed[shared.TrashKindCycle][f.cycles[0].ID] {
+		t.Error("cycle deleted on its own is not listed")
+	}
+	if listed[shared.TrashKindCycle][f.cycles[1].ID] {
+		t.Error("cycle deleted with the tool is listed")
+	}
+
+	if herr := f.s.RestoreTrashItem(shared.TrashKindCycle, f.cycles[0].ID); herr == nil || !herr.IsValidationError() {
+		t.Errorf("restoring the cycle of a deleted tool: got %v, want a validation error", herr)
+	}
+
+	if herr := f.s.RestoreTrashItem(shared.TrashKindTool, f.tool.ID); herr != nil {
+		t.Fatalf("restore tool: %v", herr)
+	}
+	if !f.cycleDeleted(t, f.cycles[0]) {
+		t.Error("cycle deleted on its own got restored with the tool")
+	}
+	if f.cycleDeleted(t, f.cycles[1]) {
+		t.Error("cycle deleted with the tool is still in the trash")
+	}
+
+	if herr := f.s.RestoreTrashItem(shared.TrashKindCycle, f.cycles[0].ID); herr != nil {
+		t.Fatalf("restore cycle: %v", herr)
+	}
+	if f.cycleDeleted(t, f.cycles[0]) {
+		t.Error("cycle is still in the trash")
+	}
+}
+
+func TestRestoreToolPutsBackSlotAndBinding(t *testing.T) {
+	f := newTrashFixture(t)
+
+	// The cassette first, the tool keeps its binding in the trash
+	if herr := f.s.DeleteTool(f.cassette.ID, 0); herr != nil {
+		t.Fatalf("delete cassette: %v", herr)
+	}
+	if herr := f.s.DeleteTool(f.tool.ID, 0); herr != nil {
+		t.Fatalf("delete tool: %v", herr)
+	}
+
+	press, herr := f.s.GetPress(f.press.ID)
+	if herr != nil {
+		t.Fatalf("get press: %v", herr)
+	}
+	if press.SlotUp != 0 {
+		t.Fatalf("press slot holds the deleted tool %d", press.SlotUp)
+	}
+
+	if herr := f.s.RestoreTrashItem(shared.TrashKindTool, f.tool.ID); herr != nil {
+		t.Fatalf("restore tool: %v", herr)
+	}
+	if herr := f.s.RestoreTrashItem(shared.TrashKindTool, f.cassette.ID); herr != nil {
+		t.Fatalf("restore cassette: %v", herr)
+	}
+
+	press, herr = f.s.GetPress(f.press.ID)
+	if herr != nil {
+		t.Fatalf("get press: %v", herr)
+	}
+	if press.SlotUp != f.tool.ID {
+		t.Errorf("press slot holds %d, want tool %d", press.SlotUp, f.tool.ID)
+	}
+
+	tool, herr := f.s.GetTool(f.tool.ID)
+	if herr != nil {
+		t.Fatalf("get tool: %v", herr)
+	}
+	if tool.Cassette != f.cassette.ID {
+		t.Errorf("tool is bound to %d, want cassette %d", tool.Cassette, f.cassette.ID)
+	}
+}
+
+func TestRestoreToolRefusesTakenSlot(t *testing.T) {
+	f := newTrashFixture(t)
+
+	if herr := f.s.DeleteTool(f.tool.ID, 0); herr != nil {
+		t.Fatalf("delete tool: %v", herr)
+	}
+
+	other := &shared.Tool{Width: 120, Height: 60, Position: shared.SlotUpper, Type: "MASS", Code: "G02"}
+	if herr := f.s.AddTool(other); herr != nil {
+		t.Fatalf("add tool: %v", herr)
+	}
+	f.press.SlotUp = other.ID
+	if herr := f.s.UpdatePress(f.press); herr != nil {
+		t.Fatalf("update press: %v", herr)
+	}
+
+	if herr := f.s.RestoreTrashItem(shared.TrashKindTool, f.tool.ID); herr == nil || !herr.IsValidationError() {
+		t.Fatalf("restore tool: got %v, want a validation error", herr)
+	}
+	if !f.listed(t)[shared.TrashKindTool][f.tool.ID] {
+		t.Error("tool is not in the trash after the refused restore")
+	}
+	if !f.cycleDeleted(t, f.cycles[0]) {
+		t.Error("cycle got restored by the refused restore")
+	}
+
+	// Free the slot and try again
+	f.press.SlotUp = 0
+	if herr := f.s.UpdatePress(f.press); herr != nil {
+		t.Fatalf("update press: %v", herr)
+	}
+	if herr := f.s.RestoreTrashItem(shared.TrashKindTool, f.tool.ID); herr != nil {
+		t.Fatalf("restore tool: %v", herr)
+	}
+}
+
+func TestRestoreToolWithDeletedPress(t *testing.T) {
+	f := newTrashFixture(t)
+
+	if herr := f.s.DeleteTool(f.tool.ID, 0); herr != nil {
+		t.Fatalf("delete tool: %v", herr)
+	}
+	if herr := f.s.DeletePress(f.press.ID, 0); herr != nil {
+		t.Fatalf("delete press: %v", herr)
+	}
+
+	// The cycles belong to the tool, the press keeps none of them
+	if herr := f.s.RestoreTrashItem(shared.TrashKindTool, f.tool.ID); herr != nil {
+		t.Fatalf("restore tool: %v", herr)
+	}
+	for _, c := range f.cycles {
+		if f.cycleDeleted(t, c) {
+			t.Errorf("cycle %d is still in the trash", c.ID)
+		}
+	}
+
+	if herr := f.s.RestoreTrashItem(shared.TrashKindPress, f.press.ID); herr != nil {
+		t.Fatalf("restore press: %v", herr)
+	}
+
+	press, herr := f.s.GetPress(f.press.ID)
+	if herr != nil {
+		t.Fatalf("get press: %v", herr)
+	}
+	if press.SlotUp != f.tool.ID {
+		t.Errorf("press slot holds %d, want tool %d", press.SlotUp, f.tool.ID)
+	}
+}
+
+func TestDeletePressKeepsToolCycles(t *testing.T) {
+	f := newTrashFixture(t)
+
+	want, herr := f.s.GetTotalToolCycles(f.tool.ID)
+	if herr != nil {
+		t.Fatalf("total tool cycles: %v", herr)
+	}
+
+	if herr := f.s.DeletePress(f.press.ID, 0); herr != nil {
+		t.Fatalf("delete press: %v", herr)
+	}
+	for _, c := range f.cycles {
+		if f.cycleDeleted(t, c) {
+			t.Errorf("cycle %d got deleted with the press", c.ID)
+		}
+	}
+
+	if _, herr := f.s.PurgeTrash(shared.NewUnixMilli(time.Now().Add(time.Hour))); herr != nil {
+		t.Fatalf("purge trash: %v", herr)
+	}
+
+	got, herr := f.s.GetTotalToolCycles(f.tool.ID)
+	if herr != nil {
+		t.Fatalf("total tool cycles: %v", herr)
+	}
+	if got != want {
+		t.Errorf("total tool cycles = %d after deleting the press, want %d", got, want)
+	}
+
+	// The cycles of the purged press are kept on purpose, not dangling
+	orphans, err := f.s.Check()
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if len(orphans) != 0 {
+		t.Errorf("check found %d dangling references, want none", len(orphans))
+	}
+}
@@ -0,0 +1,173 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/shared"
+)
+
+// executor is implemented by *sql.DB and *sql.Tx, so the same query helper can
+// run with or without a transaction.
+type executor interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// Tx is a unit of work spanning one or more databases.
+//
+// All databases are attached to a single connection, so one SQLite transaction
+// covers every database and is committed or rolled back as a whole.
+//
+// Do not use the Store methods inside a running unit of work, the shared cache
+// will lock the tables until the transaction is done, and a write would wait for
+// a Snapshot which waits for the transaction.
+type Tx struct {
+	tx *sql.Tx
+}
+
+// Transaction runs fn inside a unit of work.
+//
+// Everything done via the Tx methods gets committed if fn returns nil, and rolled
+// back otherwise. The tool database is the main database of the connection, all
+// other databases are attached with their name as schema name. The table names
+// are unique over all databases, so the queries need no schema prefix.
+//
+// The commit is atomic over all databases because they use the rollback journal,
+// with WAL SQLite would commit each attached database on its own. Connect
+// switches every database to the rollback journal and refuses to open a
+// database still in WAL mode, see dsn.
+//
+// The write gate of the Store is held until the transaction is done, so
+// Snapshot never copies half of a unit of work.
+//
+// Parameters:
+//   - fn: The function doing the work, using the Tx methods only
+//
+// Returns:
+//   - *errors.HTTPError: The error returned by fn, or from committing the transaction
+func (s *Store) Transaction(fn func(tx *Tx) *errors.HTTPError) *errors.HTTPError {
+	s.writes.RLock()
+	defer s.writes.RUnlock()
+
+	ctx := context.Background()
+
+	c, err := s.tool.Conn(ctx)
+	if err != nil {
+		return errors.NewHTTPError(fmt.Errorf("failed to get connection for transaction: %v", err))
+	}
+	defer c.Close()
+
+	attached, herr := s.attach(ctx, c)
+	defer detach(ctx, c, attached)
+	if herr != nil {
+		return herr
+	}
+
+	t, err := c.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewHTTPError(fmt.Errorf("failed to begin transaction: %v", err))
+	}
+
+	if herr := fn(&Tx{tx: t}); herr != nil {
+		if err := t.Rollback(); err != nil {
+			slog.Error("Failed to roll back transaction", "error", err)
+		}
+		return herr
+	}
+
+	if err := t.Commit(); err != nil {
+		return errors.NewHTTPError(fmt.Errorf("failed to commit transaction: %v", err))
+	}
+	return nil
+}
+
+// AddCycle adds a new cycle entry inside the transaction.
+func (tx *Tx) AddCycle(cycle *shared.Cycle) *errors.HTTPError {
+	e, herr := tx.get("press")
+	if herr != nil {
+		return herr
+	}
+	return addCycle(e, cycle)
+}
+
+// GetPress retrieves a press by its ID inside the transaction.
+func (tx *Tx) GetPress(id shared.EntityID) (*shared.Press, *errors.HTTPError) {
+	e, herr := tx.get("press")
+	if herr != nil {
+		return nil, herr
+	}
+	return ScanPress(e.QueryRow(sqlGetPress, sql.Named("id", id)))
+}
+
+// UpdatePress updates an existing press inside the transaction.
+func (tx *Tx) UpdatePress(press *shared.Press) *errors.HTTPError {
+	e, herr := tx.get("press")
+	if herr != nil {
+		return herr
+	}
+	return updatePress(e, press)
+}
+
+// GetTool retrieves a tool by its ID inside the transaction, without injecting
+// the cycles and thresholds.
+func (tx *Tx) GetTool(id shared.EntityID) (*shared.Tool, *errors.HTTPError) {
+	e, herr := tx.get("tool")
+	if herr != nil {
+		return nil, herr
+	}
+	return ScanTool(e.QueryRow(sqlGetTool, sql.Named("id", id)))
+}
+
+// UpdateTool updates an existing tool inside the transaction.
+func (tx *Tx) UpdateTool(tool *shared.Tool) *errors.HTTPError {
+	e, herr := tx.get("tool")
+	if herr != nil {
+		return herr
+	}
+	return updateTool(e, tool)
+}
+
+// get returns the transaction for a database, all databases share the same
+// transaction, the name is used for the query metrics.
+func (tx *Tx) get(name string) (executor, *errors.HTTPError) {
+	if !slices.Contains(databaseNames, name) {
+		return nil, errors.NewHTTPError(fmt.Errorf("unknown database: %s", name))
+	}
+	return &txConn{Tx: tx.tx, name: name}, nil
+}
+
+// attach attaches all databases except the tool database to the connection,
+// returns the names of the attached databases, also on errors.
+func (s *Store) attach(ctx context.Context, c *sql.Conn) ([]string, *errors.HTTPError) {
+	var attached []string
+	for _, name := range databaseNames {
+		if name == "tool" {
+			continue
+		}
+
+		_, err := c.ExecContext(ctx, fmt.Sprintf(`ATTACH DATABASE ? AS "%s";`, name), dsn(s.path, name, "rw"))
+		if err != nil {
+			return attached, errors.NewHTTPError(
+				fmt.Errorf("failed to attach %s database: %v", name, err),
+			)
+		}
+		attached = append(attached, name)
+	}
+	return attached, nil
+}
+
+// detach removes the attached databases before the connection goes back to
+// the pool.
+func detach(ctx context.Context, c *sql.Conn, attached []string) {
+	for _, name := range attached {
+		if _, err := c.ExecContext(ctx, fmt.Sprintf(`DETACH DATABASE "%s";`, name)); err != nil {
+			slog.Error("Failed to detach database", "database", name, "error", err)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/shared"
+)
+
+// newTestStore returns a migrated store inside a temporary directory.
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	s, err := Connect(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	if _, err := s.Migrate(false); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return s
+}
+
+func TestTransactionSpansDatabases(t *testing.T) {
+	s := newTestStore(t)
+
+	tool := &shared.Tool{Width: 120, Height: 60, Position: shared.SlotUpper, Type: "MASS", Code: "G01"}
+	if herr := s.AddTool(tool); herr != nil {
+		t.Fatalf("add tool: %v", herr)
+	}
+	press := &shared.Press{Number: 5, Type: shared.MachineTypeSACMI, Code: "P5"}
+	if herr := s.AddPress(press); herr != nil {
+		t.Fatalf("add press: %v", herr)
+	}
+
+	update := func(tx *Tx, code string) *errors.HTTPError {
+		tl, herr := tx.GetTool(tool.ID)
+		if herr != nil {
+			return herr
+		}
+		tl.Code = code
+		if herr := tx.UpdateTool(tl); herr != nil {
+			return herr
+		}
+
+		p, herr := tx.GetPress(press.ID)
+		if herr != nil {
+			return herr
+		}
+		p.Code = code
+		return tx.UpdatePress(p)
+	}
+
+	herr := s.Transaction(func(tx *Tx) *errors.HTTPError {
+		if herr := update(tx, "rolled back"); herr != nil {
+			return herr
+		}
+		return errors.NewValidationError("abort").HTTPError()
+	})
+	if herr == nil {
+		t.Fatal("expected the error returned by fn")
+	}
+	assertCodes(t, s, tool.ID, press.ID, "G01", "P5")
+
+	herr = s.Transaction(func(tx *Tx) *errors.HTTPError {
+		return update(tx, "committed")
+	})
+	if herr != nil {
+		t.Fatalf("transaction: %v", herr)
+	}
+	assertCodes(t, s, tool.ID, press.ID, "committed", "committed")
+}
+
+func assertCodes(t *testing.T, s *Store, toolID, pressID shared.EntityID, toolCode, pressCode string) {
+	t.Helper()
+
+	tool, herr := s.GetTool(toolID)
+	if herr != nil {
+		t.Fatalf("get tool: %v", herr)
+	}
+	if tool.Code != toolCode {
+		t.Errorf("tool code = %q, want %q", tool.Code, toolCode)
+	}
+
+	press, herr := s.GetPress(pressID)
+	if herr != nil {
+		t.Fatalf("get press: %v", herr)
+	}
+	if press.Code != pressCode {
+		t.Errorf("press code = %q, want %q", press.Code, pressCode)
+	}
+}
+
+func TestConnectConvertsWALDatabases(t *testing.T) {
+	dir := t.TempDir()
+
+	wal, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=rwc&_journal_mode=WAL", filepath.Join(dir, "tool.sqlite")))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	var mode string
+	if err := wal.QueryRow(`PRAGMA journal_mode;`).Scan(&mode); err != nil {
+		t.Fatalf("journal mode: %v", err)
+	}
+	if mode != "wal" {
+		t.Fatalf("journal mode = %q, want %q", mode, "wal")
+	}
+	if err := wal.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	s, err := Connect(dir, true)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	for _, name := range databaseNames {
+		db, err := s.database(name)
+		if err != nil {
+			t.Fatalf("database: %v", err)
+		}
+		if err := db.QueryRow(`PRAGMA journal_mode;`).Scan(&mode); err != nil {
+			t.Fatalf("journal mode: %v", err)
+		}
+		if mode != "delete" {
+			t.Errorf("%s journal mode = %q, want %q", name, mode, "delete")
+		}
+	}
+}
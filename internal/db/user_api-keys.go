@@ -0,0 +1,269 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/shared"
+)
+
+// apiKeyLastUsedInterval limits how often the last used timestamp of a key gets
+// written, the key auth middleware looks up the key with every request
+const apiKeyLastUsedInterval = time.Minute
+
+// -----------------------------------------------------------------------------
+// Table Creation Statements
+// -----------------------------------------------------------------------------
+
+const (
+	sqlCreateApiKeysTable string = `
+CREATE TABLE IF NOT EXISTS api_keys (
+	id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	prefix TEXT NOT NULL,
+	salt TEXT NOT NULL,
+	hash TEXT NOT NULL,
+	created_at INTEGER NOT NULL,
+	expires_at INTEGER NOT NULL DEFAULT 0,
+	last_used INTEGER NOT NULL DEFAULT 0,
+
+	PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_prefix ON api_keys(prefix);
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys(user_id);`
+
+	sqlAddApiKey string = `
+INSERT INTO api_keys (user_id, name, prefix, salt, hash, created_at, expires_at, last_used)
+VALUES (:user_id, :name, :prefix, :salt, :hash, :created_at, :expires_at, :last_used);`
+
+	sqlGetApiKey string = `
+SELECT id, user_id, name, prefix, salt, hash, created_at, expires_at, last_used
+FROM api_keys
+WHERE id = :id;`
+
+	sqlListApiKeys string = `
+SELECT id, user_id, name, prefix, salt, hash, created_at, expires_at, last_used
+FROM api_keys
+ORDER BY user_id ASC, id ASC;`
+
+	sqlListApiKeysByUserID string = `
+SELECT id, user_id, name, prefix, salt, hash, created_at, expires_at, last_used
+FROM api_keys
+WHERE user_id = :user_id
+ORDER BY id ASC;`
+
+	sqlListApiKeysByPrefix string = `
+SELECT id, user_id, name, prefix, salt, hash, created_at, expires_at, last_used
+FROM api_keys
+WHERE prefix = :prefix;`
+
+	sqlUpdateApiKeyLastUsed string = `
+UPDATE api_keys
+SET last_used = :last_used
+WHERE id = :id;`
+
+	sqlDeleteApiKey string = `
+DELETE FROM api_keys
+WHERE id = :id;`
+
+	sqlListUserApiKeysForMigration string = `
+SELECT id, api_key
+FROM users;`
+)
+
+// -----------------------------------------------------------------------------
+// API Key Functions
+// -----------------------------------------------------------------------------
+
+// AddApiKey adds a new (already hashed) API key for a user
+func (s *Store) AddApiKey(key *shared.ApiKey) *errors.HTTPError {
+	if verr := key.Validate(); verr != nil {
+		return verr.HTTPError().Wrap("invalid api key")
+	}
+
+	r, err := s.user.Exec(sqlAddApiKey,
+		sql.Named("user_id", key.UserID),
+		sql.Named("name", key.Name),
+		sql.Named("prefix", key.Prefix),
+		sql.Named("salt", key.Salt),
+		sql.Named("hash", key.Hash),
+		sql.Named("created_at", key.CreatedAt),
+		sql.Named("expires_at", key.ExpiresAt),
+		sql.Named("last_used", key.LastUsed),
+	)
+	if err != nil {
+		return errors.NewHTTPError(err)
+	}
+
+	if key.ID, err = lastInsertID(r); err != nil {
+		return errors.NewHTTPError(err)
+	}
+
+	return nil
+}
+
+// GetApiKey retrieves an API key by its ID
+func (s *Store) GetApiKey(id shared.EntityID) (*shared.ApiKey, *errors.HTTPError) {
+	return ScanApiKey(s.user.QueryRow(sqlGetApiKey, sql.Named("id", id)))
+}
+
+// ListApiKeys retrieves the API keys of all users
+func (s *Store) ListApiKeys() ([]*shared.ApiKey, *errors.HTTPError) {
+	return s.listApiKeys(sqlListApiKeys)
+}
+
+// ListApiKeysByUserID retrieves all API keys of a user
+func (s *Store) ListApiKeysByUserID(userID shared.TelegramID) ([]*shared.ApiKey, *errors.HTTPError) {
+	return s.listApiKeys(sqlListApiKeysByUserID, sql.Named("user_id", userID))
+}
+
+// DeleteApiKey revokes an API key
+func (s *Store) DeleteApiKey(id shared.EntityID) *errors.HTTPError {
+	r, err := s.user.Exec(sqlDeleteApiKey, sql.Named("id", id))
+	if err != nil {
+		return errors.NewHTTPError(err)
+	}
+	if n, err := r.RowsAffected(); err == nil && n == 0 {
+		return errors.NewNotFoundError("api key %d", id).HTTPError()
+	}
+	return nil
+}
+
+// GetUserByApiKey retrieves the user owning an API key, expired keys are not
+// accepted. The last used timestamp of the key gets updated.
+func (s *Store) GetUserByApiKey(apiKey string) (*shared.User, *errors.HTTPError) {
+	keys, herr := s.listApiKeys(sqlListApiKeysByPrefix, sql.Named("prefix", shared.ApiKeyPrefix(apiKey)))
+	if herr != nil {
+		return nil, herr
+	}
+
+	for _, key := range keys {
+		if !key.Matches(apiKey) {
+			continue
+		}
+		if key.IsExpired() {
+			return nil, errors.NewAuthorizationError("api key %q has expired", key.Name).HTTPError()
+		}
+
+		now := time.Now()
+		if now.Sub(key.LastUsed.ToTime()) >= apiKeyLastUsedInterval {
+			_, err := s.user.Exec(sqlUpdateApiKeyLastUsed,
+				sql.Named("id", key.ID),
+				sql.Named("last_used", shared.NewUnixMilli(now)),
+			)
+			if err != nil {
+				slog.Error("Failed to update api key last used", "api_key", key.String(), "error", err)
+			}
+		}
+
+		return s.GetUser(key.UserID)
+	}
+
+	return nil, errors.NewNotFoundError("api key").HTTPError()
+}
+
+func (s *Store) listApiKeys(query string, args ...any) ([]*shared.ApiKey, *errors.HTTPError) {
+	rows, err := s.user.Query(query, args...)
+	if err != nil {
+		return nil, errors.NewHTTPError(err)
+	}
+	defer rows.Close()
+
+	keys := []*shared.ApiKey{}
+	for rows.Next() {
+		key, herr := ScanApiKey(rows)
+		if herr != nil {
+			return nil, herr
+		}
+		keys = append(keys, key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.NewHTTPError(err)
+	}
+
+	return keys, nil
+}
+
+// -----------------------------------------------------------------------------
+// Migration Helpers
+// -----------------------------------------------------------------------------
+
+// migrateUserApiKeys moves the plain text API keys from the users table into
+// the api_keys table, hashed, named "Standard"
+func migrateUserApiKeys(tx *sql.Tx) error {
+	rows, err := tx.Query(sqlListUserApiKeysForMigration)
+	if err != nil {
+		return err
+	}
+
+	var keys []*shared.ApiKey
+	for rows.Next() {
+		var (
+			userID shared.TelegramID
+			apiKey string
+		)
+		if err = rows.Scan(&userID, &apiKey); err != nil {
+			rows.Close()
+			return err
+		}
+
+		key, err := shared.NewApiKey(userID, "Standard", apiKey, 0)
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("api key of user %d: %v", userID, err)
+		}
+		keys = append(keys, key)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		_, err = tx.Exec(sqlAddApiKey,
+			sql.Named("user_id", key.UserID),
+			sql.Named("name", key.Name),
+			sql.Named("prefix", key.Prefix),
+			sql.Named("salt", key.Salt),
+			sql.Named("hash", key.Hash),
+			sql.Named("created_at", key.CreatedAt),
+			sql.Named("expires_at", key.ExpiresAt),
+			sql.Named("last_used", key.LastUsed),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+// Scan Helpers
+// -----------------------------------------------------------------------------
+
+// ScanApiKey scans a database row into an ApiKey struct
+func ScanApiKey(row Scannable) (*shared.ApiKey, *errors.HTTPError) {
+	var k shared.ApiKey
+	err := row.Scan(
+		&k.ID,
+		&k.UserID,
+		&k.Name,
+		&k.Prefix,
+		&k.Salt,
+		&k.Hash,
+		&k.CreatedAt,
+		&k.ExpiresAt,
+		&k.LastUsed,
+	)
+	if err != nil {
+		return nil, errors.NewHTTPError(err)
+	}
+	return &k, nil
+}
@@ -0,0 +1,203 @@
+package db
+
+import (
+	"database/sql"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/shared"
+)
+
+// -----------------------------------------------------------------------------
+// Table Creation Statements
+// -----------------------------------------------------------------------------
+
+const (
+	sqlCreateAuditLogTable string = `
+CREATE TABLE IF NOT EXISTS audit_log (
+	id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	action TEXT NOT NULL,
+	entity_type TEXT NOT NULL,
+	entity_id INTEGER NOT NULL,
+	linked TEXT NOT NULL DEFAULT '',
+	created_at INTEGER NOT NULL,
+	before TEXT NOT NULL DEFAULT '',
+	after TEXT NOT NULL DEFAULT '',
+
+	PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+-- Indexes to quickly find the history of an entity, and of its children
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity
+ON audit_log(entity_type, entity_id);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_linked
+ON audit_log(linked);`
+
+	sqlAddAuditEntry string = `
+INSERT INTO audit_log (user_id, action, entity_type, entity_id, linked, created_at, before, after)
+VALUES (:user_id, :action, :entity_type, :entity_id, :linked, :created_at, :before, :after);`
+
+	sqlListAuditEntries string = `
+SELECT id, user_id, action, entity_type, entity_id, linked, created_at, before, after
+FROM audit_log
+ORDER BY created_at DESC, id DESC
+LIMIT :limit;`
+
+	sqlListAuditEntriesForEntity string = `
+SELECT id, user_id, action, entity_type, entity_id, linked, created_at, before, after
+FROM audit_log
+WHERE (entity_type = :entity_type AND entity_id = :entity_id) OR linked = :entity
+ORDER BY created_at DESC, id DESC
+LIMIT :limit;`
+)
+
+// -----------------------------------------------------------------------------
+// Audit Log Functions
+// -----------------------------------------------------------------------------
+
+// AddAuditEntry adds a new entry to the audit log
+func (s *Store) AddAuditEntry(entry *shared.AuditEntry) *errors.HTTPError {
+	if verr := entry.Validate(); verr != nil {
+		return verr.HTTPError().Wrap("invalid audit entry")
+	}
+
+	r, err := s.user.Exec(sqlAddAuditEntry,
+		sql.Named("user_id", entry.UserID),
+		sql.Named("action", entry.Action),
+		sql.Named("entity_type", entry.EntityType),
+		sql.Named("entity_id", entry.EntityID),
+		sql.Named("linked", entry.Linked),
+		sql.Named("created_at", entry.CreatedAt),
+		sql.Named("before", entry.Before),
+		sql.Named("after", entry.After),
+	)
+	if err != nil {
+		return errors.NewHTTPError(err)
+	}
+
+	if entry.ID, err = lastInsertID(r); err != nil {
+		return errors.NewHTTPError(err)
+	}
+
+	return nil
+}
+
+// Audit records a change of an entity made by a user.
+//
+// The change was already made at this point, so failing to record it only gets
+// logged and never fails the request. Changes of interest for other users also
+// end up in the activity feed.
+//
+// Parameters:
+//   - userID: The user who made the change, 0 for the command line
+//   - action: The kind of change
+//   - before: A clone of the entity before the change, nil if it was created
+//   - after: The entity after the change, nil if it was deleted
+func (s *Store) Audit(userID shared.TelegramID, action shared.AuditAction, before, after shared.Auditable) {
+	entry, err := shared.NewAuditEntry(userID, action, before, after)
+	if err != nil {
+		slog.Error("Failed to create audit entry", "user_id", userID, "action", action, "error", err)
+		return
+	}
+
+	if herr := s.AddAuditEntry(entry); herr != nil {
+		slog.Error("Failed to add audit entry", "entry", entry.String(), "error", herr)
+	}
+
+	s.feed(userID, action, before, after)
+}
+
+// ListAuditEntries retrieves the latest audit log entries, newest first.
+//
+// Parameters:
+//   - entity: An entity reference like "tool_12", matching all changes of the
+//     entity and of its children (cycles, metal sheets, notes, ...), or an
+//     empty string for all entries
+//   - limit: The maximum number of entries, 0 for no limit
+//
+// Returns:
+//   - []*shared.AuditEntry: The matching entries
+//   - *errors.HTTPError: Validation error for an invalid entity reference
+func (s *Store) ListAuditEntries(entity string, limit int) ([]*shared.AuditEntry, *errors.HTTPError) {
+	if limit <= 0 {
+		limit = -1 // No limit for SQLite
+	}
+
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	if entity == "" {
+		rows, err = s.user.Query(sqlListAuditEntries, sql.Named("limit", limit))
+	} else {
+		entityType, entityID, herr := parseAuditEntity(entity)
+		if herr != nil {
+			return nil, herr
+		}
+		rows, err = s.user.Query(sqlListAuditEntriesForEntity,
+			sql.Named("entity_type", entityType),
+			sql.Named("entity_id", entityID),
+			sql.Named("entity", entity),
+			sql.Named("limit", limit),
+		)
+	}
+	if err != nil {
+		return nil, errors.NewHTTPError(err)
+	}
+	defer rows.Close()
+
+	entries := []*shared.AuditEntry{}
+	for rows.Next() {
+		entry, herr := ScanAuditEntry(rows)
+		if herr != nil {
+			return nil, herr
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// parseAuditEntity splits an entity reference like "tool_12" into its type and ID
+func parseAuditEntity(entity string) (shared.AuditEntityType, int64, *errors.HTTPError) {
+	i := strings.LastIndex(entity, "_")
+	if i <= 0 {
+		return "", 0, errors.NewValidationError("invalid entity %q, expected e.g. \"tool_12\"", entity).HTTPError()
+	}
+
+	id, err := strconv.ParseInt(entity[i+1:], 10, 64)
+	if err != nil {
+		return "", 0, errors.NewValidationError("invalid entity %q, expected e.g. \"tool_12\"", entity).HTTPError()
+	}
+
+	return shared.AuditEntityType(entity[:i]), id, nil
+}
+
+// -----------------------------------------------------------------------------
+// Scan Helpers
+// -----------------------------------------------------------------------------
+
+// ScanAuditEntry scans a database row into an AuditEntry struct
+func ScanAuditEntry(row Scannable) (*shared.AuditEntry, *errors.HTTPError) {
+	var e shared.AuditEntry
+	err := row.Scan(
+		&e.ID,
+		&e.UserID,
+		&e.Action,
+		&e.EntityType,
+		&e.EntityID,
+		&e.Linked,
+		&e.CreatedAt,
+		&e.Before,
+		&e.After,
+	)
+	if err != nil {
+		return nil, errors.NewHTTPError(err)
+	}
+	return &e, nil
+}
@@ -28,36 +28,31 @@ CREATE INDEX IF NOT EXISTS idx_cookies_user_id
 ON cookies(user_id);`
 
 	sqlListCookies string = `
-SELECT user_agent, value, user_id, last_login
+SELECT user_agent, value, user_id, last_login, csrf_token
 FROM cookies;`
 
 	sqlListCookiesByUserID string = `
-SELECT user_agent, value, user_id, last_login
-FROM cookies
-WHERE user_id = :user_id
-ORDER BY last_login DESC;`
-
-	sqlListCookiesByApiKey string = `
-SELECT user_agent, value, user_id, last_login
+SELECT user_agent, value, user_id, last_login, csrf_token
 FROM cookies
 WHERE user_id = :user_id
 ORDER BY last_login DESC;`
 
 	sqlGetCookie string = `
-SELECT user_agent, value, user_id, last_login
+SELECT user_agent, value, user_id, last_login, csrf_token
 FROM cookies
 WHERE value = :value;`
 
 	sqlAddCookie string = `
-INSERT INTO cookies (user_agent, value, user_id, last_login)
-VALUES (:user_agent, :value, :user_id, :last_login);`
+INSERT INTO cookies (user_agent, value, user_id, last_login, csrf_token)
+VALUES (:user_agent, :value, :user_id, :last_login, :csrf_token);`
 
 	sqlUpdateCookie string = `
 UPDATE cookies
 SET
 	user_agent = :user_agent,
 	user_id = :user_id,
-	last_login = :last_login
+	last_login = :last_login,
+	csrf_token = :csrf_token
 WHERE value = :value;`
 
 	sqlDeleteCookie string = `
@@ -74,8 +69,8 @@ WHERE user_id = :user_id;`
 // -----------------------------------------------------------------------------
 
 // ListCookies retrieves all cookies from the database
-func ListCookies() (cookies []*shared.Cookie, merr *errors.HTTPError) {
-	rows, err := dbUser.Query(sqlListCookies)
+func (s *Store) ListCookies() (cookies []*shared.Cookie, merr *errors.HTTPError) {
+	rows, err := s.user.Query(sqlListCookies)
 	if err != nil {
 		return nil, errors.NewHTTPError(err)
 	}
@@ -93,33 +88,8 @@ func ListCookies() (cookies []*shared.Cookie, merr *errors.HTTPError) {
 }
 
 // ListCookiesByUserID retrieves all cookies for a specific user ID
-func ListCookiesByUserID(userID shared.TelegramID) (cookies []*shared.Cookie, merr *errors.HTTPError) {
-	rows, err := dbUser.Query(sqlListCookiesByUserID, sql.Named("user_id", userID))
-	if err != nil {
-		return nil, errors.NewHTTPError(err)
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		cookie, merr := ScanCookie(rows)
-		if merr != nil {
-			return nil, merr
-		}
-		cookies = append(cookies, cookie)
-	}
-
-	return cookies, nil
-}
-
-// ListCookiesByApiKey retrieves all cookies for a specific user by API key
-func ListCookiesByApiKey(apiKey string) (cookies []*shared.Cookie, merr *errors.HTTPError) {
-	// Get user id from the users table
-	user, merr := GetUserByApiKey(apiKey)
-	if merr != nil {
-		return nil, merr
-	}
-
-	rows, err := dbUser.Query(sqlListCookiesByApiKey, sql.Named("user_id", user.ID))
+func (s *Store) ListCookiesByUserID(userID shared.TelegramID) (cookies []*shared.Cookie, merr *errors.HTTPError) {
+	rows, err := s.user.Query(sqlListCookiesByUserID, sql.Named("user_id", userID))
 	if err != nil {
 		return nil, errors.NewHTTPError(err)
 	}
@@ -137,21 +107,22 @@ func ListCookiesByApiKey(apiKey string) (cookies []*shared.Cookie, merr *errors.
 }
 
 // GetCookie retrieves a cookie by its value
-func GetCookie(value string) (*shared.Cookie, *errors.HTTPError) {
-	return ScanCookie(dbUser.QueryRow(sqlGetCookie, sql.Named("value", value)))
+func (s *Store) GetCookie(value string) (*shared.Cookie, *errors.HTTPError) {
+	return ScanCookie(s.user.QueryRow(sqlGetCookie, sql.Named("value", value)))
 }
 
 // AddCookie adds a new cookie to the database
-func AddCookie(cookie *shared.Cookie) *errors.HTTPError {
+func (s *Store) AddCookie(cookie *shared.Cookie) *errors.HTTPError {
 	if verr := cookie.Validate(); verr != nil {
 		return verr.HTTPError().Wrap("invalid cookie data")
 	}
 
-	_, err := dbUser.Exec(sqlAddCookie,
+	_, err := s.user.Exec(sqlAddCookie,
 		sql.Named("user_agent", cookie.UserAgent),
 		sql.Named("value", cookie.Value),
 		sql.Named("user_id", cookie.UserID),
 		sql.Named("last_login", cookie.LastLogin),
+		sql.Named("csrf_token", cookie.CSRFToken),
 	)
 	if err != nil {
 		return errors.NewHTTPError(err)
@@ -160,15 +131,16 @@ func AddCookie(cookie *shared.Cookie) *errors.HTTPError {
 }
 
 // UpdateCookie updates the given cookie in the database, it just replaces all fields including the value.
-func UpdateCookie(cookie *shared.Cookie) *errors.HTTPError {
+func (s *Store) UpdateCookie(cookie *shared.Cookie) *errors.HTTPError {
 	if verr := cookie.Validate(); verr != nil {
 		return verr.HTTPError().Wrap("invalid cookie data")
 	}
 
-	_, err := dbUser.Exec(sqlUpdateCookie,
+	_, err := s.user.Exec(sqlUpdateCookie,
 		sql.Named("user_agent", cookie.UserAgent),
 		sql.Named("user_id", cookie.UserID),
 		sql.Named("last_login", cookie.LastLogin),
+		sql.Named("csrf_token", cookie.CSRFToken),
 		sql.Named("value", cookie.Value),
 	)
 	if err != nil {
@@ -178,8 +150,8 @@ func UpdateCookie(cookie *shared.Cookie) *errors.HTTPError {
 }
 
 // DeleteCookie removes a cookie from the database by value
-func DeleteCookie(value string) *errors.HTTPError {
-	_, err := dbUser.Exec(sqlDeleteCookie, sql.Named("value", value))
+func (s *Store) DeleteCookie(value string) *errors.HTTPError {
+	_, err := s.user.Exec(sqlDeleteCookie, sql.Named("value", value))
 	if err != nil {
 		return errors.NewHTTPError(err)
 	}
@@ -187,14 +159,35 @@ func DeleteCookie(value string) *errors.HTTPError {
 }
 
 // DeleteCookiesByUserID removes all cookies for a specific user
-func DeleteCookiesByUserID(userID shared.TelegramID) *errors.HTTPError {
-	_, err := dbUser.Exec(sqlDeleteCookiesByUserID, sql.Named("user_id", userID))
+func (s *Store) DeleteCookiesByUserID(userID shared.TelegramID) *errors.HTTPError {
+	_, err := s.user.Exec(sqlDeleteCookiesByUserID, sql.Named("user_id", userID))
 	if err != nil {
 		return errors.NewHTTPError(err)
 	}
 	return nil
 }
 
+// DeleteExpiredCookies removes all expired cookies and returns the number of removed cookies
+func (s *Store) DeleteExpiredCookies() (int, *errors.HTTPError) {
+	cookies, herr := s.ListCookies()
+	if herr != nil {
+		return 0, herr.Wrap("list cookies")
+	}
+
+	var n int
+	for _, c := range cookies {
+		if !c.IsExpired() {
+			continue
+		}
+		if herr = s.DeleteCookie(c.Value); herr != nil {
+			return n, herr.Wrap("delete cookie %q", c.Value)
+		}
+		n++
+	}
+
+	return n, nil
+}
+
 // -----------------------------------------------------------------------------
 // Scan Helpers
 // -----------------------------------------------------------------------------
@@ -207,6 +200,7 @@ func ScanCookie(row Scannable) (*shared.Cookie, *errors.HTTPError) {
 		&c.Value,
 		&c.UserID,
 		&c.LastLogin,
+		&c.CSRFToken,
 	)
 	if err != nil {
 		return nil, errors.NewHTTPError(err)
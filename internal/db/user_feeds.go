@@ -0,0 +1,319 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/shared"
+)
+
+// -----------------------------------------------------------------------------
+// Table Creation Statements
+// -----------------------------------------------------------------------------
+
+const (
+	sqlCreateFeedsTable string = `
+CREATE TABLE IF NOT EXISTS feeds (
+	id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	kind TEXT NOT NULL,
+	title TEXT NOT NULL,
+	content TEXT NOT NULL DEFAULT '',
+	press_id INTEGER NOT NULL DEFAULT 0,
+	tool_id INTEGER NOT NULL DEFAULT 0,
+	created_at INTEGER NOT NULL,
+
+	PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+CREATE INDEX IF NOT EXISTS idx_feeds_press ON feeds(press_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_feeds_tool ON feeds(tool_id, id DESC);`
+
+	sqlAddFeed string = `
+INSERT INTO feeds (user_id, kind, title, content, press_id, tool_id, created_at)
+VALUES (:user_id, :kind, :title, :content, :press_id, :tool_id, :created_at);`
+
+	sqlListFeeds string = `
+SELECT id, user_id, kind, title, content, press_id, tool_id, created_at
+FROM feeds
+WHERE (:press_id = 0 OR press_id = :press_id) AND (:tool_id = 0 OR tool_id = :tool_id)
+ORDER BY id DESC
+LIMIT :limit OFFSET :offset;`
+
+	sqlGetLastFeed string = `
+SELECT last_feed
+FROM users
+WHERE id = :user_id;`
+
+	sqlCountUnreadFeeds string = `
+SELECT COUNT(*)
+FROM feeds
+WHERE id > (SELECT last_feed FROM users WHERE id = :user_id) AND user_id != :user_id;`
+
+	sqlMarkFeedsRead string = `
+UPDATE users
+SET last_feed = (SELECT COALESCE(MAX(id), 0) FROM feeds)
+WHERE id = :user_id;`
+)
+
+// -----------------------------------------------------------------------------
+// Feed Functions
+// -----------------------------------------------------------------------------
+
+// AddFeed adds a new event to the activity feed
+func (s *Store) AddFeed(feed *shared.Feed) *errors.HTTPError {
+	if verr := feed.Validate(); verr != nil {
+		return verr.HTTPError().Wrap("invalid feed")
+	}
+
+	r, err := s.user.Exec(sqlAddFeed,
+		sql.Named("user_id", feed.UserID),
+		sql.Named("kind", feed.Kind),
+		sql.Named("title", feed.Title),
+		sql.Named("content", feed.Content),
+		sql.Named("press_id", feed.PressID),
+		sql.Named("tool_id", feed.ToolID),
+		sql.Named("created_at", feed.CreatedAt),
+	)
+	if err != nil {
+		return errors.NewHTTPError(err)
+	}
+
+	if feed.ID, err = lastInsertID(r); err != nil {
+		return errors.NewHTTPError(err)
+	}
+
+	return nil
+}
+
+// ListFeeds retrieves a page of the activity feed, newest first
+func (s *Store) ListFeeds(filter shared.FeedFilter) ([]*shared.Feed, *errors.HTTPError) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1 // No limit for SQLite
+	}
+
+	rows, err := s.user.Query(sqlListFeeds,
+		sql.Named("press_id", filter.PressID),
+		sql.Named("tool_id", filter.ToolID),
+		sql.Named("limit", limit),
+		sql.Named("offset", filter.Offset),
+	)
+	if err != nil {
+		return nil, errors.NewHTTPError(err)
+	}
+	defer rows.Close()
+
+	feeds := []*shared.Feed{}
+	for rows.Next() {
+		feed, herr := ScanFeed(rows)
+		if herr != nil {
+			return nil, herr
+		}
+		feeds = append(feeds, feed)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.NewHTTPError(err)
+	}
+
+	return feeds, nil
+}
+
+// GetLastFeed returns the ID of the latest feed the user has seen
+func (s *Store) GetLastFeed(userID shared.TelegramID) (shared.EntityID, *errors.HTTPError) {
+	var lastFeed shared.EntityID
+	err := s.user.QueryRow(sqlGetLastFeed, sql.Named("user_id", userID)).Scan(&lastFeed)
+	if err != nil {
+		return 0, errors.NewHTTPError(err)
+	}
+	return lastFeed, nil
+}
+
+// CountUnreadFeeds counts the feeds of other users the user has not seen yet
+func (s *Store) CountUnreadFeeds(userID shared.TelegramID) (int, *errors.HTTPError) {
+	var count int
+	err := s.user.QueryRow(sqlCountUnreadFeeds, sql.Named("user_id", userID)).Scan(&count)
+	if err != nil {
+		return 0, errors.NewHTTPError(err)
+	}
+	return count, nil
+}
+
+// MarkFeedsRead moves the users last seen marker to the latest feed
+func (s *Store) MarkFeedsRead(userID shared.TelegramID) *errors.HTTPError {
+	if _, err := s.user.Exec(sqlMarkFeedsRead, sql.Named("user_id", userID)); err != nil {
+		return errors.NewHTTPError(err)
+	}
+	return nil
+}
+
+// feed adds an event to the activity feed for a change recorded in the audit log.
+//
+// Only changes of interest for other users end up in the feed (new cycles, tool
+// changes, regenerations, new notes and trouble reports), everything else gets ignored.
+// Like the audit log, failing to add the feed only gets logged.
+func (s *Store) feed(userID shared.TelegramID, action shared.AuditAction, before, after shared.Auditable) {
+	for _, feed := range s.feedsForChange(userID, action, before, after) {
+		if herr := s.AddFeed(feed); herr != nil {
+			slog.Error("Failed to add feed", "feed", feed.String(), "error", herr)
+		}
+	}
+}
+
+// feedsForChange creates the feed events for a change, see feed
+func (s *Store) feedsForChange(userID shared.TelegramID, action shared.AuditAction, before, after shared.Auditable) []*shared.Feed {
+	if action == shared.AuditActionDelete || action == shared.AuditActionRestore {
+		return nil
+	}
+
+	switch entity := after.(type) {
+	case *shared.Cycle:
+		if action != shared.AuditActionCreate {
+			return nil
+		}
+		feed := shared.NewFeed(userID, shared.FeedKindCycle,
+			fmt.Sprintf("Neuer Zyklus an %s", s.feedPressName(entity.PressID)),
+			fmt.Sprintf("%s: %d Zyklen", s.feedToolName(entity.ToolID), entity.PressCycles))
+		feed.PressID = entity.PressID
+		feed.ToolID = entity.ToolID
+		return []*shared.Feed{feed}
+
+	case *shared.Press:
+		old, ok := before.(*shared.Press)
+		if !ok {
+			return nil
+		}
+		var feeds []*shared.Feed
+		for _, slot := range []struct {
+			name     string
+			from, to shared.EntityID
+		}{
+			{shared.SlotUpper.German(), old.SlotUp, entity.SlotUp},
+			{shared.SlotLower.German(), old.SlotDown, entity.SlotDown},
+		} {
+			if slot.from == slot.to {
+				continue
+			}
+			feed := shared.NewFeed(userID, shared.FeedKindToolChange,
+				fmt.Sprintf("Werkzeugwechsel an Presse %s", entity.Number.String()),
+				fmt.Sprintf("%s: %s → %s", slot.name, s.feedToolName(slot.from), s.feedToolName(slot.to)))
+			feed.PressID = entity.ID
+			feed.ToolID = slot.to
+			feeds = append(feeds, feed)
+		}
+		return feeds
+
+	case *shared.Tool:
+		title := fmt.Sprintf("Neues Werkzeug %s", entity.German())
+		if old, ok := before.(*shared.Tool); ok {
+			// Cycles are injected, a change of them alone is not a change of the tool
+			old = old.Clone()
+			old.Cycles = entity.Cycles
+			if *old == *entity {
+				return nil
+			}
+			title = fmt.Sprintf("Werkzeug %s geändert", entity.German())
+		}
+		feed := shared.NewFeed(userID, shared.FeedKindTool, title, "")
+		feed.ToolID = entity.ID
+		feed.PressID = s.feedPressIDForTool(entity.ID)
+		return []*shared.Feed{feed}
+
+	case *shared.ToolRegeneration:
+		kind := shared.FeedKindRegenerationStarted
+		if old, ok := before.(*shared.ToolRegeneration); ok {
+			if old.Stop != 0 || entity.Stop == 0 {
+				return nil
+			}
+			kind = shared.FeedKindRegenerationStopped
+		}
+		feed := shared.NewFeed(userID, kind,
+			fmt.Sprintf("%s: %s", kind.German(), s.feedToolName(entity.ToolID)), "")
+		feed.ToolID = entity.ToolID
+		feed.PressID = s.feedPressIDForTool(entity.ToolID)
+		return []*shared.Feed{feed}
+
+	case *shared.Note:
+		if action != shared.AuditActionCreate {
+			return nil
+		}
+		feed := shared.NewFeed(userID, shared.FeedKindNote, "Neue Notiz", entity.Content)
+		if linkedType, linkedID, herr := parseAuditEntity(entity.Linked); herr == nil {
+			switch linkedType {
+			case shared.AuditEntityPress:
+				feed.PressID = shared.EntityID(linkedID)
+				feed.Title = fmt.Sprintf("Neue Notiz für %s", s.feedPressName(feed.PressID))
+			case shared.AuditEntityTool:
+				feed.ToolID = shared.EntityID(linkedID)
+				feed.Title = fmt.Sprintf("Neue Notiz für %s", s.feedToolName(feed.ToolID))
+			}
+		}
+		return []*shared.Feed{feed}
+
+	case *shared.TroubleReport:
+		title := "Neuer Problembericht"
+		if action == shared.AuditActionUpdate {
+			title = "Problembericht geändert"
+		}
+		return []*shared.Feed{shared.NewFeed(userID, shared.FeedKindTroubleReport, title, entity.Title)}
+
+	default:
+		return nil
+	}
+}
+
+// feedToolName returns a readable name of a tool for the feed
+func (s *Store) feedToolName(toolID shared.EntityID) string {
+	if toolID == 0 {
+		return "-"
+	}
+	tool, herr := s.GetTool(toolID)
+	if herr != nil {
+		return fmt.Sprintf("Werkzeug %d", toolID)
+	}
+	return tool.German()
+}
+
+// feedPressName returns a readable name of a press for the feed
+func (s *Store) feedPressName(pressID shared.EntityID) string {
+	press, herr := s.GetPress(pressID)
+	if herr != nil {
+		return fmt.Sprintf("Presse (ID %d)", pressID)
+	}
+	return fmt.Sprintf("Presse %s", press.Number.String())
+}
+
+// feedPressIDForTool returns the press the tool is mounted in, 0 if none
+func (s *Store) feedPressIDForTool(toolID shared.EntityID) shared.EntityID {
+	press, herr := s.GetPressForTool(toolID)
+	if herr != nil || press == nil {
+		return 0
+	}
+	return press.ID
+}
+
+// -----------------------------------------------------------------------------
+// Scan Helpers
+// -----------------------------------------------------------------------------
+
+// ScanFeed scans a database row into a Feed struct
+func ScanFeed(row Scannable) (*shared.Feed, *errors.HTTPError) {
+	var f shared.Feed
+	err := row.Scan(
+		&f.ID,
+		&f.UserID,
+		&f.Kind,
+		&f.Title,
+		&f.Content,
+		&f.PressID,
+		&f.ToolID,
+		&f.CreatedAt,
+	)
+	if err != nil {
+		return nil, errors.NewHTTPError(err)
+	}
+	return &f, nil
+}
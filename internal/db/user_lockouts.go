@@ -0,0 +1,227 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/shared"
+)
+
+// -----------------------------------------------------------------------------
+// Table Creation Statements
+// -----------------------------------------------------------------------------
+
+const (
+	sqlCreateLockoutsTable string = `
+CREATE TABLE IF NOT EXISTS lockouts (
+	key TEXT NOT NULL,
+	failures INTEGER NOT NULL DEFAULT 0,
+	last_failure INTEGER NOT NULL DEFAULT 0,
+	locked_until INTEGER NOT NULL DEFAULT 0,
+
+	PRIMARY KEY("key")
+);`
+
+	sqlGetLockout string = `
+SELECT key, failures, last_failure, locked_until
+FROM lockouts
+WHERE key = :key;`
+
+	sqlListLockouts string = `
+SELECT key, failures, last_failure, locked_until
+FROM lockouts
+ORDER BY locked_until DESC, last_failure DESC;`
+
+	sqlUpsertLockout string = `
+INSERT INTO lockouts (key, failures, last_failure, locked_until)
+VALUES (:key, :failures, :last_failure, :locked_until)
+ON CONFLICT(key) DO UPDATE SET
+	failures = excluded.failures,
+	last_failure = excluded.last_failure,
+	locked_until = excluded.locked_until;`
+
+	sqlDeleteLockout string = `
+DELETE FROM lockouts
+WHERE key = :key;`
+
+	sqlDeleteLockoutsByPart string = `
+DELETE FROM lockouts
+WHERE key = :key
+	OR substr(key, 1, length(:key) + 1) = :key || '|'
+	OR substr(key, -length(:key) - 1) = '|' || :key;`
+
+	sqlDeleteAllLockouts string = `
+DELETE FROM lockouts;`
+
+	sqlDeleteStaleLockouts string = `
+DELETE FROM lockouts
+WHERE last_failure < :before AND locked_until < :now;`
+)
+
+// -----------------------------------------------------------------------------
+// Lockout Functions
+// -----------------------------------------------------------------------------
+
+// GetLockout retrieves the lockout for a key like "ip:127.0.0.1"
+func (s *Store) GetLockout(key string) (*shared.Lockout, *errors.HTTPError) {
+	l, herr := ScanLockout(s.user.QueryRow(sqlGetLockout, sql.Named("key", key)))
+	if herr != nil {
+		if herr.IsNotFoundError() {
+			return nil, errors.NewNotFoundError("lockout %q", key).HTTPError()
+		}
+		return nil, herr
+	}
+	return l, nil
+}
+
+// ListLockouts retrieves all lockouts with failed logins, locked first
+func (s *Store) ListLockouts() ([]*shared.Lockout, *errors.HTTPError) {
+	rows, err := s.user.Query(sqlListLockouts)
+	if err != nil {
+		return nil, errors.NewHTTPError(err)
+	}
+	defer rows.Close()
+
+	lockouts := []*shared.Lockout{}
+	for rows.Next() {
+		l, herr := ScanLockout(rows)
+		if herr != nil {
+			return nil, herr
+		}
+		lockouts = append(lockouts, l)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.NewHTTPError(err)
+	}
+
+	return lockouts, nil
+}
+
+// ListLockoutsByKeys retrieves the existing lockouts for keys, keys without
+// failed logins are skipped
+func (s *Store) ListLockoutsByKeys(keys ...string) ([]*shared.Lockout, *errors.HTTPError) {
+	lockouts := []*shared.Lockout{}
+	for _, key := range keys {
+		l, herr := s.GetLockout(key)
+		if herr != nil {
+			if herr.IsNotFoundError() {
+				continue
+			}
+			return nil, herr
+		}
+		lockouts = append(lockouts, l)
+	}
+	return lockouts, nil
+}
+
+// AddLoginFailure counts a failed login for all keys, see shared.Lockout
+func (s *Store) AddLoginFailure(keys ...string) *errors.HTTPError {
+	now := time.Now()
+	for _, key := range keys {
+		l, herr := s.GetLockout(key)
+		if herr != nil {
+			if !herr.IsNotFoundError() {
+				return herr
+			}
+			l = &shared.Lockout{Key: key}
+		}
+
+		l.AddFailure(now)
+		if verr := l.Validate(); verr != nil {
+			return verr.HTTPError().Wrap("invalid lockout")
+		}
+
+		_, err := s.user.Exec(sqlUpsertLockout,
+			sql.Named("key", l.Key),
+			sql.Named("failures", l.Failures),
+			sql.Named("last_failure", l.LastFailure),
+			sql.Named("locked_until", l.LockedUntil),
+		)
+		if err != nil {
+			return errors.NewHTTPError(err)
+		}
+	}
+	return nil
+}
+
+// ClearLockouts removes the lockouts (and failed login counts) for keys
+func (s *Store) ClearLockouts(keys ...string) *errors.HTTPError {
+	for _, key := range keys {
+		if _, err := s.user.Exec(sqlDeleteLockout, sql.Named("key", key)); err != nil {
+			return errors.NewHTTPError(err)
+		}
+	}
+	return nil
+}
+
+// ClearLockout removes a lockout, see shared.ParseLockoutKey for the key. A key
+// of a client IP or an API key prefix also removes the lockouts of that API key
+// prefix from a client IP, and of all API key prefixes from that client IP.
+func (s *Store) ClearLockout(key string) *errors.HTTPError {
+	key = shared.ParseLockoutKey(key)
+
+	r, err := s.user.Exec(sqlDeleteLockoutsByPart, sql.Named("key", key))
+	if err != nil {
+		return errors.NewHTTPError(err)
+	}
+	if n, err := r.RowsAffected(); err == nil && n == 0 {
+		return errors.NewNotFoundError("lockout %q", key).HTTPError()
+	}
+	return nil
+}
+
+// ClearAllLockouts removes all lockouts and returns the number of removed lockouts
+func (s *Store) ClearAllLockouts() (int, *errors.HTTPError) {
+	r, err := s.user.Exec(sqlDeleteAllLockouts)
+	if err != nil {
+		return 0, errors.NewHTTPError(err)
+	}
+
+	n, err := r.RowsAffected()
+	if err != nil {
+		return 0, errors.NewHTTPError(err)
+	}
+
+	return int(n), nil
+}
+
+// DeleteStaleLockouts removes lockouts which are not locked and whose failures
+// are forgotten anyway, see shared.LockoutResetAfter
+func (s *Store) DeleteStaleLockouts() (int, *errors.HTTPError) {
+	now := time.Now()
+	r, err := s.user.Exec(sqlDeleteStaleLockouts,
+		sql.Named("before", shared.NewUnixMilli(now.Add(-shared.LockoutResetAfter))),
+		sql.Named("now", shared.NewUnixMilli(now)),
+	)
+	if err != nil {
+		return 0, errors.NewHTTPError(err)
+	}
+
+	n, err := r.RowsAffected()
+	if err != nil {
+		return 0, errors.NewHTTPError(err)
+	}
+
+	return int(n), nil
+}
+
+// -----------------------------------------------------------------------------
+// Scan Helpers
+// -----------------------------------------------------------------------------
+
+// ScanLockout scans a database row into a Lockout struct
+func ScanLockout(row Scannable) (*shared.Lockout, *errors.HTTPError) {
+	var l shared.Lockout
+	err := row.Scan(
+		&l.Key,
+		&l.Failures,
+		&l.LastFailure,
+		&l.LockedUntil,
+	)
+	if err != nil {
+		return nil, errors.NewHTTPError(err)
+	}
+	return &l, nil
+}
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/knackwurstking/pg-press/internal/shared"
+)
+
+// failLogin records a failed form login with apiKey from ip
+func failLogin(t *testing.T, s *Store, ip, apiKey string) {
+	t.Helper()
+
+	s.RecordLoginAttempt(&shared.LoginAttempt{
+		Source:    shared.LoginSourceForm,
+		RealIP:    ip,
+		KeyPrefix: shared.ApiKeyPrefix(apiKey),
+		Reason:    "invalid API key",
+		CreatedAt: shared.NewUnixMilli(time.Now()),
+	}, shared.LoginLockoutKeys(ip, apiKey)...)
+}
+
+// loginLocked reports whether a login with apiKey from ip is locked
+func loginLocked(t *testing.T, s *Store, ip, apiKey string) bool {
+	t.Helper()
+
+	lockouts, herr := s.ListLockoutsByKeys(shared.LoginLockoutKeys(ip, apiKey)...)
+	if herr != nil {
+		t.Fatalf("list lockouts: %v", herr)
+	}
+	return shared.ActiveLockout(lockouts) != nil
+}
+
+// newLockoutUser adds a user with an API key and returns the key
+func newLockoutUser(t *testing.T, s *Store, id shared.TelegramID) string {
+	t.Helper()
+
+	if herr := s.AddUser(&shared.User{ID: id, Name: "Presser"}); herr != nil {
+		t.Fatalf("add user: %v", herr)
+	}
+
+	apiKey, err := shared.GenerateApiKey()
+	if err != nil {
+		t.Fatalf("generate api key: %v", err)
+	}
+	key, err := shared.NewApiKey(id, "Tablet", apiKey, 0)
+	if err != nil {
+		t.Fatalf("new api key: %v", err)
+	}
+	if herr := s.AddApiKey(key); herr != nil {
+		t.Fatalf("add api key: %v", herr)
+	}
+	return apiKey
+}
+
+func TestLoginGuessesLockTheIP(t *testing.T) {
+	s := newTestStore(t)
+
+	const (
+		ip    = "192.0.2.10"
+		other = "198.51.100.1"
+	)
+
+	for range shared.LockoutThresholdIP {
+		apiKey, err := shared.GenerateApiKey()
+		if err != nil {
+			t.Fatalf("generate api key: %v", err)
+		}
+		failLogin(t, s, ip, apiKey)
+	}
+
+	apiKey, err := shared.GenerateApiKey()
+	if err != nil {
+		t.Fatalf("generate api key: %v", err)
+	}
+	if !loginLocked(t, s, ip, apiKey) {
+		t.Error("IP is not locked after guessing different keys")
+	}
+	if loginLocked(t, s, other, apiKey) {
+		t.Error("another IP is locked")
+	}
+
+	// Unknown key prefixes add no lockouts of their own
+	lockouts, herr := s.ListLockouts()
+	if herr != nil {
+		t.Fatalf("list lockouts: %v", herr)
+	}
+	if len(lockouts) != 1 || lockouts[0].Key != shared.NewLockoutKeyIP(ip) {
+		t.Errorf("lockouts = %v, want only the IP", lockouts)
+	}
+}
+
+func TestLoginFailuresLockTheKeyFromTheIP(t *testing.T) {
+	s := newTestStore(t)
+
+	const (
+		proxy = "10.0.0.1"
+		other = "192.0.2.10"
+	)
+
+	keyA := newLockoutUser(t, s, 1)
+	keyB := newLockoutUser(t, s, 2)
+	wrongA := shared.ApiKeyPrefix(keyA) + strings.Repeat("x", len(keyA)-shared.ApiKeyPrefixLength)
+
+	for range shared.LockoutThreshold {
+		failLogin(t, s, proxy, wrongA)
+	}
+
+	if !loginLocked(t, s, proxy, keyA) {
+		t.Error("key A is not locked behind the proxy")
+	}
+	if loginLocked(t, s, proxy, keyB) {
+		t.Error("key B is locked behind the proxy by the failures of key A")
+	}
+
+	// The prefix of key A is locked for all IPs, but only for clients failing too
+	if loginLocked(t, s, other, keyA) {
+		t.Error("key A is locked from another IP without failures")
+	}
+	failLogin(t, s, other, wrongA)
+	if !loginLocked(t, s, other, keyA) {
+		t.Error("key A is not locked from another failing IP")
+	}
+
+	// Clearing the IP clears the lockouts of all keys from it
+	if herr := s.ClearLockout(proxy); herr != nil {
+		t.Fatalf("clear lockout: %v", herr)
+	}
+	for _, key := range []string{shared.NewLockoutKeyIP(proxy), shared.NewLockoutKeyIPApiKey(proxy, keyA)} {
+		if _, herr := s.GetLockout(key); herr == nil || !herr.IsNotFoundError() {
+			t.Errorf("lockout %s: got %v, want not found", key, herr)
+		}
+	}
+	if !loginLocked(t, s, other, keyA) {
+		t.Error("clearing the proxy IP cleared the lockout of key A from all IPs")
+	}
+}
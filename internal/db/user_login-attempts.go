@@ -0,0 +1,215 @@
+package db
+
+import (
+	"database/sql"
+	"log/slog"
+	"slices"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/shared"
+)
+
+// -----------------------------------------------------------------------------
+// Table Creation Statements
+// -----------------------------------------------------------------------------
+
+const (
+	sqlCreateLoginAttemptsTable string = `
+CREATE TABLE IF NOT EXISTS login_attempts (
+	id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL DEFAULT 0,
+	source TEXT NOT NULL,
+	real_ip TEXT NOT NULL,
+	user_agent TEXT NOT NULL DEFAULT '',
+	key_prefix TEXT NOT NULL DEFAULT '',
+	success INTEGER NOT NULL,
+	reason TEXT NOT NULL DEFAULT '',
+	created_at INTEGER NOT NULL,
+
+	PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_user_id ON login_attempts(user_id, created_at);`
+
+	sqlAddLoginAttempt string = `
+INSERT INTO login_attempts (user_id, source, real_ip, user_agent, key_prefix, success, reason, created_at)
+VALUES (:user_id, :source, :real_ip, :user_agent, :key_prefix, :success, :reason, :created_at);`
+
+	sqlListLoginAttemptsByUserID string = `
+SELECT id, user_id, source, real_ip, user_agent, key_prefix, success, reason, created_at
+FROM login_attempts
+WHERE user_id = :user_id
+ORDER BY created_at DESC, id DESC
+LIMIT :limit;`
+
+	sqlDeleteLoginAttemptsBefore string = `
+DELETE FROM login_attempts
+WHERE created_at < :before;`
+
+	sqlListApiKeyUserIDsByPrefix string = `
+SELECT DISTINCT user_id
+FROM api_keys
+WHERE prefix = :prefix;`
+)
+
+// -----------------------------------------------------------------------------
+// Login Attempt Functions
+// -----------------------------------------------------------------------------
+
+// RecordLoginAttempt adds the attempt to the login history and updates the
+// lockouts for keys, failures are counted, a success clears the lockouts.
+//
+// Failed attempts with an unknown user get assigned to the owner of the key
+// prefix, so guesses for the key of a user show up in the users history.
+// Failures with a key prefix nobody owns only count for the client IP, every
+// guess would add new lockouts otherwise. Errors are logged only, like with the
+// audit log.
+func (s *Store) RecordLoginAttempt(attempt *shared.LoginAttempt, keys ...string) {
+	if attempt.UserID == 0 && attempt.KeyPrefix != "" {
+		attempt.UserID = s.apiKeyPrefixOwner(attempt.KeyPrefix)
+	}
+	if !attempt.Success && attempt.UserID == 0 {
+		keys = slices.DeleteFunc(slices.Clone(keys), func(key string) bool {
+			return !shared.IsIPLockoutKey(key)
+		})
+	}
+
+	if herr := s.AddLoginAttempt(attempt); herr != nil {
+		slog.Error("Failed to add login attempt", "attempt", attempt.String(), "error", herr)
+	}
+
+	if attempt.Success {
+		if herr := s.ClearLockouts(keys...); herr != nil {
+			slog.Error("Failed to clear lockouts", "keys", keys, "error", herr)
+		}
+		return
+	}
+
+	if herr := s.AddLoginFailure(keys...); herr != nil {
+		slog.Error("Failed to count login failure", "keys", keys, "error", herr)
+	}
+}
+
+// AddLoginAttempt adds an entry to the login history
+func (s *Store) AddLoginAttempt(attempt *shared.LoginAttempt) *errors.HTTPError {
+	if verr := attempt.Validate(); verr != nil {
+		return verr.HTTPError().Wrap("invalid login attempt")
+	}
+
+	r, err := s.user.Exec(sqlAddLoginAttempt,
+		sql.Named("user_id", attempt.UserID),
+		sql.Named("source", attempt.Source),
+		sql.Named("real_ip", attempt.RealIP),
+		sql.Named("user_agent", attempt.UserAgent),
+		sql.Named("key_prefix", attempt.KeyPrefix),
+		sql.Named("success", attempt.Success),
+		sql.Named("reason", attempt.Reason),
+		sql.Named("created_at", attempt.CreatedAt),
+	)
+	if err != nil {
+		return errors.NewHTTPError(err)
+	}
+
+	if attempt.ID, err = lastInsertID(r); err != nil {
+		return errors.NewHTTPError(err)
+	}
+
+	return nil
+}
+
+// ListLoginAttemptsByUserID retrieves the latest login attempts of a user, newest first
+func (s *Store) ListLoginAttemptsByUserID(userID shared.TelegramID, limit int) ([]*shared.LoginAttempt, *errors.HTTPError) {
+	if limit <= 0 {
+		limit = -1 // No limit for SQLite
+	}
+
+	rows, err := s.user.Query(sqlListLoginAttemptsByUserID,
+		sql.Named("user_id", userID),
+		sql.Named("limit", limit),
+	)
+	if err != nil {
+		return nil, errors.NewHTTPError(err)
+	}
+	defer rows.Close()
+
+	attempts := []*shared.LoginAttempt{}
+	for rows.Next() {
+		a, herr := ScanLoginAttempt(rows)
+		if herr != nil {
+			return nil, herr
+		}
+		attempts = append(attempts, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.NewHTTPError(err)
+	}
+
+	return attempts, nil
+}
+
+// DeleteLoginAttemptsBefore removes the login history older than before and
+// returns the number of removed entries
+func (s *Store) DeleteLoginAttemptsBefore(before shared.UnixMilli) (int, *errors.HTTPError) {
+	r, err := s.user.Exec(sqlDeleteLoginAttemptsBefore, sql.Named("before", before))
+	if err != nil {
+		return 0, errors.NewHTTPError(err)
+	}
+
+	n, err := r.RowsAffected()
+	if err != nil {
+		return 0, errors.NewHTTPError(err)
+	}
+
+	return int(n), nil
+}
+
+// apiKeyPrefixOwner returns the user owning the keys with prefix, or 0 if
+// there is none or more than one
+func (s *Store) apiKeyPrefixOwner(prefix string) shared.TelegramID {
+	rows, err := s.user.Query(sqlListApiKeyUserIDsByPrefix, sql.Named("prefix", prefix))
+	if err != nil {
+		slog.Error("Failed to list api key owners", "prefix", prefix, "error", err)
+		return 0
+	}
+	defer rows.Close()
+
+	var owners []shared.TelegramID
+	for rows.Next() {
+		var id shared.TelegramID
+		if err = rows.Scan(&id); err != nil {
+			slog.Error("Failed to scan api key owner", "prefix", prefix, "error", err)
+			return 0
+		}
+		owners = append(owners, id)
+	}
+
+	if len(owners) != 1 {
+		return 0
+	}
+	return owners[0]
+}
+
+// -----------------------------------------------------------------------------
+// Scan Helpers
+// -----------------------------------------------------------------------------
+
+// ScanLoginAttempt scans a database row into a LoginAttempt struct
+func ScanLoginAttempt(row Scannable) (*shared.LoginAttempt, *errors.HTTPError) {
+	var a shared.LoginAttempt
+	err := row.Scan(
+		&a.ID,
+		&a.UserID,
+		&a.Source,
+		&a.RealIP,
+		&a.UserAgent,
+		&a.KeyPrefix,
+		&a.Success,
+		&a.Reason,
+		&a.CreatedAt,
+	)
+	if err != nil {
+		return nil, errors.NewHTTPError(err)
+	}
+	return &a, nil
+}
@@ -18,31 +18,37 @@ CREATE TABLE IF NOT EXISTS users (
 	name TEXT NOT NULL,
 	api_key TEXT NOT NULL UNIQUE,
 
+	PRIMARY KEY("id" AUTOINCREMENT)
+);`
+
+	// NOTE: API keys are stored hashed in the api_keys table, see migration 6 of the user database
+	sqlCreateUsersTableWithoutApiKey string = `
+CREATE TABLE users_new (
+	id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	last_feed INTEGER NOT NULL DEFAULT 0,
+	role TEXT NOT NULL DEFAULT 'operator',
+
 	PRIMARY KEY("id" AUTOINCREMENT)
 );`
 
 	sqlGetUser string = `
-SELECT id, name, api_key
+SELECT id, name, role
 FROM users
 WHERE id = :id;`
 
-	sqlGetUserByApiKey string = `
-SELECT id, name, api_key
-FROM users
-WHERE api_key = :api_key;`
-
 	sqlAddUser string = `
-INSERT INTO users (id, name, api_key)
-VALUES (:id, :name, :api_key);`
+INSERT INTO users (id, name, role)
+VALUES (:id, :name, :role);`
 
 	sqlUpdateUser string = `
 UPDATE users
 SET name = :name,
-	api_key = :api_key
+	role = :role
 WHERE id = :id;`
 
 	sqlListUsers string = `
-SELECT id, name, api_key
+SELECT id, name, role
 FROM users
 ORDER BY id ASC;`
 
@@ -56,25 +62,23 @@ WHERE id = :id;`
 // -----------------------------------------------------------------------------
 
 // GetUser retrieves a user by its ID
-func GetUser(id shared.TelegramID) (*shared.User, *errors.HTTPError) {
-	return ScanUser(dbUser.QueryRow(sqlGetUser, sql.Named("id", id)))
-}
-
-// GetUserByApiKey retrieves a user by its API key
-func GetUserByApiKey(apiKey string) (user *shared.User, merr *errors.HTTPError) {
-	return ScanUser(dbUser.QueryRow(sqlGetUserByApiKey, sql.Named("api_key", apiKey)))
+func (s *Store) GetUser(id shared.TelegramID) (*shared.User, *errors.HTTPError) {
+	return ScanUser(s.user.QueryRow(sqlGetUser, sql.Named("id", id)))
 }
 
 // AddUser adds a new user to the database
-func AddUser(user *shared.User) *errors.HTTPError {
+func (s *Store) AddUser(user *shared.User) *errors.HTTPError {
+	if user.Role == "" {
+		user.Role = shared.DefaultUserRole
+	}
 	if verr := user.Validate(); verr != nil {
 		return verr.HTTPError().Wrap("invalid user data")
 	}
 
-	_, err := dbUser.Exec(sqlAddUser,
+	_, err := s.user.Exec(sqlAddUser,
 		sql.Named("id", user.ID),
 		sql.Named("name", user.Name),
-		sql.Named("api_key", user.ApiKey),
+		sql.Named("role", user.Role),
 	)
 	if err != nil {
 		return errors.NewHTTPError(err)
@@ -83,15 +87,15 @@ func AddUser(user *shared.User) *errors.HTTPError {
 }
 
 // UpdateUser updates an existing user in the database
-func UpdateUser(user *shared.User) *errors.HTTPError {
+func (s *Store) UpdateUser(user *shared.User) *errors.HTTPError {
 	if verr := user.Validate(); verr != nil {
 		return verr.HTTPError().Wrap("invalid user data")
 	}
 
-	_, err := dbUser.Exec(sqlUpdateUser,
+	_, err := s.user.Exec(sqlUpdateUser,
 		sql.Named("id", user.ID),
 		sql.Named("name", user.Name),
-		sql.Named("api_key", user.ApiKey),
+		sql.Named("role", user.Role),
 	)
 	if err != nil {
 		return errors.NewHTTPError(err)
@@ -100,8 +104,8 @@ func UpdateUser(user *shared.User) *errors.HTTPError {
 }
 
 // ListUsers retrieves all users from the database
-func ListUsers() (users []*shared.User, merr *errors.HTTPError) {
-	rows, err := dbUser.Query(sqlListUsers)
+func (s *Store) ListUsers() (users []*shared.User, merr *errors.HTTPError) {
+	rows, err := s.user.Query(sqlListUsers)
 	if err != nil {
 		return nil, errors.NewHTTPError(err)
 	}
@@ -118,13 +122,23 @@ func ListUsers() (users []*shared.User, merr *errors.HTTPError) {
 	return users, nil
 }
 
-// DeleteUser removes a user from the database
-func DeleteUser(id shared.TelegramID) *errors.HTTPError {
-	_, err := dbUser.Exec(sqlDeleteUser, sql.Named("id", id))
-	if err != nil {
-		return errors.NewHTTPError(err)
+// ListUserNames retrieves the names of all users, mapped by their ID
+func (s *Store) ListUserNames() (map[shared.TelegramID]string, *errors.HTTPError) {
+	users, herr := s.ListUsers()
+	if herr != nil {
+		return nil, herr
 	}
-	return nil
+
+	names := make(map[shared.TelegramID]string, len(users))
+	for _, u := range users {
+		names[u.ID] = u.Name
+	}
+	return names, nil
+}
+
+// DeleteUser removes a user and all of its cookies and API keys from the database
+func (s *Store) DeleteUser(id shared.TelegramID) *errors.HTTPError {
+	return s.deleteWithCascade("user", "users", sqlDeleteUser, int64(id))
 }
 
 // -----------------------------------------------------------------------------
@@ -137,7 +151,7 @@ func ScanUser(row Scannable) (*shared.User, *errors.HTTPError) {
 	err := row.Scan(
 		&u.ID,
 		&u.Name,
-		&u.ApiKey,
+		&u.Role,
 	)
 	if err != nil {
 		return nil, errors.NewHTTPError(err)
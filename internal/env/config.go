@@ -0,0 +1,422 @@
+package env
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lmittmann/tint"
+)
+
+// ConfigFileName is the name of the configuration file in ConfigDir
+const ConfigFileName = "config.json"
+
+var (
+	// ConfigDir is the users config directory for the binary, the default
+	// location for the configuration file and the databases
+	ConfigDir string
+
+	// ConfigFile is the configuration file, CONFIG_FILE or ConfigFileName in ConfigDir
+	ConfigFile string
+
+	// Current is the resolved configuration, the file overridden by the
+	// environment variables, command line flags override the package variables
+	Current *Config
+
+	// ConfigErr is set if the configuration file could not be loaded or the
+	// resolved configuration is invalid, the server refuses to start then
+	ConfigErr error
+)
+
+// Config is the server configuration file, all fields are optional and
+// default to DefaultConfig
+type Config struct {
+	Address     string `json:"address"`      // Address is SERVER_ADDR, "<host>:<port>"
+	PathPrefix  string `json:"path_prefix"`  // PathPrefix is SERVER_PATH_PREFIX, for example "/pg-press"
+	DBPath      string `json:"db_path"`      // DBPath is SERVER_PATH_DB, the directory of the databases
+	ImagesPath  string `json:"images_path"`  // ImagesPath is SERVER_PATH_IMAGES
+	BackupsPath string `json:"backups_path"` // BackupsPath is SERVER_PATH_BACKUPS
+
+	// Admins are the telegram ids of users always being admins, ADMINS is a
+	// comma separated list
+	Admins []int64 `json:"admins"`
+
+	// TrustedProxies are the IPs or CIDR ranges of reverse proxies allowed to set
+	// the client IP with X-Forwarded-For, TRUSTED_PROXIES is a comma separated
+	// list. Without any the headers are ignored.
+	TrustedProxies []string `json:"trusted_proxies"`
+
+	Log  ConfigLog  `json:"log"`
+	TLS  ConfigTLS  `json:"tls"`
+	Jobs ConfigJobs `json:"jobs"`
+
+	TrashRetentionDays        int `json:"trash_retention_days"`         // TRASH_RETENTION_DAYS
+	LoginHistoryRetentionDays int `json:"login_history_retention_days"` // LOGIN_HISTORY_RETENTION_DAYS
+}
+
+type ConfigLog struct {
+	Level  string `json:"level"`  // Level is LOG_LEVEL, "debug", "info", "warn" or "error", VERBOSE=true is "debug"
+	Format string `json:"format"` // Format is LOG_FORMAT, "text" or "json"
+}
+
+// ConfigTLS enables HTTPS if both files are set
+type ConfigTLS struct {
+	CertFile string `json:"cert_file"` // CertFile is SERVER_TLS_CERT
+	KeyFile  string `json:"key_file"`  // KeyFile is SERVER_TLS_KEY
+}
+
+// ConfigJobs are the job schedules, see jobs.ParseSchedule for the format,
+// "off" disables a job
+type ConfigJobs struct {
+	CookieCleanup     string `json:"cookie_cleanup"`     // JOB_COOKIE_CLEANUP
+	DBSnapshot        string `json:"db_snapshot"`        // JOB_DB_SNAPSHOT
+	AttachmentCleanup string `json:"attachment_cleanup"` // JOB_ATTACHMENT_CLEANUP
+	TrashPurge        string `json:"trash_purge"`        // JOB_TRASH_PURGE
+	LoginCleanup      string `json:"login_cleanup"`      // JOB_LOGIN_CLEANUP
+}
+
+// Schedules returns the schedules by their key in the configuration file
+func (j ConfigJobs) Schedules() map[string]string {
+	return map[string]string{
+		"cookie_cleanup":     j.CookieCleanup,
+		"db_snapshot":        j.DBSnapshot,
+		"attachment_cleanup": j.AttachmentCleanup,
+		"trash_purge":        j.TrashPurge,
+		"login_cleanup":      j.LoginCleanup,
+	}
+}
+
+func init() {
+	p, err := os.UserConfigDir()
+	if err != nil {
+		panic(err)
+	}
+	// The binary name keeps separate databases for differently named builds
+	ConfigDir = filepath.Join(p, filepath.Base(os.Args[0]))
+	if err := os.MkdirAll(ConfigDir, 0700); err != nil {
+		panic(err)
+	}
+
+	ConfigFile = getenv("CONFIG_FILE", filepath.Join(ConfigDir, ConfigFileName))
+
+	Current, ConfigErr = ResolveConfig(ConfigFile)
+	Current.Apply()
+}
+
+// DefaultConfig returns the configuration used without configuration file and
+// environment variables
+func DefaultConfig() *Config {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		panic(err)
+	}
+
+	return &Config{
+		DBPath:         ConfigDir,
+		ImagesPath:     filepath.Join(home, "."+Name, "images"),
+		BackupsPath:    filepath.Join(home, "."+Name, "backups"),
+		Admins:         []int64{},
+		TrustedProxies: []string{},
+		Log: ConfigLog{
+			Level:  "info",
+			Format: "text",
+		},
+		Jobs: ConfigJobs{
+			CookieCleanup:     "0 3 * * *",
+			DBSnapshot:        "0 2 * * *",
+			AttachmentCleanup: "0 4 * * 0",
+			TrashPurge:        "30 3 * * *",
+			LoginCleanup:      "15 3 * * *",
+		},
+		TrashRetentionDays:        30,
+		LoginHistoryRetentionDays: 90,
+	}
+}
+
+// LoadConfig reads the configuration file at path over the defaults, a missing
+// file is not an error. Unknown keys are rejected to catch typos.
+func LoadConfig(path string) (*Config, error) {
+	c := DefaultConfig()
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return c, fmt.Errorf("open config: %w", err)
+	}
+	defer f.Close()
+
+	d := json.NewDecoder(f)
+	d.DisallowUnknownFields()
+	if err := d.Decode(c); err != nil {
+		return DefaultConfig(), fmt.Errorf("parse config %s: %w", path, err)
+	}
+
+	c.DBPath = expandHome(c.DBPath)
+	c.ImagesPath = expandHome(c.ImagesPath)
+	c.BackupsPath = expandHome(c.BackupsPath)
+	c.TLS.CertFile = expandHome(c.TLS.CertFile)
+	c.TLS.KeyFile = expandHome(c.TLS.KeyFile)
+
+	return c, nil
+}
+
+// ResolveConfig loads the configuration file at path, applies the environment
+// variables and validates the result. The returned configuration is usable
+// even on errors, falling back to the defaults if the file is broken.
+func ResolveConfig(path string) (*Config, error) {
+	c, err := LoadConfig(path)
+	return c, errors.Join(err, c.ApplyEnv(), c.Validate())
+}
+
+// ApplyEnv overrides the configuration with the environment variables which are set
+func (c *Config) ApplyEnv() error {
+	var errs []error
+
+	c.Address = getenv("SERVER_ADDR", c.Address)
+	c.PathPrefix = getenv("SERVER_PATH_PREFIX", c.PathPrefix)
+	c.DBPath = getenv("SERVER_PATH_DB", c.DBPath)
+	c.ImagesPath = getenv("SERVER_PATH_IMAGES", c.ImagesPath)
+	c.BackupsPath = getenv("SERVER_PATH_BACKUPS", c.BackupsPath)
+
+	if v := os.Getenv("ADMINS"); v != "" {
+		c.Admins = []int64{}
+		for s := range strings.SplitSeq(v, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid ADMINS: %q", v))
+				break
+			}
+			c.Admins = append(c.Admins, id)
+		}
+	}
+
+	if v := os.Getenv("TRUSTED_PROXIES"); v != "" {
+		c.TrustedProxies = []string{}
+		for s := range strings.SplitSeq(v, ",") {
+			c.TrustedProxies = append(c.TrustedProxies, strings.TrimSpace(s))
+		}
+	}
+
+	if os.Getenv("VERBOSE") == "true" {
+		c.Log.Level = "debug"
+	}
+	c.Log.Level = getenv("LOG_LEVEL", c.Log.Level)
+	c.Log.Format = getenv("LOG_FORMAT", c.Log.Format)
+
+	c.TLS.CertFile = getenv("SERVER_TLS_CERT", c.TLS.CertFile)
+	c.TLS.KeyFile = getenv("SERVER_TLS_KEY", c.TLS.KeyFile)
+
+	c.Jobs.CookieCleanup = getenv("JOB_COOKIE_CLEANUP", c.Jobs.CookieCleanup)
+	c.Jobs.DBSnapshot = getenv("JOB_DB_SNAPSHOT", c.Jobs.DBSnapshot)
+	c.Jobs.AttachmentCleanup = getenv("JOB_ATTACHMENT_CLEANUP", c.Jobs.AttachmentCleanup)
+	c.Jobs.TrashPurge = getenv("JOB_TRASH_PURGE", c.Jobs.TrashPurge)
+	c.Jobs.LoginCleanup = getenv("JOB_LOGIN_CLEANUP", c.Jobs.LoginCleanup)
+
+	for _, e := range []struct {
+		key  string
+		days *int
+	}{
+		{"TRASH_RETENTION_DAYS", &c.TrashRetentionDays},
+		{"LOGIN_HISTORY_RETENTION_DAYS", &c.LoginHistoryRetentionDays},
+	} {
+		v := os.Getenv(e.key)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s: %q", e.key, v))
+			continue
+		}
+		*e.days = n
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate checks the configuration, the job schedules are checked with
+// jobs.ParseSchedule by the caller because jobs depends on env
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Address != "" {
+		if _, _, err := net.SplitHostPort(c.Address); err != nil {
+			errs = append(errs, fmt.Errorf("invalid address %q: %w", c.Address, err))
+		}
+	}
+
+	if c.PathPrefix != "" && (!strings.HasPrefix(c.PathPrefix, "/") || strings.HasSuffix(c.PathPrefix, "/")) {
+		errs = append(errs, fmt.Errorf(
+			"invalid path_prefix %q: must start and must not end with a slash", c.PathPrefix))
+	}
+
+	if c.DBPath == "" {
+		errs = append(errs, fmt.Errorf("db_path is required"))
+	}
+	if c.ImagesPath == "" {
+		errs = append(errs, fmt.Errorf("images_path is required"))
+	}
+	if c.BackupsPath == "" {
+		errs = append(errs, fmt.Errorf("backups_path is required"))
+	}
+
+	for _, id := range c.Admins {
+		if id <= 0 {
+			errs = append(errs, fmt.Errorf("invalid admin telegram id %d", id))
+		}
+	}
+
+	if _, err := c.trustedProxies(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := c.Log.level(); err != nil {
+		errs = append(errs, err)
+	}
+	switch c.Log.Format {
+	case "text", "json":
+	default:
+		errs = append(errs, fmt.Errorf("invalid log format %q: must be \"text\" or \"json\"", c.Log.Format))
+	}
+
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		errs = append(errs, fmt.Errorf("tls needs both cert_file and key_file"))
+	}
+	for _, file := range []string{c.TLS.CertFile, c.TLS.KeyFile} {
+		if file == "" {
+			continue
+		}
+		if _, err := os.Stat(file); err != nil {
+			errs = append(errs, fmt.Errorf("tls file: %w", err))
+		}
+	}
+
+	schedules := c.Jobs.Schedules()
+	for _, key := range slices.Sorted(maps.Keys(schedules)) {
+		if strings.TrimSpace(schedules[key]) == "" {
+			errs = append(errs, fmt.Errorf("jobs.%s is required, use \"off\" to disable the job", key))
+		}
+	}
+
+	if c.TrashRetentionDays < 1 {
+		errs = append(errs, fmt.Errorf("trash_retention_days must be at least 1"))
+	}
+	if c.LoginHistoryRetentionDays < 1 {
+		errs = append(errs, fmt.Errorf("login_history_retention_days must be at least 1"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Apply sets the package variables and the default logger, and creates the
+// images directory
+func (c *Config) Apply() {
+	ids := make([]string, 0, len(c.Admins))
+	for _, id := range c.Admins {
+		ids = append(ids, strconv.FormatInt(id, 10))
+	}
+	Admins = strings.Join(ids, ",")
+
+	ServerAddress = c.Address
+	ServerPathPrefix = c.PathPrefix
+	ServerPathDB = c.DBPath
+	ServerPathImages = c.ImagesPath
+	ServerPathBackups = c.BackupsPath
+	ServerTLSCert = c.TLS.CertFile
+	ServerTLSKey = c.TLS.KeyFile
+	ServerTrustedProxies, _ = c.trustedProxies()
+
+	JobCookieCleanup = c.Jobs.CookieCleanup
+	JobDBSnapshot = c.Jobs.DBSnapshot
+	JobAttachmentCleanup = c.Jobs.AttachmentCleanup
+	JobTrashPurge = c.Jobs.TrashPurge
+	JobLoginCleanup = c.Jobs.LoginCleanup
+
+	TrashRetentionDays = c.TrashRetentionDays
+	LoginHistoryRetentionDays = c.LoginHistoryRetentionDays
+
+	level, err := c.Log.level()
+	if err != nil {
+		level = slog.LevelInfo
+	}
+	if c.Log.Format == "json" {
+		slog.SetDefault(slog.New(
+			slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: level}),
+		))
+	} else {
+		slog.SetDefault(slog.New(
+			tint.NewHandler(os.Stderr, &tint.Options{
+				Level:      level,
+				TimeFormat: time.Kitchen,
+			}),
+		))
+	}
+
+	if ServerPathImages != "" {
+		if err := os.MkdirAll(ServerPathImages, 0700); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (l ConfigLog) level() (slog.Level, error) {
+	switch l.Level {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf(
+			"invalid log level %q: must be \"debug\", \"info\", \"warn\" or \"error\"", l.Level)
+	}
+}
+
+// trustedProxies parses the trusted proxies, a single IP is a range of its own
+func (c *Config) trustedProxies() ([]*net.IPNet, error) {
+	ranges := make([]*net.IPNet, 0, len(c.TrustedProxies))
+	for _, p := range c.TrustedProxies {
+		if ip := net.ParseIP(p); ip != nil {
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			ranges = append(ranges, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: must be an IP or a CIDR range", p)
+		}
+		ranges = append(ranges, ipNet)
+	}
+	return ranges, nil
+}
+
+// expandHome replaces a leading "~/" with the users home directory
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
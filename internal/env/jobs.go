@@ -0,0 +1,30 @@
+package env
+
+import (
+	"os"
+)
+
+// Job schedules, see jobs.ParseSchedule for the format, "off" disables a job
+var (
+	JobCookieCleanup     string
+	JobDBSnapshot        string
+	JobAttachmentCleanup string
+	JobTrashPurge        string
+	JobLoginCleanup      string
+
+	// TrashRetentionDays is the number of days deleted entities stay in the trash
+	TrashRetentionDays int
+
+	// LoginHistoryRetentionDays is the number of days login attempts are kept
+	LoginHistoryRetentionDays int
+
+	// ServerPathBackups is the directory for the database snapshots
+	ServerPathBackups string
+)
+
+func getenv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
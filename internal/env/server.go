@@ -1,50 +1,22 @@
 package env
 
-import (
-	"fmt"
-	"log/slog"
-	"os"
-	"time"
-
-	"github.com/lmittmann/tint"
-)
+import "net"
 
 const (
 	Name = "pg-press"
 )
 
+// Server settings, resolved from the configuration file and the environment
+// variables, see Config
 var (
-	Admins           = os.Getenv("ADMINS")
-	ServerAddress    = os.Getenv("SERVER_ADDR")
-	ServerPathPrefix = os.Getenv("SERVER_PATH_PREFIX")
-	ServerPathImages = os.Getenv("SERVER_PATH_IMAGES")
-	Verbose          = os.Getenv("VERBOSE") == "true"
+	Admins           string // Admins is a comma separated list of telegram ids
+	ServerAddress    string
+	ServerPathPrefix string
+	ServerPathDB     string
+	ServerPathImages string
+	ServerTLSCert    string
+	ServerTLSKey     string
+
+	// ServerTrustedProxies are the reverse proxies allowed to set the client IP
+	ServerTrustedProxies []*net.IPNet
 )
-
-func init() {
-	level := slog.LevelInfo
-	if Verbose {
-		level = slog.LevelDebug
-	}
-	slog.SetDefault(slog.New(
-		tint.NewHandler(os.Stderr, &tint.Options{
-			Level:      level,
-			TimeFormat: time.Kitchen,
-		}),
-	))
-
-	home, err := os.UserHomeDir()
-	if err != nil {
-		panic(err)
-	}
-
-	if ServerPathImages == "" {
-		ServerPathImages = fmt.Sprintf("%s/.%s/images", home, Name)
-	}
-
-	if _, err := os.Stat(ServerPathImages); os.IsNotExist(err) {
-		if err = os.MkdirAll(ServerPathImages, 0700); err != nil {
-			panic(err)
-		}
-	}
-}
@@ -2,6 +2,7 @@ package errors
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -29,6 +30,48 @@ func (a *AuthorizationError) HTTPError() *HTTPError {
 	return NewHTTPError(a)
 }
 
+// PermissionError represents a missing permission of an authorized user
+type PermissionError struct {
+	Message string
+}
+
+// NewPermissionError creates a new permission error
+func NewPermissionError(format string, v ...any) *PermissionError {
+	return &PermissionError{
+		Message: fmt.Sprintf(format, v...),
+	}
+}
+
+func (p *PermissionError) Error() string {
+	return p.Message
+}
+
+// HTTPError converts PermissionError to HTTPError with forbidden status
+func (p *PermissionError) HTTPError() *HTTPError {
+	return NewHTTPError(p)
+}
+
+// RateLimitError represents a request rejected because of too many failed attempts
+type RateLimitError struct {
+	Message string
+}
+
+// NewRateLimitError creates a new rate limit error
+func NewRateLimitError(format string, v ...any) *RateLimitError {
+	return &RateLimitError{
+		Message: fmt.Sprintf(format, v...),
+	}
+}
+
+func (r *RateLimitError) Error() string {
+	return r.Message
+}
+
+// HTTPError converts RateLimitError to HTTPError with too many requests status
+func (r *RateLimitError) HTTPError() *HTTPError {
+	return NewHTTPError(r)
+}
+
 // NotFoundError represents a resource not found error
 type NotFoundError struct {
 	Message string
@@ -53,6 +96,7 @@ func (n *NotFoundError) HTTPError() *HTTPError {
 // ValidationError represents a validation error
 type ValidationError struct {
 	Message string
+	Fields  InputErrors // Fields holds the errors per field, see InputErrors
 }
 
 // NewValidationError creates a new validation error
@@ -133,6 +177,10 @@ func NewHTTPError(err error) *HTTPError {
 			code = http.StatusConflict
 		case *AuthorizationError:
 			code = http.StatusUnauthorized
+		case *PermissionError:
+			code = http.StatusForbidden
+		case *RateLimitError:
+			code = http.StatusTooManyRequests
 		}
 	}
 
@@ -173,7 +221,7 @@ func (e *HTTPError) Wrap(format string, a ...any) *HTTPError {
 	if msg == "" {
 		return e
 	}
-	wrapped := fmt.Errorf("%s: %v", msg, e.err)
+	wrapped := fmt.Errorf("%s: %w", msg, e.err)
 	return &HTTPError{err: wrapped, code: e.code}
 }
 
@@ -188,6 +236,15 @@ func (e *HTTPError) WrapEcho(format string, a ...any) *echo.HTTPError {
 	return echo.NewHTTPError(e.code, fmt.Sprintf("%s: %s", msg, e.err.Error()))
 }
 
+// ValidationError returns the (wrapped) validation error, nil for other errors
+func (e *HTTPError) ValidationError() *ValidationError {
+	var verr *ValidationError
+	if errors.As(e.err, &verr) {
+		return verr
+	}
+	return nil
+}
+
 // IsValidationError checks if the error is a validation error
 func (e *HTTPError) IsValidationError() bool {
 	_, ok := e.err.(*ValidationError)
@@ -205,3 +262,49 @@ func (e *HTTPError) IsNotFoundError() bool {
 	_, ok := e.err.(*NotFoundError)
 	return ok || e.code == http.StatusNotFound
 }
+
+// Severity returns "error" for server errors and "warning" for client errors,
+// used for the alerts of failed HTMX requests
+func (e *HTTPError) Severity() string {
+	if e.code >= http.StatusInternalServerError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Title returns a short (german) description of the status code, used for
+// error pages and alerts
+func (e *HTTPError) Title() string {
+	switch e.code {
+	case http.StatusBadRequest:
+		return "Ungültige Eingabe"
+	case http.StatusUnauthorized:
+		return "Nicht angemeldet"
+	case http.StatusForbidden:
+		return "Keine Berechtigung"
+	case http.StatusNotFound:
+		return "Nicht gefunden"
+	case http.StatusMethodNotAllowed:
+		return "Nicht erlaubt"
+	case http.StatusConflict:
+		return "Existiert bereits"
+	case http.StatusTooManyRequests:
+		return "Zu viele Anfragen"
+	case http.StatusServiceUnavailable:
+		return "Nicht verfügbar"
+	}
+	if e.code >= http.StatusInternalServerError {
+		return "Serverfehler"
+	}
+	return "Fehler"
+}
+
+// ClientMessage returns the message sent to clients, server errors get a
+// generic message, their error text may contain SQL or file paths and is only
+// logged on the server
+func (e *HTTPError) ClientMessage() string {
+	if e.code >= http.StatusInternalServerError {
+		return "internal server error"
+	}
+	return e.Error()
+}
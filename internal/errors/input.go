@@ -1,5 +1,10 @@
 package errors
 
+import (
+	"fmt"
+	"strings"
+)
+
 type InputError struct {
 	InputID string
 	Message string
@@ -15,3 +20,30 @@ func NewInputError(inputID, message string) *InputError {
 func (e *InputError) Error() string {
 	return e.Message
 }
+
+// InputErrors collects errors per input, the Validate methods of the entities
+// use the JSON field names as input IDs
+type InputErrors []*InputError
+
+// Add adds an error for the input
+func (ie *InputErrors) Add(inputID, format string, v ...any) {
+	*ie = append(*ie, NewInputError(inputID, fmt.Sprintf(format, v...)))
+}
+
+// ValidationError returns the collected errors as validation error, nil if
+// there are no errors
+func (ie InputErrors) ValidationError() *ValidationError {
+	if len(ie) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(ie))
+	for _, e := range ie {
+		messages = append(messages, e.Message)
+	}
+
+	return &ValidationError{
+		Message: strings.Join(messages, ", "),
+		Fields:  ie,
+	}
+}
@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/handlers/admin/templates"
+	"github.com/knackwurstking/pg-press/internal/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) HTMXGetJobs(c echo.Context) *echo.HTTPError {
+	if _, eerr := getAdminFromContext(c); eerr != nil {
+		return eerr
+	}
+
+	t := templates.Jobs(h.jobs.List())
+	if err := t.Render(c.Request().Context(), c.Response()); err != nil {
+		return errors.NewRenderError(err, "Jobs")
+	}
+
+	return nil
+}
+
+func (h *Handler) HTMXPostRunJob(c echo.Context) *echo.HTTPError {
+	user, eerr := getAdminFromContext(c)
+	if eerr != nil {
+		return eerr
+	}
+
+	name, herr := utils.GetQueryString(c, "name")
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	if err := h.jobs.RunNow(name); err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	slog.Info("Job started manually", "job", name, "user_name", user.Name)
+
+	return h.HTMXGetJobs(c)
+}
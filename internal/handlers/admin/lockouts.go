@@ -0,0 +1,48 @@
+package admin
+
+import (
+	"log/slog"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/handlers/admin/templates"
+	"github.com/knackwurstking/pg-press/internal/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) HTMXGetLockouts(c echo.Context) *echo.HTTPError {
+	if _, eerr := getAdminFromContext(c); eerr != nil {
+		return eerr
+	}
+
+	lockouts, herr := h.db.ListLockouts()
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	t := templates.Lockouts(lockouts)
+	if err := t.Render(c.Request().Context(), c.Response()); err != nil {
+		return errors.NewRenderError(err, "Lockouts")
+	}
+
+	return nil
+}
+
+func (h *Handler) HTMXDeleteLockout(c echo.Context) *echo.HTTPError {
+	user, eerr := getAdminFromContext(c)
+	if eerr != nil {
+		return eerr
+	}
+
+	key, herr := utils.GetQueryString(c, "key")
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	if herr = h.db.ClearLockout(key); herr != nil {
+		return herr.Echo()
+	}
+	slog.Info("Lockout cleared", "key", key, "user_name", user.Name)
+
+	return h.HTMXGetLockouts(c)
+}
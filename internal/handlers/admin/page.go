@@ -0,0 +1,41 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/handlers/admin/templates"
+	"github.com/knackwurstking/pg-press/internal/shared"
+	"github.com/knackwurstking/pg-press/internal/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) GetAdminPage(c echo.Context) *echo.HTTPError {
+	user, eerr := getAdminFromContext(c)
+	if eerr != nil {
+		return eerr
+	}
+
+	t := templates.Page(templates.PageProps{User: user})
+	if err := t.Render(c.Request().Context(), c.Response()); err != nil {
+		return errors.NewRenderError(err, "Admin Page")
+	}
+
+	return nil
+}
+
+// getAdminFromContext returns the user from context, if the user is an administrator
+func getAdminFromContext(c echo.Context) (*shared.User, *echo.HTTPError) {
+	user, herr := utils.GetUserFromContext(c)
+	if herr != nil {
+		return nil, herr.Echo()
+	}
+
+	if !user.IsAdmin() {
+		return nil, errors.NewAuthorizationError("administrator privileges required").
+			HTTPError().SetCode(http.StatusForbidden).Echo()
+	}
+
+	return user, nil
+}
@@ -0,0 +1,41 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/knackwurstking/pg-press/internal/db"
+	"github.com/knackwurstking/pg-press/internal/env"
+	"github.com/knackwurstking/pg-press/internal/jobs"
+
+	"github.com/knackwurstking/ui"
+	"github.com/labstack/echo/v4"
+)
+
+// Repository contains the database operations used by the admin route handlers.
+type Repository interface {
+	db.ToolRepository
+	db.LoginRepository
+	db.AuditRepository
+}
+
+// Handler holds the dependencies of all admin route handlers.
+type Handler struct {
+	db   Repository
+	jobs *jobs.Scheduler
+}
+
+func Register(e *echo.Echo, path string, store db.Repository, scheduler *jobs.Scheduler) {
+	h := &Handler{db: store, jobs: scheduler}
+
+	ui.RegisterEchoRoutes(e, env.ServerPathPrefix, []*ui.EchoRoute{
+		ui.NewEchoRoute(http.MethodGet, path, h.GetAdminPage),
+		ui.NewEchoRoute(http.MethodGet, path+"/jobs", h.HTMXGetJobs),
+		ui.NewEchoRoute(http.MethodPost, path+"/jobs/run", h.HTMXPostRunJob),
+		ui.NewEchoRoute(http.MethodGet, path+"/lockouts", h.HTMXGetLockouts),
+		ui.NewEchoRoute(http.MethodDelete, path+"/lockouts", h.HTMXDeleteLockout),
+		ui.NewEchoRoute(http.MethodGet, path+"/tool-thresholds", h.HTMXGetToolThresholds),
+		ui.NewEchoRoute(http.MethodPost, path+"/tool-thresholds", h.HTMXPostToolThreshold),
+		ui.NewEchoRoute(http.MethodPut, path+"/tool-thresholds", h.HTMXPutToolThreshold),
+		ui.NewEchoRoute(http.MethodDelete, path+"/tool-thresholds", h.HTMXDeleteToolThreshold),
+	})
+}
@@ -0,0 +1,177 @@
+package admin
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/handlers/admin/templates"
+	"github.com/knackwurstking/pg-press/internal/shared"
+	"github.com/knackwurstking/pg-press/internal/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HTMXGetToolThresholds renders the thresholds with the form, the form is filled
+// with the threshold from the "id" query parameter for editing
+func (h *Handler) HTMXGetToolThresholds(c echo.Context) *echo.HTTPError {
+	if _, eerr := getAdminFromContext(c); eerr != nil {
+		return eerr
+	}
+
+	tt := &shared.ToolThreshold{}
+	if id, _ := utils.GetQueryInt64(c, "id"); id > 0 {
+		var herr *errors.HTTPError
+		tt, herr = h.db.GetToolThreshold(shared.EntityID(id))
+		if herr != nil {
+			return herr.Echo()
+		}
+	}
+
+	return h.renderToolThresholds(c, tt)
+}
+
+func (h *Handler) HTMXPostToolThreshold(c echo.Context) *echo.HTTPError {
+	user, eerr := getAdminFromContext(c)
+	if eerr != nil {
+		return eerr
+	}
+
+	tt, ierrs := parseToolThresholdForm(c)
+	if len(ierrs) > 0 {
+		return h.renderToolThresholds(c, tt, ierrs...)
+	}
+
+	if herr := h.db.AddToolThreshold(tt); herr != nil {
+		return h.renderToolThresholds(c, tt, toolThresholdInputErrors(herr)...)
+	}
+	slog.Info("Tool threshold added", "tool_threshold", tt.String(), "user_name", user.Name)
+	h.db.Audit(user.ID, shared.AuditActionCreate, nil, tt)
+
+	return h.renderToolThresholds(c, &shared.ToolThreshold{})
+}
+
+func (h *Handler) HTMXPutToolThreshold(c echo.Context) *echo.HTTPError {
+	user, eerr := getAdminFromContext(c)
+	if eerr != nil {
+		return eerr
+	}
+
+	id, herr := utils.GetQueryInt64(c, "id")
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	tt, ierrs := parseToolThresholdForm(c)
+	tt.ID = shared.EntityID(id)
+	if len(ierrs) > 0 {
+		return h.renderToolThresholds(c, tt, ierrs...)
+	}
+
+	before, herr := h.db.GetToolThreshold(tt.ID)
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	if herr = h.db.UpdateToolThreshold(tt); herr != nil {
+		return h.renderToolThresholds(c, tt, toolThresholdInputErrors(herr)...)
+	}
+	slog.Info("Tool threshold updated", "tool_threshold", tt.String(), "user_name", user.Name)
+	h.db.Audit(user.ID, shared.AuditActionUpdate, before, tt)
+
+	return h.renderToolThresholds(c, &shared.ToolThreshold{})
+}
+
+func (h *Handler) HTMXDeleteToolThreshold(c echo.Context) *echo.HTTPError {
+	user, eerr := getAdminFromContext(c)
+	if eerr != nil {
+		return eerr
+	}
+
+	id, herr := utils.GetQueryInt64(c, "id")
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	tt, herr := h.db.GetToolThreshold(shared.EntityID(id))
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	if herr = h.db.DeleteToolThreshold(tt.ID); herr != nil {
+		return herr.Echo()
+	}
+	slog.Info("Tool threshold deleted", "tool_threshold", tt.String(), "user_name", user.Name)
+	h.db.Audit(user.ID, shared.AuditActionDelete, tt, nil)
+
+	return h.renderToolThresholds(c, &shared.ToolThreshold{})
+}
+
+// renderToolThresholds renders the list and the form, input errors are shown
+// in the form
+func (h *Handler) renderToolThresholds(c echo.Context, form *shared.ToolThreshold, ierrs ...*errors.InputError) *echo.HTTPError {
+	thresholds, herr := h.db.ListToolThresholds()
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	t := templates.ToolThresholds(templates.ToolThresholdsProps{
+		Thresholds: thresholds,
+		Form:       form,
+		Error:      ierrs,
+	})
+	if err := t.Render(c.Request().Context(), c.Response()); err != nil {
+		return errors.NewRenderError(err, "ToolThresholds")
+	}
+	if len(ierrs) > 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid input")
+	}
+	return nil
+}
+
+func parseToolThresholdForm(c echo.Context) (tt *shared.ToolThreshold, ierrs []*errors.InputError) {
+	tt = &shared.ToolThreshold{
+		Type: utils.SanitizeText(c.FormValue("type")),
+	}
+
+	var err error
+	tt.Width, err = utils.SanitizeInt(c.FormValue("width"))
+	if err != nil {
+		ierrs = append(ierrs, errors.NewInputError("width", fmt.Sprintf("Invalid width: %s", c.FormValue("width"))))
+	}
+
+	tt.Height, err = utils.SanitizeInt(c.FormValue("height"))
+	if err != nil {
+		ierrs = append(ierrs, errors.NewInputError("width", fmt.Sprintf("Invalid height: %s", c.FormValue("height"))))
+	}
+
+	position, err := utils.SanitizeInt(c.FormValue("position"))
+	if err != nil {
+		ierrs = append(ierrs, errors.NewInputError("position", fmt.Sprintf("Invalid position: %s", c.FormValue("position"))))
+	}
+	tt.Position = shared.Slot(position)
+
+	tt.Warning, err = utils.SanitizeInt64(c.FormValue("warning"))
+	if err != nil {
+		ierrs = append(ierrs, errors.NewInputError("warning", fmt.Sprintf("Invalid warning threshold: %s", c.FormValue("warning"))))
+	}
+
+	tt.Error, err = utils.SanitizeInt64(c.FormValue("error"))
+	if err != nil {
+		ierrs = append(ierrs, errors.NewInputError("error", fmt.Sprintf("Invalid error threshold: %s", c.FormValue("error"))))
+	}
+
+	slog.Debug("Tool threshold form values", "tool_threshold", tt.String())
+
+	return
+}
+
+// toolThresholdInputErrors converts the error of a failed database operation to
+// the input errors of the form, the validated fields match the input IDs
+func toolThresholdInputErrors(herr *errors.HTTPError) []*errors.InputError {
+	if verr := herr.ValidationError(); verr != nil && len(verr.Fields) > 0 {
+		return verr.Fields
+	}
+	return []*errors.InputError{errors.NewInputError("", herr.Error())}
+}
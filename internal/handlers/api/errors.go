@@ -0,0 +1,95 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/knackwurstking/pg-press/internal/env"
+	"github.com/knackwurstking/pg-press/internal/errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ErrorResponse is the body of all failed API requests
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody describes what went wrong, Type is one of "validation", "not_found",
+// "exists", "unauthorized", "forbidden", "too_many_requests" or "internal".
+// Fields holds the messages per JSON field of failed validations.
+type ErrorBody struct {
+	Status  int               `json:"status"`
+	Type    string            `json:"type"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+// NewErrorResponse creates the error response for an HTTPError
+func NewErrorResponse(herr *errors.HTTPError) *ErrorResponse {
+	t := "internal"
+	switch {
+	case herr.IsValidationError():
+		t = "validation"
+	case herr.IsNotFoundError():
+		t = "not_found"
+	case herr.IsExistsError():
+		t = "exists"
+	case herr.Code() == http.StatusUnauthorized:
+		t = "unauthorized"
+	case herr.Code() == http.StatusForbidden:
+		t = "forbidden"
+	case herr.Code() == http.StatusTooManyRequests:
+		t = "too_many_requests"
+	}
+
+	var fields map[string]string
+	if verr := herr.ValidationError(); verr != nil && len(verr.Fields) > 0 {
+		fields = make(map[string]string, len(verr.Fields))
+		for _, f := range verr.Fields {
+			if _, ok := fields[f.InputID]; !ok {
+				fields[f.InputID] = f.Message
+			}
+		}
+	}
+
+	return &ErrorResponse{
+		Error: ErrorBody{
+			Status:  herr.Code(),
+			Type:    t,
+			Message: herr.ClientMessage(),
+			Fields:  fields,
+		},
+	}
+}
+
+// WriteError writes an HTTPError as JSON error response
+func WriteError(c echo.Context, herr *errors.HTTPError) *echo.HTTPError {
+	if err := c.JSON(herr.Code(), NewErrorResponse(herr)); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	return nil
+}
+
+// IsClientPath returns true for the paths of machine clients, which get JSON
+// errors instead of the login page or error pages: the API and the metrics
+func IsClientPath(path string) bool {
+	return strings.HasPrefix(path, env.ServerPathPrefix+"/api/") ||
+		path == env.ServerPathPrefix+"/metrics"
+}
+
+// handle adapts an API handler to the route handler signature, errors get
+// written as JSON error response instead of the default error page
+func handle(fn func(c echo.Context) *errors.HTTPError) func(c echo.Context) *echo.HTTPError {
+	return func(c echo.Context) *echo.HTTPError {
+		if herr := fn(c); herr != nil {
+			if herr.Code() >= http.StatusInternalServerError {
+				r := c.Request()
+				slog.Error("API request failed", "method", r.Method, "path", r.URL.Path, "error", herr)
+			}
+			return WriteError(c, herr)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,214 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/knackwurstking/pg-press/internal/env"
+	"github.com/knackwurstking/pg-press/internal/errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+// openAPIDocument generates the OpenAPI 3 document for all resources
+func openAPIDocument(resources []apiResource) map[string]any {
+	paths := map[string]any{}
+	schemas := map[string]any{
+		"ErrorResponse": schemaOf(reflect.TypeOf(ErrorResponse{})),
+	}
+	for _, r := range resources {
+		r.openAPIPaths(paths)
+		name, schema := r.openAPISchema()
+		schemas[name] = schema
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "PG: Presse API",
+			"version": "v1",
+		},
+		"servers": []map[string]any{
+			{"url": env.ServerPathPrefix + "/api/v1"},
+		},
+		"security": []map[string]any{
+			{"bearerAuth": []string{}},
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{
+					"type":   "http",
+					"scheme": "bearer",
+				},
+			},
+		},
+	}
+}
+
+func (r *resource[T]) openAPISchema() (string, any) {
+	return r.schema, schemaOf(reflect.TypeOf(r.new()))
+}
+
+func (r *resource[T]) openAPIPaths(paths map[string]any) {
+	ref := map[string]any{"$ref": "#/components/schemas/" + r.schema}
+	body := map[string]any{
+		"required": true,
+		"content":  map[string]any{"application/json": map[string]any{"schema": ref}},
+	}
+	idParam := map[string]any{
+		"name":     "id",
+		"in":       "path",
+		"required": true,
+		"schema":   map[string]any{"type": "integer", "format": "int64"},
+	}
+
+	listParams := []map[string]any{}
+	for _, p := range r.listParams {
+		listParams = append(listParams, map[string]any{
+			"name":        p.Name,
+			"in":          "query",
+			"description": p.Description,
+			"schema":      map[string]any{"type": "string"},
+		})
+	}
+
+	paths[r.path] = map[string]any{
+		"get": map[string]any{
+			"tags":       []string{r.tag},
+			"summary":    "List " + r.schema,
+			"parameters": listParams,
+			"responses": openAPIResponses(http.StatusOK, map[string]any{
+				"type": "array", "items": ref,
+			}),
+		},
+		"post": map[string]any{
+			"tags":        []string{r.tag},
+			"summary":     "Create " + r.schema,
+			"requestBody": body,
+			"responses":   openAPIResponses(http.StatusCreated, ref),
+		},
+	}
+
+	paths[r.path+"/{id}"] = map[string]any{
+		"parameters": []map[string]any{idParam},
+		"get": map[string]any{
+			"tags":      []string{r.tag},
+			"summary":   "Get " + r.schema,
+			"responses": openAPIResponses(http.StatusOK, ref),
+		},
+		"put": map[string]any{
+			"tags":        []string{r.tag},
+			"summary":     "Update " + r.schema,
+			"requestBody": body,
+			"responses":   openAPIResponses(http.StatusOK, ref),
+		},
+		"delete": map[string]any{
+			"tags":      []string{r.tag},
+			"summary":   "Delete " + r.schema,
+			"responses": openAPIResponses(http.StatusNoContent, nil),
+		},
+	}
+}
+
+// openAPIResponses returns the success response (without body if schema is nil)
+// together with the error responses shared by all operations
+func openAPIResponses(code int, schema any) map[string]any {
+	success := map[string]any{"description": http.StatusText(code)}
+	if schema != nil {
+		success["content"] = map[string]any{
+			"application/json": map[string]any{"schema": schema},
+		}
+	}
+
+	responses := map[string]any{
+		strconv.Itoa(code): success,
+	}
+	for _, c := range []int{
+		http.StatusBadRequest,
+		http.StatusUnauthorized,
+		http.StatusForbidden,
+		http.StatusNotFound,
+		http.StatusConflict,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+	} {
+		responses[strconv.Itoa(c)] = map[string]any{
+			"description": http.StatusText(c),
+			"content": map[string]any{
+				"application/json": map[string]any{
+					"schema": map[string]any{"$ref": "#/components/schemas/ErrorResponse"},
+				},
+			},
+		}
+	}
+	return responses
+}
+
+// schemaOf generates a JSON schema from the json tags of a type, embedded
+// structs get flattened like encoding/json does
+func schemaOf(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaOf(t.Elem())}
+	case reflect.Struct:
+		properties := map[string]any{}
+		addProperties(t, properties)
+		return map[string]any{"type": "object", "properties": properties}
+	default:
+		return map[string]any{}
+	}
+}
+
+func addProperties(t reflect.Type, properties map[string]any) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		if field.Anonymous && tag == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addProperties(ft, properties)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = schemaOf(field.Type)
+	}
+}
+
+// GetOpenAPI serves the OpenAPI document of the API
+func (h *Handler) GetOpenAPI(c echo.Context) *errors.HTTPError {
+	return writeJSON(c, http.StatusOK, h.openAPI)
+}
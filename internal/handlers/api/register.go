@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/knackwurstking/pg-press/internal/db"
+	"github.com/knackwurstking/pg-press/internal/env"
+
+	"github.com/knackwurstking/ui"
+	"github.com/labstack/echo/v4"
+)
+
+// Repository contains the database operations used by the API route handlers.
+type Repository interface {
+	db.ToolRepository
+	db.PressRepository
+	db.CycleRepository
+	db.NoteRepository
+	db.ReportRepository
+	db.AuditRepository
+}
+
+// Handler holds the dependencies of all API route handlers.
+type Handler struct {
+	db      Repository
+	openAPI map[string]any
+}
+
+func Register(e *echo.Echo, path string, store db.Repository) {
+	resources := resources(store)
+	h := &Handler{db: store, openAPI: openAPIDocument(resources)}
+
+	routes := []*ui.EchoRoute{
+		ui.NewEchoRoute(http.MethodGet, path+"/openapi.json", handle(h.GetOpenAPI)),
+	}
+	for _, r := range resources {
+		routes = append(routes, r.routes(h, path)...)
+	}
+
+	ui.RegisterEchoRoutes(e, env.ServerPathPrefix, routes)
+}
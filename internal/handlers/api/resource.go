@@ -0,0 +1,221 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/shared"
+	"github.com/knackwurstking/pg-press/internal/utils"
+
+	"github.com/knackwurstking/ui"
+	"github.com/labstack/echo/v4"
+)
+
+// entity is implemented by all entities offered by the API
+type entity interface {
+	shared.Auditable
+	Validate() *errors.ValidationError
+}
+
+// queryParam is an optional query parameter of a list operation
+type queryParam struct {
+	Name        string
+	Description string
+}
+
+// resource describes the JSON CRUD operations of an entity type, routes and
+// the OpenAPI document are both generated from it
+type resource[T entity] struct {
+	path       string // path is the collection path, e.g. "/tools"
+	tag        string // tag groups the operations in the OpenAPI document
+	schema     string // schema is the name of the entity schema in the OpenAPI document
+	listParams []queryParam
+
+	new    func() T
+	setID  func(T, shared.EntityID)
+	list   func(c echo.Context) ([]T, *errors.HTTPError)
+	get    func(id shared.EntityID) (T, *errors.HTTPError)
+	add    func(T) *errors.HTTPError
+	update func(T) *errors.HTTPError
+	delete func(id shared.EntityID, deletedBy shared.TelegramID) *errors.HTTPError
+
+	// prepare is called before adding a new entity, e.g. to set defaults, optional
+	prepare func(T, *shared.User)
+	// keep is called before updating an entity with the stored one, to copy the
+	// fields a client can not change, optional
+	keep func(e, before T)
+}
+
+// apiResource is the type independent part of a resource
+type apiResource interface {
+	routes(h *Handler, prefix string) []*ui.EchoRoute
+	openAPIPaths(paths map[string]any)
+	openAPISchema() (string, any)
+}
+
+func (r *resource[T]) routes(h *Handler, prefix string) []*ui.EchoRoute {
+	return []*ui.EchoRoute{
+		ui.NewEchoRoute(http.MethodGet, prefix+r.path, handle(r.handleList)),
+		ui.NewEchoRoute(http.MethodPost, prefix+r.path, handle(func(c echo.Context) *errors.HTTPError {
+			return r.handleCreate(h, c)
+		})),
+		ui.NewEchoRoute(http.MethodGet, prefix+r.path+"/:id", handle(r.handleGet)),
+		ui.NewEchoRoute(http.MethodPut, prefix+r.path+"/:id", handle(func(c echo.Context) *errors.HTTPError {
+			return r.handleUpdate(h, c)
+		})),
+		ui.NewEchoRoute(http.MethodDelete, prefix+r.path+"/:id", handle(func(c echo.Context) *errors.HTTPError {
+			return r.handleDelete(h, c)
+		})),
+	}
+}
+
+func (r *resource[T]) handleList(c echo.Context) *errors.HTTPError {
+	entities, herr := r.list(c)
+	if herr != nil {
+		return herr
+	}
+	if entities == nil {
+		entities = []T{}
+	}
+	return writeJSON(c, http.StatusOK, entities)
+}
+
+func (r *resource[T]) handleGet(c echo.Context) *errors.HTTPError {
+	id, herr := utils.GetParamInt64(c, "id")
+	if herr != nil {
+		return herr
+	}
+
+	e, herr := r.get(shared.EntityID(id))
+	if herr != nil {
+		return herr
+	}
+	return writeJSON(c, http.StatusOK, e)
+}
+
+func (r *resource[T]) handleCreate(h *Handler, c echo.Context) *errors.HTTPError {
+	user, herr := utils.GetUserFromContext(c)
+	if herr != nil {
+		return herr
+	}
+
+	e, herr := r.decode(c)
+	if herr != nil {
+		return herr
+	}
+	r.setID(e, 0) // IDs are always assigned by the database
+
+	if r.prepare != nil {
+		r.prepare(e, user)
+	}
+	if verr := e.Validate(); verr != nil {
+		return verr.HTTPError()
+	}
+
+	if herr = r.add(e); herr != nil {
+		return herr
+	}
+	h.db.Audit(user.ID, shared.AuditActionCreate, nil, e)
+
+	// Read back, to return injected fields like the tool cycles
+	if created, herr := r.get(shared.EntityID(e.AuditRef().ID)); herr == nil {
+		e = created
+	}
+	return writeJSON(c, http.StatusCreated, e)
+}
+
+func (r *resource[T]) handleUpdate(h *Handler, c echo.Context) *errors.HTTPError {
+	user, herr := utils.GetUserFromContext(c)
+	if herr != nil {
+		return herr
+	}
+
+	id, herr := utils.GetParamInt64(c, "id")
+	if herr != nil {
+		return herr
+	}
+
+	before, herr := r.get(shared.EntityID(id))
+	if herr != nil {
+		return herr
+	}
+
+	e, herr := r.decode(c)
+	if herr != nil {
+		return herr
+	}
+	r.setID(e, shared.EntityID(id))
+
+	if r.keep != nil {
+		r.keep(e, before)
+	}
+	if verr := e.Validate(); verr != nil {
+		return verr.HTTPError()
+	}
+
+	if herr = r.update(e); herr != nil {
+		return herr
+	}
+
+	after, herr := r.get(shared.EntityID(id))
+	if herr != nil {
+		return herr
+	}
+	h.db.Audit(user.ID, shared.AuditActionUpdate, before, after)
+
+	return writeJSON(c, http.StatusOK, after)
+}
+
+func (r *resource[T]) handleDelete(h *Handler, c echo.Context) *errors.HTTPError {
+	user, herr := utils.GetUserFromContext(c)
+	if herr != nil {
+		return herr
+	}
+
+	id, herr := utils.GetParamInt64(c, "id")
+	if herr != nil {
+		return herr
+	}
+
+	e, herr := r.get(shared.EntityID(id))
+	if herr != nil {
+		return herr
+	}
+
+	if herr = r.delete(shared.EntityID(id), user.ID); herr != nil {
+		return herr
+	}
+	h.db.Audit(user.ID, shared.AuditActionDelete, e, nil)
+
+	if err := c.NoContent(http.StatusNoContent); err != nil {
+		return errors.NewHTTPError(err)
+	}
+	return nil
+}
+
+// decode reads the JSON request body into a new entity
+func (r *resource[T]) decode(c echo.Context) (T, *errors.HTTPError) {
+	e := r.new()
+	if err := json.NewDecoder(c.Request().Body).Decode(e); err != nil {
+		return e, errors.NewValidationError("invalid JSON body: %v", err).HTTPError()
+	}
+	return e, nil
+}
+
+// writeJSON writes a successful JSON response
+func writeJSON(c echo.Context, code int, data any) *errors.HTTPError {
+	if err := c.JSON(code, data); err != nil {
+		return errors.NewHTTPError(err)
+	}
+	return nil
+}
+
+// requireQueryID reads a required ID query parameter of a list operation
+func requireQueryID(c echo.Context, name string) (shared.EntityID, *errors.HTTPError) {
+	if c.QueryParam(name) == "" {
+		return 0, errors.NewValidationError("query parameter %q is required", name).HTTPError()
+	}
+	id, herr := utils.GetQueryInt64(c, name)
+	return shared.EntityID(id), herr
+}
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"time"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// resources returns all entity types offered by the API
+func resources(store Repository) []apiResource {
+	return []apiResource{
+		&resource[*shared.Tool]{
+			path:   "/tools",
+			tag:    "Tools",
+			schema: "Tool",
+			new:    func() *shared.Tool { return &shared.Tool{} },
+			setID:  func(t *shared.Tool, id shared.EntityID) { t.ID = id },
+			list: func(c echo.Context) ([]*shared.Tool, *errors.HTTPError) {
+				return store.ListTools()
+			},
+			get:    store.GetTool,
+			add:    store.AddTool,
+			update: store.UpdateTool,
+			delete: store.DeleteTool,
+		},
+
+		&resource[*shared.Press]{
+			path:   "/presses",
+			tag:    "Presses",
+			schema: "Press",
+			new:    func() *shared.Press { return &shared.Press{} },
+			setID:  func(p *shared.Press, id shared.EntityID) { p.ID = id },
+			list: func(c echo.Context) ([]*shared.Press, *errors.HTTPError) {
+				return store.ListPress()
+			},
+			get:    store.GetPress,
+			add:    store.AddPress,
+			update: store.UpdatePress,
+			delete: store.DeletePress,
+		},
+
+		&resource[*shared.Cycle]{
+			path:   "/cycles",
+			tag:    "Cycles",
+			schema: "Cycle",
+			listParams: []queryParam{
+				{Name: "tool_id", Description: "List the cycles of a tool"},
+				{Name: "press_id", Description: "List the cycles of a press, if no tool_id is given"},
+			},
+			new:   func() *shared.Cycle { return &shared.Cycle{} },
+			setID: func(cycle *shared.Cycle, id shared.EntityID) { cycle.ID = id },
+			list: func(c echo.Context) ([]*shared.Cycle, *errors.HTTPError) {
+				if c.QueryParam("tool_id") != "" {
+					toolID, herr := requireQueryID(c, "tool_id")
+					if herr != nil {
+						return nil, herr
+					}
+					return store.ListToolCycles(toolID)
+				}
+				pressID, herr := requireQueryID(c, "press_id")
+				if herr != nil {
+					return nil, errors.NewValidationError("query parameter \"tool_id\" or \"press_id\" is required").HTTPError()
+				}
+				return store.ListCyclesByPressID(pressID)
+			},
+			get: func(id shared.EntityID) (*shared.Cycle, *errors.HTTPError) {
+				cycle, herr := store.GetCycle(id)
+				if herr != nil {
+					return nil, herr
+				}
+				return cycle, store.CycleInject(cycle)
+			},
+			add:     store.AddCycle,
+			update:  store.UpdateCycle,
+			delete:  store.DeleteCycle,
+			prepare: setCyclePerformedBy,
+			keep:    keepCyclePerformedBy,
+		},
+
+		&resource[*shared.ToolRegeneration]{
+			path:   "/regenerations",
+			tag:    "Regenerations",
+			schema: "ToolRegeneration",
+			listParams: []queryParam{
+				{Name: "tool_id", Description: "List only the regenerations of a tool"},
+			},
+			new:   func() *shared.ToolRegeneration { return &shared.ToolRegeneration{} },
+			setID: func(tr *shared.ToolRegeneration, id shared.EntityID) { tr.ID = id },
+			list: func(c echo.Context) ([]*shared.ToolRegeneration, *errors.HTTPError) {
+				if c.QueryParam("tool_id") == "" {
+					return store.ListToolRegenerations()
+				}
+				toolID, herr := requireQueryID(c, "tool_id")
+				if herr != nil {
+					return nil, herr
+				}
+				return store.ListToolRegenerationsByTool(toolID)
+			},
+			get:    store.GetToolRegeneration,
+			add:    store.AddToolRegeneration,
+			update: store.UpdateToolRegeneration,
+			delete: func(id shared.EntityID, _ shared.TelegramID) *errors.HTTPError {
+				// Regenerations have no trash, they get deleted right away
+				return store.DeleteToolRegeneration(id)
+			},
+		},
+
+		&resource[*shared.UpperMetalSheet]{
+			path:   "/metal-sheets/upper",
+			tag:    "Metal Sheets",
+			schema: "UpperMetalSheet",
+			listParams: []queryParam{
+				{Name: "tool_id", Description: "List the upper metal sheets of a tool (required)"},
+			},
+			new:   func() *shared.UpperMetalSheet { return &shared.UpperMetalSheet{} },
+			setID: func(ms *shared.UpperMetalSheet, id shared.EntityID) { ms.ID = id },
+			list: func(c echo.Context) ([]*shared.UpperMetalSheet, *errors.HTTPError) {
+				toolID, herr := requireQueryID(c, "tool_id")
+				if herr != nil {
+					return nil, herr
+				}
+				return store.ListUpperMetalSheetsByTool(toolID)
+			},
+			get:    store.GetUpperMetalSheet,
+			add:    store.AddUpperMetalSheet,
+			update: store.UpdateUpperMetalSheet,
+			delete: store.DeleteUpperMetalSheet,
+		},
+
+		&resource[*shared.LowerMetalSheet]{
+			path:   "/metal-sheets/lower",
+			tag:    "Metal Sheets",
+			schema: "LowerMetalSheet",
+			listParams: []queryParam{
+				{Name: "tool_id", Description: "List the lower metal sheets of a tool (required)"},
+			},
+			new:   func() *shared.LowerMetalSheet { return &shared.LowerMetalSheet{} },
+			setID: func(ms *shared.LowerMetalSheet, id shared.EntityID) { ms.ID = id },
+			list: func(c echo.Context) ([]*shared.LowerMetalSheet, *errors.HTTPError) {
+				toolID, herr := requireQueryID(c, "tool_id")
+				if herr != nil {
+					return nil, herr
+				}
+				return store.ListLowerMetalSheetsByTool(toolID)
+			},
+			get:    store.GetLowerMetalSheet,
+			add:    store.AddLowerMetalSheet,
+			update: store.UpdateLowerMetalSheet,
+			delete: store.DeleteLowerMetalSheet,
+		},
+
+		&resource[*shared.Note]{
+			path:   "/notes",
+			tag:    "Notes",
+			schema: "Note",
+			listParams: []queryParam{
+				{Name: "linked", Description: "List only the notes linked to an entity, e.g. \"press_5\" or \"tool_12\""},
+			},
+			new:   func() *shared.Note { return &shared.Note{} },
+			setID: func(n *shared.Note, id shared.EntityID) { n.ID = id },
+			list: func(c echo.Context) ([]*shared.Note, *errors.HTTPError) {
+				notes, herr := store.ListNotes()
+				if herr != nil {
+					return nil, herr
+				}
+				linked := c.QueryParam("linked")
+				if linked == "" {
+					return notes, nil
+				}
+				filtered := []*shared.Note{}
+				for _, n := range notes {
+					if n.Linked == linked {
+						filtered = append(filtered, n)
+					}
+				}
+				return filtered, nil
+			},
+			get:    store.GetNote,
+			add:    store.AddNote,
+			update: store.UpdateNote,
+			delete: store.DeleteNote,
+			prepare: func(n *shared.Note, _ *shared.User) {
+				if n.CreatedAt == 0 {
+					n.CreatedAt = shared.NewUnixMilli(time.Now())
+				}
+			},
+		},
+
+		&resource[*shared.TroubleReport]{
+			path:   "/trouble-reports",
+			tag:    "Trouble Reports",
+			schema: "TroubleReport",
+			new:    func() *shared.TroubleReport { return &shared.TroubleReport{} },
+			setID:  func(tr *shared.TroubleReport, id shared.EntityID) { tr.ID = id },
+			list: func(c echo.Context) ([]*shared.TroubleReport, *errors.HTTPError) {
+				return store.ListTroubleReports()
+			},
+			get:    store.GetTroubleReport,
+			add:    store.AddTroubleReport,
+			update: store.UpdateTroubleReport,
+			delete: store.DeleteTroubleReport,
+		},
+	}
+}
+
+// setCyclePerformedBy sets the authenticated user as the one who read the press
+// cycles of a new cycle, the value sent by the client is ignored
+func setCyclePerformedBy(cycle *shared.Cycle, user *shared.User) {
+	cycle.PerformedBy = user.ID
+}
+
+// keepCyclePerformedBy keeps the user who read the press cycles on updates, the
+// value sent by the client is ignored
+func keepCyclePerformedBy(cycle, before *shared.Cycle) {
+	cycle.PerformedBy = before.PerformedBy
+}
@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/env"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/handlers/auth/templates"
@@ -17,14 +16,21 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func GetLoginPage(c echo.Context) *echo.HTTPError {
+func (h *Handler) GetLoginPage(c echo.Context) *echo.HTTPError {
 	slog.Debug("Login page requested from IP", "real_ip", c.RealIP())
 
+	// Remaining lockout in minutes, see PostLoginPage
+	lockedMinutes := ""
+	if seconds, herr := utils.GetQueryInt(c, "locked"); herr == nil && seconds > 0 {
+		lockedMinutes = fmt.Sprintf("%d", (seconds+59)/60)
+	}
+
 	t := templates.Page(
 		templates.PageProps{
 			FormData: map[string]string{
 				"api-key":         c.FormValue("api-key"),
 				"invalid-api-key": fmt.Sprintf("%t", utils.GetQueryBool(c, "invalid")),
+				"locked-minutes":  lockedMinutes,
 			},
 		},
 	)
@@ -36,71 +42,93 @@ func GetLoginPage(c echo.Context) *echo.HTTPError {
 	return nil
 }
 
-func PostLoginPage(c echo.Context) *echo.HTTPError {
+func (h *Handler) PostLoginPage(c echo.Context) *echo.HTTPError {
 	slog.Debug("Login attempt from IP", "real_ip", c.RealIP())
 
 	apiKey := c.FormValue("api-key")
+	keys := shared.LoginLockoutKeys(c.RealIP(), apiKey)
 
-	err := processApiKeyLogin(apiKey, c)
-	if err != nil {
-		slog.Warn("Login failed", "error", err)
+	lockouts, herr := h.db.ListLockoutsByKeys(keys...)
+	if herr != nil {
+		return herr.Echo()
 	}
-	if apiKey == "" || err != nil {
-		invalid := true
-		merr := utils.RedirectTo(c, urlb.Login(apiKey, &invalid))
-		if merr != nil {
-			return merr.Echo()
+	if l := shared.ActiveLockout(lockouts); l != nil {
+		slog.Warn("Login locked", "lockout", l.String(), "real_ip", c.RealIP())
+		if herr = utils.RedirectTo(c, urlb.LoginLocked(l.RetryAfter())); herr != nil {
+			return herr.Echo()
 		}
+		return nil
 	}
 
-	merr := utils.RedirectTo(c, urlb.Profile())
-	if merr != nil {
-		return merr.Echo()
-	}
+	user, herr := h.processApiKeyLogin(apiKey)
 
-	return nil
-}
-
-func processApiKeyLogin(apiKey string, ctx echo.Context) *errors.HTTPError {
-	if len(apiKey) < shared.MinAPIKeyLength {
-		return errors.NewValidationError("invalid API key: too short").HTTPError()
+	attempt := &shared.LoginAttempt{
+		Source:    shared.LoginSourceForm,
+		RealIP:    c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+		KeyPrefix: shared.ApiKeyPrefix(apiKey),
+		Success:   herr == nil,
+		CreatedAt: shared.NewUnixMilli(time.Now()),
+	}
+	if herr != nil {
+		attempt.Reason = herr.Error()
+	} else {
+		attempt.UserID = user.ID
 	}
+	h.db.RecordLoginAttempt(attempt, keys...)
 
-	user, merr := db.GetUserByApiKey(apiKey)
-	if merr != nil {
-		return merr
+	if herr != nil {
+		slog.Warn("Login failed", "error", herr, "real_ip", c.RealIP())
+
+		invalid := true
+		if herr = utils.RedirectTo(c, urlb.Login(apiKey, &invalid)); herr != nil {
+			return herr.Echo()
+		}
+		return nil
 	}
 
-	merr = createSession(ctx, user.ID)
-	if merr != nil {
-		return merr
+	if herr = h.createSession(c, user.ID); herr != nil {
+		return herr.Echo()
 	}
 
 	if user.IsAdmin() {
 		slog.Info("Administrator logged in",
 			"user_name", user.Name,
-			"real_ip", ctx.RealIP())
+			"real_ip", c.RealIP())
+	}
+
+	if herr = utils.RedirectTo(c, urlb.Profile()); herr != nil {
+		return herr.Echo()
 	}
 
 	return nil
 }
 
-func createSession(ctx echo.Context, userID shared.TelegramID) *errors.HTTPError {
+func (h *Handler) processApiKeyLogin(apiKey string) (*shared.User, *errors.HTTPError) {
+	if len(apiKey) < shared.MinAPIKeyLength {
+		return nil, errors.NewValidationError("invalid API key: too short").HTTPError()
+	}
+
+	return h.db.GetUserByApiKey(apiKey)
+}
+
+func (h *Handler) createSession(ctx echo.Context, userID shared.TelegramID) *errors.HTTPError {
 	cookie := &shared.Cookie{
 		UserAgent: ctx.Request().UserAgent(),
 		Value:     uuid.New().String(),
 		UserID:    userID,
 		LastLogin: shared.NewUnixMilli(time.Now()),
+		CSRFToken: utils.NewCSRFToken(),
 	}
 
 	if cookieContext, _ := ctx.Cookie(CookieName); cookieContext != nil && cookieContext.Value != "" {
-		merr := db.DeleteCookie(cookie.Value)
+		merr := h.db.DeleteCookie(cookie.Value)
 		if merr != nil {
 			return merr
 		}
 	}
 
-	merr := db.AddCookie(cookie)
+	merr := h.db.AddCookie(cookie)
 	if merr != nil {
 		return merr
 	}
@@ -3,6 +3,7 @@ package auth
 import (
 	"net/http"
 
+	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/env"
 
 	"github.com/knackwurstking/ui"
@@ -13,10 +14,24 @@ const (
 	CookieName = "pgpress-api-key"
 )
 
-func Register(e *echo.Echo, path string) {
+// Repository contains the database operations used by the auth route handlers.
+type Repository interface {
+	db.UserRepository
+	db.ApiKeyRepository
+	db.LoginRepository
+}
+
+// Handler holds the dependencies of all auth route handlers.
+type Handler struct {
+	db Repository
+}
+
+func Register(e *echo.Echo, path string, store db.Repository) {
+	h := &Handler{db: store}
+
 	ui.RegisterEchoRoutes(e, env.ServerPathPrefix, []*ui.EchoRoute{
-		ui.NewEchoRoute(http.MethodGet, path+"/login", GetLoginPage),
-		ui.NewEchoRoute(http.MethodPost, path+"/login", PostLoginPage),
-		ui.NewEchoRoute(http.MethodGet, path+"/logout", GetLogout),
+		ui.NewEchoRoute(http.MethodGet, path+"/login", h.GetLoginPage),
+		ui.NewEchoRoute(http.MethodPost, path+"/login", h.PostLoginPage),
+		ui.NewEchoRoute(http.MethodGet, path+"/logout", h.GetLogout),
 	})
 }
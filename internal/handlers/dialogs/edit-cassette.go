@@ -5,7 +5,6 @@ import (
 	"log/slog"
 	"net/http"
 
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/knackwurstking/pg-press/internal/urlb"
@@ -14,12 +13,12 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func GetCassetteDialog(c echo.Context) *echo.HTTPError {
+func (h *Handler) GetCassetteDialog(c echo.Context) *echo.HTTPError {
 	var tool *shared.Tool
 	id, _ := utils.GetQueryInt64(c, "id")
 	if id > 0 {
 		var merr *errors.HTTPError
-		tool, merr = db.GetTool(shared.EntityID(id))
+		tool, merr = h.db.GetTool(shared.EntityID(id))
 		if merr != nil {
 			return merr.Echo()
 		}
@@ -38,6 +37,9 @@ func GetCassetteDialog(c echo.Context) *echo.HTTPError {
 				Height:       tool.Height,
 				MinThickness: tool.MinThickness,
 				MaxThickness: tool.MaxThickness,
+
+				CyclesWarning: tool.CyclesWarning,
+				CyclesError:   tool.CyclesError,
 			},
 			OOB:  true,
 			Open: true,
@@ -60,10 +62,28 @@ func GetCassetteDialog(c echo.Context) *echo.HTTPError {
 	return nil
 }
 
-func PostCassette(c echo.Context) *echo.HTTPError {
+// cassetteInputs maps the validated tool fields to the inputs of the cassette
+// dialogs
+var cassetteInputs = map[string]string{
+	"width":          "width",
+	"height":         "height",
+	"type":           "type",
+	"code":           "code",
+	"min_thickness":  "min-thickness",
+	"max_thickness":  "max-thickness",
+	"cycles_warning": "cycles-warning",
+	"cycles_error":   "cycles-error",
+}
+
+func (h *Handler) PostCassette(c echo.Context) *echo.HTTPError {
+	user, merr := utils.GetUserFromContext(c)
+	if merr != nil {
+		return merr.Echo()
+	}
+
 	id, _ := utils.GetQueryInt64(c, "id")
 	if id > 0 {
-		return updateCassette(c, shared.EntityID(id))
+		return h.updateCassette(c, shared.EntityID(id), user)
 	}
 
 	formData, ierrs := parseCassetteForm(c)
@@ -79,44 +99,52 @@ func PostCassette(c echo.Context) *echo.HTTPError {
 		Height:       formData.Height,
 		MinThickness: formData.MinThickness,
 		MaxThickness: formData.MaxThickness,
+
+		CyclesWarning: formData.CyclesWarning,
+		CyclesError:   formData.CyclesError,
 	}
 
 	slog.Debug("Creating new cassette", "tool_string", tool.String())
 
-	if merr := db.AddTool(tool); merr != nil {
-		ierr := errors.NewInputError("", fmt.Sprintf("Failed to create cassette: %s", merr.Error()))
-		return reRenderNewCassetteDialog(c, true, formData, ierr)
+	if merr := h.db.AddTool(tool); merr != nil {
+		ierrs := inputErrors(merr, cassetteInputs, "Failed to create cassette")
+		return reRenderNewCassetteDialog(c, true, formData, ierrs...)
 	}
+	h.db.Audit(user.ID, shared.AuditActionCreate, nil, tool)
 
 	utils.SetHXTrigger(c, "tool-tab-content")
 
-	return reRenderNewCassetteDialog(c, false, formData, nil)
+	return reRenderNewCassetteDialog(c, false, formData)
 }
 
-func updateCassette(c echo.Context, toolID shared.EntityID) *echo.HTTPError {
+func (h *Handler) updateCassette(c echo.Context, toolID shared.EntityID, user *shared.User) *echo.HTTPError {
 	formData, ierrs := parseCassetteForm(c)
 	if len(ierrs) > 0 {
 		return reRenderEditCassetteDialog(c, toolID, true, formData, ierrs...)
 	}
 
-	tool, merr := db.GetTool(shared.EntityID(toolID))
+	tool, merr := h.db.GetTool(shared.EntityID(toolID))
 	if merr != nil {
 		ierr := errors.NewInputError("", fmt.Sprintf("Cassette with ID %d not found", toolID))
 		return reRenderEditCassetteDialog(c, toolID, true, formData, ierr)
 	}
+	before := tool.Clone()
 	tool.Type = formData.Type
 	tool.Code = formData.Code
 	tool.Width = formData.Width
 	tool.Height = formData.Height
 	tool.MinThickness = formData.MinThickness
 	tool.MaxThickness = formData.MaxThickness
+	tool.CyclesWarning = formData.CyclesWarning
+	tool.CyclesError = formData.CyclesError
 
 	slog.Debug("Updating cassette", "tool", tool)
 
-	if merr = db.UpdateTool(tool); merr != nil {
-		ierr := errors.NewInputError("", fmt.Sprintf("Failed to update cassette: %s", merr.Error()))
-		return reRenderEditCassetteDialog(c, toolID, true, formData, ierr)
+	if merr = h.db.UpdateTool(tool); merr != nil {
+		ierrs := inputErrors(merr, cassetteInputs, "Failed to update cassette")
+		return reRenderEditCassetteDialog(c, toolID, true, formData, ierrs...)
 	}
+	h.db.Audit(user.ID, shared.AuditActionUpdate, before, tool)
 
 	// Set HX headers
 	utils.SetHXRedirect(c, urlb.Tool(tool.ID))
@@ -157,6 +185,8 @@ func parseCassetteForm(c echo.Context) (data CassetteFormData, ierrs []*errors.I
 	}
 	data.MaxThickness = float32(maxThickness)
 
+	data.CyclesWarning, data.CyclesError, ierrs = parseCyclesThresholds(c, ierrs)
+
 	slog.Debug("Cassette dialog form values", "data", data)
 
 	return
@@ -6,7 +6,6 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/knackwurstking/pg-press/internal/utils"
@@ -14,11 +13,20 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func GetEditCycle(c echo.Context) *echo.HTTPError {
+// cycleInputs maps the validated cycle fields to the inputs of the cycle
+// dialogs
+var cycleInputs = map[string]string{
+	"tool_id":  "cycle_tool_id",
+	"press_id": "press_id",
+	"cycles":   "cycles",
+	"stop":     "stop",
+}
+
+func (h *Handler) GetEditCycle(c echo.Context) *echo.HTTPError {
 	// Check if we're in tool change mode
 	toolChangeMode := utils.GetQueryBool(c, "tool_change_mode")
 
-	presses, herr := db.ListPress()
+	presses, herr := h.db.ListPress()
 	if herr != nil {
 		return herr.Echo()
 	}
@@ -31,35 +39,16 @@ func GetEditCycle(c echo.Context) *echo.HTTPError {
 		}
 
 		// Get cycle data from the database
-		cycle, herr := db.GetCycle(shared.EntityID(cycleIDQuery))
+		cycle, herr := h.db.GetCycle(shared.EntityID(cycleIDQuery))
 		if herr != nil {
 			return herr.Echo()
 		}
 
-		// Set the cycles (original) tool to props
-		tool, herr := db.GetTool(cycle.ToolID)
+		tools, herr := h.editCycleTools(cycle.ToolID, toolChangeMode)
 		if herr != nil {
 			return herr.Echo()
 		}
 
-		// If in tool change mode, load all available tools for this press
-		var tools []*shared.Tool
-		if toolChangeMode {
-			// Get all tools
-			allTools, herr := db.ListTools()
-			if herr != nil {
-				return herr.Echo()
-			}
-
-			// Filter out tools not matching the original tools position
-			for _, t := range allTools {
-				if t.Position != tool.Position {
-					continue
-				}
-				tools = append(tools, t)
-			}
-		}
-
 		t := EditCycleDialog(cycle.ID, CycleDialogProps{
 			CycleFormData: CycleFormData{
 				ToolID:      cycle.ToolID,
@@ -89,13 +78,13 @@ func GetEditCycle(c echo.Context) *echo.HTTPError {
 	if merr != nil {
 		return merr.Echo()
 	}
-	tool, merr := db.GetTool(shared.EntityID(id))
+	tool, merr := h.db.GetTool(shared.EntityID(id))
 	if merr != nil {
 		return merr.Echo()
 	}
 
 	currentPressID := shared.EntityID(0)
-	if p, herr := db.GetPressForTool(tool.ID); herr != nil {
+	if p, herr := h.db.GetPressForTool(tool.ID); herr != nil {
 		return herr.Echo()
 	} else if p != nil {
 		currentPressID = p.ID
@@ -119,40 +108,48 @@ func GetEditCycle(c echo.Context) *echo.HTTPError {
 	return nil
 }
 
-func PostCycle(c echo.Context) *echo.HTTPError {
+func (h *Handler) PostCycle(c echo.Context) *echo.HTTPError {
+	user, herr := utils.GetUserFromContext(c)
+	if herr != nil {
+		return herr.Echo()
+	}
+
 	if id, _ := utils.GetQueryInt64(c, "id"); id != 0 {
-		return updateCycle(c, shared.EntityID(id))
+		return h.updateCycle(c, shared.EntityID(id), user)
 	}
 
-	data, ierrs := parseCycleForm(c, 0)
+	toolID, _ := utils.GetQueryInt64(c, "tool_id")
+	data, ierrs := parseCycleForm(c, shared.EntityID(toolID))
 	if len(ierrs) > 0 {
-		return reRenderNewCycleDialog(c, true, data, ierrs...)
+		return h.reRenderNewCycleDialog(c, true, data, ierrs...)
 	}
 
 	slog.Debug("Create a new press cycles entry.", "data", data)
 
-	cycle := shared.NewCycle(data.ToolID, data.PressID, data.PressCycles, data.Stop)
-	if herr := db.AddCycle(cycle); herr != nil {
-		ierr := errors.NewInputError("", fmt.Sprintf("failed to create cycle: %v", herr))
-		return reRenderNewCycleDialog(c, true, data, ierr)
+	cycle := shared.NewCycle(data.ToolID, data.PressID, data.PressCycles, data.Stop, user.ID)
+	if herr := h.db.AddCycle(cycle); herr != nil {
+		ierrs := inputErrors(herr, cycleInputs, "failed to create cycle")
+		return h.reRenderNewCycleDialog(c, true, data, ierrs...)
 	}
+	h.db.Audit(user.ID, shared.AuditActionCreate, nil, cycle)
 
 	utils.SetHXTrigger(c, "reload-cycles")
 
-	return reRenderNewCycleDialog(c, false, data)
+	return h.reRenderNewCycleDialog(c, false, data)
 }
 
-func updateCycle(c echo.Context, cycleID shared.EntityID) *echo.HTTPError {
-	cycle, herr := db.GetCycle(cycleID)
+func (h *Handler) updateCycle(c echo.Context, cycleID shared.EntityID, user *shared.User) *echo.HTTPError {
+	cycle, herr := h.db.GetCycle(cycleID)
 	if herr != nil {
 		ierr := errors.NewInputError("", fmt.Sprintf("failed to load cycle with ID %d: %v", cycleID, herr))
-		return reRenderEditCycleDialog(c, cycleID, true, CycleFormData{}, ierr)
+		return h.reRenderEditCycleDialog(c, cycleID, 0, true, CycleFormData{}, ierr)
 	}
 
 	data, ierrs := parseCycleForm(c, cycle.ToolID)
 	if len(ierrs) > 0 {
-		return reRenderEditCycleDialog(c, cycleID, true, data, ierrs...)
+		return h.reRenderEditCycleDialog(c, cycleID, cycle.ToolID, true, data, ierrs...)
 	}
+	before := cycle.Clone()
 	cycle.ToolID = data.ToolID
 	cycle.PressID = data.PressID
 	cycle.Stop = data.Stop
@@ -160,14 +157,15 @@ func updateCycle(c echo.Context, cycleID shared.EntityID) *echo.HTTPError {
 
 	slog.Debug("Update existing cycle.", "id", cycle.ID, "data", data)
 
-	if herr := db.UpdateCycle(cycle); herr != nil {
-		ierr := errors.NewInputError("", fmt.Sprintf("failed to update cycle: %v", herr))
-		return reRenderEditCycleDialog(c, cycleID, true, data, ierr)
+	if herr := h.db.UpdateCycle(cycle); herr != nil {
+		ierrs := inputErrors(herr, cycleInputs, "failed to update cycle")
+		return h.reRenderEditCycleDialog(c, cycleID, before.ToolID, true, data, ierrs...)
 	}
+	h.db.Audit(user.ID, shared.AuditActionUpdate, before, cycle)
 
 	utils.SetHXTrigger(c, "reload-cycles")
 
-	return reRenderEditCycleDialog(c, cycleID, false, data)
+	return h.reRenderEditCycleDialog(c, cycleID, before.ToolID, false, data)
 }
 
 func parseCycleForm(c echo.Context, toolID shared.EntityID) (data CycleFormData, ierrs []*errors.InputError) {
@@ -213,7 +211,53 @@ func parseCycleForm(c echo.Context, toolID shared.EntityID) (data CycleFormData,
 	return
 }
 
-func reRenderNewCycleDialog(c echo.Context, open bool, formData CycleFormData, ierrs ...*errors.InputError) *echo.HTTPError {
+// editCycleTools returns the tools for the tool selection of the edit cycle
+// dialog, all tools with the position of the cycles (original) tool in tool
+// change mode and none otherwise
+func (h *Handler) editCycleTools(toolID shared.EntityID, toolChangeMode bool) ([]*shared.Tool, *errors.HTTPError) {
+	tool, herr := h.db.GetTool(toolID)
+	if herr != nil {
+		return nil, herr
+	}
+
+	if !toolChangeMode {
+		return nil, nil
+	}
+
+	allTools, herr := h.db.ListTools()
+	if herr != nil {
+		return nil, herr
+	}
+
+	// Filter out tools not matching the original tools position
+	var tools []*shared.Tool
+	for _, t := range allTools {
+		if t.Position != tool.Position {
+			continue
+		}
+		tools = append(tools, t)
+	}
+
+	return tools, nil
+}
+
+// reRenderNewCycleDialog loads the presses and the tool, which are not part of
+// the submitted form, if the dialog stays open
+func (h *Handler) reRenderNewCycleDialog(c echo.Context, open bool, formData CycleFormData, ierrs ...*errors.InputError) *echo.HTTPError {
+	if open {
+		presses, herr := h.db.ListPress()
+		if herr != nil {
+			return herr.Echo()
+		}
+		formData.Presses = presses
+
+		tool, herr := h.db.GetTool(formData.ToolID)
+		if herr != nil {
+			return herr.Echo()
+		}
+		formData.Tools = []*shared.Tool{tool}
+	}
+
 	t := NewCycleDialog(CycleDialogProps{
 		CycleFormData: formData,
 		Open:          open,
@@ -228,11 +272,28 @@ func reRenderNewCycleDialog(c echo.Context, open bool, formData CycleFormData, i
 	return nil
 }
 
-func reRenderEditCycleDialog(c echo.Context, cycleID shared.EntityID, open bool, formData CycleFormData, ierrs ...*errors.InputError) *echo.HTTPError {
+// reRenderEditCycleDialog loads the presses and tools, which are not part of
+// the submitted form, if the dialog stays open, toolID is the cycles original
+// tool or 0 if the cycle could not be loaded
+func (h *Handler) reRenderEditCycleDialog(c echo.Context, cycleID, toolID shared.EntityID, open bool, formData CycleFormData, ierrs ...*errors.InputError) *echo.HTTPError {
+	if open && toolID > 0 {
+		presses, herr := h.db.ListPress()
+		if herr != nil {
+			return herr.Echo()
+		}
+		formData.Presses = presses
+
+		formData.Tools, herr = h.editCycleTools(toolID, utils.GetQueryBool(c, "tool_change_mode"))
+		if herr != nil {
+			return herr.Echo()
+		}
+	}
+
 	t := EditCycleDialog(cycleID, CycleDialogProps{
 		CycleFormData: formData,
 		Open:          open,
 		OOB:           true,
+		Error:         ierrs,
 	})
 	err := t.Render(c.Request().Context(), c.Response())
 	if err != nil {
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"net/http"
 
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/knackwurstking/pg-press/internal/utils"
@@ -12,7 +11,7 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func GetEditMetalSheet(c echo.Context) *echo.HTTPError {
+func (h *Handler) GetEditMetalSheet(c echo.Context) *echo.HTTPError {
 	// Check if we're editing an existing metal sheet (has ID) or creating new one
 	idQuery, _ := utils.GetQueryInt64(c, "id")
 	if metalSheetID := shared.EntityID(idQuery); metalSheetID > 0 {
@@ -22,11 +21,11 @@ func GetEditMetalSheet(c echo.Context) *echo.HTTPError {
 		}
 		switch shared.Slot(positionQuery) {
 		case shared.SlotUpper:
-			metalSheet, merr := db.GetUpperMetalSheet(metalSheetID)
+			metalSheet, merr := h.db.GetUpperMetalSheet(metalSheetID)
 			if merr != nil {
 				return merr.Echo()
 			}
-			tool, merr := db.GetTool(metalSheet.ToolID)
+			tool, merr := h.db.GetTool(metalSheet.ToolID)
 			if merr != nil {
 				return merr.Echo()
 			}
@@ -48,11 +47,11 @@ func GetEditMetalSheet(c echo.Context) *echo.HTTPError {
 			return nil
 
 		case shared.SlotLower:
-			metalSheet, merr := db.GetLowerMetalSheet(metalSheetID)
+			metalSheet, merr := h.db.GetLowerMetalSheet(metalSheetID)
 			if merr != nil {
 				return merr.Echo()
 			}
-			tool, merr := db.GetTool(metalSheet.ToolID)
+			tool, merr := h.db.GetTool(metalSheet.ToolID)
 			if merr != nil {
 				return merr.Echo()
 			}
@@ -88,7 +87,7 @@ func GetEditMetalSheet(c echo.Context) *echo.HTTPError {
 		return merr.Echo()
 	}
 	// Fetch the associated tool for the dialog
-	tool, merr := db.GetTool(shared.EntityID(toolIDQuery))
+	tool, merr := h.db.GetTool(shared.EntityID(toolIDQuery))
 	if merr != nil {
 		return merr.Echo()
 	}
@@ -125,20 +124,36 @@ func GetEditMetalSheet(c echo.Context) *echo.HTTPError {
 	}
 }
 
-func PostMetalSheet(c echo.Context) *echo.HTTPError {
+// metalSheetInputs maps the validated metal sheet fields to the inputs of the
+// metal sheet dialogs
+var metalSheetInputs = map[string]string{
+	"tile_height":  "tile_height",
+	"value":        "value",
+	"marke_height": "marke_height",
+	"stf":          "stf",
+	"stf_max":      "stf_max",
+	"identifier":   "machine_type",
+}
+
+func (h *Handler) PostMetalSheet(c echo.Context) *echo.HTTPError {
+	user, merr := utils.GetUserFromContext(c)
+	if merr != nil {
+		return merr.Echo()
+	}
+
 	// Extract metal sheet ID from query parameters
 	id, _ := utils.GetQueryInt64(c, "id")
 	if id > 0 {
-		return updateMetalSheet(c, shared.EntityID(id))
+		return h.updateMetalSheet(c, shared.EntityID(id), user)
 	}
 
 	// Extract tool ID from query parameters
-	id, merr := utils.GetQueryInt64(c, "tool_id")
+	id, merr = utils.GetQueryInt64(c, "tool_id")
 	if merr != nil {
 		return merr.Echo()
 	}
 	// Fetch the associated tool
-	tool, merr := db.GetTool(shared.EntityID(id))
+	tool, merr := h.db.GetTool(shared.EntityID(id))
 	if merr != nil {
 		return merr.Echo()
 	}
@@ -156,10 +171,11 @@ func PostMetalSheet(c echo.Context) *echo.HTTPError {
 				Value:      data.Value,
 			},
 		}
-		if merr = db.AddUpperMetalSheet(ums); merr != nil {
-			ierr := errors.NewInputError("", fmt.Sprintf("could not add upper metal sheet to database: %v", merr))
-			return reRenderNewUpperMetalSheetDialog(tool.ID, data, renderProps{c, true, []*errors.InputError{ierr}})
+		if merr = h.db.AddUpperMetalSheet(ums); merr != nil {
+			ierrs := inputErrors(merr, metalSheetInputs, "could not add upper metal sheet to database")
+			return reRenderNewUpperMetalSheetDialog(tool.ID, data, renderProps{c, true, ierrs})
 		}
+		h.db.Audit(user.ID, shared.AuditActionCreate, nil, ums)
 
 	case shared.SlotLower:
 		data, ierrs := parseLowerMetalSheetForm(c)
@@ -177,10 +193,11 @@ func PostMetalSheet(c echo.Context) *echo.HTTPError {
 			STFMax:      data.STFMax,
 			Identifier:  data.Identifier,
 		}
-		if merr = db.AddLowerMetalSheet(lms); merr != nil {
-			ierr := errors.NewInputError("", fmt.Sprintf("could not add lower metal sheet to database: %v", merr))
-			return reRenderNewLowerMetalSheetDialog(tool.ID, data, renderProps{c, true, []*errors.InputError{ierr}})
+		if merr = h.db.AddLowerMetalSheet(lms); merr != nil {
+			ierrs := inputErrors(merr, metalSheetInputs, "could not add lower metal sheet to database")
+			return reRenderNewLowerMetalSheetDialog(tool.ID, data, renderProps{c, true, ierrs})
 		}
+		h.db.Audit(user.ID, shared.AuditActionCreate, nil, lms)
 
 	default:
 		return errors.NewValidationError("invalid tool position").HTTPError().Echo()
@@ -199,7 +216,7 @@ func PostMetalSheet(c echo.Context) *echo.HTTPError {
 	return nil
 }
 
-func updateMetalSheet(c echo.Context, id shared.EntityID) *echo.HTTPError {
+func (h *Handler) updateMetalSheet(c echo.Context, id shared.EntityID, user *shared.User) *echo.HTTPError {
 	position, merr := utils.GetQueryInt(c, "position")
 	if merr != nil {
 		return merr.Echo()
@@ -207,7 +224,7 @@ func updateMetalSheet(c echo.Context, id shared.EntityID) *echo.HTTPError {
 
 	switch shared.Slot(position) {
 	case shared.SlotUpper:
-		ums, merr := db.GetUpperMetalSheet(shared.EntityID(id))
+		ums, merr := h.db.GetUpperMetalSheet(shared.EntityID(id))
 		if merr != nil {
 			return merr.WrapEcho("could not fetch existing upper metal sheet with ID %d", id)
 		}
@@ -216,14 +233,18 @@ func updateMetalSheet(c echo.Context, id shared.EntityID) *echo.HTTPError {
 		if len(ierrs) > 0 {
 			return reRenderEditUpperMetalSheetDialog(ums.ToolID, id, data, renderProps{c, true, ierrs})
 		}
+		before := ums.Clone()
+		ums.TileHeight = data.TileHeight
+		ums.Value = data.Value
 
-		if merr = db.UpdateUpperMetalSheet(ums); merr != nil {
-			ierr := errors.NewInputError("", fmt.Sprintf("could not update upper metal sheet in database: %v", merr))
-			return reRenderEditUpperMetalSheetDialog(ums.ToolID, id, data, renderProps{c, true, []*errors.InputError{ierr}})
+		if merr = h.db.UpdateUpperMetalSheet(ums); merr != nil {
+			ierrs := inputErrors(merr, metalSheetInputs, "could not update upper metal sheet in database")
+			return reRenderEditUpperMetalSheetDialog(ums.ToolID, id, data, renderProps{c, true, ierrs})
 		}
+		h.db.Audit(user.ID, shared.AuditActionUpdate, before, ums)
 
 	case shared.SlotLower:
-		lms, merr := db.GetLowerMetalSheet(shared.EntityID(id))
+		lms, merr := h.db.GetLowerMetalSheet(shared.EntityID(id))
 		if merr != nil {
 			return merr.WrapEcho("could not fetch existing lower metal sheet with ID %d", id)
 		}
@@ -232,12 +253,20 @@ func updateMetalSheet(c echo.Context, id shared.EntityID) *echo.HTTPError {
 		if len(ierrs) > 0 {
 			return reRenderEditLowerMetalSheetDialog(lms.ToolID, id, data, renderProps{c, true, ierrs})
 		}
-
-		merr = db.UpdateLowerMetalSheet(lms)
+		before := lms.Clone()
+		lms.TileHeight = data.TileHeight
+		lms.Value = data.Value
+		lms.MarkeHeight = data.MarkeHeight
+		lms.STF = data.STF
+		lms.STFMax = data.STFMax
+		lms.Identifier = data.Identifier
+
+		merr = h.db.UpdateLowerMetalSheet(lms)
 		if merr != nil {
-			ierr := errors.NewInputError("", fmt.Sprintf("could not update lower metal sheet in database: %v", merr))
-			return reRenderEditLowerMetalSheetDialog(lms.ToolID, id, data, renderProps{c, true, []*errors.InputError{ierr}})
+			ierrs := inputErrors(merr, metalSheetInputs, "could not update lower metal sheet in database")
+			return reRenderEditLowerMetalSheetDialog(lms.ToolID, id, data, renderProps{c, true, ierrs})
 		}
+		h.db.Audit(user.ID, shared.AuditActionUpdate, before, lms)
 
 	default:
 		return errors.NewValidationError("invalid slot position").HTTPError().Echo()
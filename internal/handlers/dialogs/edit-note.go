@@ -5,7 +5,6 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/knackwurstking/pg-press/internal/utils"
@@ -13,7 +12,7 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func GetEditNote(c echo.Context) *echo.HTTPError {
+func (h *Handler) GetEditNote(c echo.Context) *echo.HTTPError {
 	linked := c.QueryParam("linked")
 
 	user, herr := utils.GetUserFromContext(c)
@@ -27,7 +26,7 @@ func GetEditNote(c echo.Context) *echo.HTTPError {
 		noteID := shared.EntityID(id)
 
 		var merr *errors.HTTPError
-		note, merr = db.GetNote(noteID)
+		note, merr = h.db.GetNote(noteID)
 		if merr != nil {
 			return merr.Echo()
 		}
@@ -73,10 +72,21 @@ func GetEditNote(c echo.Context) *echo.HTTPError {
 	return nil
 }
 
-func PostNote(c echo.Context) *echo.HTTPError {
+// noteInputs maps the validated note fields to the inputs of the note dialogs
+var noteInputs = map[string]string{
+	"level":   "level",
+	"content": "content",
+}
+
+func (h *Handler) PostNote(c echo.Context) *echo.HTTPError {
+	user, herr := utils.GetUserFromContext(c)
+	if herr != nil {
+		return herr.Echo()
+	}
+
 	id, _ := utils.GetQueryInt64(c, "id")
 	if id > 0 {
-		return updateNote(c, shared.EntityID(id))
+		return h.updateNote(c, shared.EntityID(id), user)
 	}
 
 	data, ierrs := parseNoteForm(c)
@@ -94,17 +104,18 @@ func PostNote(c echo.Context) *echo.HTTPError {
 		CreatedAt: shared.NewUnixMilli(time.Now()),
 		Linked:    data.Linked,
 	}
-	if herr := db.AddNote(note); herr != nil {
-		ierr := errors.NewInputError("", herr.Error())
-		return reRenderNewNoteDialog(c, true, data, ierr)
+	if herr := h.db.AddNote(note); herr != nil {
+		ierrs := inputErrors(herr, noteInputs, "failed to add note")
+		return reRenderNewNoteDialog(c, true, data, ierrs...)
 	}
+	h.db.Audit(user.ID, shared.AuditActionCreate, nil, note)
 
 	utils.SetHXTrigger(c, "reload-notes")
 
 	return reRenderNewNoteDialog(c, false, NoteFormData{Linked: data.Linked})
 }
 
-func updateNote(c echo.Context, id shared.EntityID) *echo.HTTPError {
+func (h *Handler) updateNote(c echo.Context, id shared.EntityID, user *shared.User) *echo.HTTPError {
 	// Parse form data
 	data, ierrs := parseNoteForm(c)
 	if len(ierrs) > 0 {
@@ -112,11 +123,12 @@ func updateNote(c echo.Context, id shared.EntityID) *echo.HTTPError {
 	}
 
 	// Get note for id
-	note, herr := db.GetNote(id)
+	note, herr := h.db.GetNote(id)
 	if herr != nil {
 		ierr := errors.NewInputError("", "invalid note id")
 		return reRenderEditNoteDialog(c, id, true, data, ierr)
 	}
+	before := note.Clone()
 	note.Level = data.Level
 	note.Content = data.Content
 	note.Linked = data.Linked
@@ -126,10 +138,11 @@ func updateNote(c echo.Context, id shared.EntityID) *echo.HTTPError {
 		"user_name", c.Get("user-name"))
 
 	// Update the note
-	if herr := db.UpdateNote(note); herr != nil {
-		ierr := errors.NewInputError("", herr.Error())
-		return reRenderEditNoteDialog(c, id, true, data, ierr)
+	if herr := h.db.UpdateNote(note); herr != nil {
+		ierrs := inputErrors(herr, noteInputs, "failed to update note")
+		return reRenderEditNoteDialog(c, id, true, data, ierrs...)
 	}
+	h.db.Audit(user.ID, shared.AuditActionUpdate, before, note)
 
 	// Trigger reload of notes sections
 	utils.SetHXTrigger(c, "reload-notes")
@@ -137,20 +150,19 @@ func updateNote(c echo.Context, id shared.EntityID) *echo.HTTPError {
 	return reRenderEditNoteDialog(c, id, false, data)
 }
 
-// parseNoteForm will return only general input errors (inputID set to "")
 func parseNoteForm(c echo.Context) (data NoteFormData, ierrs []*errors.InputError) {
 	// Parse level (required)
 	level, err := utils.SanitizeInt(c.FormValue("level"))
 	data.Level = shared.NoteLevel(level)
 	if err != nil {
-		ierr := errors.NewInputError("", "level must be an integer")
+		ierr := errors.NewInputError("level", "level must be an integer")
 		ierrs = append(ierrs, ierr)
 	}
 
 	// Parse content (required)
 	data.Content = utils.SanitizeText(c.FormValue("content"))
 	if data.Content == "" {
-		ierr := errors.NewInputError("", "content is required")
+		ierr := errors.NewInputError("content", "content is required")
 		ierrs = append(ierrs, ierr)
 	}
 
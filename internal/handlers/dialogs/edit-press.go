@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"net/http"
 
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/knackwurstking/pg-press/internal/urlb"
@@ -13,14 +12,23 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func GetEditPress(c echo.Context) *echo.HTTPError {
+// pressInputs maps the validated press fields to the inputs of the press
+// dialogs
+var pressInputs = map[string]string{
+	"number":        "press_number",
+	"type":          "machine_type",
+	"code":          "code",
+	"cycles_offset": "cycles_offset",
+}
+
+func (h *Handler) GetEditPress(c echo.Context) *echo.HTTPError {
 	id, merr := utils.GetQueryInt64(c, "id")
 	if merr != nil && !merr.IsNotFoundError() {
 		return merr.Echo()
 	}
 
 	if id > 0 {
-		press, merr := db.GetPress(shared.EntityID(id))
+		press, merr := h.db.GetPress(shared.EntityID(id))
 		if merr != nil {
 			return merr.Echo()
 		}
@@ -53,10 +61,15 @@ func GetEditPress(c echo.Context) *echo.HTTPError {
 	return nil
 }
 
-func PostPress(c echo.Context) *echo.HTTPError {
+func (h *Handler) PostPress(c echo.Context) *echo.HTTPError {
+	user, merr := utils.GetUserFromContext(c)
+	if merr != nil {
+		return merr.Echo()
+	}
+
 	id, _ := utils.GetQueryInt64(c, "id")
 	if id > 0 {
-		return updatePress(c, shared.EntityID(id))
+		return h.updatePress(c, shared.EntityID(id), user)
 	}
 
 	data, ierrs := parseEditPressForm(c)
@@ -64,35 +77,36 @@ func PostPress(c echo.Context) *echo.HTTPError {
 		return reRenderNewPressDialog(c, true, data, ierrs...)
 	}
 
-	merr := db.AddPress(&shared.Press{
+	press := &shared.Press{
 		Number:       data.Number,
 		Type:         data.Type,
 		Code:         data.Code,
 		CyclesOffset: data.CyclesOffset,
-	})
-	if merr != nil {
-		ierr := errors.NewInputError("", fmt.Sprintf("failed to add press: %v", merr))
-		return reRenderNewPressDialog(c, true, data, ierr)
 	}
+	if merr = h.db.AddPress(press); merr != nil {
+		ierrs := inputErrors(merr, pressInputs, "failed to add press")
+		return reRenderNewPressDialog(c, true, data, ierrs...)
+	}
+	h.db.Audit(user.ID, shared.AuditActionCreate, nil, press)
 
 	utils.SetHXTrigger(c, "press-tab-content")
 
 	return reRenderNewPressDialog(c, false, data)
 }
 
-func updatePress(c echo.Context, id shared.EntityID) *echo.HTTPError {
+func (h *Handler) updatePress(c echo.Context, id shared.EntityID, user *shared.User) *echo.HTTPError {
 	data, ierrs := parseEditPressForm(c)
 	if len(ierrs) > 0 {
 		return reRenderEditPressDialog(c, id, true, data, ierrs...)
 	}
 
-	press, herr := db.GetPress(id)
+	press, herr := h.db.GetPress(id)
 	if herr != nil {
 		ierr := errors.NewInputError("", fmt.Sprintf("failed to get press: %v", herr))
 		return reRenderEditPressDialog(c, id, true, data, ierr)
 	}
 
-	merr := db.UpdatePress(&shared.Press{
+	updated := &shared.Press{
 		ID:           press.ID,
 		Number:       data.Number,
 		Type:         data.Type,
@@ -100,11 +114,12 @@ func updatePress(c echo.Context, id shared.EntityID) *echo.HTTPError {
 		CyclesOffset: data.CyclesOffset,
 		SlotUp:       press.SlotUp,
 		SlotDown:     press.SlotDown,
-	})
-	if merr != nil {
-		ierr := errors.NewInputError("", fmt.Sprintf("failed to update press: %v", merr))
-		return reRenderEditPressDialog(c, id, true, data, ierr)
 	}
+	if merr := h.db.UpdatePress(updated); merr != nil {
+		ierrs := inputErrors(merr, pressInputs, "failed to update press")
+		return reRenderEditPressDialog(c, id, true, data, ierrs...)
+	}
+	h.db.Audit(user.ID, shared.AuditActionUpdate, press, updated)
 
 	utils.SetHXRedirect(c, urlb.Press(press.ID))
 
@@ -5,7 +5,6 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/knackwurstking/pg-press/internal/utils"
@@ -13,14 +12,21 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func GetEditToolRegeneration(c echo.Context) *echo.HTTPError {
+// toolRegenerationInputs maps the validated tool regeneration fields to the
+// inputs of the tool regeneration dialogs
+var toolRegenerationInputs = map[string]string{
+	"start": "start",
+	"stop":  "stop",
+}
+
+func (h *Handler) GetEditToolRegeneration(c echo.Context) *echo.HTTPError {
 	if c.QueryParam("id") != "" {
 		trIDQuery, herr := utils.GetQueryInt64(c, "id")
 		if herr != nil {
 			return herr.Echo()
 		}
 
-		tr, herr := db.GetToolRegeneration(shared.EntityID(trIDQuery))
+		tr, herr := h.db.GetToolRegeneration(shared.EntityID(trIDQuery))
 		if herr != nil {
 			return herr.Echo()
 		}
@@ -65,12 +71,17 @@ func GetEditToolRegeneration(c echo.Context) *echo.HTTPError {
 	return nil
 }
 
-func PostToolRegeneration(c echo.Context) *echo.HTTPError {
+func (h *Handler) PostToolRegeneration(c echo.Context) *echo.HTTPError {
+	user, merr := utils.GetUserFromContext(c)
+	if merr != nil {
+		return merr.Echo()
+	}
+
 	if id, _ := utils.GetQueryInt64(c, "id"); id != 0 {
-		return updateToolRegeneration(c, shared.EntityID(id))
+		return h.updateToolRegeneration(c, shared.EntityID(id), user)
 	}
 
-	data, ierrs := parseToolRegenerationForm(c)
+	data, ierrs := parseToolRegenerationForm(c, 0)
 	if len(ierrs) > 0 {
 		return reRenderNewToolRegenerationDialog(c, true, data, ierrs...)
 	}
@@ -80,47 +91,55 @@ func PostToolRegeneration(c echo.Context) *echo.HTTPError {
 		Start:  data.Start,
 		Stop:   data.Stop,
 	}
-	if merr := db.AddToolRegeneration(tr); merr != nil {
-		ierr := errors.NewInputError("", fmt.Sprintf("failed to create tool regeneration: %v", merr))
-		return reRenderNewToolRegenerationDialog(c, true, data, ierr)
+	if merr := h.db.AddToolRegeneration(tr); merr != nil {
+		ierrs := inputErrors(merr, toolRegenerationInputs, "failed to create tool regeneration")
+		return reRenderNewToolRegenerationDialog(c, true, data, ierrs...)
 	}
+	h.db.Audit(user.ID, shared.AuditActionCreate, nil, tr)
 
 	utils.SetHXTrigger(c, "reload-tool-regenerations")
 
 	return reRenderNewToolRegenerationDialog(c, false, data)
 }
 
-func updateToolRegeneration(c echo.Context, trID shared.EntityID) *echo.HTTPError {
-	tr, merr := db.GetToolRegeneration(trID)
+func (h *Handler) updateToolRegeneration(c echo.Context, trID shared.EntityID, user *shared.User) *echo.HTTPError {
+	tr, merr := h.db.GetToolRegeneration(trID)
 	if merr != nil {
 		ierr := errors.NewInputError("", fmt.Sprintf("failed to load tool regeneration with ID %d: %v", trID, merr))
 		return reRenderEditToolRegenerationDialog(c, trID, true, ToolRegenerationFormData{}, ierr)
 	}
 
-	data, ierrs := parseToolRegenerationForm(c)
+	data, ierrs := parseToolRegenerationForm(c, tr.ToolID)
 	if len(ierrs) > 0 {
 		return reRenderEditToolRegenerationDialog(c, trID, true, data, ierrs...)
 	}
+	before := tr.Clone()
 	tr.Start = data.Start
 	tr.Stop = data.Stop
 
-	if merr := db.UpdateToolRegeneration(tr); merr != nil {
-		ierr := errors.NewInputError("", fmt.Sprintf("failed to update tool regeneration: %v", merr))
-		return reRenderEditToolRegenerationDialog(c, trID, true, data, ierr)
+	if merr := h.db.UpdateToolRegeneration(tr); merr != nil {
+		ierrs := inputErrors(merr, toolRegenerationInputs, "failed to update tool regeneration")
+		return reRenderEditToolRegenerationDialog(c, trID, true, data, ierrs...)
 	}
+	h.db.Audit(user.ID, shared.AuditActionUpdate, before, tr)
 
 	utils.SetHXTrigger(c, "reload-tool-regenerations")
 
 	return reRenderEditToolRegenerationDialog(c, trID, false, data)
 }
 
-func parseToolRegenerationForm(c echo.Context) (data ToolRegenerationFormData, ierrs []*errors.InputError) {
-	toolID, err := utils.SanitizeInt64(c.FormValue("tool_id"))
-	if err != nil {
-		ierr := errors.NewInputError("tool_id", fmt.Sprintf("invalid tool ID: %v", err))
-		ierrs = append(ierrs, ierr)
+// parseToolRegenerationForm uses the "tool_id" query param of new tool
+// regenerations, the edit dialog has no tool ID and keeps toolID
+func parseToolRegenerationForm(c echo.Context, toolID shared.EntityID) (data ToolRegenerationFormData, ierrs []*errors.InputError) {
+	if c.FormValue("tool_id") != "" {
+		newToolID, err := utils.SanitizeInt64(c.FormValue("tool_id"))
+		if err != nil {
+			ierr := errors.NewInputError("", fmt.Sprintf("invalid tool ID: %v", err))
+			ierrs = append(ierrs, ierr)
+		}
+		toolID = shared.EntityID(newToolID)
 	}
-	data.ToolID = shared.EntityID(toolID)
+	data.ToolID = toolID
 
 	startTime, err := time.Parse("2006-01-02", c.FormValue("start"))
 	if err != nil {
@@ -161,6 +180,7 @@ func reRenderEditToolRegenerationDialog(c echo.Context, trID shared.EntityID, op
 		ToolRegenerationFormData: formData,
 		Open:                     open,
 		OOB:                      true,
+		Error:                    ierrs,
 	})
 	if err := t.Render(c.Request().Context(), c.Response()); err != nil {
 		return errors.NewRenderError(err, "EditToolRegenerationDialog")
@@ -5,7 +5,6 @@ import (
 	"log/slog"
 	"net/http"
 
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/knackwurstking/pg-press/internal/urlb"
@@ -14,12 +13,23 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func GetToolDialog(c echo.Context) *echo.HTTPError {
+// toolInputs maps the validated tool fields to the inputs of the tool dialogs
+var toolInputs = map[string]string{
+	"position":       "position",
+	"width":          "width",
+	"height":         "height",
+	"type":           "type",
+	"code":           "code",
+	"cycles_warning": "cycles-warning",
+	"cycles_error":   "cycles-error",
+}
+
+func (h *Handler) GetToolDialog(c echo.Context) *echo.HTTPError {
 	var tool *shared.Tool
 	id, _ := utils.GetQueryInt64(c, "id")
 	if id > 0 {
 		var merr *errors.HTTPError
-		tool, merr = db.GetTool(shared.EntityID(id))
+		tool, merr = h.db.GetTool(shared.EntityID(id))
 		if merr != nil {
 			return merr.Echo()
 		}
@@ -34,6 +44,9 @@ func GetToolDialog(c echo.Context) *echo.HTTPError {
 				Position: tool.Position,
 				Width:    tool.Width,
 				Height:   tool.Height,
+
+				CyclesWarning: tool.CyclesWarning,
+				CyclesError:   tool.CyclesError,
 			},
 			OOB:  true,
 			Open: true,
@@ -58,10 +71,15 @@ func GetToolDialog(c echo.Context) *echo.HTTPError {
 	return nil
 }
 
-func PostTool(c echo.Context) *echo.HTTPError {
+func (h *Handler) PostTool(c echo.Context) *echo.HTTPError {
+	user, merr := utils.GetUserFromContext(c)
+	if merr != nil {
+		return merr.Echo()
+	}
+
 	id, _ := utils.GetQueryInt64(c, "id")
 	if id > 0 {
-		return updateTool(c, shared.EntityID(id))
+		return h.updateTool(c, shared.EntityID(id), user)
 	}
 
 	formData, ierrs := parseToolForm(c)
@@ -76,46 +94,54 @@ func PostTool(c echo.Context) *echo.HTTPError {
 		Position: formData.Position,
 		Width:    formData.Width,
 		Height:   formData.Height,
+
+		CyclesWarning: formData.CyclesWarning,
+		CyclesError:   formData.CyclesError,
 	}
-	if merr := db.AddTool(tool); merr != nil {
-		ierr := errors.NewInputError("", fmt.Sprintf("Failed to create tool: %s", merr.Error()))
-		return reRenderNewToolDialog(c, true, formData, ierr)
+	if merr := h.db.AddTool(tool); merr != nil {
+		ierrs := inputErrors(merr, toolInputs, "Failed to create tool")
+		return reRenderNewToolDialog(c, true, formData, ierrs...)
 	}
+	h.db.Audit(user.ID, shared.AuditActionCreate, nil, tool)
 
 	utils.SetHXTrigger(c, "tool-tab-content")
 
-	return reRenderNewToolDialog(c, false, formData, nil)
+	return reRenderNewToolDialog(c, false, formData)
 }
 
-func updateTool(c echo.Context, toolID shared.EntityID) *echo.HTTPError {
+func (h *Handler) updateTool(c echo.Context, toolID shared.EntityID, user *shared.User) *echo.HTTPError {
 	formData, ierrs := parseToolForm(c)
 	if len(ierrs) > 0 {
 		return reRenderEditToolDialog(c, toolID, true, formData, ierrs...)
 	}
 
-	tool, merr := db.GetTool(toolID)
+	tool, merr := h.db.GetTool(toolID)
 	if merr != nil {
 		ierr := errors.NewInputError("", fmt.Sprintf("Failed to load tool: %s", merr.Error()))
 		return reRenderEditToolDialog(c, toolID, true, formData, ierr)
 	}
+	before := tool.Clone()
 	tool.Type = formData.Type
 	tool.Code = formData.Code
 	tool.Position = formData.Position
 	tool.Width = formData.Width
 	tool.Height = formData.Height
+	tool.CyclesWarning = formData.CyclesWarning
+	tool.CyclesError = formData.CyclesError
 
 	slog.Debug("Updating tool", "tool", tool)
 
-	if merr = db.UpdateTool(tool); merr != nil {
-		ierr := errors.NewInputError("", fmt.Sprintf("Failed to update tool: %s", merr.Error()))
-		return reRenderEditToolDialog(c, toolID, true, formData, ierr)
+	if merr = h.db.UpdateTool(tool); merr != nil {
+		ierrs := inputErrors(merr, toolInputs, "Failed to update tool")
+		return reRenderEditToolDialog(c, toolID, true, formData, ierrs...)
 	}
+	h.db.Audit(user.ID, shared.AuditActionUpdate, before, tool)
 
 	// Set HX headers
 	utils.SetHXRedirect(c, urlb.Tool(tool.ID))
 
 	// Close dialog
-	return reRenderEditToolDialog(c, toolID, false, formData, nil)
+	return reRenderEditToolDialog(c, toolID, false, formData)
 }
 
 func parseToolForm(c echo.Context) (data ToolFormData, ierrs []*errors.InputError) {
@@ -150,11 +176,31 @@ func parseToolForm(c echo.Context) (data ToolFormData, ierrs []*errors.InputErro
 		ierrs = append(ierrs, ierr)
 	}
 
+	data.CyclesWarning, data.CyclesError, ierrs = parseCyclesThresholds(c, ierrs)
+
 	slog.Debug("Tool dialog form values", "data", data)
 
 	return
 }
 
+// parseCyclesThresholds parses the optional cycle threshold overrides of the tool
+// and cassette dialogs, empty inputs are zero (no override)
+func parseCyclesThresholds(c echo.Context, ierrs []*errors.InputError) (int64, int64, []*errors.InputError) {
+	warningCycles, err := utils.SanitizeInt64(c.FormValue("cycles-warning"))
+	if err != nil {
+		ierr := errors.NewInputError("cycles-warning", fmt.Sprintf("Invalid warning threshold: %s", c.FormValue("cycles-warning")))
+		ierrs = append(ierrs, ierr)
+	}
+
+	errorCycles, err := utils.SanitizeInt64(c.FormValue("cycles-error"))
+	if err != nil {
+		ierr := errors.NewInputError("cycles-error", fmt.Sprintf("Invalid error threshold: %s", c.FormValue("cycles-error")))
+		ierrs = append(ierrs, ierr)
+	}
+
+	return warningCycles, errorCycles, ierrs
+}
+
 func reRenderNewToolDialog(c echo.Context, open bool, data ToolFormData, ierrs ...*errors.InputError) *echo.HTTPError {
 	t := NewToolDialog(ToolDialogProps{
 		ToolFormData: data,
@@ -0,0 +1,31 @@
+package dialogs
+
+import (
+	"fmt"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+)
+
+// inputErrors converts the error of a failed database operation to the input
+// errors of a dialog, inputs maps the validated fields to the input IDs.
+// Validation errors are shown next to their inputs, errors for fields without
+// input and all other errors are shown as dialog error, prefixed with message.
+func inputErrors(herr *errors.HTTPError, inputs map[string]string, message string) []*errors.InputError {
+	verr := herr.ValidationError()
+	if verr == nil || len(verr.Fields) == 0 {
+		return []*errors.InputError{
+			errors.NewInputError("", fmt.Sprintf("%s: %v", message, herr)),
+		}
+	}
+
+	ierrs := make([]*errors.InputError, 0, len(verr.Fields))
+	for _, f := range verr.Fields {
+		inputID, ok := inputs[f.InputID]
+		if !ok {
+			ierrs = append(ierrs, errors.NewInputError("", fmt.Sprintf("%s: %s", message, f.Message)))
+			continue
+		}
+		ierrs = append(ierrs, errors.NewInputError(inputID, f.Message))
+	}
+	return ierrs
+}
@@ -3,39 +3,56 @@ package dialogs
 import (
 	"net/http"
 
+	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/env"
 	"github.com/knackwurstking/ui"
 	"github.com/labstack/echo/v4"
 )
 
-func Register(e *echo.Echo, path string) {
+// Repository contains the database operations used by the dialogs route handlers.
+type Repository interface {
+	db.ToolRepository
+	db.PressRepository
+	db.CycleRepository
+	db.NoteRepository
+	db.AuditRepository
+}
+
+// Handler holds the dependencies of all dialogs route handlers.
+type Handler struct {
+	db Repository
+}
+
+func Register(e *echo.Echo, path string, store db.Repository) {
+	h := &Handler{db: store}
+
 	ui.RegisterEchoRoutes(e, env.ServerPathPrefix, []*ui.EchoRoute{
 		// Edit tool dialog
-		ui.NewEchoRoute(http.MethodGet, path+"/edit-tool", GetToolDialog),
-		ui.NewEchoRoute(http.MethodPost, path+"/edit-tool", PostTool),
+		ui.NewEchoRoute(http.MethodGet, path+"/edit-tool", h.GetToolDialog),
+		ui.NewEchoRoute(http.MethodPost, path+"/edit-tool", h.PostTool),
 
 		// Edit cassette dialog
-		ui.NewEchoRoute(http.MethodGet, path+"/edit-cassette", GetCassetteDialog),
-		ui.NewEchoRoute(http.MethodPost, path+"/edit-cassette", PostCassette),
+		ui.NewEchoRoute(http.MethodGet, path+"/edit-cassette", h.GetCassetteDialog),
+		ui.NewEchoRoute(http.MethodPost, path+"/edit-cassette", h.PostCassette),
 
 		// Edit note dialog
-		ui.NewEchoRoute(http.MethodGet, path+"/edit-note", GetEditNote),
-		ui.NewEchoRoute(http.MethodPost, path+"/edit-note", PostNote),
+		ui.NewEchoRoute(http.MethodGet, path+"/edit-note", h.GetEditNote),
+		ui.NewEchoRoute(http.MethodPost, path+"/edit-note", h.PostNote),
 
 		// Edit cycle dialog
-		ui.NewEchoRoute(http.MethodGet, path+"/edit-cycle", GetEditCycle),
-		ui.NewEchoRoute(http.MethodPost, path+"/edit-cycle", PostCycle),
+		ui.NewEchoRoute(http.MethodGet, path+"/edit-cycle", h.GetEditCycle),
+		ui.NewEchoRoute(http.MethodPost, path+"/edit-cycle", h.PostCycle),
 
 		// Edit metal sheet dialog
-		ui.NewEchoRoute(http.MethodGet, path+"/edit-metal-sheet", GetEditMetalSheet),
-		ui.NewEchoRoute(http.MethodPost, path+"/edit-metal-sheet", PostMetalSheet),
+		ui.NewEchoRoute(http.MethodGet, path+"/edit-metal-sheet", h.GetEditMetalSheet),
+		ui.NewEchoRoute(http.MethodPost, path+"/edit-metal-sheet", h.PostMetalSheet),
 
 		// New/Edit a Press
-		ui.NewEchoRoute(http.MethodGet, path+"/edit-press", GetEditPress),
-		ui.NewEchoRoute(http.MethodPost, path+"/edit-press", PostPress),
+		ui.NewEchoRoute(http.MethodGet, path+"/edit-press", h.GetEditPress),
+		ui.NewEchoRoute(http.MethodPost, path+"/edit-press", h.PostPress),
 
 		// Edit tool regeneration dialog
-		ui.NewEchoRoute(http.MethodGet, path+"/edit-tool-regeneration", GetEditToolRegeneration),
-		ui.NewEchoRoute(http.MethodPut, path+"/edit-tool-regeneration", PostToolRegeneration),
+		ui.NewEchoRoute(http.MethodGet, path+"/edit-tool-regeneration", h.GetEditToolRegeneration),
+		ui.NewEchoRoute(http.MethodPut, path+"/edit-tool-regeneration", h.PostToolRegeneration),
 	})
 }
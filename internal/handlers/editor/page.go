@@ -2,7 +2,6 @@ package editor
 
 import (
 	"github.com/a-h/templ"
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/handlers/editor/templates"
 	"github.com/knackwurstking/pg-press/internal/shared"
@@ -10,7 +9,7 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func Page(c echo.Context) *echo.HTTPError {
+func (h *Handler) Page(c echo.Context) *echo.HTTPError {
 	editorType, eerr := getQueryEditorType(c)
 	if eerr != nil {
 		return eerr
@@ -34,7 +33,7 @@ func Page(c echo.Context) *echo.HTTPError {
 		useMarkdown bool
 	)
 	if id > 0 {
-		tr, merr := db.GetTroubleReport(id)
+		tr, merr := h.db.GetTroubleReport(id)
 		if merr != nil {
 			return merr.Echo()
 		}
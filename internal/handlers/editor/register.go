@@ -3,19 +3,33 @@ package editor
 import (
 	"net/http"
 
+	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/env"
 
 	"github.com/knackwurstking/ui"
 	"github.com/labstack/echo/v4"
 )
 
-func Register(e *echo.Echo, path string) {
+// Repository contains the database operations used by the editor route handlers.
+type Repository interface {
+	db.ReportRepository
+	db.AuditRepository
+}
+
+// Handler holds the dependencies of all editor route handlers.
+type Handler struct {
+	db Repository
+}
+
+func Register(e *echo.Echo, path string, store db.Repository) {
+	h := &Handler{db: store}
+
 	ui.RegisterEchoRoutes(
 		e,
 		env.ServerPathPrefix,
 		[]*ui.EchoRoute{
-			ui.NewEchoRoute(http.MethodGet, path, Page),
-			ui.NewEchoRoute(http.MethodPost, path+"/save", Save),
+			ui.NewEchoRoute(http.MethodGet, path, h.Page),
+			ui.NewEchoRoute(http.MethodPost, path+"/save", h.Save),
 		},
 	)
 }
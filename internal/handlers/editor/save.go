@@ -9,7 +9,6 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/env"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/shared"
@@ -20,7 +19,7 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func Save(c echo.Context) *echo.HTTPError {
+func (h *Handler) Save(c echo.Context) *echo.HTTPError {
 	var (
 		editorType  = shared.EditorType(c.FormValue("type"))
 		vID         = c.FormValue("id")
@@ -35,6 +34,11 @@ func Save(c echo.Context) *echo.HTTPError {
 		return echo.NewHTTPError(http.StatusBadRequest, "editor type is required")
 	}
 
+	user, merr := utils.GetUserFromContext(c)
+	if merr != nil {
+		return merr.Echo()
+	}
+
 	var id int64
 	if vID != "" {
 		var err error
@@ -61,10 +65,11 @@ func Save(c echo.Context) *echo.HTTPError {
 
 	switch editorType {
 	case shared.EditorTypeTroubleReport:
-		tr, merr := db.GetTroubleReport(shared.EntityID(id))
+		tr, merr := h.db.GetTroubleReport(shared.EntityID(id))
 		if merr != nil && !merr.IsNotFoundError() {
 			return merr.Echo()
 		}
+		var before *shared.TroubleReport
 		if tr == nil {
 			tr = &shared.TroubleReport{
 				Title:       title,
@@ -72,6 +77,7 @@ func Save(c echo.Context) *echo.HTTPError {
 				UseMarkdown: useMarkdown,
 			}
 		} else {
+			before = tr.Clone()
 			tr.Title = title
 			tr.Content = content
 			tr.UseMarkdown = useMarkdown
@@ -90,13 +96,15 @@ func Save(c echo.Context) *echo.HTTPError {
 		tr.LinkedAttachments = attachments
 
 		if merr != nil && merr.IsNotFoundError() {
-			if merr = db.AddTroubleReport(tr); merr != nil {
+			if merr = h.db.AddTroubleReport(tr); merr != nil {
 				return merr.Echo()
 			}
+			h.db.Audit(user.ID, shared.AuditActionCreate, nil, tr)
 		} else {
-			if merr = db.UpdateTroubleReport(tr); merr != nil {
+			if merr = h.db.UpdateTroubleReport(tr); merr != nil {
 				return merr.Echo()
 			}
+			h.db.Audit(user.ID, shared.AuditActionUpdate, before, tr)
 		}
 	}
 
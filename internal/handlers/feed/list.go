@@ -0,0 +1,75 @@
+package feed
+
+import (
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/handlers/feed/templates"
+	"github.com/knackwurstking/pg-press/internal/shared"
+	"github.com/knackwurstking/pg-press/internal/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// pageSize is the number of feeds loaded at once
+const pageSize = 25
+
+func (h *Handler) HTMXGetList(c echo.Context) *echo.HTTPError {
+	filter, herr := parseFilter(c)
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	page, herr := optionalQueryInt64(c, "page")
+	if herr != nil {
+		return herr.Echo()
+	}
+	seen, herr := optionalQueryInt64(c, "seen")
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	filter.Limit = pageSize
+	filter.Offset = int(page) * pageSize
+
+	feeds, herr := h.db.ListFeeds(filter)
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	userNames, herr := h.db.ListUserNames()
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	t := templates.List(templates.ListProps{
+		Feeds:     feeds,
+		UserNames: userNames,
+		Filter:    filter,
+		Page:      int(page),
+		HasMore:   len(feeds) == pageSize,
+		Seen:      shared.EntityID(seen),
+	})
+	if err := t.Render(c.Request().Context(), c.Response()); err != nil {
+		return errors.NewRenderError(err, "Feed List")
+	}
+
+	return nil
+}
+
+// HTMXGetUnread renders the number of unread feeds for the navigation badge
+func (h *Handler) HTMXGetUnread(c echo.Context) *echo.HTTPError {
+	user, herr := utils.GetUserFromContext(c)
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	count, herr := h.db.CountUnreadFeeds(user.ID)
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	t := templates.UnreadBadge(count)
+	if err := t.Render(c.Request().Context(), c.Response()); err != nil {
+		return errors.NewRenderError(err, "Feed Unread Badge")
+	}
+	return nil
+}
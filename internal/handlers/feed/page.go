@@ -0,0 +1,79 @@
+package feed
+
+import (
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/handlers/feed/templates"
+	"github.com/knackwurstking/pg-press/internal/shared"
+	"github.com/knackwurstking/pg-press/internal/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) GetFeedPage(c echo.Context) *echo.HTTPError {
+	user, herr := utils.GetUserFromContext(c)
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	filter, herr := parseFilter(c)
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	presses, herr := h.db.ListPress()
+	if herr != nil {
+		return herr.Echo()
+	}
+	tools, herr := h.db.ListTools()
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	// Remember what the user has seen before, to highlight new feeds in the list
+	seen, herr := h.db.GetLastFeed(user.ID)
+	if herr != nil {
+		return herr.Echo()
+	}
+	if herr = h.db.MarkFeedsRead(user.ID); herr != nil {
+		return herr.Echo()
+	}
+
+	t := templates.Page(templates.PageProps{
+		Presses: presses,
+		Tools:   tools,
+		PressID: filter.PressID,
+		ToolID:  filter.ToolID,
+		Seen:    seen,
+	})
+	if err := t.Render(c.Request().Context(), c.Response()); err != nil {
+		return errors.NewRenderError(err, "Feed Page")
+	}
+
+	return nil
+}
+
+// parseFilter reads the optional press and tool filters from the query
+func parseFilter(c echo.Context) (shared.FeedFilter, *errors.HTTPError) {
+	var filter shared.FeedFilter
+
+	pressID, herr := optionalQueryInt64(c, "press")
+	if herr != nil {
+		return filter, herr
+	}
+	toolID, herr := optionalQueryInt64(c, "tool")
+	if herr != nil {
+		return filter, herr
+	}
+
+	filter.PressID = shared.EntityID(pressID)
+	filter.ToolID = shared.EntityID(toolID)
+	return filter, nil
+}
+
+// optionalQueryInt64 returns 0 for a missing query parameter
+func optionalQueryInt64(c echo.Context, paramName string) (int64, *errors.HTTPError) {
+	if c.QueryParam(paramName) == "" {
+		return 0, nil
+	}
+	return utils.GetQueryInt64(c, paramName)
+}
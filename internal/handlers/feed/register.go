@@ -0,0 +1,34 @@
+package feed
+
+import (
+	"net/http"
+
+	"github.com/knackwurstking/pg-press/internal/db"
+	"github.com/knackwurstking/pg-press/internal/env"
+
+	"github.com/knackwurstking/ui"
+	"github.com/labstack/echo/v4"
+)
+
+// Repository contains the database operations used by the feed route handlers.
+type Repository interface {
+	db.FeedRepository
+	db.ToolRepository
+	db.PressRepository
+	db.UserRepository
+}
+
+// Handler holds the dependencies of all feed route handlers.
+type Handler struct {
+	db Repository
+}
+
+func Register(e *echo.Echo, path string, store db.Repository) {
+	h := &Handler{db: store}
+
+	ui.RegisterEchoRoutes(e, env.ServerPathPrefix, []*ui.EchoRoute{
+		ui.NewEchoRoute(http.MethodGet, path, h.GetFeedPage),
+		ui.NewEchoRoute(http.MethodGet, path+"/list", h.HTMXGetList),
+		ui.NewEchoRoute(http.MethodGet, path+"/unread", h.HTMXGetUnread),
+	})
+}
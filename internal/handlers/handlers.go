@@ -1,29 +1,43 @@
 package handlers
 
 import (
+	"github.com/knackwurstking/pg-press/internal/db"
+	"github.com/knackwurstking/pg-press/internal/handlers/admin"
+	"github.com/knackwurstking/pg-press/internal/handlers/api"
 	"github.com/knackwurstking/pg-press/internal/handlers/auth"
 	"github.com/knackwurstking/pg-press/internal/handlers/dialogs"
 	"github.com/knackwurstking/pg-press/internal/handlers/editor"
+	"github.com/knackwurstking/pg-press/internal/handlers/feed"
+	"github.com/knackwurstking/pg-press/internal/handlers/health"
 	"github.com/knackwurstking/pg-press/internal/handlers/home"
 	"github.com/knackwurstking/pg-press/internal/handlers/metalsheets"
 	"github.com/knackwurstking/pg-press/internal/handlers/notes"
 	"github.com/knackwurstking/pg-press/internal/handlers/press"
 	"github.com/knackwurstking/pg-press/internal/handlers/profile"
+	"github.com/knackwurstking/pg-press/internal/handlers/search"
 	"github.com/knackwurstking/pg-press/internal/handlers/tool"
 	"github.com/knackwurstking/pg-press/internal/handlers/tools"
+	"github.com/knackwurstking/pg-press/internal/handlers/trash"
 	"github.com/knackwurstking/pg-press/internal/handlers/troublereports"
 	"github.com/knackwurstking/pg-press/internal/handlers/umbau"
+	"github.com/knackwurstking/pg-press/internal/jobs"
 
 	"github.com/labstack/echo/v4"
 )
 
-func RegisterAll(e *echo.Echo) {
+// RegisterAll registers the routes of all handler packages, using store for
+// all database access, the admin page shows and runs the jobs of scheduler.
+// All routes are wrapped with the permission middleware, see permissions.
+func RegisterAll(e *echo.Echo, store db.Repository, scheduler *jobs.Scheduler) {
+	e.Use(middlewarePermissions())
+
 	registers := []struct {
-		handler func(e *echo.Echo, path string)
+		handler func(e *echo.Echo, path string, store db.Repository)
 		subPath string
 	}{
 		{handler: home.Register, subPath: ""},
 		{handler: auth.Register, subPath: ""},
+		{handler: health.Register, subPath: ""},
 		{handler: profile.Register, subPath: "/profile"},
 		{handler: tools.Register, subPath: "/tools"},
 		{handler: dialogs.Register, subPath: "/dialog"},
@@ -34,8 +48,15 @@ func RegisterAll(e *echo.Echo) {
 		{handler: metalsheets.Register, subPath: "/metal-sheets"},
 		{handler: troublereports.Register, subPath: "/trouble-reports"},
 		{handler: editor.Register, subPath: "/editor"},
+		{handler: func(e *echo.Echo, path string, store db.Repository) {
+			admin.Register(e, path, store, scheduler)
+		}, subPath: "/admin"},
+		{handler: trash.Register, subPath: "/trash"},
+		{handler: search.Register, subPath: "/search"},
+		{handler: feed.Register, subPath: "/feed"},
+		{handler: api.Register, subPath: "/api/v1"},
 	}
 	for _, reg := range registers {
-		reg.handler(e, reg.subPath)
+		reg.handler(e, reg.subPath, store)
 	}
 }
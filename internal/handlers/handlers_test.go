@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/knackwurstking/pg-press/internal/db"
+	"github.com/knackwurstking/pg-press/internal/jobs"
+	"github.com/knackwurstking/pg-press/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// newTestServer registers all routes on a migrated store inside a temporary
+// directory, all requests are done as user.
+func newTestServer(t *testing.T, user *shared.User) (*echo.Echo, *db.Store) {
+	t.Helper()
+
+	store, err := db.Connect(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	if _, err := store.Migrate(false); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if herr := store.AddUser(user); herr != nil {
+		t.Fatalf("add user: %v", herr)
+	}
+
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user", user)
+			return next(c)
+		}
+	})
+	RegisterAll(e, store, jobs.NewScheduler())
+
+	return e, store
+}
+
+func request(e *echo.Echo, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	req.Header.Set("HX-Request", "true")
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestToolLifecycle(t *testing.T) {
+	e, store := newTestServer(t, &shared.User{ID: 1, Name: "admin", Role: shared.UserRoleAdmin})
+
+	rec := request(e, http.MethodPost, "/api/v1/tools",
+		`{"width":120,"height":60,"position":1,"type":"MASS","code":"G01"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create tool: status %d, body %s", rec.Code, rec.Body)
+	}
+	var created shared.Tool
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode tool: %v", err)
+	}
+	if created.ID == 0 || created.Code != "G01" {
+		t.Fatalf("created tool = %+v", created)
+	}
+
+	tool, herr := store.GetTool(created.ID)
+	if herr != nil {
+		t.Fatalf("get tool from store: %v", herr)
+	}
+	if tool.Type != "MASS" {
+		t.Errorf("stored tool type = %q, want %q", tool.Type, "MASS")
+	}
+
+	rec = request(e, http.MethodGet, "/api/v1/tools/"+created.ID.String(), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("get tool: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	rec = request(e, http.MethodDelete, "/tools/delete?id="+created.ID.String(), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("delete tool: status %d, body %s", rec.Code, rec.Body)
+	}
+	if rec.Header().Get("HX-Redirect") == "" {
+		t.Error("delete tool: missing HX-Redirect header")
+	}
+
+	rec = request(e, http.MethodGet, "/api/v1/tools/"+created.ID.String(), "")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("get deleted tool: status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	rec = request(e, http.MethodPost, "/trash/restore?kind=tools&id="+created.ID.String(), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("restore tool: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	entries, herr := store.ListAuditEntries("tool_"+created.ID.String(), 10)
+	if herr != nil {
+		t.Fatalf("list audit entries: %v", herr)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d audit entries for the tool, want 3 (create, delete and restore)", len(entries))
+	}
+	if restored := entries[0]; restored.Action != shared.AuditActionRestore || restored.After == "" {
+		t.Errorf("latest audit entry = %s, want the restore with the restored tool", restored.String())
+	}
+}
+
+func TestPermissionDenied(t *testing.T) {
+	e, store := newTestServer(t, &shared.User{ID: 1, Name: "viewer", Role: shared.UserRoleViewer})
+
+	rec := request(e, http.MethodPost, "/api/v1/tools",
+		`{"width":120,"height":60,"position":1,"type":"MASS","code":"G01"}`)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("create tool as viewer: status %d, want %d", rec.Code, http.StatusForbidden)
+	}
+
+	tools, herr := store.ListTools()
+	if herr != nil {
+		t.Fatalf("list tools: %v", herr)
+	}
+	if len(tools) != 0 {
+		t.Errorf("got %d tools, want none", len(tools))
+	}
+
+	rec = request(e, http.MethodGet, "/api/v1/tools", "")
+	if rec.Code != http.StatusOK {
+		t.Errorf("list tools as viewer: status %d, body %s", rec.Code, rec.Body)
+	}
+}
+
+func TestCyclePerformedBy(t *testing.T) {
+	user := &shared.User{ID: 1, Name: "admin", Role: shared.UserRoleAdmin}
+	e, store := newTestServer(t, user)
+
+	tool := &shared.Tool{Width: 120, Height: 60, Position: shared.SlotUpper, Type: "MASS", Code: "G01"}
+	if herr := store.AddTool(tool); herr != nil {
+		t.Fatalf("add tool: %v", herr)
+	}
+	press := &shared.Press{Number: 5, Type: shared.MachineTypeSACMI}
+	if herr := store.AddPress(press); herr != nil {
+		t.Fatalf("add press: %v", herr)
+	}
+
+	body := func(cycles int) string {
+		return fmt.Sprintf(`{"tool_id":%d,"press_id":%d,"cycles":%d,"stop":1000,"performed_by":999}`,
+			tool.ID, press.ID, cycles)
+	}
+
+	rec := request(e, http.MethodPost, "/api/v1/cycles", body(1000))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create cycle: status %d, body %s", rec.Code, rec.Body)
+	}
+	var created shared.Cycle
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode cycle: %v", err)
+	}
+
+	cycle, herr := store.GetCycle(created.ID)
+	if herr != nil {
+		t.Fatalf("get cycle from store: %v", herr)
+	}
+	if cycle.PerformedBy != user.ID {
+		t.Errorf("created cycle performed by %d, want %d", cycle.PerformedBy, user.ID)
+	}
+
+	// Somebody else read the cycles, the update keeps them as the performer
+	cycle.PerformedBy = 2
+	if herr := store.UpdateCycle(cycle); herr != nil {
+		t.Fatalf("update cycle in store: %v", herr)
+	}
+
+	rec = request(e, http.MethodPut, "/api/v1/cycles/"+created.ID.String(), body(2000))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update cycle: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	cycle, herr = store.GetCycle(created.ID)
+	if herr != nil {
+		t.Fatalf("get cycle from store: %v", herr)
+	}
+	if cycle.PerformedBy != 2 {
+		t.Errorf("updated cycle performed by %d, want the stored %d", cycle.PerformedBy, 2)
+	}
+	if cycle.PressCycles != 2000 {
+		t.Errorf("updated cycle has %d press cycles, want %d", cycle.PressCycles, 2000)
+	}
+}
+
+func TestToolThresholdAudit(t *testing.T) {
+	e, store := newTestServer(t, &shared.User{ID: 1, Name: "admin", Role: shared.UserRoleAdmin})
+
+	form := func(method, target, warning string) *httptest.ResponseRecorder {
+		body := "type=MASS&width=0&height=0&position=0&error=5000&warning=" + warning
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		req.Header.Set("HX-Request", "true")
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := form(http.MethodPost, "/admin/tool-thresholds", "1000"); rec.Code != http.StatusOK {
+		t.Fatalf("add threshold: status %d, body %s", rec.Code, rec.Body)
+	}
+	thresholds, herr := store.ListToolThresholds()
+	if herr != nil {
+		t.Fatalf("list thresholds: %v", herr)
+	}
+	if len(thresholds) != 1 {
+		t.Fatalf("got %d thresholds, want 1", len(thresholds))
+	}
+	id := thresholds[0].ID.String()
+
+	if rec := form(http.MethodPut, "/admin/tool-thresholds?id="+id, "2000"); rec.Code != http.StatusOK {
+		t.Fatalf("update threshold: status %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := request(e, http.MethodDelete, "/admin/tool-thresholds?id="+id, ""); rec.Code != http.StatusOK {
+		t.Fatalf("delete threshold: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	entries, herr := store.ListAuditEntries("tool_threshold_"+id, 10)
+	if herr != nil {
+		t.Fatalf("list audit entries: %v", herr)
+	}
+	var actions []shared.AuditAction
+	for _, entry := range entries {
+		actions = append(actions, entry.Action)
+	}
+	want := []shared.AuditAction{shared.AuditActionDelete, shared.AuditActionUpdate, shared.AuditActionCreate}
+	if fmt.Sprint(actions) != fmt.Sprint(want) {
+		t.Errorf("audit actions = %v, want %v", actions, want)
+	}
+}
@@ -0,0 +1,94 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/knackwurstking/pg-press/internal/db"
+	"github.com/knackwurstking/pg-press/internal/env"
+
+	"github.com/labstack/echo/v4"
+)
+
+// checkTimeout limits the time of all checks of a request
+const checkTimeout = 2 * time.Second
+
+// shuttingDown is set by ShuttingDown, readyz reports not ready then
+var shuttingDown atomic.Bool
+
+// ShuttingDown marks the server as not ready, called before draining requests
+// on shutdown so load balancers stop sending new ones.
+func ShuttingDown() {
+	shuttingDown.Store(true)
+}
+
+// Status is the response of the health endpoints, checks maps every database
+// ("db:<name>") and the images directory to "ok" or the error.
+type Status struct {
+	Status string            `json:"status"` // Status is "ok", "error" or "shutting_down"
+	Checks map[string]string `json:"checks"`
+}
+
+// GetHealthz checks every database handle and the images directory.
+func (h *Handler) GetHealthz(c echo.Context) *echo.HTTPError {
+	return h.writeStatus(c, h.check(c.Request().Context()))
+}
+
+// GetReadyz is GetHealthz, but also fails while the server shuts down.
+func (h *Handler) GetReadyz(c echo.Context) *echo.HTTPError {
+	status := h.check(c.Request().Context())
+	if shuttingDown.Load() {
+		status.Status = "shutting_down"
+	}
+	return h.writeStatus(c, status)
+}
+
+func (h *Handler) check(ctx context.Context) *Status {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	status := &Status{Status: "ok", Checks: map[string]string{}}
+	add := func(name string, err error) {
+		if err != nil {
+			status.Status = "error"
+			status.Checks[name] = err.Error()
+			return
+		}
+		status.Checks[name] = "ok"
+	}
+
+	for _, name := range db.DatabaseNames() {
+		add("db:"+name, h.db.Ping(ctx, name))
+	}
+	add("images", checkDir(env.ServerPathImages))
+
+	return status
+}
+
+func (h *Handler) writeStatus(c echo.Context, status *Status) *echo.HTTPError {
+	code := http.StatusOK
+	if status.Status != "ok" {
+		code = http.StatusServiceUnavailable
+	}
+
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	if err := c.JSON(code, status); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	return nil
+}
+
+func checkDir(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package health
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/metrics"
+	"github.com/knackwurstking/pg-press/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// scrapeMutex serializes scrapes, the domain gauges are reset and set per scrape
+var scrapeMutex sync.Mutex
+
+// GetMetrics updates the domain gauges and writes all metrics in the Prometheus
+// text exposition format. Needs an admin, see the permissions of the handlers package.
+func (h *Handler) GetMetrics(c echo.Context) *echo.HTTPError {
+	scrapeMutex.Lock()
+	defer scrapeMutex.Unlock()
+
+	if herr := h.updateDomainGauges(); herr != nil {
+		return herr.WrapEcho("update domain metrics")
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, metrics.ContentType)
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	c.Response().WriteHeader(http.StatusOK)
+	if err := metrics.Write(c.Response()); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	return nil
+}
+
+func (h *Handler) updateDomainGauges() *errors.HTTPError {
+	cookies, herr := h.db.ListCookies()
+	if herr != nil {
+		return herr
+	}
+	sessions := 0
+	for _, cookie := range cookies {
+		if !cookie.IsExpired() {
+			sessions++
+		}
+	}
+	metrics.ActiveSessions.Set(float64(sessions))
+
+	presses, herr := h.db.ListPress()
+	if herr != nil {
+		return herr
+	}
+	metrics.PressCycles.Reset()
+	for _, p := range presses {
+		cycles, herr := h.db.ListCyclesByPressID(p.ID)
+		if herr != nil {
+			return herr
+		}
+		if len(cycles) > 0 {
+			metrics.PressCycles.Set(float64(cycles[0].PressCycles), p.Number.String()) // Newest first
+		}
+	}
+
+	tools, herr := h.db.ListTools()
+	if herr != nil {
+		return herr
+	}
+	var warning, critical int
+	for _, t := range tools {
+		switch {
+		case t.IsDead:
+		case t.Thresholds.IsError(t.Cycles):
+			critical++
+		case t.Thresholds.IsWarning(t.Cycles):
+			warning++
+		}
+	}
+	metrics.ToolsOverCycles.Set(float64(warning), "warning")
+	metrics.ToolsOverCycles.Set(float64(critical), "error")
+
+	regenerations, herr := h.db.ListToolRegenerations()
+	if herr != nil {
+		return herr
+	}
+	regenerating := map[shared.EntityID]bool{}
+	for _, r := range regenerations {
+		if r.Stop == 0 {
+			regenerating[r.ToolID] = true
+		}
+	}
+	metrics.ToolsRegenerating.Set(float64(len(regenerating)))
+
+	return nil
+}
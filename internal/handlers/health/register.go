@@ -0,0 +1,35 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/knackwurstking/pg-press/internal/db"
+	"github.com/knackwurstking/pg-press/internal/env"
+
+	"github.com/knackwurstking/ui"
+	"github.com/labstack/echo/v4"
+)
+
+// Repository contains the database operations used by the health check and metrics handlers.
+type Repository interface {
+	db.HealthRepository
+	db.UserRepository
+	db.ToolRepository
+	db.PressRepository
+	db.CycleRepository
+}
+
+// Handler holds the dependencies of the health check and metrics handlers.
+type Handler struct {
+	db Repository
+}
+
+func Register(e *echo.Echo, path string, store db.Repository) {
+	h := &Handler{db: store}
+
+	ui.RegisterEchoRoutes(e, env.ServerPathPrefix, []*ui.EchoRoute{
+		ui.NewEchoRoute(http.MethodGet, path+"/healthz", h.GetHealthz),
+		ui.NewEchoRoute(http.MethodGet, path+"/readyz", h.GetReadyz),
+		ui.NewEchoRoute(http.MethodGet, path+"/metrics", h.GetMetrics),
+	})
+}
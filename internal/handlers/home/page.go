@@ -7,7 +7,7 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func GetHomePage(c echo.Context) *echo.HTTPError {
+func (h *Handler) GetHomePage(c echo.Context) *echo.HTTPError {
 	t := templates.HomePage()
 	err := t.Render(c.Request().Context(), c.Response())
 	if err != nil {
@@ -3,14 +3,20 @@ package home
 import (
 	"net/http"
 
+	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/env"
 
 	"github.com/knackwurstking/ui"
 	"github.com/labstack/echo/v4"
 )
 
-func Register(e *echo.Echo, path string) {
+// Handler holds the dependencies of all home route handlers.
+type Handler struct{}
+
+func Register(e *echo.Echo, path string, _ db.Repository) {
+	h := &Handler{}
+
 	ui.RegisterEchoRoutes(e, env.ServerPathPrefix, []*ui.EchoRoute{
-		ui.NewEchoRoute(http.MethodGet, path, GetHomePage),
+		ui.NewEchoRoute(http.MethodGet, path, h.GetHomePage),
 	})
 }
@@ -3,14 +3,28 @@ package metalsheets
 import (
 	"net/http"
 
+	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/env"
 
 	"github.com/knackwurstking/ui"
 	"github.com/labstack/echo/v4"
 )
 
-func Register(e *echo.Echo, path string) {
+// Repository contains the database operations used by the metalsheets route handlers.
+type Repository interface {
+	db.ToolRepository
+	db.AuditRepository
+}
+
+// Handler holds the dependencies of all metalsheets route handlers.
+type Handler struct {
+	db Repository
+}
+
+func Register(e *echo.Echo, path string, store db.Repository) {
+	h := &Handler{db: store}
+
 	ui.RegisterEchoRoutes(e, env.ServerPathPrefix, []*ui.EchoRoute{
-		ui.NewEchoRoute(http.MethodDelete, path+"/delete", DeleteMetalSheet),
+		ui.NewEchoRoute(http.MethodDelete, path+"/delete", h.DeleteMetalSheet),
 	})
 }
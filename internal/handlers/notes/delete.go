@@ -3,26 +3,36 @@ package notes
 import (
 	"log/slog"
 
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/knackwurstking/pg-press/internal/utils"
 
 	"github.com/labstack/echo/v4"
 )
 
-func DeleteNote(c echo.Context) *echo.HTTPError {
+func (h *Handler) DeleteNote(c echo.Context) *echo.HTTPError {
 	id, merr := utils.GetQueryInt64(c, "id")
 	if merr != nil {
 		return merr.Echo()
 	}
 
+	user, merr := utils.GetUserFromContext(c)
+	if merr != nil {
+		return merr.Echo()
+	}
+
 	slog.Debug("Deleting note", "id", id, "user_name", c.Get("user_name"))
 
+	note, merr := h.db.GetNote(shared.EntityID(id))
+	if merr != nil {
+		return merr.Echo()
+	}
+
 	// Delete the note
-	merr = db.DeleteNote(shared.EntityID(id))
+	merr = h.db.DeleteNote(note.ID, user.ID)
 	if merr != nil {
 		return merr.Echo()
 	}
+	h.db.Audit(user.ID, shared.AuditActionDelete, note, nil)
 
 	// Trigger reload of notes sections
 	utils.SetHXTrigger(c, "reload-notes")
@@ -1,25 +1,30 @@
 package notes
 
 import (
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/handlers/notes/templates"
+	"github.com/knackwurstking/pg-press/internal/utils"
 
 	"github.com/labstack/echo/v4"
 )
 
-func GetNotesGrid(c echo.Context) *echo.HTTPError {
-	notes, merr := db.ListNotes()
+func (h *Handler) GetNotesGrid(c echo.Context) *echo.HTTPError {
+	user, merr := utils.GetUserFromContext(c)
 	if merr != nil {
 		return merr.Echo()
 	}
 
-	tools, merr := db.ListTools()
+	notes, merr := h.db.ListNotes()
 	if merr != nil {
 		return merr.Echo()
 	}
 
-	ng := templates.NotesGrid(notes, tools)
+	tools, merr := h.db.ListTools()
+	if merr != nil {
+		return merr.Echo()
+	}
+
+	ng := templates.NotesGrid(notes, tools, user)
 	err := ng.Render(c.Request().Context(), c.Response())
 	if err != nil {
 		return errors.NewRenderError(err, "NotesGrid")
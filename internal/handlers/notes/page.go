@@ -1,18 +1,23 @@
 package notes
 
 import (
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/handlers/notes/templates"
 	"github.com/knackwurstking/pg-press/internal/shared"
+	"github.com/knackwurstking/pg-press/internal/utils"
 
 	"github.com/labstack/echo/v4"
 )
 
 // GetPage serves the main notes page
-func GetPage(c echo.Context) *echo.HTTPError {
+func (h *Handler) GetPage(c echo.Context) *echo.HTTPError {
+	user, merr := utils.GetUserFromContext(c)
+	if merr != nil {
+		return merr.Echo()
+	}
+
 	// Get all notes with defensive error handling
-	notes, merr := db.ListNotes()
+	notes, merr := h.db.ListNotes()
 	if merr != nil {
 		return merr.Echo()
 	}
@@ -23,12 +28,12 @@ func GetPage(c echo.Context) *echo.HTTPError {
 	}
 
 	// Get all tools to show relationships
-	tools, merr := db.ListTools()
+	tools, merr := h.db.ListTools()
 	if merr != nil {
 		return merr.Echo()
 	}
 
-	t := templates.Page(notes, tools)
+	t := templates.Page(notes, tools, user)
 	err := t.Render(c.Request().Context(), c.Response())
 	if err != nil {
 		return errors.NewRenderError(err, "Notes Page")
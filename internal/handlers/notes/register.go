@@ -3,21 +3,36 @@ package notes
 import (
 	"net/http"
 
+	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/env"
 
 	"github.com/knackwurstking/ui"
 	"github.com/labstack/echo/v4"
 )
 
-func Register(e *echo.Echo, path string) {
+// Repository contains the database operations used by the notes route handlers.
+type Repository interface {
+	db.NoteRepository
+	db.ToolRepository
+	db.AuditRepository
+}
+
+// Handler holds the dependencies of all notes route handlers.
+type Handler struct {
+	db Repository
+}
+
+func Register(e *echo.Echo, path string, store db.Repository) {
+	h := &Handler{db: store}
+
 	ui.RegisterEchoRoutes(e, env.ServerPathPrefix, []*ui.EchoRoute{
 		// Notes page
-		ui.NewEchoRoute(http.MethodGet, path, GetPage),
+		ui.NewEchoRoute(http.MethodGet, path, h.GetPage),
 
 		// HTMX routes for notes deletion
-		ui.NewEchoRoute(http.MethodDelete, path+"/delete", DeleteNote),
+		ui.NewEchoRoute(http.MethodDelete, path+"/delete", h.DeleteNote),
 
 		// Render Notes Grid
-		ui.NewEchoRoute(http.MethodGet, path+"/grid", GetNotesGrid),
+		ui.NewEchoRoute(http.MethodGet, path+"/grid", h.GetNotesGrid),
 	})
 }
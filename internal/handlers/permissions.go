@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/knackwurstking/pg-press/internal/env"
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/handlers/api"
+	"github.com/knackwurstking/pg-press/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// permissions maps "METHOD /route/path" (without the server path prefix) to the
+// permission needed for the route.
+//
+// Routes not listed here need shared.PermissionView for GET requests and
+// shared.PermissionAdminister for everything else, so new routes which modify
+// data have to be added here.
+var permissions = map[string]shared.Permission{
+	"POST /login":              shared.PermissionView,
+	"POST /profile":            shared.PermissionView,
+	"DELETE /profile/cookies":  shared.PermissionView,
+	"POST /profile/api-keys":   shared.PermissionView,
+	"DELETE /profile/api-keys": shared.PermissionView,
+
+	"GET /admin":                 shared.PermissionAdminister,
+	"GET /admin/jobs":            shared.PermissionAdminister,
+	"POST /admin/jobs/run":       shared.PermissionAdminister,
+	"GET /admin/lockouts":        shared.PermissionAdminister,
+	"GET /admin/tool-thresholds": shared.PermissionAdminister,
+	"GET /metrics":               shared.PermissionAdminister,
+	"POST /trash/restore":        shared.PermissionAdminister,
+
+	"POST /editor/save": shared.PermissionEditCycles,
+
+	"GET /dialog/edit-note":   shared.PermissionEditCycles,
+	"POST /dialog/edit-note":  shared.PermissionEditCycles,
+	"GET /dialog/edit-cycle":  shared.PermissionEditCycles,
+	"POST /dialog/edit-cycle": shared.PermissionEditCycles,
+
+	"GET /dialog/edit-tool":              shared.PermissionEditTools,
+	"POST /dialog/edit-tool":             shared.PermissionEditTools,
+	"GET /dialog/edit-cassette":          shared.PermissionEditTools,
+	"POST /dialog/edit-cassette":         shared.PermissionEditTools,
+	"GET /dialog/edit-metal-sheet":       shared.PermissionEditTools,
+	"POST /dialog/edit-metal-sheet":      shared.PermissionEditTools,
+	"GET /dialog/edit-press":             shared.PermissionEditTools,
+	"POST /dialog/edit-press":            shared.PermissionEditTools,
+	"GET /dialog/edit-tool-regeneration": shared.PermissionEditTools,
+	"PUT /dialog/edit-tool-regeneration": shared.PermissionEditTools,
+	"POST /umbau/:press":                 shared.PermissionEditTools,
+	"POST /press/:press/replace-tool":    shared.PermissionEditTools,
+	"GET /tool/:id/regeneration-edit":    shared.PermissionEditTools,
+	"PUT /tool/:id/regeneration":         shared.PermissionEditTools,
+	"PATCH /tool/:id/bind":               shared.PermissionEditTools,
+	"PATCH /tool/:id/unbind":             shared.PermissionEditTools,
+	"PATCH /tools/mark-dead":             shared.PermissionEditTools,
+
+	"DELETE /press/:press":                 shared.PermissionDelete,
+	"DELETE /tool/:id/delete-regeneration": shared.PermissionDelete,
+	"DELETE /tool/cycle/delete":            shared.PermissionDelete,
+	"DELETE /tools/delete":                 shared.PermissionDelete,
+	"DELETE /notes/delete":                 shared.PermissionDelete,
+	"DELETE /trouble-reports/delete":       shared.PermissionDelete,
+	"DELETE /metal-sheets/delete":          shared.PermissionDelete,
+}
+
+func init() {
+	// JSON API resources, see the api package
+	for path, p := range map[string]shared.Permission{
+		"/api/v1/tools":              shared.PermissionEditTools,
+		"/api/v1/presses":            shared.PermissionEditTools,
+		"/api/v1/regenerations":      shared.PermissionEditTools,
+		"/api/v1/metal-sheets/upper": shared.PermissionEditTools,
+		"/api/v1/metal-sheets/lower": shared.PermissionEditTools,
+		"/api/v1/cycles":             shared.PermissionEditCycles,
+		"/api/v1/notes":              shared.PermissionEditCycles,
+		"/api/v1/trouble-reports":    shared.PermissionEditCycles,
+	} {
+		permissions["POST "+path] = p
+		permissions["PUT "+path+"/:id"] = p
+		permissions["DELETE "+path+"/:id"] = shared.PermissionDelete
+	}
+}
+
+// routePermission returns the permission needed for a route
+func routePermission(method, path string) shared.Permission {
+	path = strings.TrimPrefix(path, env.ServerPathPrefix)
+	if p, ok := permissions[method+" "+path]; ok {
+		return p
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		return shared.PermissionView
+	default:
+		return shared.PermissionAdminister
+	}
+}
+
+// middlewarePermissions rejects requests of users without the permission
+// needed for the route.
+//
+// Authentication is done by the key auth middleware, requests without a user
+// (e.g. the login page or static files) are not checked here.
+func middlewarePermissions() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user, ok := c.Get("user").(*shared.User)
+			if !ok {
+				return next(c)
+			}
+
+			permission := routePermission(c.Request().Method, c.Path())
+			if user.Can(permission) {
+				return next(c)
+			}
+
+			slog.Warn("Permission denied",
+				"user_name", user.Name,
+				"role", user.EffectiveRole(),
+				"permission", permission,
+				"method", c.Request().Method,
+				"path", c.Path())
+
+			herr := errors.NewPermissionError(
+				"permission %q denied for role %q", permission, user.EffectiveRole()).HTTPError()
+
+			if api.IsClientPath(c.Request().URL.Path) {
+				if eerr := api.WriteError(c, herr); eerr != nil {
+					return eerr
+				}
+				return nil
+			}
+			return herr.Echo()
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package press
 
 import (
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/handlers/press/templates"
 	"github.com/knackwurstking/pg-press/internal/shared"
@@ -10,7 +9,7 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func GetActiveTools(c echo.Context) *echo.HTTPError {
+func (h *Handler) GetActiveTools(c echo.Context) *echo.HTTPError {
 	id, merr := utils.GetParamInt64(c, "press")
 	if merr != nil {
 		return merr.Echo()
@@ -20,7 +19,7 @@ func GetActiveTools(c echo.Context) *echo.HTTPError {
 	toolsForSelection := make(map[shared.Slot][]*shared.Tool)
 	toolsForSelection[shared.SlotUpper] = []*shared.Tool{}
 	toolsForSelection[shared.SlotLower] = []*shared.Tool{}
-	tools, merr := db.ListTools()
+	tools, merr := h.db.ListTools()
 	if merr != nil {
 		return merr.WrapEcho("list tools for active tools selection")
 	}
@@ -36,12 +35,25 @@ func GetActiveTools(c echo.Context) *echo.HTTPError {
 		return herr.Echo()
 	}
 
-	u, merr := db.GetPressUtilization(pressID)
+	u, merr := h.db.GetPressUtilization(pressID)
 	if merr != nil {
 		return merr.WrapEcho("get press utilizations for press %d", pressID)
 	}
 
-	t := templates.ActiveToolsSection(u, toolsForSelection, user)
+	// Forecasts of the mounted tools, mapped by tool ID
+	forecasts := make(map[shared.EntityID]*shared.ToolForecast)
+	for _, tool := range []*shared.Tool{u.SlotUpper, u.SlotUpperCassette, u.SlotLower} {
+		if tool == nil || !tool.IsTrackable() {
+			continue
+		}
+		f, merr := h.db.GetToolForecast(tool.ID)
+		if merr != nil {
+			return merr.WrapEcho("get forecast for tool %d", tool.ID)
+		}
+		forecasts[tool.ID] = f
+	}
+
+	t := templates.ActiveToolsSection(u, toolsForSelection, forecasts, user)
 	err := t.Render(c.Request().Context(), c.Response())
 	if err != nil {
 		return errors.NewRenderError(err, "Active Tools Section")
@@ -0,0 +1,37 @@
+package press
+
+import (
+	"fmt"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/templates/components"
+	"github.com/knackwurstking/pg-press/internal/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// auditLimit is the number of history entries shown on the press page
+const auditLimit = 50
+
+func (h *Handler) GetAudit(c echo.Context) *echo.HTTPError {
+	id, merr := utils.GetParamInt64(c, "press")
+	if merr != nil {
+		return merr.Echo()
+	}
+
+	entries, merr := h.db.ListAuditEntries(fmt.Sprintf("press_%d", id), auditLimit)
+	if merr != nil {
+		return merr.Echo()
+	}
+
+	userNames, merr := h.db.ListUserNames()
+	if merr != nil {
+		return merr.Echo()
+	}
+
+	t := components.AuditLog(entries, userNames)
+	if err := t.Render(c.Request().Context(), c.Response()); err != nil {
+		return errors.NewRenderError(err, "AuditLog")
+	}
+	return nil
+}
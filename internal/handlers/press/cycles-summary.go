@@ -1,32 +1,63 @@
 package press
 
-//func (h *Handler) HTMXGetCycleSummaryPDF(c echo.Context) error {
-//	press, merr := h.getPressNumberFromParam(c)
-//	if merr != nil {
-//		return merr.Echo()
-//	}
-//
-//	// Get cycle summary data using service
-//	cycles, toolsMap, usersMap, merr := h.registry.PressCycles.GetCycleSummaryData(press)
-//	if merr != nil {
-//		return merr.Echo()
-//	}
-//
-//	// Generate PDF
-//	pdfBuffer, err := pdf.GenerateCycleSummaryPDF(press, cycles, toolsMap, usersMap)
-//	if err != nil {
-//		return echo.NewHTTPError(http.StatusInternalServerError, errors.Wrap(err, "generate PDF"))
-//	}
-//
-//	// Set response headers
-//	filename := fmt.Sprintf("press_%d_cycle_summary_%s.pdf", press, time.Now().Format("2006-01-02"))
-//	c.Response().Header().Set("Content-Type", "application/pdf")
-//	c.Response().Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
-//	c.Response().Header().Set("Content-Length", fmt.Sprintf("%d", pdfBuffer.Len()))
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/knackwurstking/pg-press/internal/pdf"
+	"github.com/knackwurstking/pg-press/internal/shared"
+	"github.com/knackwurstking/pg-press/internal/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetCycleSummaryPDF renders the cycle summary of a press as PDF download.
 //
-//	err = c.Stream(http.StatusOK, "application/pdf", pdfBuffer)
-//	if err != nil {
-//		return echo.NewHTTPError(http.StatusInternalServerError, errors.Wrap(err, "stream"))
-//	}
-//	return nil
-//}
+// Optional query parameters:
+//   - from: first day to include (YYYY-MM-DD)
+//   - to: last day to include (YYYY-MM-DD)
+//   - tool: tool ID to include, can be repeated
+func (h *Handler) GetCycleSummaryPDF(c echo.Context) *echo.HTTPError {
+	id, merr := utils.GetParamInt64(c, "press")
+	if merr != nil {
+		return merr.Echo()
+	}
+	pressID := shared.EntityID(id)
+
+	var toolIDs []shared.EntityID
+	for _, v := range c.QueryParams()["tool"] {
+		toolID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid tool ID %q", v))
+		}
+		toolIDs = append(toolIDs, shared.EntityID(toolID))
+	}
+
+	filter, err := shared.NewCycleSummaryFilter(c.QueryParam("from"), c.QueryParam("to"), toolIDs)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	summary, merr := h.db.GetCycleSummary(pressID, filter)
+	if merr != nil {
+		return merr.WrapEcho("get cycle summary for press %d", pressID)
+	}
+
+	buf, err := pdf.GenerateCycleSummaryPDF(summary)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Fehler beim Generieren des PDFs").SetInternal(err)
+	}
+
+	filename := fmt.Sprintf("presse_%d_zyklen_%s.pdf", summary.Press.Number, time.Now().Format("2006-01-02"))
+
+	c.Response().Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Response().Header().Set("Content-Length", fmt.Sprintf("%d", buf.Len()))
+	c.Response().Header().Set("Cache-Control", "private, max-age=0, no-cache, no-store, must-revalidate")
+
+	if err := c.Blob(http.StatusOK, "application/pdf", buf.Bytes()); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	return nil
+}
@@ -1,7 +1,6 @@
 package press
 
 import (
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/handlers/press/templates"
 	"github.com/knackwurstking/pg-press/internal/shared"
@@ -10,7 +9,7 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func GetCycles(c echo.Context) *echo.HTTPError {
+func (h *Handler) GetCycles(c echo.Context) *echo.HTTPError {
 	user, merr := utils.GetUserFromContext(c)
 	if merr != nil {
 		return merr.Echo()
@@ -22,13 +21,13 @@ func GetCycles(c echo.Context) *echo.HTTPError {
 	}
 	pressID := shared.EntityID(id)
 
-	cycles, merr := db.ListCyclesByPressID(pressID)
+	cycles, merr := h.db.ListCyclesByPressID(pressID)
 	if merr != nil {
 		return merr.Echo()
 	}
 
 	toolsMap := make(map[shared.EntityID]*shared.Tool)
-	tools, merr := db.ListTools()
+	tools, merr := h.db.ListTools()
 	if merr != nil {
 		return merr.Echo()
 	}
@@ -36,10 +35,16 @@ func GetCycles(c echo.Context) *echo.HTTPError {
 		toolsMap[t.ID] = t
 	}
 
+	userNames, merr := h.db.ListUserNames()
+	if merr != nil {
+		return merr.Echo()
+	}
+
 	t := templates.Cycles(templates.CyclesProps{
-		Cycles: cycles,
-		Tools:  toolsMap,
-		User:   user,
+		Cycles:    cycles,
+		Tools:     toolsMap,
+		UserNames: userNames,
+		User:      user,
 	})
 	if err := t.Render(c.Request().Context(), c.Response()); err != nil {
 		return errors.NewRenderError(err, "Cycles")
@@ -1,23 +1,33 @@
 package press
 
 import (
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/knackwurstking/pg-press/internal/urlb"
 	"github.com/knackwurstking/pg-press/internal/utils"
 	"github.com/labstack/echo/v4"
 )
 
-func DeletePress(c echo.Context) *echo.HTTPError {
+func (h *Handler) DeletePress(c echo.Context) *echo.HTTPError {
 	id, merr := utils.GetParamInt64(c, "press")
 	if merr != nil {
 		return merr.Echo()
 	}
 	pressID := shared.EntityID(id)
 
-	if merr := db.DeletePress(pressID); merr != nil {
+	user, merr := utils.GetUserFromContext(c)
+	if merr != nil {
+		return merr.Echo()
+	}
+
+	press, merr := h.db.GetPress(pressID)
+	if merr != nil {
+		return merr.Echo()
+	}
+
+	if merr := h.db.DeletePress(pressID, user.ID); merr != nil {
 		return merr.Echo()
 	}
+	h.db.Audit(user.ID, shared.AuditActionDelete, press, nil)
 
 	utils.SetHXRedirect(c, urlb.Tools())
 
@@ -1,7 +1,6 @@
 package press
 
 import (
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/handlers/press/templates"
 	"github.com/knackwurstking/pg-press/internal/shared"
@@ -10,14 +9,14 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func GetPressMetalSheets(c echo.Context) *echo.HTTPError {
+func (h *Handler) GetPressMetalSheets(c echo.Context) *echo.HTTPError {
 	id, merr := utils.GetParamInt64(c, "press")
 	if merr != nil {
 		return merr.Echo()
 	}
 	pressID := shared.EntityID(id)
 
-	u, merr := db.GetPressUtilization(pressID)
+	u, merr := h.db.GetPressUtilization(pressID)
 	if merr != nil {
 		return merr.WrapEcho("get press utilizations for press %d (ID: %d)", u.PressNumber, u.PressID)
 	}
@@ -25,7 +24,7 @@ func GetPressMetalSheets(c echo.Context) *echo.HTTPError {
 	var ums []*shared.UpperMetalSheet
 	if u.SlotUpper != nil {
 		var merr *errors.HTTPError
-		if ums, merr = db.ListUpperMetalSheetsByTool(u.SlotUpper.ID); merr != nil {
+		if ums, merr = h.db.ListUpperMetalSheetsByTool(u.SlotUpper.ID); merr != nil {
 			return merr.Echo()
 		}
 	}
@@ -33,7 +32,7 @@ func GetPressMetalSheets(c echo.Context) *echo.HTTPError {
 	var lms []*shared.LowerMetalSheet
 	if u.SlotLower != nil {
 		var merr *errors.HTTPError
-		if lms, merr = db.ListLowerMetalSheetsByTool(u.SlotLower.ID); merr != nil {
+		if lms, merr = h.db.ListLowerMetalSheetsByTool(u.SlotLower.ID); merr != nil {
 			return merr.Echo()
 		} else {
 			i := 0
@@ -1,7 +1,6 @@
 package press
 
 import (
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/handlers/press/templates"
 	"github.com/knackwurstking/pg-press/internal/shared"
@@ -10,19 +9,24 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func GetNotes(c echo.Context) *echo.HTTPError {
+func (h *Handler) GetNotes(c echo.Context) *echo.HTTPError {
+	user, merr := utils.GetUserFromContext(c)
+	if merr != nil {
+		return merr.Echo()
+	}
+
 	id, merr := utils.GetParamInt8(c, "press")
 	if merr != nil {
 		return merr.Echo()
 	}
 	pressID := shared.EntityID(id)
 
-	pressNotes, merr := db.ListNotesForLinked("press", int(pressID))
+	pressNotes, merr := h.db.ListNotesForLinked("press", int(pressID))
 	if merr != nil {
 		return merr.Echo()
 	}
 
-	u, merr := db.GetPressUtilization(pressID)
+	u, merr := h.db.GetPressUtilization(pressID)
 	if merr != nil {
 		return merr.Echo()
 	}
@@ -40,7 +44,7 @@ func GetNotes(c echo.Context) *echo.HTTPError {
 
 	var toolNotes []*shared.Note
 	for id := range toolsMap {
-		notes, merr := db.ListNotesForLinked("tool", int(id))
+		notes, merr := h.db.ListNotesForLinked("tool", int(id))
 		if merr != nil {
 			return merr.Echo()
 		}
@@ -51,6 +55,7 @@ func GetNotes(c echo.Context) *echo.HTTPError {
 		PressNotes: pressNotes,
 		ToolNotes:  toolNotes,
 		Tools:      toolsMap,
+		User:       user,
 	})
 	err := t.Render(c.Request().Context(), c.Response())
 	if err != nil {
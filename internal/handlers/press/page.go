@@ -1,7 +1,6 @@
 package press
 
 import (
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/handlers/press/templates"
 	"github.com/knackwurstking/pg-press/internal/shared"
@@ -10,7 +9,7 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func GetPage(c echo.Context) *echo.HTTPError {
+func (h *Handler) GetPage(c echo.Context) *echo.HTTPError {
 	user, merr := utils.GetUserFromContext(c)
 	if merr != nil {
 		return merr.Echo()
@@ -20,7 +19,7 @@ func GetPage(c echo.Context) *echo.HTTPError {
 	if merr != nil {
 		return merr.Echo()
 	}
-	press, merr := db.GetPress(shared.EntityID(id))
+	press, merr := h.db.GetPress(shared.EntityID(id))
 	if merr != nil {
 		return merr.Echo()
 	}
@@ -3,30 +3,50 @@ package press
 import (
 	"net/http"
 
+	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/env"
 
 	"github.com/knackwurstking/ui"
 	"github.com/labstack/echo/v4"
 )
 
-func Register(e *echo.Echo, path string) {
+// Repository contains the database operations used by the press route handlers.
+type Repository interface {
+	db.PressRepository
+	db.CycleRepository
+	db.ToolRepository
+	db.NoteRepository
+	db.UserRepository
+	db.AuditRepository
+	db.TransactionRepository
+}
+
+// Handler holds the dependencies of all press route handlers.
+type Handler struct {
+	db Repository
+}
+
+func Register(e *echo.Echo, path string, store db.Repository) {
+	h := &Handler{db: store}
+
 	ui.RegisterEchoRoutes(
 		e,
 		env.ServerPathPrefix,
 		[]*ui.EchoRoute{
 			// Press page
-			ui.NewEchoRoute(http.MethodGet, path+"/:press", GetPage),
+			ui.NewEchoRoute(http.MethodGet, path+"/:press", h.GetPage),
 
 			// HTMX endpoints for press content
-			ui.NewEchoRoute(http.MethodGet, path+"/:press/active-tools", GetActiveTools),
-			ui.NewEchoRoute(http.MethodGet, path+"/:press/metal-sheets", GetPressMetalSheets),
-			ui.NewEchoRoute(http.MethodGet, path+"/:press/cycles", GetCycles),
-			ui.NewEchoRoute(http.MethodGet, path+"/:press/notes", GetNotes),
-			ui.NewEchoRoute(http.MethodDelete, path+"/:press", DeletePress),
-			ui.NewEchoRoute(http.MethodPost, path+"/:press/replace-tool", ReplaceTool),
+			ui.NewEchoRoute(http.MethodGet, path+"/:press/active-tools", h.GetActiveTools),
+			ui.NewEchoRoute(http.MethodGet, path+"/:press/metal-sheets", h.GetPressMetalSheets),
+			ui.NewEchoRoute(http.MethodGet, path+"/:press/cycles", h.GetCycles),
+			ui.NewEchoRoute(http.MethodGet, path+"/:press/notes", h.GetNotes),
+			ui.NewEchoRoute(http.MethodGet, path+"/:press/audit", h.GetAudit),
+			ui.NewEchoRoute(http.MethodDelete, path+"/:press", h.DeletePress),
+			ui.NewEchoRoute(http.MethodPost, path+"/:press/replace-tool", h.ReplaceTool),
 
 			// PDF Handlers
-			//ui.NewEchoRoute(http.MethodGet, path+"/:press/cycle-summary-pdf", GetCycleSummaryPDF),
+			ui.NewEchoRoute(http.MethodGet, path+"/:press/cycle-summary-pdf", h.GetCycleSummaryPDF),
 		},
 	)
 }
@@ -5,12 +5,13 @@ import (
 	"strconv"
 
 	"github.com/knackwurstking/pg-press/internal/db"
+	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/knackwurstking/pg-press/internal/utils"
 	"github.com/labstack/echo/v4"
 )
 
-func ReplaceTool(c echo.Context) *echo.HTTPError {
+func (h *Handler) ReplaceTool(c echo.Context) *echo.HTTPError {
 	// Get press number from param
 	var pressID shared.EntityID
 	if id, merr := utils.GetParamInt8(c, "press"); merr != nil {
@@ -41,22 +42,38 @@ func ReplaceTool(c echo.Context) *echo.HTTPError {
 		newToolID = shared.EntityID(i)
 	}
 
-	if press, merr := db.GetPress(pressID); merr != nil {
+	if position != shared.SlotUpper && position != shared.SlotLower {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			"invalid position value: %s", position)
+	}
+
+	user, merr := utils.GetUserFromContext(c)
+	if merr != nil {
 		return merr.Echo()
-	} else {
+	}
+
+	var before, press *shared.Press
+	merr = h.db.Transaction(func(tx *db.Tx) *errors.HTTPError {
+		var merr *errors.HTTPError
+		press, merr = tx.GetPress(pressID)
+		if merr != nil {
+			return merr
+		}
+		before = press.Clone()
+
 		switch position {
 		case shared.SlotUpper:
 			press.SlotUp = newToolID
 		case shared.SlotLower:
 			press.SlotDown = newToolID
-		default:
-			return echo.NewHTTPError(http.StatusBadRequest,
-				"invalid position value: %s", position)
-		}
-		if merr = db.UpdatePress(press); merr != nil {
-			return merr.Echo()
 		}
+
+		return tx.UpdatePress(press)
+	})
+	if merr != nil {
+		return merr.Echo()
 	}
+	h.db.Audit(user.ID, shared.AuditActionUpdate, before, press)
 
 	utils.SetHXTrigger(c, "reload-active-tools")
 
@@ -0,0 +1,109 @@
+package profile
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/handlers/profile/templates"
+	"github.com/knackwurstking/pg-press/internal/shared"
+	"github.com/knackwurstking/pg-press/internal/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) HTMXGetApiKeys(c echo.Context) *echo.HTTPError {
+	user, herr := utils.GetUserFromContext(c)
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	return h.renderApiKeys(c, user, "")
+}
+
+// HTMXPostApiKey creates a new named key for the current user, the key itself
+// is rendered once and can't be shown again afterwards
+func (h *Handler) HTMXPostApiKey(c echo.Context) *echo.HTTPError {
+	user, herr := utils.GetUserFromContext(c)
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	name := strings.TrimSpace(c.FormValue("name"))
+
+	var expiresAt shared.UnixMilli
+	if v := c.FormValue("expires"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return errors.NewValidationError("invalid expiry date %q", v).HTTPError().Echo()
+		}
+		// Keys are valid until the end of the day
+		expiresAt = shared.NewUnixMilli(t.AddDate(0, 0, 1).Add(-time.Millisecond))
+		if expiresAt.ToTime().Before(time.Now()) {
+			return errors.NewValidationError("expiry date %q is in the past", v).HTTPError().Echo()
+		}
+	}
+
+	apiKey, err := shared.GenerateApiKey()
+	if err != nil {
+		return errors.NewHTTPError(err).Echo()
+	}
+
+	key, err := shared.NewApiKey(user.ID, name, apiKey, expiresAt)
+	if err != nil {
+		return errors.NewHTTPError(err).Echo()
+	}
+
+	if herr = h.db.AddApiKey(key); herr != nil {
+		return herr.Echo()
+	}
+	slog.Info("User created an api key", "user_name", user.Name, "api_key", key.String())
+
+	return h.renderApiKeys(c, user, apiKey)
+}
+
+// HTMXDeleteApiKey revokes one of the keys of the current user
+func (h *Handler) HTMXDeleteApiKey(c echo.Context) *echo.HTTPError {
+	user, herr := utils.GetUserFromContext(c)
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	id, herr := utils.GetQueryInt64(c, "id")
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	key, herr := h.db.GetApiKey(shared.EntityID(id))
+	if herr != nil {
+		return herr.Echo()
+	}
+	if key.UserID != user.ID {
+		return errors.NewPermissionError("api key %d belongs to another user", id).HTTPError().Echo()
+	}
+
+	if herr = h.db.DeleteApiKey(key.ID); herr != nil {
+		return herr.Echo()
+	}
+	slog.Info("User revoked an api key", "user_name", user.Name, "api_key", key.String())
+
+	return h.renderApiKeys(c, user, "")
+}
+
+func (h *Handler) renderApiKeys(c echo.Context, user *shared.User, newApiKey string) *echo.HTTPError {
+	keys, herr := h.db.ListApiKeysByUserID(user.ID)
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	t := templates.ApiKeys(templates.ApiKeysProps{
+		ApiKeys:   keys,
+		NewApiKey: newApiKey,
+	})
+	if err := t.Render(c.Request().Context(), c.Response()); err != nil {
+		return errors.NewRenderError(err, "ApiKeys")
+	}
+
+	return nil
+}
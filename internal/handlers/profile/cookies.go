@@ -3,7 +3,6 @@ package profile
 import (
 	"slices"
 
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/handlers/profile/templates"
 	"github.com/knackwurstking/pg-press/internal/shared"
@@ -12,36 +11,36 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func HTMXGetCookies(c echo.Context) *echo.HTTPError {
-	return renderCookies(c, false)
+func (h *Handler) HTMXGetCookies(c echo.Context) *echo.HTTPError {
+	return h.renderCookies(c, false)
 }
 
-func HTMXDeleteCookies(c echo.Context) *echo.HTTPError {
+func (h *Handler) HTMXDeleteCookies(c echo.Context) *echo.HTTPError {
 	value, merr := utils.GetQueryString(c, "value")
 	if merr != nil {
 		return merr.Echo()
 	}
 
-	merr = db.DeleteCookie(value)
+	merr = h.db.DeleteCookie(value)
 	if merr != nil {
 		return merr.Echo()
 	}
 
-	eerr := HTMXGetCookies(c)
+	eerr := h.HTMXGetCookies(c)
 	if eerr != nil {
 		return eerr
 	}
 
-	return renderCookies(c, true)
+	return h.renderCookies(c, true)
 }
 
-func renderCookies(c echo.Context, oob bool) *echo.HTTPError {
+func (h *Handler) renderCookies(c echo.Context, oob bool) *echo.HTTPError {
 	user, merr := utils.GetUserFromContext(c)
 	if merr != nil {
 		return merr.Echo()
 	}
 
-	cookies, merr := db.ListCookiesByApiKey(user.ApiKey)
+	cookies, merr := h.db.ListCookiesByUserID(user.ID)
 	if merr != nil {
 		return merr.Echo()
 	}
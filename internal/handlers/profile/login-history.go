@@ -0,0 +1,31 @@
+package profile
+
+import (
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/handlers/profile/templates"
+	"github.com/knackwurstking/pg-press/internal/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// loginHistoryLimit is the number of login attempts shown on the profile page
+const loginHistoryLimit = 20
+
+func (h *Handler) HTMXGetLoginHistory(c echo.Context) *echo.HTTPError {
+	user, herr := utils.GetUserFromContext(c)
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	attempts, herr := h.db.ListLoginAttemptsByUserID(user.ID, loginHistoryLimit)
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	t := templates.LoginHistory(attempts)
+	if err := t.Render(c.Request().Context(), c.Response()); err != nil {
+		return errors.NewRenderError(err, "LoginHistory")
+	}
+
+	return nil
+}
@@ -3,7 +3,6 @@ package profile
 import (
 	"log/slog"
 
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/handlers/profile/templates"
 	"github.com/knackwurstking/pg-press/internal/shared"
@@ -13,7 +12,7 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func GetProfilePage(c echo.Context) *echo.HTTPError {
+func (h *Handler) GetProfilePage(c echo.Context) *echo.HTTPError {
 	user, herr := utils.GetUserFromContext(c)
 	if herr != nil {
 		return herr.Echo()
@@ -27,13 +26,13 @@ func GetProfilePage(c echo.Context) *echo.HTTPError {
 	return nil
 }
 
-func PostProfilePage(c echo.Context) *echo.HTTPError {
+func (h *Handler) PostProfilePage(c echo.Context) *echo.HTTPError {
 	user, herr := utils.GetUserFromContext(c)
 	if herr != nil {
 		return herr.Echo()
 	}
 
-	if herr = handleUserNameChange(c, user); herr != nil {
+	if herr = h.handleUserNameChange(c, user); herr != nil {
 		return herr.Echo()
 	}
 
@@ -44,7 +43,7 @@ func PostProfilePage(c echo.Context) *echo.HTTPError {
 	return nil
 }
 
-func handleUserNameChange(c echo.Context, user *shared.User) *errors.HTTPError {
+func (h *Handler) handleUserNameChange(c echo.Context, user *shared.User) *errors.HTTPError {
 	userName := c.FormValue("user-name")
 	if userName == "" || userName == user.Name {
 		return nil
@@ -57,12 +56,14 @@ func handleUserNameChange(c echo.Context, user *shared.User) *errors.HTTPError {
 		).HTTPError()
 	}
 
+	before := user.Clone()
 	user.Name = userName
-	herr := db.UpdateUser(user)
+	herr := h.db.UpdateUser(user)
 	if herr != nil {
 		return herr
 	}
-	slog.Info("User changed their name", "old_name", user.Name, "new_name", userName)
+	h.db.Audit(user.ID, shared.AuditActionUpdate, before, user)
+	slog.Info("User changed their name", "old_name", before.Name, "new_name", userName)
 
 	return nil
 }
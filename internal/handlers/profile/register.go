@@ -3,21 +3,41 @@ package profile
 import (
 	"net/http"
 
+	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/env"
 
 	"github.com/knackwurstking/ui"
 	"github.com/labstack/echo/v4"
 )
 
-func Register(e *echo.Echo, path string) {
+// Repository contains the database operations used by the profile route handlers.
+type Repository interface {
+	db.UserRepository
+	db.ApiKeyRepository
+	db.LoginRepository
+	db.AuditRepository
+}
+
+// Handler holds the dependencies of all profile route handlers.
+type Handler struct {
+	db Repository
+}
+
+func Register(e *echo.Echo, path string, store db.Repository) {
+	h := &Handler{db: store}
+
 	ui.RegisterEchoRoutes(
 		e,
 		env.ServerPathPrefix,
 		[]*ui.EchoRoute{
-			ui.NewEchoRoute(http.MethodGet, path, GetProfilePage),
-			ui.NewEchoRoute(http.MethodPost, path, PostProfilePage),
-			ui.NewEchoRoute(http.MethodGet, path+"/cookies", HTMXGetCookies),
-			ui.NewEchoRoute(http.MethodDelete, path+"/cookies", HTMXDeleteCookies),
+			ui.NewEchoRoute(http.MethodGet, path, h.GetProfilePage),
+			ui.NewEchoRoute(http.MethodPost, path, h.PostProfilePage),
+			ui.NewEchoRoute(http.MethodGet, path+"/cookies", h.HTMXGetCookies),
+			ui.NewEchoRoute(http.MethodDelete, path+"/cookies", h.HTMXDeleteCookies),
+			ui.NewEchoRoute(http.MethodGet, path+"/login-history", h.HTMXGetLoginHistory),
+			ui.NewEchoRoute(http.MethodGet, path+"/api-keys", h.HTMXGetApiKeys),
+			ui.NewEchoRoute(http.MethodPost, path+"/api-keys", h.HTMXPostApiKey),
+			ui.NewEchoRoute(http.MethodDelete, path+"/api-keys", h.HTMXDeleteApiKey),
 		},
 	)
 }
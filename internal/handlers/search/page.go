@@ -0,0 +1,49 @@
+package search
+
+import (
+	"strings"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/handlers/search/templates"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// pageLimit is the maximum number of hits per group on the search page
+	pageLimit = 50
+	// quickLimit is the maximum number of hits per group for the quick search
+	quickLimit = 5
+)
+
+func (h *Handler) GetSearchPage(c echo.Context) *echo.HTTPError {
+	query := strings.TrimSpace(c.QueryParam("q"))
+
+	results, herr := h.db.Search(query, pageLimit)
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	t := templates.Page(results)
+	if err := t.Render(c.Request().Context(), c.Response()); err != nil {
+		return errors.NewRenderError(err, "Search Page")
+	}
+
+	return nil
+}
+
+func (h *Handler) HTMXGetQuickSearch(c echo.Context) *echo.HTTPError {
+	query := strings.TrimSpace(c.QueryParam("q"))
+
+	results, herr := h.db.Search(query, quickLimit)
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	t := templates.Results(results, quickLimit)
+	if err := t.Render(c.Request().Context(), c.Response()); err != nil {
+		return errors.NewRenderError(err, "Search Results")
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package search
+
+import (
+	"net/http"
+
+	"github.com/knackwurstking/pg-press/internal/db"
+	"github.com/knackwurstking/pg-press/internal/env"
+
+	"github.com/knackwurstking/ui"
+	"github.com/labstack/echo/v4"
+)
+
+// Repository contains the database operations used by the search route handlers.
+type Repository interface {
+	db.SearchRepository
+}
+
+// Handler holds the dependencies of all search route handlers.
+type Handler struct {
+	db Repository
+}
+
+func Register(e *echo.Echo, path string, store db.Repository) {
+	h := &Handler{db: store}
+
+	ui.RegisterEchoRoutes(e, env.ServerPathPrefix, []*ui.EchoRoute{
+		ui.NewEchoRoute(http.MethodGet, path, h.GetSearchPage),
+		ui.NewEchoRoute(http.MethodGet, path+"/quick", h.HTMXGetQuickSearch),
+	})
+}
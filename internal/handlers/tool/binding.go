@@ -5,7 +5,6 @@ import (
 	"net/http"
 	"strconv"
 
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/knackwurstking/pg-press/internal/utils"
@@ -13,7 +12,7 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func ToolBinding(c echo.Context) *echo.HTTPError {
+func (h *Handler) ToolBinding(c echo.Context) *echo.HTTPError {
 	id, merr := utils.GetParamInt64(c, "id")
 	if merr != nil {
 		return merr.Echo()
@@ -37,44 +36,63 @@ func ToolBinding(c echo.Context) *echo.HTTPError {
 	}
 	cassetteID := shared.EntityID(id)
 
+	user, merr := utils.GetUserFromContext(c)
+	if merr != nil {
+		return merr.Echo()
+	}
+	before, merr := h.db.GetTool(toolID)
+	if merr != nil {
+		return merr.Echo()
+	}
+
 	// Bind tool to target, this will get an error if target already has a binding
-	merr = db.BindTool(toolID, cassetteID)
+	merr = h.db.BindTool(toolID, cassetteID)
 	if merr != nil {
 		return merr.Echo()
 	}
 
-	tool, merr := db.GetTool(toolID)
+	tool, merr := h.db.GetTool(toolID)
 	if merr != nil {
 		return merr.Echo()
 	}
-	return renderBindingSection(c, tool)
+	h.db.Audit(user.ID, shared.AuditActionUpdate, before, tool)
+	return h.renderBindingSection(c, tool)
 }
 
-func ToolUnBinding(c echo.Context) *echo.HTTPError {
+func (h *Handler) ToolUnBinding(c echo.Context) *echo.HTTPError {
 	id, merr := utils.GetParamInt64(c, "id")
 	if merr != nil {
 		return merr.Echo()
 	}
 	toolID := shared.EntityID(id)
-	merr = db.UnbindTool(toolID)
+	user, merr := utils.GetUserFromContext(c)
+	if merr != nil {
+		return merr.Echo()
+	}
+	before, merr := h.db.GetTool(toolID)
+	if merr != nil {
+		return merr.Echo()
+	}
+	merr = h.db.UnbindTool(toolID)
 	if merr != nil {
 		return merr.Echo()
 	}
-	tool, merr := db.GetTool(toolID)
+	tool, merr := h.db.GetTool(toolID)
 	if merr != nil {
 		return merr.Echo()
 	}
+	h.db.Audit(user.ID, shared.AuditActionUpdate, before, tool)
 
-	return renderBindingSection(c, tool)
+	return h.renderBindingSection(c, tool)
 }
 
-func renderBindingSection(c echo.Context, tool *shared.Tool) *echo.HTTPError {
+func (h *Handler) renderBindingSection(c echo.Context, tool *shared.Tool) *echo.HTTPError {
 	user, merr := utils.GetUserFromContext(c)
 	if merr != nil {
 		return merr.Echo()
 	}
 
-	bindableCassettes, eerr := listBindableCassettes(tool)
+	bindableCassettes, eerr := h.listBindableCassettes(tool)
 	if eerr != nil {
 		return eerr
 	}
@@ -83,7 +101,7 @@ func renderBindingSection(c echo.Context, tool *shared.Tool) *echo.HTTPError {
 	t := Binding(BindingProps{
 		Tool:                tool,
 		CassettesForBinding: bindableCassettes,
-		IsAdmin:             user.IsAdmin(),
+		UserHasPermission:   user.Can(shared.PermissionEditTools),
 	})
 	if err := t.Render(c.Request().Context(), c.Response()); err != nil {
 		return errors.NewRenderError(err, "BindingSection")
@@ -1,7 +1,6 @@
 package tool
 
 import (
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/knackwurstking/pg-press/internal/utils"
@@ -10,8 +9,8 @@ import (
 )
 
 // GetCyclesSectionContent handles the request to get the cycles section content
-func GetCyclesSectionContent(c echo.Context) *echo.HTTPError {
-	return renderCyclesSectionContent(c)
+func (h *Handler) GetCyclesSectionContent(c echo.Context) *echo.HTTPError {
+	return h.renderCyclesSectionContent(c)
 }
 
 // renderCyclesSection renders the cycles section for a tool
@@ -26,24 +25,24 @@ func renderCyclesSection(c echo.Context, tool *shared.Tool) *echo.HTTPError {
 }
 
 // renderCyclesSectionContent renders the content for the cycles section
-func renderCyclesSectionContent(c echo.Context) *echo.HTTPError {
+func (h *Handler) renderCyclesSectionContent(c echo.Context) *echo.HTTPError {
 	// Get tool from URL param "id"
 	id, merr := utils.GetParamInt64(c, "id")
 	if merr != nil {
 		return merr.Echo()
 	}
 
-	tool, merr := db.GetTool(shared.EntityID(id))
+	tool, merr := h.db.GetTool(shared.EntityID(id))
 	if merr != nil {
 		return merr.Echo()
 	}
 
-	toolCycles, herr := db.ListToolCycles(tool.ID)
+	toolCycles, herr := h.db.ListToolCycles(tool.ID)
 	if herr != nil {
 		return herr.Echo()
 	}
 
-	presses, herr := db.ListPress()
+	presses, herr := h.db.ListPress()
 	if herr != nil {
 		return herr.Echo()
 	}
@@ -55,19 +54,29 @@ func renderCyclesSectionContent(c echo.Context) *echo.HTTPError {
 	}
 
 	// Get active press number for this tool, -1 if none
-	activePress, merr := db.GetPressForTool(tool.ID)
+	activePress, merr := h.db.GetPressForTool(tool.ID)
 	if merr != nil && !merr.IsNotFoundError() {
 		return merr.Echo()
 	}
 
 	// Get bindable cassettes for this tool, if it is a tool and not a cassette
-	bindableCassettes, eerr := listBindableCassettes(tool)
+	bindableCassettes, eerr := h.listBindableCassettes(tool)
 	if eerr != nil {
 		return eerr
 	}
 
 	// Get regenerations for this tool
-	regenerations, herr := db.ListToolRegenerationsByTool(tool.ID)
+	regenerations, herr := h.db.ListToolRegenerationsByTool(tool.ID)
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	forecast, herr := h.db.GetToolForecast(tool.ID)
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	userNames, herr := h.db.ListUserNames()
 	if herr != nil {
 		return herr.Echo()
 	}
@@ -85,6 +94,8 @@ func renderCyclesSectionContent(c echo.Context) *echo.HTTPError {
 		ActivePress:         activePress,
 		CassettesForBinding: bindableCassettes,
 		Regenerations:       regenerations,
+		Forecast:            forecast,
+		UserNames:           userNames,
 		User:                user,
 	})
 
@@ -1,27 +1,31 @@
 package tool
 
 import (
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/knackwurstking/pg-press/internal/utils"
 
 	"github.com/labstack/echo/v4"
 )
 
-func DeleteRegeneration(c echo.Context) *echo.HTTPError {
+func (h *Handler) DeleteRegeneration(c echo.Context) *echo.HTTPError {
 	id, merr := utils.GetQueryInt64(c, "id")
 	if merr != nil {
 		return merr.Echo()
 	}
-	regeneration, merr := db.GetToolRegeneration(shared.EntityID(id))
+	user, merr := utils.GetUserFromContext(c)
+	if merr != nil {
+		return merr.Echo()
+	}
+	regeneration, merr := h.db.GetToolRegeneration(shared.EntityID(id))
 	if merr != nil {
 		return merr.Echo()
 	}
 
-	merr = db.DeleteToolRegeneration(regeneration.ID)
+	merr = h.db.DeleteToolRegeneration(regeneration.ID)
 	if merr != nil {
 		return merr.Echo()
 	}
+	h.db.Audit(user.ID, shared.AuditActionDelete, regeneration, nil)
 
 	utils.SetHXTrigger(c, "reload-cycles")
 
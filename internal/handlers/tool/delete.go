@@ -1,24 +1,34 @@
 package tool
 
 import (
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/knackwurstking/pg-press/internal/utils"
 	"github.com/labstack/echo/v4"
 )
 
-func DeleteToolCycle(c echo.Context) *echo.HTTPError {
+func (h *Handler) DeleteToolCycle(c echo.Context) *echo.HTTPError {
 	id, merr := utils.GetQueryInt64(c, "id")
 	if merr != nil {
 		return merr.Echo()
 	}
 	cycleID := shared.EntityID(id)
 
-	merr = db.DeleteCycle(cycleID)
+	user, merr := utils.GetUserFromContext(c)
 	if merr != nil {
 		return merr.Echo()
 	}
 
+	cycle, merr := h.db.GetCycle(cycleID)
+	if merr != nil {
+		return merr.Echo()
+	}
+
+	merr = h.db.DeleteCycle(cycleID, user.ID)
+	if merr != nil {
+		return merr.Echo()
+	}
+	h.db.Audit(user.ID, shared.AuditActionDelete, cycle, nil)
+
 	utils.SetHXTrigger(c, "reload-cycles")
 
 	return nil
@@ -3,7 +3,6 @@ package tool
 import (
 	"net/http"
 
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/knackwurstking/pg-press/internal/utils"
@@ -12,7 +11,7 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func GetToolMetalSheets(c echo.Context) *echo.HTTPError {
+func (h *Handler) GetToolMetalSheets(c echo.Context) *echo.HTTPError {
 	user, merr := utils.GetUserFromContext(c)
 	if merr != nil {
 		return merr.Echo()
@@ -22,7 +21,7 @@ func GetToolMetalSheets(c echo.Context) *echo.HTTPError {
 	if merr != nil {
 		return merr.Echo()
 	}
-	tool, merr := db.GetTool(shared.EntityID(id))
+	tool, merr := h.db.GetTool(shared.EntityID(id))
 	if merr != nil {
 		return merr.WrapEcho("tool not found")
 	}
@@ -34,7 +33,7 @@ func GetToolMetalSheets(c echo.Context) *echo.HTTPError {
 	}
 
 	if tool.IsLowerTool() {
-		metalSheets, merr := db.ListLowerMetalSheetsByTool(tool.ID)
+		metalSheets, merr := h.db.ListLowerMetalSheetsByTool(tool.ID)
 		if merr != nil {
 			return merr.Echo()
 		}
@@ -48,7 +47,7 @@ func GetToolMetalSheets(c echo.Context) *echo.HTTPError {
 		return nil
 	}
 
-	metalSheets, merr := db.ListUpperMetalSheetsByTool(tool.ID)
+	metalSheets, merr := h.db.ListUpperMetalSheetsByTool(tool.ID)
 	if merr != nil {
 		return merr.Echo()
 	}
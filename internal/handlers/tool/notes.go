@@ -1,7 +1,6 @@
 package tool
 
 import (
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/knackwurstking/pg-press/internal/utils"
@@ -9,18 +8,23 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func GetToolNotes(c echo.Context) *echo.HTTPError {
+func (h *Handler) GetToolNotes(c echo.Context) *echo.HTTPError {
+	user, merr := utils.GetUserFromContext(c)
+	if merr != nil {
+		return merr.Echo()
+	}
+
 	id, merr := utils.GetParamInt64(c, "id")
 	if merr != nil {
 		return merr.Echo()
 	}
 	toolID := shared.EntityID(id)
-	notes, merr := db.ListNotesForLinked("tool", int(toolID))
+	notes, merr := h.db.ListNotesForLinked("tool", int(toolID))
 	if merr != nil {
 		return merr.Echo()
 	}
 
-	t := Notes(toolID, notes)
+	t := Notes(toolID, notes, user)
 	err := t.Render(c.Request().Context(), c.Response())
 	if err != nil {
 		return errors.NewRenderError(err, "Notes")
@@ -1,7 +1,6 @@
 package tool
 
 import (
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/knackwurstking/pg-press/internal/utils"
@@ -9,7 +8,7 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func GetToolPage(c echo.Context) *echo.HTTPError {
+func (h *Handler) GetToolPage(c echo.Context) *echo.HTTPError {
 	user, merr := utils.GetUserFromContext(c)
 	if merr != nil {
 		return merr.Echo()
@@ -20,7 +19,7 @@ func GetToolPage(c echo.Context) *echo.HTTPError {
 		return merr.Echo()
 	}
 
-	tool, merr := db.GetTool(shared.EntityID(id))
+	tool, merr := h.db.GetTool(shared.EntityID(id))
 	if merr != nil {
 		return merr.WrapEcho("could not get tool by ID")
 	}
@@ -4,7 +4,6 @@ import (
 	"log/slog"
 	"net/http"
 
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/knackwurstking/pg-press/internal/utils"
@@ -12,17 +11,17 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func RegenerationEditable(c echo.Context) *echo.HTTPError {
+func (h *Handler) RegenerationEditable(c echo.Context) *echo.HTTPError {
 	id, merr := utils.GetParamInt64(c, "id")
 	if merr != nil {
 		return merr.Echo()
 	}
-	tool, merr := db.GetTool(shared.EntityID(id))
+	tool, merr := h.db.GetTool(shared.EntityID(id))
 	if merr != nil {
 		return merr.Echo()
 	}
 
-	eerr := renderRegenerationEdit(c, tool, true)
+	eerr := h.renderRegenerationEdit(c, tool, true)
 	if eerr != nil {
 		return eerr
 	}
@@ -30,17 +29,17 @@ func RegenerationEditable(c echo.Context) *echo.HTTPError {
 	return nil
 }
 
-func RegenerationNonEditable(c echo.Context) *echo.HTTPError {
+func (h *Handler) RegenerationNonEditable(c echo.Context) *echo.HTTPError {
 	id, merr := utils.GetParamInt64(c, "id")
 	if merr != nil {
 		return merr.Echo()
 	}
-	tool, merr := db.GetTool(shared.EntityID(id))
+	tool, merr := h.db.GetTool(shared.EntityID(id))
 	if merr != nil {
 		return merr.Echo()
 	}
 
-	eerr := renderRegenerationEdit(c, tool, false)
+	eerr := h.renderRegenerationEdit(c, tool, false)
 	if eerr != nil {
 		return eerr
 	}
@@ -48,12 +47,12 @@ func RegenerationNonEditable(c echo.Context) *echo.HTTPError {
 	return nil
 }
 
-func Regeneration(c echo.Context) *echo.HTTPError {
+func (h *Handler) Regeneration(c echo.Context) *echo.HTTPError {
 	id, merr := utils.GetParamInt64(c, "id")
 	if merr != nil {
 		return merr.Echo()
 	}
-	tool, merr := db.GetTool(shared.EntityID(id))
+	tool, merr := h.db.GetTool(shared.EntityID(id))
 	if merr != nil {
 		return merr.Echo()
 	}
@@ -65,22 +64,34 @@ func Regeneration(c echo.Context) *echo.HTTPError {
 
 	slog.Debug("Regeneration status change requested", "tool_id", tool.ID, "status", statusStr)
 
+	user, merr := utils.GetUserFromContext(c)
+	if merr != nil {
+		return merr.Echo()
+	}
+
+	// Keep the current state for the audit log
+	before := tool.Clone()
+	regenerations, merr := h.db.ListToolRegenerationsByTool(tool.ID)
+	if merr != nil && !merr.IsNotFoundError() {
+		return merr.Echo()
+	}
+
 	// Handle regeneration start/stop/abort only
 	switch statusStr {
 	case "regenerating":
-		merr = db.StopToolRegeneration(tool.ID)
+		merr = h.db.StopToolRegeneration(tool.ID)
 		if merr != nil {
 			return merr.Echo()
 		}
 
 	case "active":
-		merr = db.StartToolRegeneration(tool.ID)
+		merr = h.db.StartToolRegeneration(tool.ID)
 		if merr != nil {
 			return merr.Echo()
 		}
 
 	case "abort":
-		merr := db.AbortToolRegeneration(tool.ID)
+		merr := h.db.AbortToolRegeneration(tool.ID)
 		if merr != nil {
 			return merr.Echo()
 		}
@@ -93,13 +104,14 @@ func Regeneration(c echo.Context) *echo.HTTPError {
 	}
 
 	// Get updated tool and render status display
-	tool, merr = db.GetTool(tool.ID)
+	tool, merr = h.db.GetTool(tool.ID)
 	if merr != nil {
 		return merr.Echo()
 	}
+	h.auditRegeneration(user, statusStr, before, tool, regenerations)
 
 	// Render the updated status component
-	eerr := renderRegenerationEdit(c, tool, false)
+	eerr := h.renderRegenerationEdit(c, tool, false)
 	if eerr != nil {
 		return eerr
 	}
@@ -107,13 +119,47 @@ func Regeneration(c echo.Context) *echo.HTTPError {
 	return renderCyclesSection(c, tool)
 }
 
-func renderRegenerationEdit(c echo.Context, tool *shared.Tool, editable bool) *echo.HTTPError {
+// auditRegeneration records a regeneration status change in the audit log,
+// regenerations are the regenerations of the tool before the change.
+func (h *Handler) auditRegeneration(user *shared.User, status string, before, tool *shared.Tool, regenerations []*shared.ToolRegeneration) {
+	switch status {
+	case "regenerating":
+		for _, r := range regenerations {
+			if r.Stop != 0 {
+				continue
+			}
+			if stopped, herr := h.db.GetToolRegeneration(r.ID); herr == nil {
+				h.db.Audit(user.ID, shared.AuditActionUpdate, r, stopped)
+			}
+		}
+		// Stopping a regeneration resets the tool cycles
+		h.db.Audit(user.ID, shared.AuditActionUpdate, before, tool)
+
+	case "active":
+		started, herr := h.db.ListToolRegenerationsByTool(tool.ID)
+		if herr != nil {
+			return
+		}
+		for _, r := range started {
+			if r.Stop == 0 {
+				h.db.Audit(user.ID, shared.AuditActionCreate, nil, r)
+			}
+		}
+
+	case "abort":
+		for _, r := range regenerations {
+			h.db.Audit(user.ID, shared.AuditActionDelete, r, nil)
+		}
+	}
+}
+
+func (h *Handler) renderRegenerationEdit(c echo.Context, tool *shared.Tool, editable bool) *echo.HTTPError {
 	user, herr := utils.GetUserFromContext(c)
 	if herr != nil {
 		return herr.Wrap("getting user from context failed").Echo()
 	}
 
-	regenerations, herr := db.ListToolRegenerationsByTool(tool.ID)
+	regenerations, herr := h.db.ListToolRegenerationsByTool(tool.ID)
 	if herr != nil && !herr.IsNotFoundError() {
 		return herr.Wrap("listing regenerations for tool ID %d failed", tool.ID).Echo()
 	}
@@ -125,7 +171,7 @@ func renderRegenerationEdit(c echo.Context, tool *shared.Tool, editable bool) *e
 		}
 	}
 
-	press, herr := db.GetPressForTool(tool.ID)
+	press, herr := h.db.GetPressForTool(tool.ID)
 	if herr != nil {
 		return herr.Wrap("getting press number for tool ID %d failed", tool.ID).Echo()
 	}
@@ -135,7 +181,7 @@ func renderRegenerationEdit(c echo.Context, tool *shared.Tool, editable bool) *e
 		ToolIsActive:      press != nil,
 		IsRegenerating:    isRegenerating,
 		Editable:          editable,
-		UserHasPermission: user.IsAdmin(),
+		UserHasPermission: user.Can(shared.PermissionEditTools),
 	})
 	err := t.Render(c.Request().Context(), c.Response())
 	if err != nil {
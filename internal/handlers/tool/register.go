@@ -3,38 +3,57 @@ package tool
 import (
 	"net/http"
 
+	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/env"
 
 	"github.com/knackwurstking/ui"
 	"github.com/labstack/echo/v4"
 )
 
-func Register(e *echo.Echo, path string) {
+// Repository contains the database operations used by the tool route handlers.
+type Repository interface {
+	db.ToolRepository
+	db.PressRepository
+	db.CycleRepository
+	db.NoteRepository
+	db.UserRepository
+	db.AuditRepository
+}
+
+// Handler holds the dependencies of all tool route handlers.
+type Handler struct {
+	db Repository
+}
+
+func Register(e *echo.Echo, path string, store db.Repository) {
+	h := &Handler{db: store}
+
 	ui.RegisterEchoRoutes(e, env.ServerPathPrefix, []*ui.EchoRoute{
 		// Main Page
-		ui.NewEchoRoute(http.MethodGet, path+"/:id", GetToolPage), // "is_cassette" defines the tool type
+		ui.NewEchoRoute(http.MethodGet, path+"/:id", h.GetToolPage), // "is_cassette" defines the tool type
 
 		// Regenerations Table
-		ui.NewEchoRoute(http.MethodDelete, path+"/:id/delete-regeneration", DeleteRegeneration), // "id" is regeneration ID
+		ui.NewEchoRoute(http.MethodDelete, path+"/:id/delete-regeneration", h.DeleteRegeneration), // "id" is regeneration ID
 
 		// Tool status and regenerations management
-		ui.NewEchoRoute(http.MethodGet, path+"/:id/regeneration-edit", RegenerationEditable),
-		ui.NewEchoRoute(http.MethodGet, path+"/:id/regeneration-display", RegenerationNonEditable),
-		ui.NewEchoRoute(http.MethodPut, path+"/:id/regeneration", Regeneration),
+		ui.NewEchoRoute(http.MethodGet, path+"/:id/regeneration-edit", h.RegenerationEditable),
+		ui.NewEchoRoute(http.MethodGet, path+"/:id/regeneration-display", h.RegenerationNonEditable),
+		ui.NewEchoRoute(http.MethodPut, path+"/:id/regeneration", h.Regeneration),
 
 		// Section loading
-		ui.NewEchoRoute(http.MethodGet, path+"/:id/notes", GetToolNotes),
-		ui.NewEchoRoute(http.MethodGet, path+"/:id/metal-sheets", GetToolMetalSheets),
+		ui.NewEchoRoute(http.MethodGet, path+"/:id/notes", h.GetToolNotes),
+		ui.NewEchoRoute(http.MethodGet, path+"/:id/metal-sheets", h.GetToolMetalSheets),
+		ui.NewEchoRoute(http.MethodGet, path+"/:id/audit", h.GetToolAudit),
 
 		// Cycles table rows
-		ui.NewEchoRoute(http.MethodGet, path+"/:id/cycles", GetCyclesSectionContent),
-		ui.NewEchoRoute(http.MethodGet, path+"/:id/total-cycles", GetToolTotalCycles),
+		ui.NewEchoRoute(http.MethodGet, path+"/:id/cycles", h.GetCyclesSectionContent),
+		ui.NewEchoRoute(http.MethodGet, path+"/:id/total-cycles", h.GetToolTotalCycles),
 
 		// Update tools binding data
-		ui.NewEchoRoute(http.MethodPatch, path+"/:id/bind", ToolBinding),
-		ui.NewEchoRoute(http.MethodPatch, path+"/:id/unbind", ToolUnBinding),
+		ui.NewEchoRoute(http.MethodPatch, path+"/:id/bind", h.ToolBinding),
+		ui.NewEchoRoute(http.MethodPatch, path+"/:id/unbind", h.ToolUnBinding),
 
 		// Delete a cycle table entry
-		ui.NewEchoRoute(http.MethodDelete, path+"/cycle/delete", DeleteToolCycle),
+		ui.NewEchoRoute(http.MethodDelete, path+"/cycle/delete", h.DeleteToolCycle),
 	})
 }
@@ -1,7 +1,6 @@
 package tool
 
 import (
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/knackwurstking/pg-press/internal/utils"
@@ -9,17 +8,17 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func GetToolTotalCycles(c echo.Context) *echo.HTTPError {
+func (h *Handler) GetToolTotalCycles(c echo.Context) *echo.HTTPError {
 	id, merr := utils.GetParamInt64(c, "id")
 	if merr != nil {
 		return merr.Echo()
 	}
-	totalCycles, merr := db.GetTotalToolCycles(shared.EntityID(id))
+	tool, merr := h.db.GetTool(shared.EntityID(id))
 	if merr != nil {
 		return merr.Echo()
 	}
 
-	t := TotalCycles(totalCycles, utils.GetQueryBool(c, "input"))
+	t := TotalCycles(tool.Cycles, tool.Thresholds, utils.GetQueryBool(c, "input"))
 	err := t.Render(c.Request().Context(), c.Response())
 	if err != nil {
 		return errors.NewRenderError(err, "TotalCycles")
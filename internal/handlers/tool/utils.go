@@ -3,24 +3,23 @@ package tool
 import (
 	"slices"
 
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/labstack/echo/v4"
 )
 
-func listBindableCassettes(tool *shared.Tool) ([]*shared.Tool, *echo.HTTPError) {
+func (h *Handler) listBindableCassettes(tool *shared.Tool) ([]*shared.Tool, *echo.HTTPError) {
 	var bindableCassettes []*shared.Tool
 	if !tool.IsCassette() {
 		var herr *errors.HTTPError
-		bindableCassettes, herr = db.ListBindableCassettes(tool.ID)
+		bindableCassettes, herr = h.db.ListBindableCassettes(tool.ID)
 		if herr != nil {
 			return bindableCassettes, herr.Echo()
 		}
 	}
 
 	if tool.Cassette > 0 {
-		cassette, herr := db.GetTool(tool.Cassette)
+		cassette, herr := h.db.GetTool(tool.Cassette)
 		if herr != nil {
 			return bindableCassettes, herr.Echo()
 		}
@@ -3,7 +3,6 @@ package tools
 import (
 	"log/slog"
 
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/knackwurstking/pg-press/internal/urlb"
 	"github.com/knackwurstking/pg-press/internal/utils"
@@ -11,16 +10,25 @@ import (
 )
 
 // Delete deletes a tool
-func Delete(c echo.Context) *echo.HTTPError {
+func (h *Handler) Delete(c echo.Context) *echo.HTTPError {
 	id, merr := utils.GetQueryInt64(c, "id")
 	if merr != nil {
 		return merr.Echo()
 	}
-	merr = db.DeleteTool(shared.EntityID(id))
+	user, merr := utils.GetUserFromContext(c)
 	if merr != nil {
 		return merr.Echo()
 	}
-	slog.Debug("Deleted tool", "id", id)
+	tool, merr := h.db.GetTool(shared.EntityID(id))
+	if merr != nil {
+		return merr.Echo()
+	}
+	merr = h.db.DeleteTool(tool.ID, user.ID)
+	if merr != nil {
+		return merr.Echo()
+	}
+	h.db.Audit(user.ID, shared.AuditActionDelete, tool, nil)
+	slog.Debug("Deleted tool", "id", id, "user_name", user.Name)
 
 	utils.SetHXRedirect(c, urlb.Tools())
 
@@ -1,7 +1,6 @@
 package tools
 
 import (
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/knackwurstking/pg-press/internal/urlb"
 	"github.com/knackwurstking/pg-press/internal/utils"
@@ -9,16 +8,32 @@ import (
 )
 
 // MarkAsDead marks a tool as dead
-func MarkAsDead(c echo.Context) *echo.HTTPError {
+func (h *Handler) MarkAsDead(c echo.Context) *echo.HTTPError {
 	id, merr := utils.GetQueryInt64(c, "id")
 	if merr != nil {
 		return merr.Echo()
 	}
 	toolID := shared.EntityID(id)
-	merr = db.MarkToolAsDead(toolID)
+
+	user, merr := utils.GetUserFromContext(c)
+	if merr != nil {
+		return merr.Echo()
+	}
+	before, merr := h.db.GetTool(toolID)
+	if merr != nil {
+		return merr.Echo()
+	}
+
+	merr = h.db.MarkToolAsDead(toolID)
+	if merr != nil {
+		return merr.Echo()
+	}
+
+	tool, merr := h.db.GetTool(toolID)
 	if merr != nil {
 		return merr.Echo()
 	}
+	h.db.Audit(user.ID, shared.AuditActionUpdate, before, tool)
 
 	utils.SetHXRedirect(c, urlb.Tool(toolID))
 
@@ -7,7 +7,7 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func GetToolsPage(c echo.Context) *echo.HTTPError {
+func (h *Handler) GetToolsPage(c echo.Context) *echo.HTTPError {
 	t := templates.Page()
 	err := t.Render(c.Request().Context(), c.Response())
 	if err != nil {
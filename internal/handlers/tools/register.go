@@ -3,18 +3,36 @@ package tools
 import (
 	"net/http"
 
+	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/env"
 
 	"github.com/knackwurstking/ui"
 	"github.com/labstack/echo/v4"
 )
 
-func Register(e *echo.Echo, path string) {
+// Repository contains the database operations used by the tools route handlers.
+type Repository interface {
+	db.ToolRepository
+	db.PressRepository
+	db.CycleRepository
+	db.NoteRepository
+	db.AuditRepository
+}
+
+// Handler holds the dependencies of all tools route handlers.
+type Handler struct {
+	db Repository
+}
+
+func Register(e *echo.Echo, path string, store db.Repository) {
+	h := &Handler{db: store}
+
 	ui.RegisterEchoRoutes(e, env.ServerPathPrefix, []*ui.EchoRoute{
-		ui.NewEchoRoute(http.MethodGet, path, GetToolsPage),
-		ui.NewEchoRoute(http.MethodDelete, path+"/delete", Delete),
-		ui.NewEchoRoute(http.MethodPatch, path+"/mark-dead", MarkAsDead),
-		ui.NewEchoRoute(http.MethodGet, path+"/section/press", PressSection),
-		ui.NewEchoRoute(http.MethodGet, path+"/section/tools", ToolsSection),
+		ui.NewEchoRoute(http.MethodGet, path, h.GetToolsPage),
+		ui.NewEchoRoute(http.MethodDelete, path+"/delete", h.Delete),
+		ui.NewEchoRoute(http.MethodPatch, path+"/mark-dead", h.MarkAsDead),
+		ui.NewEchoRoute(http.MethodGet, path+"/section/press", h.PressSection),
+		ui.NewEchoRoute(http.MethodGet, path+"/section/tools", h.ToolsSection),
+		ui.NewEchoRoute(http.MethodGet, path+"/section/forecast", h.ForecastSection),
 	})
 }
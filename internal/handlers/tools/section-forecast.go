@@ -0,0 +1,31 @@
+package tools
+
+import (
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/handlers/tools/templates"
+	"github.com/knackwurstking/pg-press/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ForecastSection renders the due soon list of all mounted tools, the query
+// "sort" defines the order, see shared.ToolForecastSort
+func (h *Handler) ForecastSection(c echo.Context) *echo.HTTPError {
+	forecasts, herr := h.db.ListToolForecasts()
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	sort := shared.NewToolForecastSort(c.QueryParam("sort"))
+	shared.SortToolForecasts(forecasts, sort)
+
+	t := templates.SectionForecast(templates.SectionForecastProps{
+		Forecasts: forecasts,
+		Sort:      sort,
+	})
+	if err := t.Render(c.Request().Context(), c.Response()); err != nil {
+		return errors.NewRenderError(err, "Section Forecast")
+	}
+
+	return nil
+}
@@ -3,7 +3,6 @@ package tools
 import (
 	"slices"
 
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/handlers/tools/templates"
 	"github.com/knackwurstking/pg-press/internal/shared"
@@ -12,12 +11,12 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func PressSection(c echo.Context) *echo.HTTPError {
-	return renderPressSection(c)
+func (h *Handler) PressSection(c echo.Context) *echo.HTTPError {
+	return h.renderPressSection(c)
 }
 
-func renderPressSection(c echo.Context) *echo.HTTPError {
-	pressUtilizations, herr := db.GetPressUtilizations()
+func (h *Handler) renderPressSection(c echo.Context) *echo.HTTPError {
+	pressUtilizations, herr := h.db.GetPressUtilizations()
 	if herr != nil && !herr.IsNotFoundError() {
 		return herr.Echo()
 	}
@@ -5,7 +5,6 @@ import (
 	"strings"
 	"sync"
 
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/handlers/tools/templates"
 	"github.com/knackwurstking/pg-press/internal/shared"
@@ -14,11 +13,11 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func ToolsSection(c echo.Context) *echo.HTTPError {
-	return renderToolsSection(c)
+func (h *Handler) ToolsSection(c echo.Context) *echo.HTTPError {
+	return h.renderToolsSection(c)
 }
 
-func renderToolsSection(c echo.Context) *echo.HTTPError {
+func (h *Handler) renderToolsSection(c echo.Context) *echo.HTTPError {
 	wg := &sync.WaitGroup{}
 	errCh := make(chan *echo.HTTPError, 4)
 
@@ -26,7 +25,7 @@ func renderToolsSection(c echo.Context) *echo.HTTPError {
 	tools := []*shared.Tool{}
 	cassettes := []*shared.Tool{}
 	wg.Go(func() {
-		allTools, merr := db.ListTools()
+		allTools, merr := h.db.ListTools()
 		if merr != nil {
 			errCh <- merr.Echo()
 			return
@@ -55,7 +54,7 @@ func renderToolsSection(c echo.Context) *echo.HTTPError {
 	// Active Tools
 	activeTools := make(map[shared.EntityID]*shared.Press)
 	wg.Go(func() {
-		presses, herr := db.ListPress()
+		presses, herr := h.db.ListPress()
 		if herr != nil {
 			errCh <- herr.Echo()
 			return
@@ -77,7 +76,7 @@ func renderToolsSection(c echo.Context) *echo.HTTPError {
 	isRegenerating := make(map[shared.EntityID]bool)
 	regenerationsCount := make(map[shared.EntityID]int)
 	wg.Go(func() {
-		regenerations, merr := db.ListToolRegenerations()
+		regenerations, merr := h.db.ListToolRegenerations()
 		if merr != nil {
 			errCh <- merr.Echo()
 			return
@@ -103,7 +102,7 @@ func renderToolsSection(c echo.Context) *echo.HTTPError {
 	// Notes Count
 	notesCount := map[shared.EntityID]int{}
 	wg.Go(func() {
-		notes, merr := db.ListNotes()
+		notes, merr := h.db.ListNotes()
 		if merr != nil {
 			errCh <- merr.Echo()
 			return
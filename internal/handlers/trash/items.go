@@ -0,0 +1,91 @@
+package trash
+
+import (
+	"log/slog"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/handlers/trash/templates"
+	"github.com/knackwurstking/pg-press/internal/shared"
+	"github.com/knackwurstking/pg-press/internal/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) HTMXGetItems(c echo.Context) *echo.HTTPError {
+	user, herr := utils.GetUserFromContext(c)
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	items, herr := h.db.ListTrash()
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	userNames, herr := h.db.ListUserNames()
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	t := templates.Items(items, userNames, user)
+	if err := t.Render(c.Request().Context(), c.Response()); err != nil {
+		return errors.NewRenderError(err, "Trash Items")
+	}
+
+	return nil
+}
+
+func (h *Handler) HTMXPostRestore(c echo.Context) *echo.HTTPError {
+	user, herr := utils.GetUserFromContext(c)
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	kind, herr := utils.GetQueryString(c, "kind")
+	if herr != nil {
+		return herr.Echo()
+	}
+	id, herr := utils.GetQueryInt64(c, "id")
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	if herr = h.db.RestoreTrashItem(shared.TrashKind(kind), shared.EntityID(id)); herr != nil {
+		return herr.Echo()
+	}
+	slog.Info("Restored from trash", "kind", kind, "id", id, "user_name", user.Name)
+
+	// Like the audit log itself, a failure here only gets logged
+	if restored, herr := h.restoredEntity(shared.TrashKind(kind), shared.EntityID(id)); herr != nil {
+		slog.Error("Failed to get restored entity for the audit log", "kind", kind, "id", id, "error", herr)
+	} else {
+		h.db.Audit(user.ID, shared.AuditActionRestore, nil, restored)
+	}
+
+	return h.HTMXGetItems(c)
+}
+
+// restoredEntity returns the entity of a trash item after it got restored, for
+// the audit log
+func (h *Handler) restoredEntity(kind shared.TrashKind, id shared.EntityID) (shared.Auditable, *errors.HTTPError) {
+	switch kind {
+	case shared.TrashKindTool:
+		return h.db.GetTool(id)
+	case shared.TrashKindMetalSheet:
+		// Upper and lower metal sheets share the table
+		if ums, herr := h.db.GetUpperMetalSheet(id); herr == nil {
+			return ums, nil
+		}
+		return h.db.GetLowerMetalSheet(id)
+	case shared.TrashKindPress:
+		return h.db.GetPress(id)
+	case shared.TrashKindCycle:
+		return h.db.GetCycle(id)
+	case shared.TrashKindNote:
+		return h.db.GetNote(id)
+	case shared.TrashKindTroubleReport:
+		return h.db.GetTroubleReport(id)
+	default:
+		return nil, errors.NewValidationError("invalid trash kind: %s", kind).HTTPError()
+	}
+}
@@ -0,0 +1,18 @@
+package trash
+
+import (
+	"github.com/knackwurstking/pg-press/internal/env"
+	"github.com/knackwurstking/pg-press/internal/errors"
+	"github.com/knackwurstking/pg-press/internal/handlers/trash/templates"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) GetTrashPage(c echo.Context) *echo.HTTPError {
+	t := templates.Page(templates.PageProps{RetentionDays: env.TrashRetentionDays})
+	if err := t.Render(c.Request().Context(), c.Response()); err != nil {
+		return errors.NewRenderError(err, "Trash Page")
+	}
+
+	return nil
+}
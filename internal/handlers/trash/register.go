@@ -0,0 +1,38 @@
+package trash
+
+import (
+	"net/http"
+
+	"github.com/knackwurstking/pg-press/internal/db"
+	"github.com/knackwurstking/pg-press/internal/env"
+
+	"github.com/knackwurstking/ui"
+	"github.com/labstack/echo/v4"
+)
+
+// Repository contains the database operations used by the trash route handlers.
+type Repository interface {
+	db.TrashRepository
+	db.UserRepository
+	db.ToolRepository
+	db.PressRepository
+	db.CycleRepository
+	db.NoteRepository
+	db.ReportRepository
+	db.AuditRepository
+}
+
+// Handler holds the dependencies of all trash route handlers.
+type Handler struct {
+	db Repository
+}
+
+func Register(e *echo.Echo, path string, store db.Repository) {
+	h := &Handler{db: store}
+
+	ui.RegisterEchoRoutes(e, env.ServerPathPrefix, []*ui.EchoRoute{
+		ui.NewEchoRoute(http.MethodGet, path, h.GetTrashPage),
+		ui.NewEchoRoute(http.MethodGet, path+"/items", h.HTMXGetItems),
+		ui.NewEchoRoute(http.MethodPost, path+"/restore", h.HTMXPostRestore),
+	})
+}
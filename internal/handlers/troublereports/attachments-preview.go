@@ -1,7 +1,6 @@
 package troublereports
 
 import (
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/handlers/troublereports/templates"
 	"github.com/knackwurstking/pg-press/internal/shared"
@@ -9,13 +8,13 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func GetAttachmentsPreview(c echo.Context) *echo.HTTPError {
+func (h *Handler) GetAttachmentsPreview(c echo.Context) *echo.HTTPError {
 	id, merr := utils.GetQueryInt64(c, "id") // Get trouble report ID
 	if merr != nil {
 		return merr.Echo()
 	}
 
-	tr, merr := db.GetTroubleReport(shared.EntityID(id))
+	tr, merr := h.db.GetTroubleReport(shared.EntityID(id))
 	if merr != nil {
 		return merr.Echo()
 	}
@@ -1,7 +1,6 @@
 package troublereports
 
 import (
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/handlers/troublereports/templates"
 	"github.com/knackwurstking/pg-press/internal/utils"
@@ -9,13 +8,13 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func GetData(c echo.Context) *echo.HTTPError {
+func (h *Handler) GetData(c echo.Context) *echo.HTTPError {
 	user, merr := utils.GetUserFromContext(c)
 	if merr != nil {
 		return merr.Echo()
 	}
 
-	troubleReports, merr := db.ListTroubleReports()
+	troubleReports, merr := h.db.ListTroubleReports()
 	if merr != nil {
 		return merr.Echo()
 	}
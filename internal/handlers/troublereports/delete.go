@@ -1,22 +1,32 @@
 package troublereports
 
 import (
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/knackwurstking/pg-press/internal/utils"
 	"github.com/labstack/echo/v4"
 )
 
-func DeleteTroubleReport(c echo.Context) *echo.HTTPError {
+func (h *Handler) DeleteTroubleReport(c echo.Context) *echo.HTTPError {
 	id, merr := utils.GetQueryInt64(c, "id")
 	if merr != nil {
 		return merr.Echo()
 	}
 	trID := shared.EntityID(id)
 
-	if merr = db.DeleteTroubleReport(trID); merr != nil {
+	user, merr := utils.GetUserFromContext(c)
+	if merr != nil {
+		return merr.Echo()
+	}
+
+	tr, merr := h.db.GetTroubleReport(trID)
+	if merr != nil {
+		return merr.Echo()
+	}
+
+	if merr = h.db.DeleteTroubleReport(trID, user.ID); merr != nil {
 		return merr.Echo()
 	}
+	h.db.Audit(user.ID, shared.AuditActionDelete, tr, nil)
 
 	utils.SetHXTrigger(c, "reload-trouble-reports")
 
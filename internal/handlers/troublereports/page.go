@@ -3,11 +3,18 @@ package troublereports
 import (
 	"github.com/knackwurstking/pg-press/internal/errors"
 	"github.com/knackwurstking/pg-press/internal/handlers/troublereports/templates"
+	"github.com/knackwurstking/pg-press/internal/utils"
+
 	"github.com/labstack/echo/v4"
 )
 
-func GetPage(c echo.Context) *echo.HTTPError {
-	t := templates.Page()
+func (h *Handler) GetPage(c echo.Context) *echo.HTTPError {
+	user, herr := utils.GetUserFromContext(c)
+	if herr != nil {
+		return herr.Echo()
+	}
+
+	t := templates.Page(user)
 	if err := t.Render(c.Request().Context(), c.Response()); err != nil {
 		return errors.NewRenderError(err, "Page")
 	}
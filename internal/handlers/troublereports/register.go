@@ -3,22 +3,36 @@ package troublereports
 import (
 	"net/http"
 
+	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/env"
 
 	"github.com/knackwurstking/ui"
 	"github.com/labstack/echo/v4"
 )
 
-func Register(e *echo.Echo, path string) {
+// Repository contains the database operations used by the troublereports route handlers.
+type Repository interface {
+	db.ReportRepository
+	db.AuditRepository
+}
+
+// Handler holds the dependencies of all troublereports route handlers.
+type Handler struct {
+	db Repository
+}
+
+func Register(e *echo.Echo, path string, store db.Repository) {
+	h := &Handler{db: store}
+
 	ui.RegisterEchoRoutes(
 		e,
 		env.ServerPathPrefix,
 		[]*ui.EchoRoute{
-			ui.NewEchoRoute(http.MethodGet, path, GetPage),
-			ui.NewEchoRoute(http.MethodGet, path+"/data", GetData),
-			ui.NewEchoRoute(http.MethodDelete, path+"/delete", DeleteTroubleReport),
-			ui.NewEchoRoute(http.MethodGet, path+"/attachments-preview", GetAttachmentsPreview),
-			ui.NewEchoRoute(http.MethodGet, path+"/share-pdf", GetSharePDF),
+			ui.NewEchoRoute(http.MethodGet, path, h.GetPage),
+			ui.NewEchoRoute(http.MethodGet, path+"/data", h.GetData),
+			ui.NewEchoRoute(http.MethodDelete, path+"/delete", h.DeleteTroubleReport),
+			ui.NewEchoRoute(http.MethodGet, path+"/attachments-preview", h.GetAttachmentsPreview),
+			ui.NewEchoRoute(http.MethodGet, path+"/share-pdf", h.GetSharePDF),
 		},
 	)
 }
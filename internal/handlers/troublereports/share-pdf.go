@@ -7,21 +7,20 @@ import (
 	"strings"
 	"time"
 
-	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/pdf"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/knackwurstking/pg-press/internal/utils"
 	"github.com/labstack/echo/v4"
 )
 
-func GetSharePDF(c echo.Context) *echo.HTTPError {
+func (h *Handler) GetSharePDF(c echo.Context) *echo.HTTPError {
 	id, merr := utils.GetQueryInt64(c, "id")
 	if merr != nil {
 		return merr.Echo()
 	}
 
 	// Get trouble report by ID
-	tr, merr := db.GetTroubleReport(shared.EntityID(id))
+	tr, merr := h.db.GetTroubleReport(shared.EntityID(id))
 	if merr != nil {
 		return merr.Echo()
 	}
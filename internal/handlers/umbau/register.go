@@ -3,19 +3,35 @@ package umbau
 import (
 	"net/http"
 
+	"github.com/knackwurstking/pg-press/internal/db"
 	"github.com/knackwurstking/pg-press/internal/env"
 
 	"github.com/knackwurstking/ui"
 	"github.com/labstack/echo/v4"
 )
 
-func Register(e *echo.Echo, path string) {
+// Repository contains the database operations used by the umbau route handlers.
+type Repository interface {
+	db.PressRepository
+	db.ToolRepository
+	db.AuditRepository
+	db.TransactionRepository
+}
+
+// Handler holds the dependencies of all umbau route handlers.
+type Handler struct {
+	db Repository
+}
+
+func Register(e *echo.Echo, path string, store db.Repository) {
+	h := &Handler{db: store}
+
 	ui.RegisterEchoRoutes(
 		e,
 		env.ServerPathPrefix,
 		[]*ui.EchoRoute{
-			ui.NewEchoRoute(http.MethodGet, path+"/:press", GetUmbauPage),
-			ui.NewEchoRoute(http.MethodPost, path+"/:press", PostUmbauPage),
+			ui.NewEchoRoute(http.MethodGet, path+"/:press", h.GetUmbauPage),
+			ui.NewEchoRoute(http.MethodPost, path+"/:press", h.PostUmbauPage),
 		},
 	)
 }
@@ -14,7 +14,7 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func GetUmbauPage(c echo.Context) *echo.HTTPError {
+func (h *Handler) GetUmbauPage(c echo.Context) *echo.HTTPError {
 	user, merr := utils.GetUserFromContext(c)
 	if merr != nil {
 		return merr.Echo()
@@ -24,17 +24,17 @@ func GetUmbauPage(c echo.Context) *echo.HTTPError {
 	if herr != nil {
 		return herr.Echo()
 	}
-	press, herr := db.GetPress(shared.EntityID(id))
+	press, herr := h.db.GetPress(shared.EntityID(id))
 	if herr != nil {
 		return herr.Echo()
 	}
 
-	u, merr := db.GetPressUtilization(press.ID)
+	u, merr := h.db.GetPressUtilization(press.ID)
 	if merr != nil {
 		return merr.Echo()
 	}
 
-	tools, merr := db.ListTools()
+	tools, merr := h.db.ListTools()
 	if merr != nil {
 		return merr.Echo()
 	}
@@ -64,65 +64,94 @@ func GetUmbauPage(c echo.Context) *echo.HTTPError {
 	return nil
 }
 
-func PostUmbauPage(c echo.Context) *echo.HTTPError {
+func (h *Handler) PostUmbauPage(c echo.Context) *echo.HTTPError {
+	user, herr := utils.GetUserFromContext(c)
+	if herr != nil {
+		return herr.Echo()
+	}
+
 	id, herr := utils.GetParamInt64(c, "press")
 	if herr != nil {
 		return herr.Echo()
 	}
 	pressID := shared.EntityID(id)
 
-	data, eerr := getFormData(c)
+	data, eerr := h.getFormData(c)
 	if eerr != nil {
 		return eerr
 	}
 
-	// Press Utilization
-	u, merr := db.GetPressUtilization(pressID)
-	if merr != nil {
-		return merr.WrapEcho("get press utilization")
-	}
+	var (
+		cycles []*shared.Cycle
+		before *shared.Press
+		press  *shared.Press
+	)
 
-	// Old Tools for setting cycles
-	tools := make([]*shared.Tool, 0)
-	if u.SlotUpper != nil {
-		tools = append(tools, u.SlotUpper)
-	}
-	if u.SlotUpperCassette != nil {
-		tools = append(tools, u.SlotUpperCassette)
-	}
-	if u.SlotLower != nil {
-		tools = append(tools, u.SlotLower)
-	}
-	// Set cycles for old tools
-	for _, t := range tools {
-		merr = db.AddCycle(
-			shared.NewCycle(
-				t.ID,
+	// Cycles for the old tools and the new press slots are written together,
+	// or not at all. The press is read inside the unit of work, so concurrent
+	// changes are not overwritten.
+	merr := h.db.Transaction(func(tx *db.Tx) *errors.HTTPError {
+		var merr *errors.HTTPError
+		press, merr = tx.GetPress(pressID)
+		if merr != nil {
+			return merr.Wrap("get press")
+		}
+		before = press.Clone()
+
+		// Old tools for setting cycles, the cassette is bound to the upper tool
+		var toolIDs []shared.EntityID
+		if press.SlotUp > 0 {
+			upper, merr := tx.GetTool(press.SlotUp)
+			if merr != nil {
+				return merr.Wrap("get upper tool")
+			}
+			toolIDs = append(toolIDs, upper.ID)
+			if upper.Cassette > 0 {
+				toolIDs = append(toolIDs, upper.Cassette)
+			}
+		}
+		if press.SlotDown > 0 {
+			toolIDs = append(toolIDs, press.SlotDown)
+		}
+
+		// Set cycles for old tools
+		for _, toolID := range toolIDs {
+			cycle := shared.NewCycle(
+				toolID,
 				pressID,
 				data.totalCycles,
 				shared.NewUnixMilli(time.Now()),
-			),
-		)
-		if merr != nil {
-			return merr.WrapEcho("add cycle")
+				user.ID,
+			)
+			if merr := tx.AddCycle(cycle); merr != nil {
+				return merr.Wrap("add cycle")
+			}
+			cycles = append(cycles, cycle)
 		}
-	}
 
-	// Update press with new tools
-	press := u.Press()
-	tools = []*shared.Tool{data.upperTool, data.lowerTool}
-	for _, t := range tools {
-		switch t.Position {
-		case shared.SlotUpper:
-			press.SlotUp = t.ID
-		case shared.SlotLower:
-			press.SlotDown = t.ID
+		// Update press with new tools
+		for _, t := range []*shared.Tool{data.upperTool, data.lowerTool} {
+			switch t.Position {
+			case shared.SlotUpper:
+				press.SlotUp = t.ID
+			case shared.SlotLower:
+				press.SlotDown = t.ID
+			}
 		}
-	}
-	merr = db.UpdatePress(press)
+		if merr := tx.UpdatePress(press); merr != nil {
+			return merr.Wrap("update press")
+		}
+
+		return nil
+	})
 	if merr != nil {
-		return merr.WrapEcho("update press")
+		return merr.Echo()
+	}
+
+	for _, cycle := range cycles {
+		h.db.Audit(user.ID, shared.AuditActionCreate, nil, cycle)
 	}
+	h.db.Audit(user.ID, shared.AuditActionUpdate, before, press)
 
 	return nil
 }
@@ -133,7 +162,7 @@ type formData struct {
 	lowerTool   *shared.Tool
 }
 
-func getFormData(c echo.Context) (*formData, *echo.HTTPError) {
+func (h *Handler) getFormData(c echo.Context) (*formData, *echo.HTTPError) {
 	data := &formData{}
 
 	// TotalCycles from form
@@ -153,7 +182,7 @@ func getFormData(c echo.Context) (*formData, *echo.HTTPError) {
 		return data, echo.NewHTTPError(http.StatusBadRequest, "missing top tool")
 	}
 	var merr *errors.HTTPError
-	data.upperTool, merr = db.GetTool(shared.EntityID(id))
+	data.upperTool, merr = h.db.GetTool(shared.EntityID(id))
 	if merr != nil {
 		return data, merr.WrapEcho("get (upper) tool with ID %d", id)
 	}
@@ -163,7 +192,7 @@ func getFormData(c echo.Context) (*formData, *echo.HTTPError) {
 	if err != nil {
 		return data, echo.NewHTTPError(http.StatusBadRequest, "missing bottom tool")
 	}
-	data.lowerTool, merr = db.GetTool(shared.EntityID(id))
+	data.lowerTool, merr = h.db.GetTool(shared.EntityID(id))
 	if merr != nil {
 		return data, merr.WrapEcho("get (bottom) tool with ID %d", id)
 	}
@@ -0,0 +1,193 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/knackwurstking/pg-press/internal/backup"
+	"github.com/knackwurstking/pg-press/internal/db"
+	"github.com/knackwurstking/pg-press/internal/env"
+	"github.com/knackwurstking/pg-press/internal/shared"
+)
+
+const (
+	// snapshotsToKeep is the number of database snapshots kept in env.ServerPathBackups
+	snapshotsToKeep = 14
+
+	// attachmentGracePeriod protects uploads not yet linked to a trouble report
+	attachmentGracePeriod = 24 * time.Hour
+)
+
+// builtin holds the dependencies of the built-in jobs.
+type builtin struct {
+	db *db.Store
+}
+
+// RegisterBuiltin registers all built-in jobs with the schedules from env.
+func (s *Scheduler) RegisterBuiltin(store *db.Store) error {
+	b := &builtin{db: store}
+
+	for _, job := range []*Job{
+		{
+			Name:        "cookie-cleanup",
+			Description: "Entfernt abgelaufene Cookies",
+			Schedule:    env.JobCookieCleanup,
+			Run:         b.cleanUpCookies,
+		},
+		{
+			Name:        "db-snapshot",
+			Description: "Erstellt eine Sicherung aller Datenbanken",
+			Schedule:    env.JobDBSnapshot,
+			Run:         b.snapshotDatabases,
+		},
+		{
+			Name:        "attachment-cleanup",
+			Description: "Entfernt Bilder, die keinem Problembericht mehr zugeordnet sind",
+			Schedule:    env.JobAttachmentCleanup,
+			Run:         b.cleanUpAttachments,
+		},
+		{
+			Name:        "login-cleanup",
+			Description: "Entfernt alte Einträge des Anmeldeverlaufs und abgelaufene Anmeldesperren",
+			Schedule:    env.JobLoginCleanup,
+			Run:         b.cleanUpLogins,
+		},
+		{
+			Name:        "trash-purge",
+			Description: "Entfernt Einträge endgültig, die länger als die Aufbewahrungszeit im Papierkorb liegen",
+			Schedule:    env.JobTrashPurge,
+			Run:         b.purgeTrash,
+		},
+	} {
+		if err := s.Register(job); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *builtin) cleanUpCookies(ctx context.Context) error {
+	n, herr := b.db.DeleteExpiredCookies()
+	if herr != nil {
+		return herr
+	}
+
+	slog.Info("Removed expired cookies", "count", n)
+	return nil
+}
+
+func (b *builtin) cleanUpLogins(ctx context.Context) error {
+	before := time.Now().AddDate(0, 0, -env.LoginHistoryRetentionDays)
+
+	attempts, herr := b.db.DeleteLoginAttemptsBefore(shared.NewUnixMilli(before))
+	if herr != nil {
+		return herr
+	}
+
+	lockouts, herr := b.db.DeleteStaleLockouts()
+	if herr != nil {
+		return herr
+	}
+
+	slog.Info("Removed old login attempts and stale lockouts",
+		"login_attempts", attempts, "lockouts", lockouts,
+		"retention_days", env.LoginHistoryRetentionDays)
+	return nil
+}
+
+func (b *builtin) snapshotDatabases(ctx context.Context) error {
+	if err := os.MkdirAll(env.ServerPathBackups, 0700); err != nil {
+		return fmt.Errorf("create backups directory: %w", err)
+	}
+
+	// Images are not part of the snapshots, use "db backup" for a full backup
+	archivePath, err := backup.Create(b.db, env.ServerPathBackups, "")
+	if err != nil {
+		return err
+	}
+	slog.Info("Created database snapshot", "path", archivePath)
+
+	// Remove old snapshots, the timestamp in the name keeps them sorted
+	snapshots, err := filepath.Glob(filepath.Join(env.ServerPathBackups, env.Name+"-backup-*.tar.gz"))
+	if err != nil {
+		return err
+	}
+	slices.Sort(snapshots)
+	for len(snapshots) > snapshotsToKeep {
+		if err = os.Remove(snapshots[0]); err != nil {
+			return fmt.Errorf("remove old snapshot: %w", err)
+		}
+		slog.Info("Removed old database snapshot", "path", snapshots[0])
+		snapshots = snapshots[1:]
+	}
+
+	return nil
+}
+
+func (b *builtin) cleanUpAttachments(ctx context.Context) error {
+	// Trouble reports in the trash still reference their attachments
+	attachments, herr := b.db.ListTroubleReportAttachments()
+	if herr != nil {
+		return herr
+	}
+
+	linked := make(map[string]struct{})
+	for _, a := range attachments {
+		linked[a] = struct{}{}
+	}
+
+	files, err := os.ReadDir(env.ServerPathImages)
+	if err != nil {
+		return fmt.Errorf("read images directory: %w", err)
+	}
+
+	var removed int
+	for _, f := range files {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if !f.Type().IsRegular() || strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+		if _, ok := linked[f.Name()]; ok {
+			continue
+		}
+
+		info, err := f.Info()
+		if err != nil {
+			return err
+		}
+		if time.Since(info.ModTime()) < attachmentGracePeriod {
+			continue
+		}
+
+		if err = os.Remove(filepath.Join(env.ServerPathImages, f.Name())); err != nil {
+			return fmt.Errorf("remove orphaned attachment: %w", err)
+		}
+		slog.Info("Removed orphaned attachment", "name", f.Name())
+		removed++
+	}
+
+	slog.Info("Orphaned attachments cleanup done", "removed", removed)
+	return nil
+}
+
+func (b *builtin) purgeTrash(ctx context.Context) error {
+	before := time.Now().AddDate(0, 0, -env.TrashRetentionDays)
+
+	n, herr := b.db.PurgeTrash(shared.NewUnixMilli(before))
+	if herr != nil {
+		return herr
+	}
+
+	slog.Info("Purged trash", "count", n, "retention_days", env.TrashRetentionDays)
+	return nil
+}
@@ -0,0 +1,228 @@
+// Package jobs runs housekeeping tasks in the background while the server is running.
+//
+// Jobs are registered on a Scheduler with a cron like schedule (see ParseSchedule)
+// and started together with the server. The state of each job (last run, duration
+// and error) is kept in memory and shown on the admin page.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Job is a task running on a schedule.
+type Job struct {
+	Name        string
+	Description string
+	Schedule    string
+	Run         func(ctx context.Context) error
+}
+
+// Status contains the current state of a registered job.
+type Status struct {
+	Name        string
+	Description string
+	Schedule    string
+	Running     bool
+	NextRun     time.Time
+	LastRun     time.Time
+	Duration    time.Duration
+	Error       string
+}
+
+type entry struct {
+	job      *Job
+	schedule Schedule
+	status   Status
+}
+
+// Scheduler runs the registered jobs on their schedule.
+type Scheduler struct {
+	mutex   sync.Mutex
+	entries []*entry
+	ctx     context.Context // ctx is set while running, cancelled by Stop
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewScheduler returns a Scheduler without jobs.
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Register adds a job, jobs with the schedule ScheduleOff are skipped.
+//
+// Returns an error if the schedule is invalid or a job with this name already exists.
+func (s *Scheduler) Register(job *Job) error {
+	if strings.TrimSpace(job.Schedule) == ScheduleOff {
+		slog.Info("Job disabled", "job", job.Name)
+		return nil
+	}
+
+	schedule, err := ParseSchedule(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if slices.ContainsFunc(s.entries, func(e *entry) bool { return e.job.Name == job.Name }) {
+		return fmt.Errorf("job %s already registered", job.Name)
+	}
+
+	s.entries = append(s.entries, &entry{
+		job:      job,
+		schedule: schedule,
+		status: Status{
+			Name:        job.Name,
+			Description: job.Description,
+			Schedule:    job.Schedule,
+		},
+	})
+	return nil
+}
+
+// Start runs all registered jobs on their schedule until Stop is called.
+func (s *Scheduler) Start() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.ctx, s.cancel = ctx, cancel
+
+	for _, e := range s.entries {
+		slog.Info("Starting job", "job", e.job.Name, "schedule", e.job.Schedule)
+		s.wg.Go(func() { s.loop(ctx, e) })
+	}
+}
+
+// Stop cancels all jobs and waits for running jobs to return, or until ctx is
+// done.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mutex.Lock()
+	if s.cancel == nil {
+		s.mutex.Unlock()
+		return nil
+	}
+	s.cancel()
+	s.ctx, s.cancel = nil, nil
+	s.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RunNow runs a registered job immediately, outside of its schedule. The job
+// gets cancelled by Stop like the scheduled runs.
+//
+// Returns an error if the job does not exist or the scheduler is not running.
+func (s *Scheduler) RunNow(name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	idx := slices.IndexFunc(s.entries, func(e *entry) bool { return e.job.Name == name })
+	if idx < 0 {
+		return fmt.Errorf("job %s not found", name)
+	}
+	if s.ctx == nil {
+		return fmt.Errorf("job %s can not run, the scheduler is not running", name)
+	}
+
+	e, ctx := s.entries[idx], s.ctx
+	s.wg.Go(func() { s.run(ctx, e) })
+	return nil
+}
+
+// List returns the status of all registered jobs, sorted by name.
+func (s *Scheduler) List() []Status {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	list := make([]Status, 0, len(s.entries))
+	for _, e := range s.entries {
+		list = append(list, e.status)
+	}
+
+	slices.SortFunc(list, func(a, b Status) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return list
+}
+
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	for {
+		next := e.schedule.Next(time.Now())
+		if next.IsZero() {
+			slog.Warn("Job will never run again", "job", e.job.Name)
+			return
+		}
+
+		s.mutex.Lock()
+		e.status.NextRun = next
+		s.mutex.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.run(ctx, e)
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, e *entry) {
+	s.mutex.Lock()
+	if e.status.Running {
+		s.mutex.Unlock()
+		slog.Warn("Job is still running, skipping", "job", e.job.Name)
+		return
+	}
+	e.status.Running = true
+	s.mutex.Unlock()
+
+	slog.Debug("Running job", "job", e.job.Name)
+
+	start := time.Now()
+	err := e.job.Run(ctx)
+	duration := time.Since(start)
+
+	if err != nil {
+		slog.Error("Job failed", "job", e.job.Name, "duration", duration, "error", err)
+	} else {
+		slog.Info("Job done", "job", e.job.Name, "duration", duration)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	e.status.Running = false
+	e.status.LastRun = start
+	e.status.Duration = duration
+	e.status.Error = ""
+	if err != nil {
+		e.status.Error = err.Error()
+	}
+}
@@ -0,0 +1,112 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestScheduler(t *testing.T) {
+	a, b := NewScheduler(), NewScheduler()
+
+	runs := make(chan string, 1)
+	job := &Job{
+		Name:     "test",
+		Schedule: "@daily",
+		Run: func(ctx context.Context) error {
+			runs <- "test"
+			return errors.New("failed")
+		},
+	}
+
+	if err := a.Register(job); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := a.Register(job); err == nil {
+		t.Error("Register of a duplicate job name, want an error")
+	}
+	if err := a.Register(&Job{Name: "off", Schedule: ScheduleOff}); err != nil {
+		t.Errorf("Register of a disabled job: %v", err)
+	}
+	if err := a.Register(&Job{Name: "invalid", Schedule: "* * *"}); err == nil {
+		t.Error("Register with an invalid schedule, want an error")
+	}
+
+	if n := len(b.List()); n != 0 {
+		t.Fatalf("second scheduler has %d jobs, want 0", n)
+	}
+	if err := b.RunNow("test"); err == nil {
+		t.Error("RunNow on the second scheduler, want an error")
+	}
+
+	if err := a.RunNow("test"); err == nil {
+		t.Error("RunNow before Start, want an error")
+	}
+
+	a.Start()
+	if err := a.RunNow("test"); err != nil {
+		t.Fatalf("RunNow: %v", err)
+	}
+	<-runs
+	if err := a.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	list := a.List()
+	if len(list) != 1 {
+		t.Fatalf("List() returned %d jobs, want 1", len(list))
+	}
+	if s := list[0]; s.Running || s.LastRun.IsZero() || s.Error != "failed" {
+		t.Errorf("status after run = %+v", s)
+	}
+}
+
+func TestSchedulerStop(t *testing.T) {
+	s := NewScheduler()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	cancelled := make(chan struct{})
+	job := &Job{
+		Name:     "test",
+		Schedule: "@daily",
+		Run: func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			close(cancelled)
+			<-release
+			return ctx.Err()
+		},
+	}
+	if err := s.Register(job); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	s.Start()
+	if err := s.RunNow("test"); err != nil {
+		t.Fatalf("RunNow: %v", err)
+	}
+	<-started
+
+	// The job is cancelled, but does not return in time
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := s.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stop with a running job = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("job started by RunNow was not cancelled by Stop")
+	}
+
+	close(release)
+	if err := s.Stop(context.Background()); err != nil {
+		t.Errorf("Stop of a stopped scheduler = %v, want nil", err)
+	}
+	if err := s.RunNow("test"); err == nil {
+		t.Error("RunNow after Stop, want an error")
+	}
+}
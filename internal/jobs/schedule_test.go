@@ -0,0 +1,114 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	for _, spec := range []string{
+		"* * * * *",
+		"*/15 * * * *",
+		"0-30/10 1,13 * * *",
+		"0 9-17 * * 1-5",
+		"0 0 * * 7",
+		" @daily ",
+		"@every 6h",
+	} {
+		if _, err := ParseSchedule(spec); err != nil {
+			t.Errorf("ParseSchedule(%q) = %v, want no error", spec, err)
+		}
+	}
+
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@yearly",
+		"@every 30s",
+		"@every soon",
+	} {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("ParseSchedule(%q) = nil, want an error", spec)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	date := func(s string) time.Time {
+		t.Helper()
+		v, err := time.ParseInLocation("2006-01-02 15:04", s, time.UTC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	for _, tc := range []struct {
+		name string
+		spec string
+		from string
+		want string // empty if the schedule never runs
+	}{
+		{"every minute", "* * * * *", "2026-10-14 10:07", "2026-10-14 10:08"},
+		{"step", "*/15 * * * *", "2026-10-14 10:07", "2026-10-14 10:15"},
+		{"step on match", "*/15 * * * *", "2026-10-14 10:15", "2026-10-14 10:30"},
+		{"step next hour", "*/15 * * * *", "2026-10-14 10:45", "2026-10-14 11:00"},
+		{"range with step", "0-30/10 * * * *", "2026-10-14 10:31", "2026-10-14 11:00"},
+		{"list", "0 1,13 * * *", "2026-10-14 01:00", "2026-10-14 13:00"},
+		{"hour range", "0 9-17 * * *", "2026-10-14 17:00", "2026-10-15 09:00"},
+		{"weekdays", "0 9 * * 1-5", "2026-10-16 10:00", "2026-10-19 09:00"},
+		{"sunday as 0", "0 0 * * 0", "2026-10-14 00:00", "2026-10-18 00:00"},
+		{"sunday as 7", "0 0 * * 7", "2026-10-14 00:00", "2026-10-18 00:00"},
+		{"day of week only", "0 0 * * 6", "2026-10-14 00:00", "2026-10-17 00:00"},
+		{"day of month only", "0 0 13 * *", "2026-10-14 00:00", "2026-11-13 00:00"},
+		{"day of month or week", "0 0 13 * 5", "2026-10-14 00:00", "2026-10-16 00:00"},
+		{"day of month before week", "0 0 15 * 1", "2026-10-14 00:00", "2026-10-15 00:00"},
+		{"month end skips short months", "0 0 31 * *", "2026-04-01 00:00", "2026-05-31 00:00"},
+		{"month end in february", "0 0 28-31 2 *", "2026-02-28 00:00", "2027-02-28 00:00"},
+		{"leap day", "0 0 29 2 *", "2026-03-01 00:00", "2028-02-29 00:00"},
+		{"year end", "59 23 31 12 *", "2026-12-31 23:59", "2027-12-31 23:59"},
+		{"new year", "@monthly", "2026-12-31 23:59", "2027-01-01 00:00"},
+		{"weekly", "@weekly", "2026-10-18 00:00", "2026-10-25 00:00"},
+		{"impossible date", "0 0 30 2 *", "2026-01-01 00:00", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := ParseSchedule(tc.spec)
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q): %v", tc.spec, err)
+			}
+
+			got := s.Next(date(tc.from))
+			if tc.want == "" {
+				if !got.IsZero() {
+					t.Errorf("Next(%s) = %s, want never", tc.from, got)
+				}
+				return
+			}
+			if want := date(tc.want); !got.Equal(want) {
+				t.Errorf("Next(%s) = %s, want %s", tc.from, got, want)
+			}
+		})
+	}
+}
+
+func TestScheduleNextEvery(t *testing.T) {
+	s, err := ParseSchedule("@every 90m")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from := time.Date(2026, 10, 14, 10, 7, 30, 500, time.UTC)
+	want := time.Date(2026, 10, 14, 11, 37, 30, 0, time.UTC)
+	if got := s.Next(from); !got.Equal(want) {
+		t.Errorf("Next(%s) = %s, want %s", from, got, want)
+	}
+}
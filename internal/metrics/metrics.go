@@ -0,0 +1,294 @@
+// Package metrics collects the server metrics and writes them in the
+// Prometheus text exposition format, see Write.
+//
+// Only the metric types needed here are implemented: counters, gauges and
+// histograms, all with optional labels. Metrics register themselves on
+// creation, label values are passed in the order of the label names.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ContentType is the content type of the text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var (
+	mutex    sync.Mutex
+	registry []metric
+)
+
+type metric interface {
+	write(w io.Writer)
+}
+
+// Write writes all registered metrics in the Prometheus text exposition format
+func Write(w io.Writer) error {
+	mutex.Lock()
+	metrics := slices.Clone(registry)
+	mutex.Unlock()
+
+	b := bufio.NewWriter(w)
+	for _, m := range metrics {
+		m.write(b)
+	}
+	return b.Flush()
+}
+
+func register(m metric) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	registry = append(registry, m)
+}
+
+// -----------------------------------------------------------------------------
+// Counter
+// -----------------------------------------------------------------------------
+
+// Counter is a value which only goes up, like the number of handled requests
+type Counter struct {
+	desc   *desc
+	values *series[float64]
+}
+
+func NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{
+		desc:   &desc{name: name, help: help, kind: "counter", labels: labels},
+		values: newSeries[float64](nil),
+	}
+	register(c)
+	return c
+}
+
+// Inc adds 1 to the counter for the label values
+func (c *Counter) Inc(labels ...string) {
+	c.Add(1, labels...)
+}
+
+// Add adds v to the counter for the label values, v must not be negative
+func (c *Counter) Add(v float64, labels ...string) {
+	c.desc.check(labels)
+	c.values.update(labels, func(value *float64) { *value += v })
+}
+
+func (c *Counter) write(w io.Writer) {
+	c.desc.writeHeader(w)
+	c.values.each(func(labels []string, value float64) {
+		c.desc.writeSample(w, "", labels, "", value)
+	})
+}
+
+// -----------------------------------------------------------------------------
+// Gauge
+// -----------------------------------------------------------------------------
+
+// Gauge is a value which can go up and down, like the number of active sessions
+type Gauge struct {
+	desc   *desc
+	values *series[float64]
+}
+
+func NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{
+		desc:   &desc{name: name, help: help, kind: "gauge", labels: labels},
+		values: newSeries[float64](nil),
+	}
+	register(g)
+	return g
+}
+
+// Set sets the gauge for the label values
+func (g *Gauge) Set(v float64, labels ...string) {
+	g.desc.check(labels)
+	g.values.update(labels, func(value *float64) { *value = v })
+}
+
+// Reset removes the values of all label values, used before setting gauges
+// for label values which may disappear, like deleted presses
+func (g *Gauge) Reset() {
+	g.values.reset()
+}
+
+func (g *Gauge) write(w io.Writer) {
+	g.desc.writeHeader(w)
+	g.values.each(func(labels []string, value float64) {
+		g.desc.writeSample(w, "", labels, "", value)
+	})
+}
+
+// -----------------------------------------------------------------------------
+// Histogram
+// -----------------------------------------------------------------------------
+
+// Histogram counts observations, like request durations, in buckets
+type Histogram struct {
+	desc    *desc
+	buckets []float64 // buckets are the upper bounds, sorted, without +Inf
+	values  *series[histogramValue]
+}
+
+type histogramValue struct {
+	counts []uint64 // counts per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{
+		desc:    &desc{name: name, help: help, kind: "histogram", labels: labels},
+		buckets: slices.Sorted(slices.Values(buckets)),
+		values:  newSeries(histogramValue.clone),
+	}
+	register(h)
+	return h
+}
+
+func (v histogramValue) clone() histogramValue {
+	v.counts = slices.Clone(v.counts)
+	return v
+}
+
+// Observe adds an observation for the label values
+func (h *Histogram) Observe(v float64, labels ...string) {
+	h.desc.check(labels)
+	h.values.update(labels, func(value *histogramValue) {
+		if value.counts == nil {
+			value.counts = make([]uint64, len(h.buckets))
+		}
+		if i, _ := slices.BinarySearch(h.buckets, v); i < len(h.buckets) {
+			value.counts[i]++
+		}
+		value.count++
+		value.sum += v
+	})
+}
+
+// ObserveSince observes the seconds since start, for use with defer
+func (h *Histogram) ObserveSince(start time.Time, labels ...string) {
+	h.Observe(time.Since(start).Seconds(), labels...)
+}
+
+func (h *Histogram) write(w io.Writer) {
+	h.desc.writeHeader(w)
+	h.values.each(func(labels []string, value histogramValue) {
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += value.counts[i]
+			h.desc.writeSample(w, "_bucket", labels, formatFloat(bound), float64(cumulative))
+		}
+		h.desc.writeSample(w, "_bucket", labels, "+Inf", float64(value.count))
+		h.desc.writeSample(w, "_sum", labels, "", value.sum)
+		h.desc.writeSample(w, "_count", labels, "", float64(value.count))
+	})
+}
+
+// -----------------------------------------------------------------------------
+// Helpers
+// -----------------------------------------------------------------------------
+
+type desc struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+}
+
+func (d *desc) check(labels []string) {
+	if len(labels) != len(d.labels) {
+		panic(fmt.Sprintf("metric %s needs %d label values, got %d", d.name, len(d.labels), len(labels)))
+	}
+}
+
+func (d *desc) writeHeader(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.name, d.help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.name, d.kind)
+}
+
+// writeSample writes a line, le is the histogram bucket label if not empty
+func (d *desc) writeSample(w io.Writer, suffix string, labels []string, le string, value float64) {
+	pairs := make([]string, 0, len(labels)+1)
+	for i, v := range labels {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, d.labels[i], labelEscaper.Replace(v)))
+	}
+	if le != "" {
+		pairs = append(pairs, fmt.Sprintf(`le="%s"`, le))
+	}
+
+	if len(pairs) > 0 {
+		fmt.Fprintf(w, "%s%s{%s} %s\n", d.name, suffix, strings.Join(pairs, ","), formatFloat(value))
+		return
+	}
+	fmt.Fprintf(w, "%s%s %s\n", d.name, suffix, formatFloat(value))
+}
+
+// labelEscaper escapes label values for the text exposition format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// series holds a value per label values combination
+type series[T any] struct {
+	mutex  sync.Mutex
+	values map[string]*seriesEntry[T]
+	clone  func(T) T // clone copies values holding references, nil for plain values
+}
+
+type seriesEntry[T any] struct {
+	labels []string
+	value  T
+}
+
+func newSeries[T any](clone func(T) T) *series[T] {
+	return &series[T]{values: make(map[string]*seriesEntry[T]), clone: clone}
+}
+
+func (s *series[T]) update(labels []string, fn func(value *T)) {
+	key := strings.Join(labels, "\xff")
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	e, ok := s.values[key]
+	if !ok {
+		e = &seriesEntry[T]{labels: slices.Clone(labels)}
+		s.values[key] = e
+	}
+	fn(&e.value)
+}
+
+func (s *series[T]) reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	clear(s.values)
+}
+
+// each calls fn for all label values sorted, with a copy of the value
+func (s *series[T]) each(fn func(labels []string, value T)) {
+	s.mutex.Lock()
+	keys := slices.Sorted(maps.Keys(s.values))
+	entries := make([]seriesEntry[T], 0, len(keys))
+	for _, k := range keys {
+		e := *s.values[k]
+		if s.clone != nil {
+			e.value = s.clone(e.value)
+		}
+		entries = append(entries, e)
+	}
+	s.mutex.Unlock()
+
+	for _, e := range entries {
+		fn(e.labels, e.value)
+	}
+}
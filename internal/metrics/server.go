@@ -0,0 +1,44 @@
+package metrics
+
+// Bucket upper bounds in seconds
+var (
+	HTTPBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	DBBuckets   = []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1}
+	PDFBuckets  = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+)
+
+// Server metrics, updated where the work happens
+var (
+	HTTPRequests = NewCounter("pgpress_http_requests_total",
+		"Handled HTTP requests by route, method and status code.",
+		"route", "method", "code")
+
+	HTTPRequestDuration = NewHistogram("pgpress_http_request_duration_seconds",
+		"HTTP request latencies by route and method.",
+		HTTPBuckets, "route", "method")
+
+	DBQueryDuration = NewHistogram("pgpress_db_query_duration_seconds",
+		"Database query durations by database.",
+		DBBuckets, "database")
+
+	PDFRenderDuration = NewHistogram("pgpress_pdf_render_duration_seconds",
+		"PDF render durations by generator (trouble_report, cycle_summary).",
+		PDFBuckets, "generator")
+)
+
+// Domain gauges, updated on every scrape of the metrics endpoint
+var (
+	ActiveSessions = NewGauge("pgpress_active_sessions",
+		"Session cookies which are not expired.")
+
+	PressCycles = NewGauge("pgpress_press_cycles",
+		"Latest press cycles reading per press.",
+		"press")
+
+	ToolsOverCycles = NewGauge("pgpress_tools_over_cycles",
+		"Active tools over the cycles warning or error threshold.",
+		"level")
+
+	ToolsRegenerating = NewGauge("pgpress_tools_regenerating",
+		"Tools with a regeneration in progress.")
+)
@@ -3,8 +3,11 @@ package pdf
 import (
 	"bytes"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/knackwurstking/pg-press/internal/metrics"
 	"github.com/knackwurstking/pg-press/internal/shared"
 
 	"github.com/jung-kurt/gofpdf/v2"
@@ -12,12 +15,14 @@ import (
 
 // cycleSummaryOptions contains options for cycle summary PDF generation
 type cycleSummaryOptions struct {
-	PDF        *gofpdf.Fpdf
-	Translator func(string) string
-	Press      shared.PressNumber
-	Cycles     []*shared.Cycle
-	ToolsMap   map[shared.EntityID]*shared.Tool
-	UsersMap   map[shared.TelegramID]*shared.User
+	PDF         *gofpdf.Fpdf
+	Translator  func(string) string
+	Press       shared.PressNumber
+	Filter      *shared.CycleSummaryFilter
+	Cycles      []*shared.Cycle
+	ToolsMap    map[shared.EntityID]*shared.Tool
+	UsersMap    map[shared.TelegramID]*shared.User
+	Utilization *shared.PressUtilization
 }
 
 // ToolSummary holds summary information for a tool in the cycle report
@@ -29,33 +34,35 @@ type ToolSummary struct {
 	EndDate           time.Time
 	MaxCycles         int64
 	TotalPartial      int64
+	PerformedBy       shared.TelegramID // PerformedBy is the user of the latest cycle
 	IsFirstAppearance bool
 }
 
 // GenerateCycleSummaryPDF creates a PDF with cycle summary data for a press
-func GenerateCycleSummaryPDF(
-	press shared.PressNumber,
-	cycles []*shared.Cycle,
-	toolsMap map[shared.EntityID]*shared.Tool,
-	usersMap map[shared.TelegramID]*shared.User,
-) (*bytes.Buffer, error) {
+func GenerateCycleSummaryPDF(summary *shared.CycleSummary) (*bytes.Buffer, error) {
+	defer metrics.PDFRenderDuration.ObserveSince(time.Now(), "cycle_summary")
+
 	pdf := gofpdf.New("P", "mm", "A4", "")
 	pdf.SetAutoPageBreak(true, 25)
 	pdf.AddPage()
 	pdf.SetMargins(20, 20, 20)
 
 	o := &cycleSummaryOptions{
-		PDF:        pdf,
-		Translator: pdf.UnicodeTranslatorFromDescriptor(""),
-		Press:      press,
-		Cycles:     cycles,
-		ToolsMap:   toolsMap,
-		UsersMap:   usersMap,
+		PDF:         pdf,
+		Translator:  pdf.UnicodeTranslatorFromDescriptor(""),
+		Press:       summary.Press.Number,
+		Filter:      summary.Filter,
+		Cycles:      summary.Cycles,
+		ToolsMap:    summary.Tools,
+		UsersMap:    summary.Users,
+		Utilization: summary.Utilization,
 	}
 
 	addCycleSummaryHeader(o)
 	addCycleSummaryStats(o)
+	addCycleSummaryMountedTools(o)
 	addCycleSummaryTable(o)
+	addCycleSummarySubtotals(o)
 
 	var buf bytes.Buffer
 	err := pdf.Output(&buf)
@@ -76,7 +83,31 @@ func addCycleSummaryHeader(o *cycleSummaryOptions) {
 	o.PDF.SetFont("Arial", "", 12)
 	o.PDF.SetTextColor(128, 128, 128)
 	o.PDF.Cell(0, 8, o.Translator(fmt.Sprintf("Erstellt am: %s", time.Now().Format("02.01.2006 15:04"))))
-	o.PDF.Ln(15)
+	o.PDF.Ln(6)
+
+	if o.Filter != nil && (o.Filter.From > 0 || o.Filter.To > 0) {
+		from, to := "Anfang", "Heute"
+		if o.Filter.From > 0 {
+			from = o.Filter.From.FormatDate()
+		}
+		if o.Filter.To > 0 {
+			// The to filter is exclusive, show the last included day
+			to = o.Filter.To.ToTime().AddDate(0, 0, -1).Format(shared.DateFormat)
+		}
+		o.PDF.Cell(0, 8, o.Translator(fmt.Sprintf("Zeitraum: %s - %s", from, to)))
+		o.PDF.Ln(6)
+	}
+
+	if o.Filter != nil && len(o.Filter.ToolIDs) > 0 {
+		var codes []string
+		for _, id := range o.Filter.ToolIDs {
+			codes = append(codes, o.toolCode(id))
+		}
+		o.PDF.Cell(0, 8, o.Translator(fmt.Sprintf("Werkzeuge: %s", strings.Join(codes, ", "))))
+		o.PDF.Ln(6)
+	}
+
+	o.PDF.Ln(9)
 
 	o.PDF.SetTextColor(0, 0, 0)
 }
@@ -122,21 +153,16 @@ func addCycleSummaryTable(o *cycleSummaryOptions) {
 
 	// Create a summary for each individual cycle
 	for _, cycle := range o.Cycles {
-		// Create tool code string - handle missing tools gracefully
-		toolCode := fmt.Sprintf("Tool ID %d", cycle.ToolID)
-		if tool, exists := o.ToolsMap[cycle.ToolID]; exists && tool != nil {
-			toolCode = fmt.Sprintf("%s %s", tool.Type, tool.Code)
-		}
-
 		// Convert UnixMilli to time.Time
 		toolSummaries = append(toolSummaries, &ToolSummary{
 			ToolID:            cycle.ToolID,
-			ToolCode:          toolCode,
+			ToolCode:          o.toolCode(cycle.ToolID),
 			Position:          shared.SlotUnknown, // We don't have position information in the cycle data
 			StartDate:         cycle.Start.ToTime(),
 			EndDate:           cycle.Stop.ToTime(),
 			MaxCycles:         cycle.PressCycles,
 			TotalPartial:      cycle.PartialCycles,
+			PerformedBy:       cycle.PerformedBy,
 			IsFirstAppearance: false, // Will be set during consolidation
 		})
 	}
@@ -145,8 +171,8 @@ func addCycleSummaryTable(o *cycleSummaryOptions) {
 	o.PDF.SetFont("Arial", "B", 10)
 	o.PDF.SetFillColor(220, 220, 220)
 
-	colWidths := []float64{40, 22, 28, 28, 22, 30}
-	headers := []string{"Werkzeug", "Position", "Start Datum", "End Datum", "Zyklen", "Teil-Zyklen"}
+	colWidths := []float64{34, 20, 24, 24, 20, 22, 26}
+	headers := []string{"Werkzeug", "Position", "Start Datum", "End Datum", "Zyklen", "Teil-Zyklen", "Benutzer"}
 
 	for i, header := range headers {
 		o.PDF.CellFormat(colWidths[i], 8, o.Translator(header), "1", 0, "C", true, 0, "")
@@ -187,6 +213,7 @@ func addCycleSummaryTable(o *cycleSummaryOptions) {
 			}
 			if summary.EndDate.After(existingSummary.EndDate) {
 				existingSummary.EndDate = summary.EndDate
+				existingSummary.PerformedBy = summary.PerformedBy
 			}
 
 			// Take highest total cycles
@@ -206,6 +233,7 @@ func addCycleSummaryTable(o *cycleSummaryOptions) {
 				EndDate:           summary.EndDate,
 				MaxCycles:         summary.MaxCycles,
 				TotalPartial:      summary.TotalPartial,
+				PerformedBy:       summary.PerformedBy,
 				IsFirstAppearance: false, // Will be set in second pass
 			}
 
@@ -312,14 +340,187 @@ func addCycleSummaryTable(o *cycleSummaryOptions) {
 		// Total partial cycles
 		o.PDF.CellFormat(colWidths[5], 6, fmt.Sprintf("%d", summary.TotalPartial), "1", 0, "C", fill, 0, "")
 
+		// User who performed the latest cycle
+		o.PDF.CellFormat(colWidths[6], 6, o.Translator(o.userName(summary.PerformedBy)), "1", 0, "C", fill, 0, "")
+
+		o.PDF.Ln(6)
+
+		// Add new page if needed
+		_, y := o.PDF.GetXY()
+		if y > 250 {
+			o.PDF.AddPage()
+		}
+	}
+}
+
+// addCycleSummaryMountedTools adds the tools currently mounted in the press
+func addCycleSummaryMountedTools(o *cycleSummaryOptions) {
+	if o.Utilization == nil {
+		return
+	}
+
+	o.PDF.SetFont("Arial", "B", 14)
+	o.PDF.SetFillColor(240, 248, 255)
+	o.PDF.CellFormat(0, 10, o.Translator("AKTUELL EINGEBAUTE WERKZEUGE"), "1", 1, "L", true, 0, "")
+	o.PDF.Ln(5)
+
+	o.PDF.SetFont("Arial", "B", 10)
+	o.PDF.SetFillColor(220, 220, 220)
+
+	colWidths := []float64{40, 60, 35, 35}
+	headers := []string{"Position", "Werkzeug", "Zyklen", "Zyklen (Presse)"}
+
+	for i, header := range headers {
+		o.PDF.CellFormat(colWidths[i], 8, o.Translator(header), "1", 0, "C", true, 0, "")
+	}
+	o.PDF.Ln(8)
+
+	o.PDF.SetFont("Arial", "", 9)
+
+	mounted := []struct {
+		Position shared.Slot
+		Tool     *shared.Tool
+	}{
+		{shared.SlotUpper, o.Utilization.SlotUpper},
+		{shared.SlotUpperCassette, o.Utilization.SlotUpperCassette},
+		{shared.SlotLower, o.Utilization.SlotLower},
+	}
+
+	for _, m := range mounted {
+		toolCode, cycles := "-", "-"
+		if m.Tool != nil {
+			toolCode = fmt.Sprintf("%s %s", m.Tool.Type, m.Tool.Code)
+			cycles = fmt.Sprintf("%d", m.Tool.Cycles)
+		}
+
+		// Cycles of the latest reading of this tool in this press
+		pressCycles := "-"
+		if m.Tool != nil {
+			for _, cycle := range o.Cycles {
+				if cycle.ToolID == m.Tool.ID {
+					pressCycles = fmt.Sprintf("%d", cycle.PressCycles)
+					break
+				}
+			}
+		}
+
+		o.PDF.CellFormat(colWidths[0], 6, o.Translator(m.Position.German()), "1", 0, "C", false, 0, "")
+		o.PDF.CellFormat(colWidths[1], 6, o.Translator(toolCode), "1", 0, "C", false, 0, "")
+		if m.Tool != nil {
+			setCyclesTextColor(o.PDF, m.Tool)
+		}
+		o.PDF.CellFormat(colWidths[2], 6, cycles, "1", 0, "C", false, 0, "")
+		o.PDF.SetTextColor(0, 0, 0)
+		o.PDF.CellFormat(colWidths[3], 6, pressCycles, "1", 0, "C", false, 0, "")
+		o.PDF.Ln(6)
+	}
+
+	o.PDF.Ln(10)
+}
+
+// setCyclesTextColor colors the tool cycles like the tool page, orange over the
+// warning and red over the error threshold of the tool
+func setCyclesTextColor(pdf *gofpdf.Fpdf, tool *shared.Tool) {
+	switch {
+	case tool.Thresholds.IsError(tool.Cycles):
+		pdf.SetTextColor(220, 38, 38)
+	case tool.Thresholds.IsWarning(tool.Cycles):
+		pdf.SetTextColor(249, 115, 22)
+	default:
+		pdf.SetTextColor(0, 0, 0)
+	}
+}
+
+// addCycleSummarySubtotals adds the partial cycles summed up per tool, with a grand total
+func addCycleSummarySubtotals(o *cycleSummaryOptions) {
+	type subtotal struct {
+		ToolID    shared.EntityID
+		Entries   int
+		Partial   int64
+		MaxCycles int64
+	}
+
+	var subtotals []*subtotal
+	subtotalsByTool := make(map[shared.EntityID]*subtotal)
+	for _, cycle := range o.Cycles {
+		st, ok := subtotalsByTool[cycle.ToolID]
+		if !ok {
+			st = &subtotal{ToolID: cycle.ToolID}
+			subtotalsByTool[cycle.ToolID] = st
+			subtotals = append(subtotals, st)
+		}
+		st.Entries++
+		st.Partial += cycle.PartialCycles
+		if cycle.PressCycles > st.MaxCycles {
+			st.MaxCycles = cycle.PressCycles
+		}
+	}
+
+	sort.Slice(subtotals, func(i, j int) bool {
+		return subtotals[i].Partial > subtotals[j].Partial
+	})
+
+	o.PDF.Ln(10)
+	o.PDF.SetFont("Arial", "B", 14)
+	o.PDF.SetFillColor(240, 248, 255)
+	o.PDF.CellFormat(0, 10, o.Translator("ZWISCHENSUMMEN PRO WERKZEUG"), "1", 1, "L", true, 0, "")
+	o.PDF.Ln(5)
+
+	o.PDF.SetFont("Arial", "B", 10)
+	o.PDF.SetFillColor(220, 220, 220)
+
+	colWidths := []float64{60, 30, 40, 40}
+	headers := []string{"Werkzeug", "Einträge", "Zyklen (max)", "Teil-Zyklen"}
+
+	for i, header := range headers {
+		o.PDF.CellFormat(colWidths[i], 8, o.Translator(header), "1", 0, "C", true, 0, "")
+	}
+	o.PDF.Ln(8)
+
+	o.PDF.SetFont("Arial", "", 9)
+
+	var totalEntries int
+	var totalPartial int64
+	for _, st := range subtotals {
+		o.PDF.CellFormat(colWidths[0], 6, o.Translator(o.toolCode(st.ToolID)), "1", 0, "C", false, 0, "")
+		o.PDF.CellFormat(colWidths[1], 6, fmt.Sprintf("%d", st.Entries), "1", 0, "C", false, 0, "")
+		o.PDF.CellFormat(colWidths[2], 6, fmt.Sprintf("%d", st.MaxCycles), "1", 0, "C", false, 0, "")
+		o.PDF.CellFormat(colWidths[3], 6, fmt.Sprintf("%d", st.Partial), "1", 0, "C", false, 0, "")
 		o.PDF.Ln(6)
 
+		totalEntries += st.Entries
+		totalPartial += st.Partial
+
 		// Add new page if needed
 		_, y := o.PDF.GetXY()
 		if y > 250 {
 			o.PDF.AddPage()
 		}
 	}
+
+	o.PDF.SetFont("Arial", "B", 9)
+	o.PDF.SetFillColor(220, 220, 220)
+	o.PDF.CellFormat(colWidths[0], 6, o.Translator("Gesamt"), "1", 0, "C", true, 0, "")
+	o.PDF.CellFormat(colWidths[1], 6, fmt.Sprintf("%d", totalEntries), "1", 0, "C", true, 0, "")
+	o.PDF.CellFormat(colWidths[2], 6, "", "1", 0, "C", true, 0, "")
+	o.PDF.CellFormat(colWidths[3], 6, fmt.Sprintf("%d", totalPartial), "1", 0, "C", true, 0, "")
+	o.PDF.Ln(6)
+}
+
+// toolCode returns the type and code of a tool, handles missing tools gracefully
+func (o *cycleSummaryOptions) toolCode(id shared.EntityID) string {
+	if tool, exists := o.ToolsMap[id]; exists && tool != nil {
+		return fmt.Sprintf("%s %s", tool.Type, tool.Code)
+	}
+	return fmt.Sprintf("Tool ID %d", id)
+}
+
+// userName returns the name of a user, or an empty string if unknown
+func (o *cycleSummaryOptions) userName(id shared.TelegramID) string {
+	if user, ok := o.UsersMap[id]; ok && user != nil {
+		return user.Name
+	}
+	return ""
 }
 
 // getPositionOrder returns the sort order for a position
@@ -0,0 +1,25 @@
+package pdf
+
+import (
+	"context"
+	"sync"
+)
+
+// renders tracks the running chromedp renders, so a shutdown can wait for them
+var renders sync.WaitGroup
+
+// Wait blocks until all running PDF renders are done, or until ctx is done.
+func Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		renders.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -13,12 +13,15 @@ import (
 
 	"github.com/chromedp/cdproto/page"
 	"github.com/chromedp/chromedp"
+	"github.com/knackwurstking/pg-press/internal/metrics"
 	"github.com/knackwurstking/pg-press/internal/shared"
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/extension"
 )
 
 func GenerateTroubleReportPDF(tr *shared.TroubleReport) (*bytes.Buffer, error) {
+	defer metrics.PDFRenderDuration.ObserveSince(time.Now(), "trouble_report")
+
 	htmlContent, err := generateTroubleReportHTML(tr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate HTML: %w", err)
@@ -291,6 +294,9 @@ func generateImagesHTML(attachments []string) (string, error) {
 }
 
 func generatePDFFromHTML(htmlContent template.HTML) (*bytes.Buffer, error) {
+	renders.Add(1)
+	defer renders.Done()
+
 	tmpDir := os.TempDir()
 	tmpFile := filepath.Join(tmpDir, "trouble-report-pdf.html")
 
@@ -0,0 +1,306 @@
+package shared
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+)
+
+// AuditAction is the kind of change recorded in the audit log
+type AuditAction string
+
+const (
+	AuditActionCreate  AuditAction = "create"
+	AuditActionUpdate  AuditAction = "update"
+	AuditActionDelete  AuditAction = "delete"
+	AuditActionRestore AuditAction = "restore"
+)
+
+func (a AuditAction) IsValid() bool {
+	switch a {
+	case AuditActionCreate, AuditActionUpdate, AuditActionDelete, AuditActionRestore:
+		return true
+	default:
+		return false
+	}
+}
+
+func (a AuditAction) German() string {
+	switch a {
+	case AuditActionCreate:
+		return "Erstellt"
+	case AuditActionUpdate:
+		return "Geändert"
+	case AuditActionDelete:
+		return "Gelöscht"
+	case AuditActionRestore:
+		return "Wiederhergestellt"
+	default:
+		return "Unbekannt"
+	}
+}
+
+// AuditEntityType is the type of the changed entity, used as prefix for the
+// entity reference (e.g., "tool_12")
+type AuditEntityType string
+
+const (
+	AuditEntityTool             AuditEntityType = "tool"
+	AuditEntityMetalSheet       AuditEntityType = "metal_sheet"
+	AuditEntityToolRegeneration AuditEntityType = "tool_regeneration"
+	AuditEntityToolThreshold    AuditEntityType = "tool_threshold"
+	AuditEntityPress            AuditEntityType = "press"
+	AuditEntityCycle            AuditEntityType = "cycle"
+	AuditEntityNote             AuditEntityType = "note"
+	AuditEntityTroubleReport    AuditEntityType = "trouble_report"
+	AuditEntityUser             AuditEntityType = "user"
+)
+
+func (t AuditEntityType) German() string {
+	switch t {
+	case AuditEntityTool:
+		return "Werkzeug"
+	case AuditEntityMetalSheet:
+		return "Blech"
+	case AuditEntityToolRegeneration:
+		return "Regenerierung"
+	case AuditEntityToolThreshold:
+		return "Grenzwert"
+	case AuditEntityPress:
+		return "Presse"
+	case AuditEntityCycle:
+		return "Zyklus"
+	case AuditEntityNote:
+		return "Notiz"
+	case AuditEntityTroubleReport:
+		return "Problembericht"
+	case AuditEntityUser:
+		return "Benutzer"
+	default:
+		return "Unbekannt"
+	}
+}
+
+// AuditRef identifies the entity of an audit entry
+type AuditRef struct {
+	Type   AuditEntityType
+	ID     int64
+	Linked string // Linked is the parent entity, if any (e.g., "tool_12" for a cycle)
+}
+
+// Auditable is implemented by all entities tracked in the audit log
+type Auditable interface {
+	AuditRef() AuditRef
+}
+
+// AuditEntry is a single change of an entity, with the state before and after
+// the change stored as JSON
+type AuditEntry struct {
+	ID         EntityID        `json:"id"`
+	UserID     TelegramID      `json:"user_id"` // UserID is 0 if changed from the command line
+	Action     AuditAction     `json:"action"`
+	EntityType AuditEntityType `json:"entity_type"`
+	EntityID   int64           `json:"entity_id"`
+	Linked     string          `json:"linked,omitempty"`
+	CreatedAt  UnixMilli       `json:"created_at"`
+	Before     string          `json:"before,omitempty"` // Before is empty for created and restored entities
+	After      string          `json:"after,omitempty"`  // After is empty for deleted entities
+}
+
+// NewAuditEntry creates an audit entry for a change of an entity.
+//
+// Pass nil for before if the entity was created or restored from the trash, and
+// nil for after if it was deleted. For updates, before should be a Clone of the
+// entity taken before it got modified. API keys of users never end up in the audit log.
+func NewAuditEntry(userID TelegramID, action AuditAction, before, after Auditable) (*AuditEntry, error) {
+	entity := after
+	if entity == nil {
+		entity = before
+	}
+	if entity == nil {
+		return nil, fmt.Errorf("audit entry without entity")
+	}
+	ref := entity.AuditRef()
+
+	e := &AuditEntry{
+		UserID:     userID,
+		Action:     action,
+		EntityType: ref.Type,
+		EntityID:   ref.ID,
+		Linked:     ref.Linked,
+		CreatedAt:  NewUnixMilli(time.Now()),
+	}
+
+	var err error
+	if e.Before, err = auditJSON(before); err != nil {
+		return nil, err
+	}
+	if e.After, err = auditJSON(after); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func (e *AuditEntry) Validate() *errors.ValidationError {
+	if !e.Action.IsValid() {
+		return errors.NewValidationError("invalid audit action: %s", e.Action)
+	}
+	if e.EntityType == "" {
+		return errors.NewValidationError("audit entity type is required")
+	}
+	if e.CreatedAt == 0 {
+		return errors.NewValidationError("audit timestamp is required")
+	}
+	if e.Before == "" && e.After == "" {
+		return errors.NewValidationError("audit entry needs a before or after state")
+	}
+	return nil
+}
+
+func (e *AuditEntry) Clone() *AuditEntry {
+	return &AuditEntry{
+		ID:         e.ID,
+		UserID:     e.UserID,
+		Action:     e.Action,
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID,
+		Linked:     e.Linked,
+		CreatedAt:  e.CreatedAt,
+		Before:     e.Before,
+		After:      e.After,
+	}
+}
+
+func (e *AuditEntry) String() string {
+	return fmt.Sprintf(
+		"AuditEntry{ID:%s, UserID:%s, Action:%s, Entity:%s, CreatedAt:%s}",
+		e.ID.String(),
+		e.UserID.String(),
+		e.Action,
+		e.Entity(),
+		e.CreatedAt.FormatDateTime(),
+	)
+}
+
+// Entity returns the reference of the changed entity, e.g. "tool_12"
+func (e *AuditEntry) Entity() string {
+	return fmt.Sprintf("%s_%d", e.EntityType, e.EntityID)
+}
+
+// AuditChange is a single changed field of an audit entry
+type AuditChange struct {
+	Field  string
+	Before string // Before is empty if the field was added
+	After  string // After is empty if the field was removed
+}
+
+// Changes returns all fields which differ between the before and after state,
+// sorted by field name. Created entities list all fields as added, deleted
+// entities all fields as removed.
+func (e *AuditEntry) Changes() []AuditChange {
+	before := auditFields(e.Before)
+	after := auditFields(e.After)
+
+	var fields []string
+	for field := range before {
+		fields = append(fields, field)
+	}
+	for field := range after {
+		if _, ok := before[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	slices.Sort(fields)
+
+	var changes []AuditChange
+	for _, field := range fields {
+		if before[field] == after[field] {
+			continue
+		}
+		changes = append(changes, AuditChange{
+			Field:  field,
+			Before: before[field],
+			After:  after[field],
+		})
+	}
+	return changes
+}
+
+// auditJSON marshals an entity for the audit log
+func auditJSON(entity Auditable) (string, error) {
+	if entity == nil {
+		return "", nil
+	}
+
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return "", fmt.Errorf("marshal %s for the audit log: %v", entity.AuditRef().Type, err)
+	}
+	return string(data), nil
+}
+
+// auditFields returns the top level fields of a JSON object, with the values
+// in their JSON representation
+func auditFields(data string) map[string]string {
+	fields := map[string]string{}
+	if data == "" {
+		return fields
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(data), &raw); err != nil {
+		return fields
+	}
+	for field, value := range raw {
+		fields[field] = string(value)
+	}
+	return fields
+}
+
+// -----------------------------------------------------------------------------
+// Auditable Entities
+// -----------------------------------------------------------------------------
+
+func (t *Tool) AuditRef() AuditRef {
+	return AuditRef{Type: AuditEntityTool, ID: int64(t.ID)}
+}
+
+func (u *UpperMetalSheet) AuditRef() AuditRef {
+	return AuditRef{Type: AuditEntityMetalSheet, ID: int64(u.ID), Linked: fmt.Sprintf("tool_%d", u.ToolID)}
+}
+
+func (l *LowerMetalSheet) AuditRef() AuditRef {
+	return AuditRef{Type: AuditEntityMetalSheet, ID: int64(l.ID), Linked: fmt.Sprintf("tool_%d", l.ToolID)}
+}
+
+func (tr *ToolRegeneration) AuditRef() AuditRef {
+	return AuditRef{Type: AuditEntityToolRegeneration, ID: int64(tr.ID), Linked: fmt.Sprintf("tool_%d", tr.ToolID)}
+}
+
+func (tt *ToolThreshold) AuditRef() AuditRef {
+	return AuditRef{Type: AuditEntityToolThreshold, ID: int64(tt.ID)}
+}
+
+func (p *Press) AuditRef() AuditRef {
+	return AuditRef{Type: AuditEntityPress, ID: int64(p.ID)}
+}
+
+func (c *Cycle) AuditRef() AuditRef {
+	return AuditRef{Type: AuditEntityCycle, ID: int64(c.ID), Linked: fmt.Sprintf("tool_%d", c.ToolID)}
+}
+
+func (n *Note) AuditRef() AuditRef {
+	return AuditRef{Type: AuditEntityNote, ID: int64(n.ID), Linked: n.Linked}
+}
+
+func (tr *TroubleReport) AuditRef() AuditRef {
+	return AuditRef{Type: AuditEntityTroubleReport, ID: int64(tr.ID)}
+}
+
+func (u *User) AuditRef() AuditRef {
+	return AuditRef{Type: AuditEntityUser, ID: int64(u.ID)}
+}
@@ -0,0 +1,69 @@
+package shared
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestCloneCopiesAllFields fills every field of an entity with a non-zero value,
+// the clones are used as "before" snapshots for the audit log, a missing field
+// shows up as a change.
+func TestCloneCopiesAllFields(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		clone func(entity any) any
+		new   func() any
+	}{
+		{"Press", func(e any) any { return e.(*Press).Clone() }, func() any { return &Press{} }},
+		{"Tool", func(e any) any { return e.(*Tool).Clone() }, func() any { return &Tool{} }},
+		{"Cycle", func(e any) any { return e.(*Cycle).Clone() }, func() any { return &Cycle{} }},
+		{"Note", func(e any) any { return e.(*Note).Clone() }, func() any { return &Note{} }},
+		{"User", func(e any) any { return e.(*User).Clone() }, func() any { return &User{} }},
+		{"ToolRegeneration", func(e any) any { return e.(*ToolRegeneration).Clone() }, func() any { return &ToolRegeneration{} }},
+		{"ToolThreshold", func(e any) any { return e.(*ToolThreshold).Clone() }, func() any { return &ToolThreshold{} }},
+		{"TroubleReport", func(e any) any { return e.(*TroubleReport).Clone() }, func() any { return &TroubleReport{} }},
+		{"UpperMetalSheet", func(e any) any { return e.(*UpperMetalSheet).Clone() }, func() any { return &UpperMetalSheet{} }},
+		{"LowerMetalSheet", func(e any) any { return e.(*LowerMetalSheet).Clone() }, func() any { return &LowerMetalSheet{} }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			entity := tc.new()
+			fill(t, reflect.ValueOf(entity).Elem())
+
+			clone := tc.clone(entity)
+			if !reflect.DeepEqual(clone, entity) {
+				t.Errorf("clone differs:\n got: %+v\nwant: %+v", clone, entity)
+			}
+		})
+	}
+}
+
+// fill sets all fields of a struct to a non-zero value
+func fill(t *testing.T, v reflect.Value) {
+	t.Helper()
+
+	for i := range v.NumField() {
+		f := v.Field(i)
+		switch f.Kind() {
+		case reflect.Bool:
+			f.SetBool(true)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f.SetInt(int64(i + 1))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			f.SetUint(uint64(i + 1))
+		case reflect.Float32, reflect.Float64:
+			f.SetFloat(float64(i) + 0.5)
+		case reflect.String:
+			f.SetString(v.Type().Field(i).Name)
+		case reflect.Slice:
+			s := reflect.MakeSlice(f.Type(), 1, 1)
+			if s.Index(0).Kind() == reflect.String {
+				s.Index(0).SetString(v.Type().Field(i).Name)
+			}
+			f.Set(s)
+		case reflect.Struct:
+			fill(t, f)
+		default:
+			t.Fatalf("can not fill field %s of kind %s", v.Type().Field(i).Name, f.Kind())
+		}
+	}
+}
@@ -7,48 +7,51 @@ import (
 )
 
 type Cycle struct {
-	ID            EntityID  `json:"id"`             // ID is the unique identifier for the Cycle entity
-	ToolID        EntityID  `json:"tool_id"`        // ToolID is the identifier for the associated Tool entity
-	PressID       EntityID  `json:"press_id"`       // PressID is the identifier for the associated Press entity
-	PressCycles   int64     `json:"cycles"`         // PressCycles is the number of cycles completed during this time period
-	PartialCycles int64     `json:"partial_cycles"` // PartialCycles are the completed cycles during this time period (calculated)
-	Start         UnixMilli `json:"start"`          // Start timestamp in milliseconds (injected)
-	Stop          UnixMilli `json:"stop"`           // Stop timestamp in milliseconds, should be the date were the press cycles got read
+	ID            EntityID   `json:"id"`             // ID is the unique identifier for the Cycle entity
+	ToolID        EntityID   `json:"tool_id"`        // ToolID is the identifier for the associated Tool entity
+	PressID       EntityID   `json:"press_id"`       // PressID is the identifier for the associated Press entity
+	PressCycles   int64      `json:"cycles"`         // PressCycles is the number of cycles completed during this time period
+	PartialCycles int64      `json:"partial_cycles"` // PartialCycles are the completed cycles during this time period (calculated)
+	Start         UnixMilli  `json:"start"`          // Start timestamp in milliseconds (injected)
+	Stop          UnixMilli  `json:"stop"`           // Stop timestamp in milliseconds, should be the date were the press cycles got read
+	PerformedBy   TelegramID `json:"performed_by"`   // PerformedBy is the user who read the press cycles, 0 if unknown
 }
 
-func NewCycle(toolID EntityID, pressID EntityID, pressCycles int64, stop UnixMilli) *Cycle {
+func NewCycle(toolID EntityID, pressID EntityID, pressCycles int64, stop UnixMilli, performedBy TelegramID) *Cycle {
 	return &Cycle{
 		ToolID:      toolID,
 		PressID:     pressID,
 		PressCycles: pressCycles,
 		Stop:        stop,
+		PerformedBy: performedBy,
 	}
 }
 
 func (c *Cycle) Validate() *errors.ValidationError {
+	var ierrs errors.InputErrors
+
 	if c.PressID <= 0 {
-		return errors.NewValidationError("press ID must be specified")
+		ierrs.Add("press_id", "press ID must be specified")
 	}
 
 	if c.PressCycles < 0 {
-		return errors.NewValidationError("number of cycles must be 0 or greater")
+		ierrs.Add("cycles", "number of cycles must be 0 or greater")
 	}
 
 	if c.Start < 0 {
-		return errors.NewValidationError("start timestamp must be a positive number")
+		ierrs.Add("start", "start timestamp must be a positive number")
 	}
 	if c.Stop < 0 {
-		return errors.NewValidationError("stop timestamp must be a positive number")
-	}
-	if c.Stop < c.Start {
-		return errors.NewValidationError("stop date must be after or equal to start date")
+		ierrs.Add("stop", "stop timestamp must be a positive number")
+	} else if c.Stop < c.Start {
+		ierrs.Add("stop", "stop date must be after or equal to start date")
 	}
 
 	if c.ToolID <= 0 {
-		return errors.NewValidationError("tool ID must be specified")
+		ierrs.Add("tool_id", "tool ID must be specified")
 	}
 
-	return nil
+	return ierrs.ValidationError()
 }
 
 func (c *Cycle) Clone() *Cycle {
@@ -60,12 +63,13 @@ func (c *Cycle) Clone() *Cycle {
 		PartialCycles: c.PartialCycles,
 		Start:         c.Start,
 		Stop:          c.Stop,
+		PerformedBy:   c.PerformedBy,
 	}
 }
 
 func (c *Cycle) String() string {
 	return fmt.Sprintf(
-		"Cycle{ID:%d, ToolID:%d, PressID:%d, PressCycles:%d, PartialCycles:%d, Start:%d, Stop:%d}",
-		c.ID, c.ToolID, c.PressID, c.PressCycles, c.PartialCycles, c.Start, c.Stop,
+		"Cycle{ID:%d, ToolID:%d, PressID:%d, PressCycles:%d, PartialCycles:%d, Start:%d, Stop:%d, PerformedBy:%d}",
+		c.ID, c.ToolID, c.PressID, c.PressCycles, c.PartialCycles, c.Start, c.Stop, c.PerformedBy,
 	)
 }
@@ -0,0 +1,115 @@
+package shared
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+)
+
+// FeedKind is the kind of event shown in the activity feed
+type FeedKind string
+
+const (
+	FeedKindCycle               FeedKind = "cycle"
+	FeedKindToolChange          FeedKind = "tool_change"
+	FeedKindTool                FeedKind = "tool"
+	FeedKindRegenerationStarted FeedKind = "regeneration_started"
+	FeedKindRegenerationStopped FeedKind = "regeneration_stopped"
+	FeedKindNote                FeedKind = "note"
+	FeedKindTroubleReport       FeedKind = "trouble_report"
+)
+
+func (k FeedKind) IsValid() bool {
+	switch k {
+	case FeedKindCycle, FeedKindToolChange, FeedKindTool, FeedKindRegenerationStarted,
+		FeedKindRegenerationStopped, FeedKindNote, FeedKindTroubleReport:
+		return true
+	default:
+		return false
+	}
+}
+
+func (k FeedKind) German() string {
+	switch k {
+	case FeedKindCycle:
+		return "Zyklus"
+	case FeedKindToolChange:
+		return "Werkzeugwechsel"
+	case FeedKindTool:
+		return "Werkzeug"
+	case FeedKindRegenerationStarted:
+		return "Regenerierung gestartet"
+	case FeedKindRegenerationStopped:
+		return "Regenerierung beendet"
+	case FeedKindNote:
+		return "Notiz"
+	case FeedKindTroubleReport:
+		return "Problembericht"
+	default:
+		return "Unbekannt"
+	}
+}
+
+// Feed is a single event in the activity feed
+type Feed struct {
+	ID        EntityID   `json:"id"`
+	UserID    TelegramID `json:"user_id"` // UserID is 0 if triggered from the command line
+	Kind      FeedKind   `json:"kind"`
+	Title     string     `json:"title"`
+	Content   string     `json:"content"`
+	PressID   EntityID   `json:"press_id"` // PressID is the related press, 0 if none
+	ToolID    EntityID   `json:"tool_id"`  // ToolID is the related tool, 0 if none
+	CreatedAt UnixMilli  `json:"created_at"`
+}
+
+func NewFeed(userID TelegramID, kind FeedKind, title, content string) *Feed {
+	return &Feed{
+		UserID:    userID,
+		Kind:      kind,
+		Title:     title,
+		Content:   content,
+		CreatedAt: NewUnixMilli(time.Now()),
+	}
+}
+
+func (f *Feed) Validate() *errors.ValidationError {
+	if !f.Kind.IsValid() {
+		return errors.NewValidationError("invalid feed kind: %s", f.Kind)
+	}
+	if f.Title == "" {
+		return errors.NewValidationError("feed title is required")
+	}
+	if f.CreatedAt == 0 {
+		return errors.NewValidationError("feed timestamp is required")
+	}
+	return nil
+}
+
+func (f *Feed) Clone() *Feed {
+	return &Feed{
+		ID:        f.ID,
+		UserID:    f.UserID,
+		Kind:      f.Kind,
+		Title:     f.Title,
+		Content:   f.Content,
+		PressID:   f.PressID,
+		ToolID:    f.ToolID,
+		CreatedAt: f.CreatedAt,
+	}
+}
+
+func (f *Feed) String() string {
+	return fmt.Sprintf(
+		"Feed{ID:%d, UserID:%d, Kind:%s, Title:%s, PressID:%d, ToolID:%d, CreatedAt:%s}",
+		f.ID, f.UserID, f.Kind, f.Title, f.PressID, f.ToolID, f.CreatedAt.FormatDateTime(),
+	)
+}
+
+// FeedFilter selects a page of the activity feed
+type FeedFilter struct {
+	PressID EntityID // PressID limits the feed to events of this press, 0 for all
+	ToolID  EntityID // ToolID limits the feed to events of this tool, 0 for all
+	Offset  int
+	Limit   int // Limit is the page size, 0 for no limit
+}
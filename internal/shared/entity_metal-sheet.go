@@ -32,20 +32,22 @@ type UpperMetalSheet struct {
 
 // Validate checks if the lower metal sheet has valid data
 func (u *LowerMetalSheet) Validate() *errors.ValidationError {
+	var ierrs errors.InputErrors
+
 	if u.MarkeHeight <= 0 {
-		return errors.NewValidationError("marke height must be a positive number, got %d", u.MarkeHeight)
+		ierrs.Add("marke_height", "marke height must be a positive number, got %d", u.MarkeHeight)
 	}
 	if u.STF <= 0 {
-		return errors.NewValidationError("STF value must be a positive number, got %.2f", u.STF)
+		ierrs.Add("stf", "STF value must be a positive number, got %.2f", u.STF)
 	}
 	if u.STFMax <= 0 {
-		return errors.NewValidationError("STF max value must be a positive number, got %.2f", u.STFMax)
+		ierrs.Add("stf_max", "STF max value must be a positive number, got %.2f", u.STFMax)
 	}
 	if u.Identifier != MachineTypeSACMI && u.Identifier != MachineTypeSITI {
-		return errors.NewValidationError("identifier must be either 'SACMI' or 'SITI', got '%s'", u.Identifier)
+		ierrs.Add("identifier", "identifier must be either 'SACMI' or 'SITI', got '%s'", u.Identifier)
 	}
 
-	return nil
+	return ierrs.ValidationError()
 }
 
 // Clone creates a copy of the lower metal sheet
@@ -32,16 +32,19 @@ type Note struct {
 
 // Validate checks if the note has valid data
 func (n *Note) Validate() *errors.ValidationError {
+	var ierrs errors.InputErrors
+
 	if n.CreatedAt == 0 {
-		return errors.NewValidationError("note creation timestamp is required")
+		ierrs.Add("created_at", "note creation timestamp is required")
 	}
 	if !n.Level.IsValid() {
-		return errors.NewValidationError("note level must be one of: 0 (Normal), 1 (Info), 2 (Attention), or 3 (Broken)")
+		ierrs.Add("level", "note level must be one of: 0 (Normal), 1 (Info), 2 (Attention), or 3 (Broken)")
 	}
 	if n.Content == "" {
-		return errors.NewValidationError("note content cannot be empty")
+		ierrs.Add("content", "note content cannot be empty")
 	}
-	return nil
+
+	return ierrs.ValidationError()
 }
 
 // Clone creates a copy of the note
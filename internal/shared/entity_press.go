@@ -44,11 +44,13 @@ type Press struct {
 // Returns:
 //   - *errors.ValidationError: Validation error if type is invalid, nil otherwise
 func (p *Press) Validate() *errors.ValidationError {
+	var ierrs errors.InputErrors
+
 	if !slices.Contains([]MachineType{MachineTypeSACMI, MachineTypeSITI}, p.Type) {
-		return errors.NewValidationError("press type must be either 'SACMI' or 'SITI'")
+		ierrs.Add("type", "press type must be either 'SACMI' or 'SITI'")
 	}
 
-	return nil
+	return ierrs.ValidationError()
 }
 
 // Clone creates a copy of the Press struct.
@@ -58,6 +60,7 @@ func (p *Press) Validate() *errors.ValidationError {
 func (p *Press) Clone() *Press {
 	return &Press{
 		ID:           p.ID,
+		Number:       p.Number,
 		Type:         p.Type,
 		Code:         p.Code,
 		SlotUp:       p.SlotUp,
@@ -75,8 +78,8 @@ func (p *Press) Clone() *Press {
 //   - string: Formatted string showing all Press fields
 func (p *Press) String() string {
 	return fmt.Sprintf(
-		"Press{ID:%d, Type:%s, Code:%s, SlotUp:%d, SlotDown:%d, CyclesOffset:%d}",
-		p.ID, p.Type, p.Code, p.SlotUp, p.SlotDown, p.CyclesOffset,
+		"Press{ID:%d, Number:%d, Type:%s, Code:%s, SlotUp:%d, SlotDown:%d, CyclesOffset:%d}",
+		p.ID, p.Number, p.Type, p.Code, p.SlotUp, p.SlotDown, p.CyclesOffset,
 	)
 }
 
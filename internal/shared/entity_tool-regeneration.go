@@ -15,17 +15,20 @@ type ToolRegeneration struct {
 }
 
 func (tr *ToolRegeneration) Validate() *errors.ValidationError {
+	var ierrs errors.InputErrors
+
 	if tr.ToolID < 0 {
-		return errors.NewValidationError("tool ID must be a positive number")
+		ierrs.Add("tool_id", "tool ID must be a positive number")
 	}
 
 	if tr.Start < 0 {
-		return errors.NewValidationError("start timestamp must be a positive number")
+		ierrs.Add("start", "start timestamp must be a positive number")
 	}
 	if tr.Stop > 0 && tr.Stop < tr.Start {
-		return errors.NewValidationError("stop date must be after or equal to start date")
+		ierrs.Add("stop", "stop date must be after or equal to start date")
 	}
-	return nil
+
+	return ierrs.ValidationError()
 }
 
 func (tr *ToolRegeneration) Clone() *ToolRegeneration {
@@ -0,0 +1,200 @@
+package shared
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+)
+
+// ToolThreshold defines the cycle thresholds for all tools of a type, optionally
+// limited to a format (width x height) and a position, see ResolveCycleThresholds
+type ToolThreshold struct {
+	ID       EntityID `json:"id"`
+	Type     string   `json:"type"`     // Type is the tool type, e.g. "MASS", "FC", "GTC"
+	Width    int      `json:"width"`    // Width of the format, 0 for all formats
+	Height   int      `json:"height"`   // Height of the format, 0 for all formats
+	Position Slot     `json:"position"` // Position of the tools, 0 for all positions
+	Warning  int64    `json:"warning"`  // Warning is the cycles warning threshold
+	Error    int64    `json:"error"`    // Error is the cycles error threshold
+}
+
+func (tt *ToolThreshold) Validate() *errors.ValidationError {
+	var ierrs errors.InputErrors
+
+	if tt.Type == "" {
+		ierrs.Add("type", "tool type is required")
+	}
+
+	// The format is optional, but needs both width and height
+	if tt.Width < 0 || tt.Height < 0 || (tt.Width == 0) != (tt.Height == 0) {
+		ierrs.Add("width", "format needs a positive width and height, or none of both")
+	}
+
+	switch tt.Position {
+	case SlotUnknown, SlotUpper, SlotLower, SlotUpperCassette:
+	default:
+		ierrs.Add("position", "position must be 0 (all), 1 (upper), 2 (lower) or 3 (upper cassette)")
+	}
+
+	if tt.Warning <= 0 {
+		ierrs.Add("warning", "warning threshold must be greater than zero, got %d", tt.Warning)
+	}
+	if tt.Error <= tt.Warning {
+		ierrs.Add("error", "error threshold %d must be greater than the warning threshold %d", tt.Error, tt.Warning)
+	}
+
+	return ierrs.ValidationError()
+}
+
+func (tt *ToolThreshold) Clone() *ToolThreshold {
+	return &ToolThreshold{
+		ID:       tt.ID,
+		Type:     tt.Type,
+		Width:    tt.Width,
+		Height:   tt.Height,
+		Position: tt.Position,
+		Warning:  tt.Warning,
+		Error:    tt.Error,
+	}
+}
+
+func (tt *ToolThreshold) String() string {
+	return fmt.Sprintf(
+		"ToolThreshold{ID:%d, Type:%s, Width:%d, Height:%d, Position:%d, Warning:%d, Error:%d}",
+		tt.ID, tt.Type, tt.Width, tt.Height, tt.Position, tt.Warning, tt.Error,
+	)
+}
+
+// German returns the tools this threshold applies to, e.g. "MASS 100x100 Oberteil"
+func (tt *ToolThreshold) German() string {
+	parts := []string{tt.Type}
+	if tt.HasFormat() {
+		parts = append(parts, fmt.Sprintf("%dx%d", tt.Width, tt.Height))
+	}
+	if tt.Position != SlotUnknown {
+		parts = append(parts, tt.Position.German())
+	}
+	return strings.Join(parts, " ")
+}
+
+// HasFormat returns true if the threshold is limited to a format
+func (tt *ToolThreshold) HasFormat() bool {
+	return tt.Width > 0 && tt.Height > 0
+}
+
+// Matches returns true if the threshold applies to the tool, the type is
+// compared case insensitive
+func (tt *ToolThreshold) Matches(tool *Tool) bool {
+	if !strings.EqualFold(tt.Type, tool.Type) {
+		return false
+	}
+	if tt.HasFormat() && (tt.Width != tool.Width || tt.Height != tool.Height) {
+		return false
+	}
+	return tt.Position == SlotUnknown || tt.Position == tool.Position
+}
+
+// specificity ranks matching thresholds, the format counts more than the position
+func (tt *ToolThreshold) specificity() int {
+	var n int
+	if tt.HasFormat() {
+		n += 2
+	}
+	if tt.Position != SlotUnknown {
+		n++
+	}
+	return n
+}
+
+// CycleThresholds are the resolved cycle thresholds of a tool
+type CycleThresholds struct {
+	Warning int64 `json:"warning"`
+	Error   int64 `json:"error"`
+}
+
+// DefaultCycleThresholds apply to tools without a matching ToolThreshold
+var DefaultCycleThresholds = CycleThresholds{
+	Warning: ToolCyclesWarning,
+	Error:   ToolCyclesError,
+}
+
+// ResolveCycleThresholds returns the thresholds of the most specific matching
+// ToolThreshold, or DefaultCycleThresholds if none matches. The overrides of the
+// tool (CyclesWarning, CyclesError) replace the resolved values.
+//
+// A single override may cross the other, inherited threshold, e.g. after the
+// thresholds table changed, see ValidateCycleThresholds. The inherited threshold
+// keeps its ratio to the override then, so the warning stays below the error.
+func ResolveCycleThresholds(tool *Tool, thresholds []*ToolThreshold) CycleThresholds {
+	base := matchCycleThresholds(tool, thresholds)
+	ct := base.override(tool)
+
+	if ct.Error <= ct.Warning {
+		if tool.CyclesWarning > 0 {
+			ct.Error = ct.Warning * base.Error / base.Warning
+		} else {
+			ct.Warning = ct.Error * base.Warning / base.Error
+		}
+	}
+
+	return ct
+}
+
+// ValidateCycleThresholds checks a single override of the tool against the
+// other threshold it inherits, both overrides are checked by Tool.Validate
+func ValidateCycleThresholds(tool *Tool, thresholds []*ToolThreshold) *errors.ValidationError {
+	base := matchCycleThresholds(tool, thresholds)
+	ct := base.override(tool)
+	if ct.Error > ct.Warning {
+		return nil
+	}
+
+	var ierrs errors.InputErrors
+	switch {
+	case tool.CyclesWarning > 0 && tool.CyclesError == 0:
+		ierrs.Add("cycles_warning", "cycles warning threshold %d must be lower than the error threshold %d of the tool type",
+			tool.CyclesWarning, base.Error)
+	case tool.CyclesError > 0 && tool.CyclesWarning == 0:
+		ierrs.Add("cycles_error", "cycles error threshold %d must be greater than the warning threshold %d of the tool type",
+			tool.CyclesError, base.Warning)
+	}
+	return ierrs.ValidationError()
+}
+
+// matchCycleThresholds returns the thresholds of the most specific matching
+// ToolThreshold, or DefaultCycleThresholds if none matches
+func matchCycleThresholds(tool *Tool, thresholds []*ToolThreshold) CycleThresholds {
+	var match *ToolThreshold
+	for _, tt := range thresholds {
+		if tt.Matches(tool) && (match == nil || tt.specificity() > match.specificity()) {
+			match = tt
+		}
+	}
+	if match == nil {
+		return DefaultCycleThresholds
+	}
+	return CycleThresholds{Warning: match.Warning, Error: match.Error}
+}
+
+// override returns the thresholds with the overrides of the tool
+func (ct CycleThresholds) override(tool *Tool) CycleThresholds {
+	if tool.CyclesWarning > 0 {
+		ct.Warning = tool.CyclesWarning
+	}
+	if tool.CyclesError > 0 {
+		ct.Error = tool.CyclesError
+	}
+	return ct
+}
+
+// IsWarning returns true if the cycles are over the warning, but not over the
+// error threshold
+func (ct CycleThresholds) IsWarning(cycles int64) bool {
+	return cycles > ct.Warning && !ct.IsError(cycles)
+}
+
+// IsError returns true if the cycles are over the error threshold
+func (ct CycleThresholds) IsError(cycles int64) bool {
+	return cycles > ct.Error
+}
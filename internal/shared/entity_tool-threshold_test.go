@@ -0,0 +1,36 @@
+package shared
+
+import "testing"
+
+// TestResolveCycleThresholdsKeepsWarningBelowError overrides a single threshold
+// of a tool, the other one is inherited from the table and must not be crossed.
+func TestResolveCycleThresholdsKeepsWarningBelowError(t *testing.T) {
+	thresholds := []*ToolThreshold{{Type: "MASS", Warning: 800000, Error: 1000000}}
+
+	for _, tc := range []struct {
+		name           string
+		warning, error int64
+		want           CycleThresholds
+		invalid        bool
+	}{
+		{name: "table", want: CycleThresholds{Warning: 800000, Error: 1000000}},
+		{name: "both overrides", warning: 1200000, error: 1500000, want: CycleThresholds{Warning: 1200000, Error: 1500000}},
+		{name: "warning below the table error", warning: 900000, want: CycleThresholds{Warning: 900000, Error: 1000000}},
+		{name: "warning over the table error", warning: 1200000, want: CycleThresholds{Warning: 1200000, Error: 1500000}, invalid: true},
+		{name: "error below the table warning", error: 400000, want: CycleThresholds{Warning: 320000, Error: 400000}, invalid: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tool := &Tool{Type: "MASS", Width: 120, Height: 60, Position: SlotUpper,
+				CyclesWarning: tc.warning, CyclesError: tc.error}
+
+			if got := ResolveCycleThresholds(tool, thresholds); got != tc.want {
+				t.Errorf("thresholds = %+v, want %+v", got, tc.want)
+			}
+
+			verr := ValidateCycleThresholds(tool, thresholds)
+			if (verr != nil) != tc.invalid {
+				t.Errorf("validation error = %v, want invalid %t", verr, tc.invalid)
+			}
+		})
+	}
+}
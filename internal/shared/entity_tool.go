@@ -7,10 +7,12 @@ import (
 )
 
 const (
-	// ToolCyclesWarning is the threshold at which tools start showing warnings for high cycle counts
+	// ToolCyclesWarning is the default threshold at which tools start showing warnings for
+	// high cycle counts, see ToolThreshold
 	ToolCyclesWarning int64 = 800000
 
-	// ToolCyclesError is the threshold at which tools start showing errors for high cycle counts
+	// ToolCyclesError is the default threshold at which tools start showing errors for high
+	// cycle counts, see ToolThreshold
 	ToolCyclesError int64 = 1000000
 )
 
@@ -32,6 +34,10 @@ type Tool struct {
 	Cassette     EntityID `json:"cassette"`      // Cassette indicates the cassette ID this tool belongs to (if any)
 	MinThickness float32  `json:"min_thickness"`
 	MaxThickness float32  `json:"max_thickness"`
+
+	CyclesWarning int64           `json:"cycles_warning"` // CyclesWarning overrides the warning threshold of this tool, 0 for none
+	CyclesError   int64           `json:"cycles_error"`   // CyclesError overrides the error threshold of this tool, 0 for none
+	Thresholds    CycleThresholds `json:"thresholds"`     // Thresholds are the resolved cycle thresholds, see ResolveCycleThresholds [injected]
 }
 
 func (t *Tool) IsTrackable() bool {
@@ -88,13 +94,18 @@ func (t *Tool) Clone() *Tool {
 		Cassette:     t.Cassette,
 		MinThickness: t.MinThickness,
 		MaxThickness: t.MaxThickness,
+
+		CyclesWarning: t.CyclesWarning,
+		CyclesError:   t.CyclesError,
+		Thresholds:    t.Thresholds,
 	}
 }
 
 func (t *Tool) String() string {
 	return fmt.Sprintf(
 		"Tool{ID:%d, Width:%d, Height:%d, Position:%d, Type:%s, Code:%s, "+
-			"CyclesOffset:%d, Cycles:%d, IsDead:%t, Cassette:%d, MinThickness:%.1f, MaxThickness:%.1f}",
+			"CyclesOffset:%d, Cycles:%d, IsDead:%t, Cassette:%d, MinThickness:%.1f, MaxThickness:%.1f, "+
+			"CyclesWarning:%d, CyclesError:%d}",
 		t.ID,
 		t.Width,
 		t.Height,
@@ -107,46 +118,62 @@ func (t *Tool) String() string {
 		t.Cassette,
 		t.MinThickness,
 		t.MaxThickness,
+		t.CyclesWarning,
+		t.CyclesError,
 	)
 }
 
 // Validate checks if the tool data is valid
 func (t *Tool) Validate() *errors.ValidationError {
+	var ierrs errors.InputErrors
+
 	// Validate position to be upper/lower or cassette
 	switch t.Position {
 	case SlotUpper, SlotLower, SlotUpperCassette:
 	default:
-		return errors.NewValidationError("tool position must be either 1 (upper), 2 (lower), or 3 (upper cassette)")
+		ierrs.Add("position", "tool position must be either 1 (upper), 2 (lower), or 3 (upper cassette)")
 	}
 
 	// Width and Height must be positive, zero is allowed for reason of special (placeholder) tools
 	if t.Width < 0 {
-		return errors.NewValidationError("tool width must be a positive number, got %d", t.Width)
+		ierrs.Add("width", "tool width must be a positive number, got %d", t.Width)
 	}
 	if t.Height < 0 {
-		return errors.NewValidationError("tool height must be a positive number, got %d", t.Height)
+		ierrs.Add("height", "tool height must be a positive number, got %d", t.Height)
 	}
 
 	// Type and Code must be set
 	if t.Type == "" {
-		return errors.NewValidationError("tool type is required")
+		ierrs.Add("type", "tool type is required")
 	}
 
 	// For cassettes, MinThickness must be less than MaxThickness
 	if t.IsCassette() {
 		if t.MinThickness < 0 {
-			return errors.NewValidationError("cassette minimum thickness must be positive, got %.1f", t.MinThickness)
+			ierrs.Add("min_thickness", "cassette minimum thickness must be positive, got %.1f", t.MinThickness)
 		}
 		if t.MaxThickness <= 0 {
-			return errors.NewValidationError("cassette maximum thickness must be positive, got %.1f", t.MaxThickness)
-		}
-		if t.MinThickness >= t.MaxThickness {
-			return errors.NewValidationError(
+			ierrs.Add("max_thickness", "cassette maximum thickness must be positive, got %.1f", t.MaxThickness)
+		} else if t.MinThickness >= t.MaxThickness {
+			ierrs.Add("min_thickness",
 				"cassette minimum thickness %.1f must be less than maximum thickness %.1f",
 				t.MinThickness, t.MaxThickness,
 			)
 		}
 	}
 
-	return nil
+	// The thresholds overrides are optional, the error threshold must be above the warning
+	if t.CyclesWarning < 0 {
+		ierrs.Add("cycles_warning", "cycles warning threshold must be positive, got %d", t.CyclesWarning)
+	}
+	if t.CyclesError < 0 {
+		ierrs.Add("cycles_error", "cycles error threshold must be positive, got %d", t.CyclesError)
+	} else if t.CyclesWarning > 0 && t.CyclesError > 0 && t.CyclesError <= t.CyclesWarning {
+		ierrs.Add("cycles_error",
+			"cycles error threshold %d must be greater than the warning threshold %d",
+			t.CyclesError, t.CyclesWarning,
+		)
+	}
+
+	return ierrs.ValidationError()
 }
@@ -0,0 +1,132 @@
+package shared
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+
+	"github.com/williepotgieter/keymaker"
+)
+
+const (
+	ApiKeyNameMaxLength = 100
+	ApiKeyPrefixLength  = 12 // ApiKeyPrefixLength is the number of characters stored in plain text for the lookup
+	ApiKeySaltLength    = 16
+
+	apiKeyPrefix = "pgp" // apiKeyPrefix starts all generated keys, followed by "_"
+)
+
+// ApiKey is one of the named API keys of a user, only a salted hash of the key
+// is stored, the key itself is shown once after creation
+type ApiKey struct {
+	ID        EntityID   `json:"id"`
+	UserID    TelegramID `json:"user_id"`
+	Name      string     `json:"name"`   // Name describes where the key is used, e.g. "Tablet Presse 3"
+	Prefix    string     `json:"prefix"` // Prefix is the start of the key, used for the lookup
+	Salt      string     `json:"-"`      // Salt is the hex encoded random salt of the hash
+	Hash      string     `json:"-"`      // Hash is the hex encoded SHA-256 hash of salt and key
+	CreatedAt UnixMilli  `json:"created_at"`
+	ExpiresAt UnixMilli  `json:"expires_at"` // ExpiresAt is 0 for keys without expiry
+	LastUsed  UnixMilli  `json:"last_used"`  // LastUsed is 0 if the key was never used
+}
+
+// NewApiKey hashes key for storing it as a named key of a user
+func NewApiKey(userID TelegramID, name, key string, expiresAt UnixMilli) (*ApiKey, error) {
+	if !ValidateAPIKey(key) {
+		return nil, fmt.Errorf("API key must be at least %d characters long", MinAPIKeyLength)
+	}
+
+	salt := make([]byte, ApiKeySaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generate salt: %v", err)
+	}
+
+	k := &ApiKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    ApiKeyPrefix(key),
+		Salt:      hex.EncodeToString(salt),
+		CreatedAt: NewUnixMilli(time.Now()),
+		ExpiresAt: expiresAt,
+	}
+	k.Hash = k.hash(key)
+	return k, nil
+}
+
+// GenerateApiKey creates a new random API key
+func GenerateApiKey() (string, error) {
+	apiKey, err := keymaker.NewApiKey(apiKeyPrefix, 32)
+	if err != nil {
+		return "", errors.Wrap(err, "generate api key")
+	}
+	return apiKey.String(), nil
+}
+
+// ApiKeyPrefix returns the part of the key used for the lookup
+func ApiKeyPrefix(key string) string {
+	if len(key) <= ApiKeyPrefixLength {
+		return key
+	}
+	return key[:ApiKeyPrefixLength]
+}
+
+// Matches checks key against the stored hash
+func (k *ApiKey) Matches(key string) bool {
+	return subtle.ConstantTimeCompare([]byte(k.hash(key)), []byte(k.Hash)) == 1
+}
+
+// IsExpired returns true if the key has an expiry which has passed
+func (k *ApiKey) IsExpired() bool {
+	return k.ExpiresAt > 0 && time.Now().UnixMilli() >= int64(k.ExpiresAt)
+}
+
+func (k *ApiKey) hash(key string) string {
+	salt, err := hex.DecodeString(k.Salt)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(append(salt, []byte(key)...))
+	return hex.EncodeToString(sum[:])
+}
+
+func (k *ApiKey) Validate() *errors.ValidationError {
+	if k.UserID == 0 {
+		return errors.NewValidationError("api key user_id is required")
+	}
+	if k.Name == "" {
+		return errors.NewValidationError("api key name is required")
+	}
+	if len(k.Name) > ApiKeyNameMaxLength {
+		return errors.NewValidationError("api key name must be at most %d characters", ApiKeyNameMaxLength)
+	}
+	if k.Prefix == "" || k.Salt == "" || k.Hash == "" {
+		return errors.NewValidationError("api key hash is missing")
+	}
+	return nil
+}
+
+func (k *ApiKey) Clone() *ApiKey {
+	return &ApiKey{
+		ID:        k.ID,
+		UserID:    k.UserID,
+		Name:      k.Name,
+		Prefix:    k.Prefix,
+		Salt:      k.Salt,
+		Hash:      k.Hash,
+		CreatedAt: k.CreatedAt,
+		ExpiresAt: k.ExpiresAt,
+		LastUsed:  k.LastUsed,
+	}
+}
+
+func (k *ApiKey) String() string {
+	return fmt.Sprintf(
+		"ApiKey{ID:%d, UserID:%d, Name:%s, Prefix:%s, ExpiresAt:%d, LastUsed:%d}",
+		k.ID, k.UserID, k.Name, k.Prefix, k.ExpiresAt, k.LastUsed,
+	)
+}
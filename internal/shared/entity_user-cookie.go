@@ -17,6 +17,7 @@ type Cookie struct {
 	Value     string     `json:"value"`      // Unique UUID cookie value
 	UserID    TelegramID `json:"user_id"`    // Associated Telegram ID
 	LastLogin UnixMilli  `json:"last_login"` // Last login timestamp in milliseconds
+	CSRFToken string     `json:"csrf_token"` // CSRF token of the session, empty for sessions created before
 }
 
 func (e *Cookie) Validate() *errors.ValidationError {
@@ -35,6 +36,7 @@ func (e *Cookie) Clone() *Cookie {
 		Value:     e.Value,
 		UserID:    e.UserID,
 		LastLogin: e.LastLogin,
+		CSRFToken: e.CSRFToken,
 	}
 }
 
@@ -0,0 +1,203 @@
+package shared
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+)
+
+const (
+	LockoutThreshold   = 5              // LockoutThreshold is the number of failed logins before the first lockout
+	LockoutThresholdIP = 20             // LockoutThresholdIP is LockoutThreshold for a client IP, with any API key
+	LockoutBase        = time.Minute    // LockoutBase is the first lockout, doubled with every further failure
+	LockoutMax         = time.Hour      // LockoutMax is the longest lockout
+	LockoutResetAfter  = 24 * time.Hour // LockoutResetAfter forgets failures older than this
+	lockoutPrefixIP    = "ip:"          // lockoutPrefixIP is the key prefix for lockouts of a client IP
+	lockoutPrefixKey   = "key:"         // lockoutPrefixKey is the key prefix for lockouts of an API key prefix
+	lockoutSeparator   = "|"            // lockoutSeparator joins the IP and the API key prefix of a lockout key
+)
+
+// Lockout counts the failed logins of a client IP, of an API key prefix from a
+// client IP, or of an API key prefix from all IPs, after LockoutThreshold
+// failures (LockoutThresholdIP for a client IP) logins get locked with an
+// exponential backoff
+type Lockout struct {
+	Key         string    `json:"key"` // Key is "ip:<ip>", "ip:<ip>|key:<api key prefix>" or "key:<api key prefix>"
+	Failures    int       `json:"failures"`
+	LastFailure UnixMilli `json:"last_failure"`
+	LockedUntil UnixMilli `json:"locked_until"` // LockedUntil is 0 if not locked (yet)
+}
+
+// NewLockoutKeyIP returns the lockout key for a client IP
+func NewLockoutKeyIP(ip string) string {
+	return lockoutPrefixIP + ip
+}
+
+// NewLockoutKeyApiKey returns the lockout key for the prefix of an API key
+func NewLockoutKeyApiKey(apiKey string) string {
+	return lockoutPrefixKey + ApiKeyPrefix(apiKey)
+}
+
+// NewLockoutKeyIPApiKey returns the lockout key for the prefix of an API key
+// sent from a client IP
+func NewLockoutKeyIPApiKey(ip, apiKey string) string {
+	return NewLockoutKeyIP(ip) + lockoutSeparator + NewLockoutKeyApiKey(apiKey)
+}
+
+// ParseLockoutKey returns the lockout key for v, which is either a lockout key
+// or a plain IP or API key prefix
+func ParseLockoutKey(v string) string {
+	switch {
+	case strings.HasPrefix(v, lockoutPrefixIP), strings.HasPrefix(v, lockoutPrefixKey):
+		return v
+	case strings.HasPrefix(v, apiKeyPrefix+"_"):
+		return NewLockoutKeyApiKey(v)
+	default:
+		return NewLockoutKeyIP(v)
+	}
+}
+
+// LoginLockoutKeys returns the lockout keys checked for a login attempt, the
+// keys with the key prefix are only used if an API key was sent.
+//
+// The client IP alone has the higher LockoutThresholdIP, all users behind a
+// reverse proxy share its IP, and a few failures of one user must not lock out
+// the others.
+func LoginLockoutKeys(ip, apiKey string) []string {
+	keys := []string{NewLockoutKeyIP(ip)}
+	if apiKey != "" {
+		keys = append(keys, NewLockoutKeyIPApiKey(ip, apiKey), NewLockoutKeyApiKey(apiKey))
+	}
+	return keys
+}
+
+// IsIPLockoutKey returns true for the key of a client IP alone
+func IsIPLockoutKey(key string) bool {
+	return strings.HasPrefix(key, lockoutPrefixIP) && !strings.Contains(key, lockoutSeparator)
+}
+
+// ActiveLockout returns the locked lockout with the longest remaining time, or
+// nil if none of the lockouts is locked.
+//
+// The lockout of a key prefix from all IPs only counts if the client IP failed
+// too, so a client knowing the prefix can not lock out the key everywhere,
+// lockouts holds the lockouts for LoginLockoutKeys.
+func ActiveLockout(lockouts []*Lockout) *Lockout {
+	now := time.Now()
+	ipFailed := slices.ContainsFunc(lockouts, func(l *Lockout) bool {
+		return IsIPLockoutKey(l.Key) && l.Failures > 0 &&
+			now.Sub(l.LastFailure.ToTime()) <= LockoutResetAfter
+	})
+
+	var active *Lockout
+	for _, l := range lockouts {
+		if !l.IsLocked() {
+			continue
+		}
+		if !l.IsIP() && !ipFailed {
+			continue
+		}
+		if active == nil || l.LockedUntil > active.LockedUntil {
+			active = l
+		}
+	}
+	return active
+}
+
+// AddFailure counts a failed login, failures older than LockoutResetAfter are
+// forgotten first
+func (l *Lockout) AddFailure(now time.Time) {
+	if now.Sub(l.LastFailure.ToTime()) > LockoutResetAfter {
+		l.Failures = 0
+		l.LockedUntil = 0
+	}
+
+	l.Failures++
+	l.LastFailure = NewUnixMilli(now)
+
+	if threshold := l.threshold(); l.Failures >= threshold {
+		l.LockedUntil = NewUnixMilli(now.Add(lockoutDuration(l.Failures, threshold)))
+	}
+}
+
+// IsLocked returns true while logins are locked
+func (l *Lockout) IsLocked() bool {
+	return time.Now().UnixMilli() < int64(l.LockedUntil)
+}
+
+// RetryAfter returns the remaining lockout time, rounded up to seconds
+func (l *Lockout) RetryAfter() time.Duration {
+	d := time.Until(l.LockedUntil.ToTime())
+	if d <= 0 {
+		return 0
+	}
+	return d.Truncate(time.Second) + time.Second
+}
+
+// IsIP returns true for lockouts of a client IP
+func (l *Lockout) IsIP() bool {
+	return strings.HasPrefix(l.Key, lockoutPrefixIP)
+}
+
+// IsApiKey returns true for lockouts of an API key prefix, also from a single IP
+func (l *Lockout) IsApiKey() bool {
+	return strings.HasPrefix(l.Key, lockoutPrefixKey) ||
+		strings.Contains(l.Key, lockoutSeparator+lockoutPrefixKey)
+}
+
+// Value returns the IP and/or API key prefix without the key prefixes
+func (l *Lockout) Value() string {
+	ip, key, ok := strings.Cut(l.Key, lockoutSeparator)
+	if !ok {
+		return strings.TrimPrefix(strings.TrimPrefix(l.Key, lockoutPrefixIP), lockoutPrefixKey)
+	}
+	return strings.TrimPrefix(ip, lockoutPrefixIP) + " / " + strings.TrimPrefix(key, lockoutPrefixKey)
+}
+
+func (l *Lockout) Validate() *errors.ValidationError {
+	if !strings.HasPrefix(l.Key, lockoutPrefixIP) && !strings.HasPrefix(l.Key, lockoutPrefixKey) {
+		return errors.NewValidationError("invalid lockout key %q", l.Key)
+	}
+	if l.Failures < 0 {
+		return errors.NewValidationError("lockout failures must not be negative")
+	}
+	return nil
+}
+
+func (l *Lockout) Clone() *Lockout {
+	return &Lockout{
+		Key:         l.Key,
+		Failures:    l.Failures,
+		LastFailure: l.LastFailure,
+		LockedUntil: l.LockedUntil,
+	}
+}
+
+func (l *Lockout) String() string {
+	return fmt.Sprintf(
+		"Lockout{Key:%s, Failures:%d, LastFailure:%d, LockedUntil:%d}",
+		l.Key, l.Failures, l.LastFailure, l.LockedUntil,
+	)
+}
+
+// threshold returns the number of failures before the first lockout
+func (l *Lockout) threshold() int {
+	if IsIPLockoutKey(l.Key) {
+		return LockoutThresholdIP
+	}
+	return LockoutThreshold
+}
+
+// lockoutDuration returns LockoutBase doubled for every failure after the
+// threshold, limited to LockoutMax
+func lockoutDuration(failures, threshold int) time.Duration {
+	d := float64(LockoutBase) * math.Pow(2, float64(failures-threshold))
+	if d > float64(LockoutMax) {
+		return LockoutMax
+	}
+	return time.Duration(d)
+}
@@ -0,0 +1,75 @@
+package shared
+
+import (
+	"fmt"
+
+	"github.com/knackwurstking/pg-press/internal/errors"
+)
+
+// LoginSource is where the API key of a login attempt was sent
+type LoginSource string
+
+const (
+	LoginSourceForm  LoginSource = "form"  // LoginSourceForm is the login page
+	LoginSourceToken LoginSource = "token" // LoginSourceToken is the Authorization header or the access_token query parameter
+)
+
+func (s LoginSource) German() string {
+	switch s {
+	case LoginSourceForm:
+		return "Anmeldeseite"
+	case LoginSourceToken:
+		return "API-Schlüssel"
+	default:
+		return "Unbekannt"
+	}
+}
+
+// LoginAttempt is an entry of the login history
+type LoginAttempt struct {
+	ID        EntityID    `json:"id"`
+	UserID    TelegramID  `json:"user_id"` // UserID is 0 if the key is unknown
+	Source    LoginSource `json:"source"`
+	RealIP    string      `json:"real_ip"`
+	UserAgent string      `json:"user_agent"`
+	KeyPrefix string      `json:"key_prefix"` // KeyPrefix is the lookup prefix of the sent API key
+	Success   bool        `json:"success"`
+	Reason    string      `json:"reason"` // Reason why the attempt failed, empty on success
+	CreatedAt UnixMilli   `json:"created_at"`
+}
+
+func (a *LoginAttempt) Validate() *errors.ValidationError {
+	switch a.Source {
+	case LoginSourceForm, LoginSourceToken:
+	default:
+		return errors.NewValidationError("invalid login source %q", a.Source)
+	}
+	if a.RealIP == "" {
+		return errors.NewValidationError("login attempt real_ip is required")
+	}
+	if a.Success && a.UserID == 0 {
+		return errors.NewValidationError("successful login attempt without user_id")
+	}
+	return nil
+}
+
+func (a *LoginAttempt) Clone() *LoginAttempt {
+	return &LoginAttempt{
+		ID:        a.ID,
+		UserID:    a.UserID,
+		Source:    a.Source,
+		RealIP:    a.RealIP,
+		UserAgent: a.UserAgent,
+		KeyPrefix: a.KeyPrefix,
+		Success:   a.Success,
+		Reason:    a.Reason,
+		CreatedAt: a.CreatedAt,
+	}
+}
+
+func (a *LoginAttempt) String() string {
+	return fmt.Sprintf(
+		"LoginAttempt{ID:%d, UserID:%d, Source:%s, RealIP:%s, KeyPrefix:%s, Success:%t}",
+		a.ID, a.UserID, a.Source, a.RealIP, a.KeyPrefix, a.Success,
+	)
+}
@@ -17,9 +17,9 @@ const (
 
 // User represents a user entity with relevant information.
 type User struct {
-	ID     TelegramID `json:"id"`      // Unique Telegram ID for the user
-	Name   string     `json:"name"`    // User's display name
-	ApiKey string     `json:"api_key"` // Unique API key for the user
+	ID   TelegramID `json:"id"`   // Unique Telegram ID for the user
+	Name string     `json:"name"` // User's display name
+	Role UserRole   `json:"role"` // Role defines what the user is allowed to do
 }
 
 func (e *User) Validate() *errors.ValidationError {
@@ -32,31 +32,41 @@ func (e *User) Validate() *errors.ValidationError {
 			UserNameMinLength, UserNameMaxLength,
 		)
 	}
-	if !ValidateAPIKey(e.ApiKey) {
-		return errors.NewValidationError("API key must be at least %d characters long", MinAPIKeyLength)
+	if !e.Role.IsValid() {
+		return errors.NewValidationError("invalid user role: %s", e.Role)
 	}
 	return nil
 }
 
 func (e *User) Clone() *User {
 	return &User{
-		ID:     e.ID,
-		Name:   e.Name,
-		ApiKey: e.ApiKey,
+		ID:   e.ID,
+		Name: e.Name,
+		Role: e.Role,
 	}
 }
 
 func (e *User) String() string {
-	return "User{ID:" + e.ID.String() + ", Name:" + e.Name + ", ApiKey:" + MaskString(e.ApiKey) + "}"
+	return "User{ID:" + e.ID.String() + ", Name:" + e.Name + ", Role:" + e.Role.String() + "}"
 }
 
-// IsAdmin checks if the user is an administrator
-func (u *User) IsAdmin() bool {
-	if env.Admins == "" {
-		return false
+// EffectiveRole returns the users role, users listed in the ADMINS environment
+// variable are always administrators
+func (u *User) EffectiveRole() UserRole {
+	if env.Admins != "" && slices.Contains(strings.Split(env.Admins, ","), fmt.Sprintf("%d", u.ID)) {
+		return UserRoleAdmin
 	}
+	return u.Role
+}
 
-	return slices.Contains(strings.Split(env.Admins, ","), fmt.Sprintf("%d", u.ID))
+// Can checks if the user has the permission
+func (u *User) Can(p Permission) bool {
+	return u.EffectiveRole().Includes(p.Role())
+}
+
+// IsAdmin checks if the user is an administrator
+func (u *User) IsAdmin() bool {
+	return u.EffectiveRole() == UserRoleAdmin
 }
 
 // ValidateAPIKey validates an API key according to the minimum length requirement.
@@ -28,17 +28,44 @@ var (
 	_ Entity[*Press]            = (*Press)(nil)
 	_ Entity[*ToolRegeneration] = (*ToolRegeneration)(nil)
 	_ Entity[*Tool]             = (*Tool)(nil)
+	_ Entity[*ToolThreshold]    = (*ToolThreshold)(nil)
 	_ Entity[*Cookie]           = (*Cookie)(nil)
 	_ Entity[*Session]          = (*Session)(nil)
 	_ Entity[*User]             = (*User)(nil)
+	_ Entity[*ApiKey]           = (*ApiKey)(nil)
+	_ Entity[*LoginAttempt]     = (*LoginAttempt)(nil)
+	_ Entity[*Lockout]          = (*Lockout)(nil)
 	_ Entity[*TroubleReport]    = (*TroubleReport)(nil)
+	_ Entity[*AuditEntry]       = (*AuditEntry)(nil)
+	_ Entity[*Feed]             = (*Feed)(nil)
 )
 
 // Ensure Translate implementations
 
 var (
 	_ Translate = (*Tool)(nil)
+	_ Translate = (*ToolThreshold)(nil)
 	_ Translate = (*TroubleReport)(nil)
 	_ Translate = (*Press)(nil)
 	_ Translate = Slot(0)
+	_ Translate = TrashKind("")
+	_ Translate = AuditAction("")
+	_ Translate = AuditEntityType("")
+	_ Translate = FeedKind("")
+	_ Translate = LoginSource("")
+)
+
+// Ensure Auditable implementations
+
+var (
+	_ Auditable = (*Tool)(nil)
+	_ Auditable = (*UpperMetalSheet)(nil)
+	_ Auditable = (*LowerMetalSheet)(nil)
+	_ Auditable = (*ToolRegeneration)(nil)
+	_ Auditable = (*ToolThreshold)(nil)
+	_ Auditable = (*Press)(nil)
+	_ Auditable = (*Cycle)(nil)
+	_ Auditable = (*Note)(nil)
+	_ Auditable = (*TroubleReport)(nil)
+	_ Auditable = (*User)(nil)
 )
@@ -0,0 +1,69 @@
+package shared
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// CycleSummaryDateLayout is the date layout used for the from and to filters
+const CycleSummaryDateLayout = "2006-01-02"
+
+// CycleSummaryFilter limits the cycles used for a press cycle summary
+type CycleSummaryFilter struct {
+	From    UnixMilli  // From includes cycles read at or after this time, 0 for no limit
+	To      UnixMilli  // To includes cycles read before this time, 0 for no limit
+	ToolIDs []EntityID // ToolIDs limits the summary to these tools, empty for all tools
+}
+
+// NewCycleSummaryFilter parses the optional from and to dates (CycleSummaryDateLayout),
+// the to date is inclusive
+func NewCycleSummaryFilter(from, to string, toolIDs []EntityID) (*CycleSummaryFilter, error) {
+	f := &CycleSummaryFilter{ToolIDs: toolIDs}
+
+	if from != "" {
+		t, err := time.ParseInLocation(CycleSummaryDateLayout, from, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from date %q: %v", from, err)
+		}
+		f.From = NewUnixMilli(t)
+	}
+
+	if to != "" {
+		t, err := time.ParseInLocation(CycleSummaryDateLayout, to, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to date %q: %v", to, err)
+		}
+		f.To = NewUnixMilli(t.AddDate(0, 0, 1))
+	}
+
+	if f.From > 0 && f.To > 0 && f.From >= f.To {
+		return nil, fmt.Errorf("from date %s is after to date %s", from, to)
+	}
+
+	return f, nil
+}
+
+// Match returns true if the cycle passes the filter
+func (f *CycleSummaryFilter) Match(cycle *Cycle) bool {
+	if f.From > 0 && cycle.Stop < f.From {
+		return false
+	}
+	if f.To > 0 && cycle.Stop >= f.To {
+		return false
+	}
+	if len(f.ToolIDs) > 0 && !slices.Contains(f.ToolIDs, cycle.ToolID) {
+		return false
+	}
+	return true
+}
+
+// CycleSummary contains everything needed to render the cycle summary of a press
+type CycleSummary struct {
+	Press       *Press
+	Filter      *CycleSummaryFilter
+	Cycles      []*Cycle
+	Tools       map[EntityID]*Tool
+	Users       map[TelegramID]*User
+	Utilization *PressUtilization // Utilization holds the currently mounted tools
+}
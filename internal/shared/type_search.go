@@ -0,0 +1,62 @@
+package shared
+
+import "strings"
+
+// Markers around the matched words in SearchHit.Title and SearchHit.Snippet,
+// from the unicode private use area, so they never appear in the indexed text
+const (
+	SearchMarkOpen  = "\uE000"
+	SearchMarkClose = "\uE001"
+)
+
+// SearchHit is a single entity found by the full-text search
+type SearchHit struct {
+	ID      EntityID `json:"id"`
+	Title   string   `json:"title"`
+	Snippet string   `json:"snippet"`          // Snippet is the matching part of the entity
+	Linked  string   `json:"linked,omitempty"` // Linked entity of a note (e.g., "press_5", "tool_123")
+}
+
+// SearchResults contains the search hits grouped by entity
+type SearchResults struct {
+	Query          string       `json:"query"`
+	Tools          []*SearchHit `json:"tools"`
+	Notes          []*SearchHit `json:"notes"`
+	TroubleReports []*SearchHit `json:"trouble_reports"`
+}
+
+// Empty returns true if nothing was found
+func (sr *SearchResults) Empty() bool {
+	return len(sr.Tools) == 0 && len(sr.Notes) == 0 && len(sr.TroubleReports) == 0
+}
+
+// HighlightPart is a part of a highlighted text, Match is true for matched words
+type HighlightPart struct {
+	Text  string
+	Match bool
+}
+
+// SplitHighlight splits a text containing the search markers into its parts
+func SplitHighlight(s string) []HighlightPart {
+	var parts []HighlightPart
+	for s != "" {
+		i := strings.Index(s, SearchMarkOpen)
+		if i < 0 {
+			parts = append(parts, HighlightPart{Text: s})
+			break
+		}
+		if i > 0 {
+			parts = append(parts, HighlightPart{Text: s[:i]})
+		}
+		s = s[i+len(SearchMarkOpen):]
+
+		j := strings.Index(s, SearchMarkClose)
+		if j < 0 {
+			parts = append(parts, HighlightPart{Text: s, Match: true})
+			break
+		}
+		parts = append(parts, HighlightPart{Text: s[:j], Match: true})
+		s = s[j+len(SearchMarkClose):]
+	}
+	return parts
+}
@@ -0,0 +1,154 @@
+package shared
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// ToolForecastDays is the period of the cycle history used for the cycles per day
+const ToolForecastDays = 30
+
+// unixMilliDay is one day in milliseconds
+const unixMilliDay = UnixMilli(24 * time.Hour / time.Millisecond)
+
+// ToolForecast projects the dates a mounted tool exceeds its cycle thresholds
+// (Tool.Thresholds), based on the cycles per day of the recent cycle history
+type ToolForecast struct {
+	Tool         *Tool
+	Press        *Press    // Press the tool is mounted on, nil if not mounted
+	CyclesPerDay float64   // CyclesPerDay is 0 without enough recent cycle history
+	LastRead     UnixMilli // LastRead is the stop of the newest cycle, Tool.Cycles counts up to this date
+}
+
+// NewToolForecast calculates the cycles per day from the partial cycles read in
+// the last ToolForecastDays before now. Only the time between start and stop of
+// the cycles is counted, so periods where the tool was not mounted are skipped.
+func NewToolForecast(tool *Tool, press *Press, cycles []*Cycle, now time.Time) *ToolForecast {
+	f := &ToolForecast{Tool: tool, Press: press}
+
+	from := NewUnixMilli(now.AddDate(0, 0, -ToolForecastDays))
+	var partialCycles float64
+	var duration UnixMilli
+	for _, c := range cycles {
+		f.LastRead = max(f.LastRead, c.Stop)
+
+		// Skip cycles read before the period, the first cycle of a press has no start
+		if c.Stop <= from || c.Stop <= c.Start || c.PartialCycles < 0 {
+			continue
+		}
+
+		// Cycles started before the period only count with the part inside
+		start := max(c.Start, from)
+		partialCycles += float64(c.PartialCycles) * float64(c.Stop-start) / float64(c.Stop-c.Start)
+		duration += c.Stop - start
+	}
+
+	// Less than a day is not enough for a reliable rate
+	if duration >= unixMilliDay {
+		f.CyclesPerDay = partialCycles / (float64(duration) / float64(unixMilliDay))
+	}
+
+	return f
+}
+
+// WarningAt returns the projected date for the warning threshold, see At
+func (f *ToolForecast) WarningAt() UnixMilli {
+	return f.At(f.Tool.Thresholds.Warning)
+}
+
+// ErrorAt returns the projected date for the error threshold, see At
+func (f *ToolForecast) ErrorAt() UnixMilli {
+	return f.At(f.Tool.Thresholds.Error)
+}
+
+// At returns the projected date the tool exceeds the threshold, counted from the
+// last read. Returns 0 if the threshold is already exceeded, the tool is not
+// mounted or there is no cycles per day rate.
+func (f *ToolForecast) At(threshold int64) UnixMilli {
+	if f.Exceeded(threshold) || f.Press == nil || f.CyclesPerDay <= 0 {
+		return 0
+	}
+
+	days := float64(threshold-f.Tool.Cycles) / f.CyclesPerDay
+	return f.LastRead + UnixMilli(days*float64(unixMilliDay))
+}
+
+// Exceeded returns true if the tool cycles are over the threshold
+func (f *ToolForecast) Exceeded(threshold int64) bool {
+	return f.Tool.Cycles > threshold
+}
+
+// DueThreshold returns the next threshold the tool will exceed, the error
+// threshold once the warning threshold is exceeded
+func (f *ToolForecast) DueThreshold() int64 {
+	if f.Exceeded(f.Tool.Thresholds.Warning) {
+		return f.Tool.Thresholds.Error
+	}
+	return f.Tool.Thresholds.Warning
+}
+
+// DueAt returns the projected date for the DueThreshold
+func (f *ToolForecast) DueAt() UnixMilli {
+	return f.At(f.DueThreshold())
+}
+
+// order sorts exceeded thresholds first, then the projected dates and unknown
+// dates last
+func (f *ToolForecast) order(threshold int64) (int, UnixMilli) {
+	if f.Exceeded(threshold) {
+		return 0, 0
+	}
+	if at := f.At(threshold); at > 0 {
+		return 1, at
+	}
+	return 2, 0
+}
+
+// ToolForecastSort defines the order of the tool forecasts list
+type ToolForecastSort string
+
+const (
+	ToolForecastSortDue     ToolForecastSort = "due"
+	ToolForecastSortWarning ToolForecastSort = "warning"
+	ToolForecastSortError   ToolForecastSort = "error"
+	ToolForecastSortRate    ToolForecastSort = "rate"
+	ToolForecastSortCycles  ToolForecastSort = "cycles"
+)
+
+// NewToolForecastSort parses the sort, falls back to ToolForecastSortDue
+func NewToolForecastSort(s string) ToolForecastSort {
+	switch sort := ToolForecastSort(s); sort {
+	case ToolForecastSortWarning, ToolForecastSortError, ToolForecastSortRate, ToolForecastSortCycles:
+		return sort
+	default:
+		return ToolForecastSortDue
+	}
+}
+
+// SortToolForecasts sorts the forecasts in place, dates ascending (see
+// ToolForecast.order), cycles per day and cycles descending. Equal entries are
+// ordered by the tool cycles.
+func SortToolForecasts(forecasts []*ToolForecast, sort ToolForecastSort) {
+	compareDates := func(a, b *ToolForecast, thresholdA, thresholdB int64) int {
+		rankA, atA := a.order(thresholdA)
+		rankB, atB := b.order(thresholdB)
+		return cmp.Or(cmp.Compare(rankA, rankB), cmp.Compare(atA, atB))
+	}
+
+	slices.SortStableFunc(forecasts, func(a, b *ToolForecast) int {
+		var c int
+		switch sort {
+		case ToolForecastSortWarning:
+			c = compareDates(a, b, a.Tool.Thresholds.Warning, b.Tool.Thresholds.Warning)
+		case ToolForecastSortError:
+			c = compareDates(a, b, a.Tool.Thresholds.Error, b.Tool.Thresholds.Error)
+		case ToolForecastSortRate:
+			c = cmp.Compare(b.CyclesPerDay, a.CyclesPerDay)
+		case ToolForecastSortCycles:
+		default:
+			c = compareDates(a, b, a.DueThreshold(), b.DueThreshold())
+		}
+		return cmp.Or(c, cmp.Compare(b.Tool.Cycles, a.Tool.Cycles))
+	})
+}
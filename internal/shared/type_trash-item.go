@@ -0,0 +1,41 @@
+package shared
+
+// TrashKind is the type of a soft deleted entity, the value is the name of its table
+type TrashKind string
+
+const (
+	TrashKindTool          TrashKind = "tools"
+	TrashKindMetalSheet    TrashKind = "metal_sheets"
+	TrashKindPress         TrashKind = "presses"
+	TrashKindCycle         TrashKind = "cycles"
+	TrashKindNote          TrashKind = "notes"
+	TrashKindTroubleReport TrashKind = "trouble_reports"
+)
+
+func (k TrashKind) German() string {
+	switch k {
+	case TrashKindTool:
+		return "Werkzeug"
+	case TrashKindMetalSheet:
+		return "Blech"
+	case TrashKindPress:
+		return "Presse"
+	case TrashKindCycle:
+		return "Zyklus"
+	case TrashKindNote:
+		return "Notiz"
+	case TrashKindTroubleReport:
+		return "Problembericht"
+	default:
+		return "Unbekannt"
+	}
+}
+
+// TrashItem is a soft deleted entity, which can be restored until it gets purged
+type TrashItem struct {
+	Kind      TrashKind  `json:"kind"`
+	ID        EntityID   `json:"id"`
+	Title     string     `json:"title"`      // Title is a short, human readable description
+	DeletedAt UnixMilli  `json:"deleted_at"` // DeletedAt timestamp in milliseconds
+	DeletedBy TelegramID `json:"deleted_by"` // DeletedBy is 0 if deleted from the command line
+}
@@ -0,0 +1,85 @@
+package shared
+
+// UserRole defines what a user is allowed to do, roles are ordered, every role
+// includes the permissions of the roles before
+type UserRole string
+
+const (
+	UserRoleViewer      UserRole = "viewer"      // UserRoleViewer can only look at things
+	UserRoleOperator    UserRole = "operator"    // UserRoleOperator records cycles, notes and trouble reports
+	UserRoleMaintenance UserRole = "maintenance" // UserRoleMaintenance manages tools, presses, metal sheets and regenerations
+	UserRoleAdmin       UserRole = "admin"       // UserRoleAdmin is allowed to do everything
+
+	DefaultUserRole = UserRoleOperator
+)
+
+// UserRoles lists all roles, lowest first
+var UserRoles = []UserRole{
+	UserRoleViewer,
+	UserRoleOperator,
+	UserRoleMaintenance,
+	UserRoleAdmin,
+}
+
+func (r UserRole) IsValid() bool {
+	return r.level() >= 0
+}
+
+// Includes returns true if the role has at least the permissions of other
+func (r UserRole) Includes(other UserRole) bool {
+	return other.IsValid() && r.level() >= other.level()
+}
+
+func (r UserRole) German() string {
+	switch r {
+	case UserRoleViewer:
+		return "Betrachter"
+	case UserRoleOperator:
+		return "Bediener"
+	case UserRoleMaintenance:
+		return "Instandhaltung"
+	case UserRoleAdmin:
+		return "Administrator"
+	default:
+		return "Unbekannt"
+	}
+}
+
+func (r UserRole) String() string {
+	return string(r)
+}
+
+func (r UserRole) level() int {
+	for i, role := range UserRoles {
+		if role == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// Permission is an action a user needs a minimum role for
+type Permission string
+
+const (
+	PermissionView       Permission = "view"        // PermissionView allows reading everything
+	PermissionEditCycles Permission = "edit_cycles" // PermissionEditCycles allows adding and editing cycles, notes and trouble reports
+	PermissionEditTools  Permission = "edit_tools"  // PermissionEditTools allows managing tools, presses, metal sheets and regenerations
+	PermissionDelete     Permission = "delete"      // PermissionDelete allows deleting entities
+	PermissionAdminister Permission = "administer"  // PermissionAdminister allows the admin page, jobs and the trash
+)
+
+// Role returns the minimum role needed for the permission, unknown
+// permissions need the admin role
+func (p Permission) Role() UserRole {
+	switch p {
+	case PermissionView:
+		return UserRoleViewer
+	case PermissionEditCycles:
+		return UserRoleOperator
+	case PermissionEditTools:
+		return UserRoleMaintenance
+	default:
+		return UserRoleAdmin
+	}
+}
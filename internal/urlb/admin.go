@@ -0,0 +1,37 @@
+package urlb
+
+import (
+	"github.com/a-h/templ"
+	"github.com/knackwurstking/pg-press/internal/shared"
+)
+
+func Admin() templ.SafeURL {
+	return BuildURL("/admin")
+}
+
+func AdminJobs() templ.SafeURL {
+	return BuildURL("/admin/jobs")
+}
+
+func AdminJobsRun(name string) templ.SafeURL {
+	return BuildURLWithParams("/admin/jobs/run", map[string]string{
+		"name": name,
+	})
+}
+
+func AdminLockouts(key string) templ.SafeURL {
+	return BuildURLWithParams("/admin/lockouts", map[string]string{
+		"key": key,
+	})
+}
+
+// AdminToolThresholds constructs the tool thresholds URL, with the ID for
+// editing, updating or deleting a threshold
+func AdminToolThresholds(id shared.EntityID) templ.SafeURL {
+	params := map[string]string{}
+	if id > 0 {
+		params["id"] = id.String()
+	}
+
+	return BuildURLWithParams("/admin/tool-thresholds", params)
+}
@@ -2,6 +2,7 @@ package urlb
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/a-h/templ"
 )
@@ -16,3 +17,11 @@ func Login(apiKey string, invalid *bool) templ.SafeURL {
 	}
 	return BuildURLWithParams("/login", params)
 }
+
+// LoginLocked constructs the login page URL shown while logins are locked after
+// too many failed attempts
+func LoginLocked(retryAfter time.Duration) templ.SafeURL {
+	return BuildURLWithParams("/login", map[string]string{
+		"locked": fmt.Sprintf("%d", int(retryAfter.Seconds())),
+	})
+}
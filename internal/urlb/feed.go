@@ -1,6 +1,11 @@
 package urlb
 
-import "github.com/a-h/templ"
+import (
+	"fmt"
+
+	"github.com/a-h/templ"
+	"github.com/knackwurstking/pg-press/internal/shared"
+)
 
 func Feed() templ.SafeURL {
 	return BuildURL("/feed")
@@ -9,3 +14,24 @@ func Feed() templ.SafeURL {
 func FeedList() templ.SafeURL {
 	return BuildURL("/feed/list")
 }
+
+// FeedListPage constructs the feed list URL for a page, seen is the last feed the
+// user has seen before opening the feed page
+func FeedListPage(pressID, toolID shared.EntityID, page int, seen shared.EntityID) templ.SafeURL {
+	params := map[string]string{
+		"page": fmt.Sprintf("%d", page),
+		"seen": fmt.Sprintf("%d", seen),
+	}
+	if pressID > 0 {
+		params["press"] = fmt.Sprintf("%d", pressID)
+	}
+	if toolID > 0 {
+		params["tool"] = fmt.Sprintf("%d", toolID)
+	}
+	return BuildURLWithParams("/feed/list", params)
+}
+
+// FeedUnread constructs the URL of the unread feeds badge
+func FeedUnread() templ.SafeURL {
+	return BuildURL("/feed/unread")
+}
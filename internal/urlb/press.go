@@ -32,6 +32,11 @@ func PressNotes(pressID shared.EntityID) templ.SafeURL {
 	return BuildURL(fmt.Sprintf("/press/%d/notes", pressID))
 }
 
+// PressAudit constructs press audit log URL
+func PressAudit(pressID shared.EntityID) templ.SafeURL {
+	return BuildURL(fmt.Sprintf("/press/%d/audit", pressID))
+}
+
 // PressCycleSummaryPDF constructs press cycle summary PDF URL
 func PressCycleSummaryPDF(pressID shared.EntityID) templ.SafeURL {
 	return BuildURL(fmt.Sprintf("/press/%d/cycle-summary-pdf", pressID))
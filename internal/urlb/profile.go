@@ -1,6 +1,11 @@
 package urlb
 
-import "github.com/a-h/templ"
+import (
+	"fmt"
+
+	"github.com/a-h/templ"
+	"github.com/knackwurstking/pg-press/internal/shared"
+)
 
 func Profile() templ.SafeURL {
 	return BuildURL("/profile")
@@ -11,3 +16,15 @@ func ProfileCookies(cookieValue string) templ.SafeURL {
 		"value": cookieValue,
 	})
 }
+
+func ProfileApiKeys(apiKeyID shared.EntityID) templ.SafeURL {
+	params := map[string]string{}
+	if apiKeyID != 0 {
+		params["id"] = fmt.Sprintf("%d", apiKeyID)
+	}
+	return BuildURLWithParams("/profile/api-keys", params)
+}
+
+func ProfileLoginHistory() templ.SafeURL {
+	return BuildURL("/profile/login-history")
+}
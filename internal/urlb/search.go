@@ -0,0 +1,17 @@
+package urlb
+
+import (
+	"github.com/a-h/templ"
+)
+
+// Search constructs search page URL
+func Search(query string) templ.SafeURL {
+	return BuildURLWithParams("/search", map[string]string{
+		"q": query,
+	})
+}
+
+// SearchQuick constructs quick search results URL, the query gets added by the search input
+func SearchQuick() templ.SafeURL {
+	return BuildURL("/search/quick")
+}
@@ -46,6 +46,11 @@ func ToolMetalSheets(toolID shared.EntityID) templ.SafeURL {
 	return BuildURL(fmt.Sprintf("/tool/%d/metal-sheets", toolID))
 }
 
+// ToolAudit constructs tool audit log URL
+func ToolAudit(toolID shared.EntityID) templ.SafeURL {
+	return BuildURL(fmt.Sprintf("/tool/%d/audit", toolID))
+}
+
 // ToolCycles constructs tool cycles URL
 func ToolCycles(toolID shared.EntityID) templ.SafeURL {
 	return BuildURL(fmt.Sprintf("/tool/%d/cycles", toolID))
@@ -36,6 +36,13 @@ func ToolsSectionTools() templ.SafeURL {
 	return BuildURL("/tools/section/tools")
 }
 
+// ToolsSectionForecast constructs tools section forecast URL
+func ToolsSectionForecast(sort shared.ToolForecastSort) templ.SafeURL {
+	return BuildURLWithParams("/tools/section/forecast", map[string]string{
+		"sort": string(sort),
+	})
+}
+
 // ToolsAdminOverlapping constructs admin overlapping tools URL
 func ToolsAdminOverlapping() templ.SafeURL {
 	return BuildURL("/tools/admin/overlapping-tools")
@@ -0,0 +1,26 @@
+package urlb
+
+import (
+	"fmt"
+
+	"github.com/a-h/templ"
+	"github.com/knackwurstking/pg-press/internal/shared"
+)
+
+// Trash constructs trash page URL
+func Trash() templ.SafeURL {
+	return BuildURL("/trash")
+}
+
+// TrashItems constructs trash items list URL
+func TrashItems() templ.SafeURL {
+	return BuildURL("/trash/items")
+}
+
+// TrashRestore constructs trash restore URL
+func TrashRestore(kind shared.TrashKind, id shared.EntityID) templ.SafeURL {
+	return BuildURLWithParams("/trash/restore", map[string]string{
+		"kind": string(kind),
+		"id":   fmt.Sprintf("%d", id),
+	})
+}
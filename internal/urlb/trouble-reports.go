@@ -12,6 +12,11 @@ func TroubleReports() templ.SafeURL {
 	return BuildURL("/trouble-reports")
 }
 
+// TroubleReport constructs trouble reports page URL, with the report opened
+func TroubleReport(trID shared.EntityID) templ.SafeURL {
+	return BuildURL(fmt.Sprintf("/trouble-reports#trouble-report-%d", trID))
+}
+
 // TroubleReportsSharePDF constructs trouble reports share PDF URL
 func TroubleReportsSharePDF(trID shared.EntityID) templ.SafeURL {
 	return BuildURLWithParams("/trouble-reports/share-pdf", map[string]string{
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+)
+
+const (
+	// CSRFHeader is the request header for the CSRF token, set for all HTMX
+	// requests by "js/layout/main.js"
+	CSRFHeader = "X-CSRF-Token"
+
+	// CSRFFormField is the form field for the CSRF token, added to all plain
+	// POST forms by "js/layout/main.js"
+	CSRFFormField = "csrf_token"
+)
+
+type csrfTokenKey struct{}
+
+// NewCSRFToken creates a new random CSRF token for a session
+func NewCSRFToken() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err) // crypto/rand never fails, see rand.Read
+	}
+	return hex.EncodeToString(b)
+}
+
+// WithCSRFToken returns a copy of ctx holding the CSRF token of the session,
+// used by the layout template
+func WithCSRFToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, csrfTokenKey{}, token)
+}
+
+// GetCSRFToken returns the CSRF token of the session, or an empty string for
+// requests without a session cookie
+func GetCSRFToken(ctx context.Context) string {
+	token, _ := ctx.Value(csrfTokenKey{}).(string)
+	return token
+}
+
+// ValidCSRFToken compares the token sent with a request to the token of the session
+func ValidCSRFToken(expected, got string) bool {
+	if expected == "" || got == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(got)) == 1
+}
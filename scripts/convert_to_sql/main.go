@@ -36,10 +36,15 @@ func main() {
 		os.RemoveAll(sqlPath)
 	}
 
-	if err := db.Open(sqlPath, true); err != nil {
+	store, err := db.Connect(sqlPath, true)
+	if err != nil {
 		panic("failed to open database: " + err.Error())
 	}
-	defer db.Close()
+	defer store.Close()
+
+	if _, err := store.Migrate(false); err != nil {
+		panic("failed to migrate database: " + err.Error())
+	}
 
 	images := []string{}
 	{ // Load Attachments (images)
@@ -63,28 +68,28 @@ func main() {
 		panic("failed to read tools: " + err.Error())
 	}
 
-	if err := CreateToolData(oldCycles, oldTools); err != nil {
+	if err := CreateToolData(store, oldCycles, oldTools); err != nil {
 		panic("failed to convert tool data: " + err.Error())
 	}
 
-	if err := CreatePressData(oldCycles, oldTools); err != nil {
+	if err := CreatePressData(store, oldCycles, oldTools); err != nil {
 		panic("failed to convert press data: " + err.Error())
 	}
 
-	if err := CreateNoteData(); err != nil {
+	if err := CreateNoteData(store); err != nil {
 		panic("failed to convert note data: " + err.Error())
 	}
 
-	if err := CreateUserData(); err != nil {
+	if err := CreateUserData(store); err != nil {
 		panic("failed to convert user data: " + err.Error())
 	}
 
-	if err := CreateReportsData(images); err != nil {
+	if err := CreateReportsData(store, images); err != nil {
 		panic("failed to convert reports data: " + err.Error())
 	}
 }
 
-func CreateToolData(oldCycles []m.Cycle, oldTools []m.Tool) error {
+func CreateToolData(store *db.Store, oldCycles []m.Cycle, oldTools []m.Tool) error {
 	{ // Metal Sheets
 		oldMetalSheets := []m.MetalSheet{}
 		if err := readJSON("metal-sheets.json", &oldMetalSheets); err != nil {
@@ -107,14 +112,14 @@ func CreateToolData(oldCycles []m.Cycle, oldTools []m.Tool) error {
 					STFMax:         ms.STFMax,
 					Identifier:     shared.MachineType(ms.Identifier),
 				}
-				if err := db.AddLowerMetalSheet(lms); err != nil {
+				if err := store.AddLowerMetalSheet(lms); err != nil {
 					return err
 				}
 			} else {
 				ums := &shared.UpperMetalSheet{
 					BaseMetalSheet: base,
 				}
-				if err := db.AddUpperMetalSheet(ums); err != nil {
+				if err := store.AddUpperMetalSheet(ums); err != nil {
 					return err
 				}
 			}
@@ -137,7 +142,7 @@ func CreateToolData(oldCycles []m.Cycle, oldTools []m.Tool) error {
 					break
 				}
 			}
-			err := db.AddToolRegeneration(&shared.ToolRegeneration{
+			err := store.AddToolRegeneration(&shared.ToolRegeneration{
 				ID:     shared.EntityID(r.ID),
 				ToolID: shared.EntityID(r.ToolID),
 				Start:  start,
@@ -187,7 +192,7 @@ func CreateToolData(oldCycles []m.Cycle, oldTools []m.Tool) error {
 				MaxThickness: 2, // MaxThickness does not exists in old data
 			}
 
-			if err := db.AddTool(tool); err != nil {
+			if err := store.AddTool(tool); err != nil {
 				fmt.Printf("Failed to add tool: %#v\n", tool)
 				return err
 			}
@@ -197,7 +202,7 @@ func CreateToolData(oldCycles []m.Cycle, oldTools []m.Tool) error {
 	return nil
 }
 
-func CreatePressData(cycles []m.Cycle, tools []m.Tool) error {
+func CreatePressData(store *db.Store, cycles []m.Cycle, tools []m.Tool) error {
 	{ // Create presses from cycles and tools (`Press` & `PressNumber`)
 		pressNumbers := []shared.PressNumber{}
 		for _, c := range cycles {
@@ -252,14 +257,14 @@ func CreatePressData(cycles []m.Cycle, tools []m.Tool) error {
 				SlotDown:     slotDown,
 				CyclesOffset: 0,
 			}
-			if err := db.AddPress(press); err != nil {
+			if err := store.AddPress(press); err != nil {
 				return fmt.Errorf("failed to add press %#v: %w", press, err)
 			}
 		}
 	}
 
 	{ // Cycles
-		presses, herr := db.ListPress()
+		presses, herr := store.ListPress()
 		if herr != nil {
 			return herr.Wrap("listing presses failed")
 		}
@@ -282,8 +287,9 @@ func CreatePressData(cycles []m.Cycle, tools []m.Tool) error {
 				PressID:     pressID,
 				PressCycles: c.TotalCycles,
 				Stop:        shared.NewUnixMilli(c.Date),
+				PerformedBy: shared.TelegramID(c.PerformedBy),
 			}
-			if err := db.AddCycle(cycle); err != nil {
+			if err := store.AddCycle(cycle); err != nil {
 				return fmt.Errorf("failed to add cycle %#v: %w", cycle, err)
 			}
 		}
@@ -292,7 +298,7 @@ func CreatePressData(cycles []m.Cycle, tools []m.Tool) error {
 	return nil
 }
 
-func CreateNoteData() error {
+func CreateNoteData(store *db.Store) error {
 	oldNotes := []m.Note{}
 	if err := readJSON("notes.json", &oldNotes); err != nil {
 		panic("failed to read notes: " + err.Error())
@@ -318,7 +324,7 @@ func CreateNoteData() error {
 			CreatedAt: shared.NewUnixMilli(n.CreatedAt),
 			Linked:    n.Linked,
 		}
-		if err := db.AddNote(note); err != nil {
+		if err := store.AddNote(note); err != nil {
 			return err
 		}
 	}
@@ -326,7 +332,7 @@ func CreateNoteData() error {
 	return nil
 }
 
-func CreateUserData() error {
+func CreateUserData(store *db.Store) error {
 	oldUsers := []m.User{}
 	if err := readJSON("users.json", &oldUsers); err != nil {
 		panic("failed to read users: " + err.Error())
@@ -334,11 +340,18 @@ func CreateUserData() error {
 
 	for _, u := range oldUsers {
 		user := &shared.User{
-			ID:     shared.TelegramID(u.TelegramID),
-			Name:   u.Name,
-			ApiKey: u.ApiKey,
+			ID:   shared.TelegramID(u.TelegramID),
+			Name: u.Name,
+		}
+		if err := store.AddUser(user); err != nil {
+			return err
+		}
+
+		key, err := shared.NewApiKey(user.ID, "Standard", u.ApiKey, 0)
+		if err != nil {
+			return err
 		}
-		if err := db.AddUser(user); err != nil {
+		if err := store.AddApiKey(key); err != nil {
 			return err
 		}
 	}
@@ -347,7 +360,7 @@ func CreateUserData() error {
 }
 
 // CreateReportsData migrates trouble reports from old JSON data to the new SQL database.
-func CreateReportsData(images []string) error {
+func CreateReportsData(store *db.Store, images []string) error {
 	troubleReports := []m.TroubleReport{}
 	if err := readJSON("trouble-reports.json", &troubleReports); err != nil {
 		return err
@@ -361,7 +374,7 @@ func CreateReportsData(images []string) error {
 			LinkedAttachments: tr.NewLinkedAttachments,
 			UseMarkdown:       tr.UseMarkdown,
 		}
-		if err := db.AddTroubleReport(report); err != nil {
+		if err := store.AddTroubleReport(report); err != nil {
 			return err
 		}
 	}